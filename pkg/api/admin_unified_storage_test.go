@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+type fakeUnifiedStorageClient struct {
+	resource.ResourceClient
+}
+
+func (c *fakeUnifiedStorageClient) List(_ context.Context, _ *resource.ListRequest, _ ...grpc.CallOption) (*resource.ListResponse, error) {
+	return &resource.ListResponse{ResourceVersion: 1}, nil
+}
+
+func TestAPI_AdminUnifiedStorage_AccessControl(t *testing.T) {
+	tests := []struct {
+		desc         string
+		method       string
+		url          string
+		admin        bool
+		expectedCode int
+	}{
+		{
+			desc:         "backup is forbidden for users that are not Grafana admins",
+			method:       http.MethodGet,
+			url:          "/api/admin/unified-storage/backup?resource=playlist.grafana.app/playlists",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "restore is forbidden for users that are not Grafana admins",
+			method:       http.MethodPost,
+			url:          "/api/admin/unified-storage/restore",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "backup requires a resource",
+			method:       http.MethodGet,
+			url:          "/api/admin/unified-storage/backup",
+			admin:        true,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "backup rejects an invalid resource",
+			method:       http.MethodGet,
+			url:          "/api/admin/unified-storage/backup?resource=playlists",
+			admin:        true,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "restore rejects an unknown conflict policy",
+			method:       http.MethodPost,
+			url:          "/api/admin/unified-storage/restore?conflict=merge",
+			admin:        true,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "backup works for Grafana admins",
+			method:       http.MethodGet,
+			url:          "/api/admin/unified-storage/backup?resource=playlist.grafana.app/playlists",
+			admin:        true,
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := SetupAPITestServer(t, func(hs *HTTPServer) {
+				hs.Cfg = setting.NewCfg()
+				hs.unifiedStorageClient = &fakeUnifiedStorageClient{}
+			})
+
+			req := server.NewGetRequest(tt.url)
+			if tt.method == http.MethodPost {
+				req = server.NewPostRequest(tt.url, nil)
+			}
+			res, err := server.Send(webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+				OrgID:          1,
+				OrgRole:        org.RoleAdmin,
+				IsGrafanaAdmin: tt.admin,
+			}))
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedCode, res.StatusCode)
+			if tt.expectedCode == http.StatusOK {
+				require.Equal(t, "application/gzip", res.Header.Get("Content-Type"))
+			}
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/datamigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/secretsmigrations"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/unifiedstorage"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
 	"github.com/grafana/grafana/pkg/infra/db"
@@ -167,6 +168,57 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:  "unified-storage",
+		Usage: "Backs up and restores the resources stored in unified storage",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "backup",
+				Usage:  "Writes resources and their blobs to a gzipped tar archive.",
+				Action: runRunnerCommand(unifiedstorage.Backup),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "output",
+						Usage:    "Path of the archive to write",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "namespace",
+						Usage: "Only back up resources in this namespace",
+					},
+					&cli.StringSliceFlag{
+						Name:  "resource",
+						Usage: "group/resource to back up, can be repeated. Defaults to all resources",
+					},
+				},
+			},
+			{
+				Name:   "restore",
+				Usage:  "Restores resources and their blobs from an archive written by backup.",
+				Action: runRunnerCommand(unifiedstorage.Restore),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "input",
+						Usage:    "Path of the archive to read",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "conflict",
+						Usage: "What to do with resources that already exist: skip, overwrite or fail",
+						Value: "fail",
+					},
+					&cli.StringFlag{
+						Name:  "namespace",
+						Usage: "Only restore resources in this namespace",
+					},
+					&cli.StringSliceFlag{
+						Name:  "resource",
+						Usage: "group/resource to restore, can be repeated. Defaults to all resources in the archive",
+					},
+				},
+			},
+		},
+	},
 	{
 		Name:  "user-manager",
 		Usage: "Runs different helpful user commands",
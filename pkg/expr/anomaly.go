@@ -0,0 +1,118 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// AnomalyCommand is an expression command that detects outliers in, or
+// forecasts, the series of a query without the machine learning plugin.
+type AnomalyCommand struct {
+	VarToCheck string
+	Model      mathexp.AnomalyModel
+	Options    mathexp.AnomalyOptions
+	refID      string
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand from the query model.
+func NewAnomalyCommand(refID, varToCheck string, q AnomalyQuery) (*AnomalyCommand, error) {
+	opts := mathexp.DefaultAnomalyOptions()
+	opts.Window = q.Window
+	if q.Seasons != 0 {
+		opts.Seasons = q.Seasons
+	}
+	if q.Alpha != nil {
+		opts.Alpha = *q.Alpha
+	}
+	if q.Beta != nil {
+		opts.Beta = *q.Beta
+	}
+	if q.Gamma != nil {
+		opts.Gamma = *q.Gamma
+	}
+
+	var err error
+	if q.Season != "" {
+		if opts.Season, err = gtime.ParseDuration(q.Season); err != nil {
+			return nil, fmt.Errorf(`failed to parse anomaly "season" duration field %q: %w`, q.Season, err)
+		}
+	}
+	if q.Horizon != "" {
+		if opts.Horizon, err = gtime.ParseDuration(q.Horizon); err != nil {
+			return nil, fmt.Errorf(`failed to parse anomaly "horizon" duration field %q: %w`, q.Horizon, err)
+		}
+	}
+
+	model := mathexp.AnomalyModel(strings.ToLower(string(q.Model)))
+	if err := opts.Validate(model); err != nil {
+		return nil, fmt.Errorf("invalid anomaly command: %w", err)
+	}
+
+	return &AnomalyCommand{
+		VarToCheck: varToCheck,
+		Model:      model,
+		Options:    opts,
+		refID:      refID,
+	}, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	q := AnomalyQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the anomaly command: %w", err)
+	}
+	varToCheck := strings.TrimPrefix(q.Expression, "$")
+	if varToCheck == "" {
+		return nil, fmt.Errorf("no variable specified to reference for refId %v", rn.RefID)
+	}
+	return NewAnomalyCommand(rn.RefID, varToCheck, q)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AnomalyCommand) NeedsVars() []string {
+	return []string{ac.VarToCheck}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AnomalyCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteAnomaly")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("model", string(ac.Model)))
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[ac.VarToCheck].Values {
+		if val == nil {
+			continue
+		}
+		switch v := val.(type) {
+		case mathexp.Series:
+			s, err := v.Anomaly(ac.refID, ac.Model, ac.Options)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, s)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only detect anomalies in type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (ac *AnomalyCommand) Type() string {
+	return TypeAnomaly.String()
+}
@@ -0,0 +1,124 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func unmarshalAnomaly(t *testing.T, query string) (*AnomalyCommand, error) {
+	t.Helper()
+	qmap := make(map[string]any)
+	require.NoError(t, json.Unmarshal([]byte(query), &qmap))
+	return UnmarshalAnomalyCommand(&rawNode{
+		RefID:    "B",
+		Query:    qmap,
+		QueryRaw: []byte(query),
+	})
+}
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	t.Run("applies the defaults", func(t *testing.T) {
+		cmd, err := unmarshalAnomaly(t, `{"expression": "$A", "type": "anomaly", "model": "zscore"}`)
+		require.NoError(t, err)
+		require.Equal(t, []string{"A"}, cmd.NeedsVars())
+		require.Equal(t, mathexp.AnomalyModelZScore, cmd.Model)
+		require.Equal(t, mathexp.DefaultAnomalyOptions(), cmd.Options)
+	})
+
+	t.Run("reads the holt_winters settings", func(t *testing.T) {
+		cmd, err := unmarshalAnomaly(t, `{"expression": "A", "type": "anomaly", "model": "holt_winters",
+			"season": "1d", "horizon": "1h", "alpha": 0.2, "beta": 0, "gamma": 0.3}`)
+		require.NoError(t, err)
+		require.Equal(t, mathexp.AnomalyModelHoltWinters, cmd.Model)
+		require.Equal(t, 24*time.Hour, cmd.Options.Season)
+		require.Equal(t, time.Hour, cmd.Options.Horizon)
+		require.Equal(t, 0.2, cmd.Options.Alpha)
+		require.Equal(t, 0.0, cmd.Options.Beta)
+		require.Equal(t, 0.3, cmd.Options.Gamma)
+	})
+
+	testCases := []struct {
+		name          string
+		query         string
+		expectedError string
+	}{
+		{
+			name:          "missing expression",
+			query:         `{"type": "anomaly", "model": "zscore"}`,
+			expectedError: "no variable specified",
+		},
+		{
+			name:          "unknown model",
+			query:         `{"expression": "A", "type": "anomaly", "model": "prophet"}`,
+			expectedError: "unsupported anomaly model",
+		},
+		{
+			name:          "invalid season",
+			query:         `{"expression": "A", "type": "anomaly", "model": "seasonal", "season": "often"}`,
+			expectedError: `failed to parse anomaly "season" duration`,
+		},
+		{
+			name:          "seasonal without season",
+			query:         `{"expression": "A", "type": "anomaly", "model": "seasonal"}`,
+			expectedError: "season must be specified",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := unmarshalAnomaly(t, tc.query)
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestAnomalyCommand_Execute(t *testing.T) {
+	varToCheck := util.GenerateShortUID()
+	cmd, err := NewAnomalyCommand("B", varToCheck, AnomalyQuery{Model: mathexp.AnomalyModelMAD})
+	require.NoError(t, err)
+
+	var tests = []struct {
+		name         string
+		vals         mathexp.Value
+		isError      bool
+		expectedType parse.ReturnType
+	}{
+		{
+			name:         "should score when input Series",
+			vals:         mathexp.NewSeries(varToCheck, nil, 10),
+			expectedType: parse.TypeSeriesSet,
+		},
+		{
+			name:         "should return NoData when input NoData",
+			vals:         mathexp.NoData{},
+			expectedType: parse.TypeNoData,
+		},
+		{
+			name:    "should return error when input Number",
+			vals:    mathexp.NewNumber("test", nil),
+			isError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+				varToCheck: mathexp.Results{Values: mathexp.Values{test.vals}},
+			}, tracing.InitializeTracerForTest())
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, result.Values, 1)
+			require.Equal(t, test.expectedType, result.Values[0].Type())
+		})
+	}
+}
@@ -3,6 +3,9 @@ package classic
 import (
 	"math"
 	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
@@ -15,8 +18,11 @@ func (cr reducer) ValidReduceFunc() bool {
 		return true
 	case "diff", "diff_abs", "percent_diff", "percent_diff_abs", "count_non_null":
 		return true
+	case "first", "stddev", "increase", "rate":
+		return true
 	}
-	return false
+	_, ok := mathexp.ParsePercentile(mathexp.ReducerID(cr))
+	return ok
 }
 
 //nolint:gocyclo
@@ -144,6 +150,55 @@ func (cr reducer) Reduce(series mathexp.Series) mathexp.Number {
 		if value > 0 {
 			allNull = false
 		}
+	case "first":
+		for i := 0; i < ff.Len(); i++ {
+			f := ff.GetValue(i)
+			if !nilOrNaN(f) {
+				value = *f
+				allNull = false
+				break
+			}
+		}
+	case "stddev":
+		values := nonNullValues(ff)
+		if len(values) > 0 {
+			allNull = false
+			value = *mathexp.StdDev(float64Field(values))
+		}
+	case "increase":
+		values := nonNullValues(ff)
+		if len(values) > 0 {
+			allNull = false
+			value = *mathexp.Increase(float64Field(values))
+		}
+	case "rate":
+		var (
+			values      []*float64
+			first, last time.Time
+		)
+		for i := 0; i < ff.Len(); i++ {
+			f := ff.GetValue(i)
+			if nilOrNaN(f) {
+				continue
+			}
+			if len(values) == 0 {
+				first = series.GetTime(i)
+			}
+			last = series.GetTime(i)
+			values = append(values, f)
+		}
+		if rate := mathexp.Rate(float64Field(values), first, last); !math.IsNaN(*rate) {
+			allNull = false
+			value = *rate
+		}
+	default:
+		if p, ok := mathexp.ParsePercentile(mathexp.ReducerID(cr)); ok {
+			values := nonNullValues(ff)
+			if len(values) > 0 {
+				allNull = false
+				value = *mathexp.Percentile(p)(float64Field(values))
+			}
+		}
 	}
 
 	if allNull {
@@ -182,6 +237,22 @@ func calculateDiff(ff mathexp.Float64Field, allNull bool, value float64, fn func
 	return allNull, value
 }
 
+// nonNullValues returns the values of the field that are neither null nor NaN.
+func nonNullValues(ff mathexp.Float64Field) []*float64 {
+	values := make([]*float64, 0, ff.Len())
+	for i := 0; i < ff.Len(); i++ {
+		if f := ff.GetValue(i); !nilOrNaN(f) {
+			values = append(values, f)
+		}
+	}
+	return values
+}
+
+func float64Field(values []*float64) *mathexp.Float64Field {
+	ff := mathexp.Float64Field(*data.NewField("", nil, values))
+	return &ff
+}
+
 func nilOrNaN(f *float64) bool {
 	return f == nil || math.IsNaN(*f)
 }
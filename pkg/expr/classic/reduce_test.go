@@ -115,6 +115,43 @@ func TestReducer(t *testing.T) {
 			inputSeries:    newSeries(nil, nil),
 			expectedNumber: newNumber(nil),
 		},
+		{
+			name:           "first should ignore null values",
+			reducer:        reducer("first"),
+			inputSeries:    newSeries(nil, util.Pointer(2.0), util.Pointer(3.0)),
+			expectedNumber: newNumber(util.Pointer(2.0)),
+		},
+		{
+			name:    "stddev",
+			reducer: reducer("stddev"),
+			inputSeries: newSeries(util.Pointer(2.0), util.Pointer(4.0), util.Pointer(4.0), util.Pointer(4.0),
+				util.Pointer(5.0), util.Pointer(5.0), nil, util.Pointer(7.0), util.Pointer(9.0)),
+			expectedNumber: newNumber(util.Pointer(2.0)),
+		},
+		{
+			name:           "increase with counter reset",
+			reducer:        reducer("increase"),
+			inputSeries:    newSeries(util.Pointer(1.0), util.Pointer(3.0), util.Pointer(1.0), util.Pointer(4.0)),
+			expectedNumber: newNumber(util.Pointer(6.0)),
+		},
+		{
+			name:           "rate",
+			reducer:        reducer("rate"),
+			inputSeries:    newSeries(util.Pointer(0.0), util.Pointer(2.0), util.Pointer(4.0)),
+			expectedNumber: newNumber(util.Pointer(2.0)),
+		},
+		{
+			name:           "rate with a single value",
+			reducer:        reducer("rate"),
+			inputSeries:    newSeries(util.Pointer(1.0), nil),
+			expectedNumber: newNumber(nil),
+		},
+		{
+			name:           "percentile",
+			reducer:        reducer("p50"),
+			inputSeries:    newSeries(util.Pointer(4.0), util.Pointer(1.0), nil, util.Pointer(3.0), util.Pointer(2.0)),
+			expectedNumber: newNumber(util.Pointer(2.5)),
+		},
 	}
 
 	for _, tt := range tests {
@@ -126,6 +163,12 @@ func TestReducer(t *testing.T) {
 	}
 }
 
+func TestInvalidPercentileReducer(t *testing.T) {
+	for _, r := range []reducer{"p", "p101", "p-1", "pxx", "p80"} {
+		require.False(t, r.ValidReduceFunc(), r)
+	}
+}
+
 func TestDiffReducer(t *testing.T) {
 	var tests = []struct {
 		name           string
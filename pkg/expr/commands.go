@@ -217,16 +217,19 @@ type ResampleCommand struct {
 	Downsampler   mathexp.ReducerID
 	Upsampler     mathexp.Upsampler
 	TimeRange     TimeRange
+	Options       mathexp.ResampleOptions
 	refID         string
 }
 
 // NewResampleCommand creates a new ResampleCMD.
 func NewResampleCommand(refID, rawWindow, varToResample string, downsampler mathexp.ReducerID, upsampler mathexp.Upsampler, tr TimeRange) (*ResampleCommand, error) {
-	// TODO: validate reducer here, before execution
 	window, err := gtime.ParseDuration(rawWindow)
 	if err != nil {
 		return nil, fmt.Errorf(`failed to parse resample "window" duration field %q: %w`, window, err)
 	}
+	if _, err := mathexp.GetReduceFunc(downsampler); err != nil {
+		return nil, fmt.Errorf("invalid downsampler in resample command: %w", err)
+	}
 	return &ResampleCommand{
 		Window:        window,
 		VarToResample: varToResample,
@@ -280,11 +283,48 @@ func UnmarshalResampleCommand(rn *rawNode) (*ResampleCommand, error) {
 		return nil, fmt.Errorf("expected resample downsampler to be a string, got type %T", upsampler)
 	}
 
-	return NewResampleCommand(rn.RefID, window,
+	cmd, err := NewResampleCommand(rn.RefID, window,
 		varToResample,
 		mathexp.ReducerID(downsampler),
 		mathexp.Upsampler(upsampler),
 		rn.TimeRange)
+	if err != nil {
+		return nil, err
+	}
+
+	var fillValue *float64
+	if rawFill, ok := rn.Query["fillValue"]; ok && rawFill != nil {
+		fill, ok := rawFill.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected resample fillValue to be a number, got type %T", rawFill)
+		}
+		fillValue = &fill
+	}
+
+	alignToWindow := false
+	if rawAlign, ok := rn.Query["alignToWindow"]; ok && rawAlign != nil {
+		align, ok := rawAlign.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected resample alignToWindow to be a boolean, got type %T", rawAlign)
+		}
+		alignToWindow = align
+	}
+
+	if err := cmd.setOptions(fillValue, alignToWindow); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// setOptions sets the optional settings of the resample. The fill value is required by the constant upsampler.
+func (gr *ResampleCommand) setOptions(fillValue *float64, alignToWindow bool) error {
+	if fillValue != nil {
+		gr.Options.FillValue = *fillValue
+	} else if gr.Upsampler == mathexp.UpsamplerConstant {
+		return errors.New("fillValue must be specified when upsampler is 'constant'")
+	}
+	gr.Options.AlignToInterval = alignToWindow
+	return nil
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -306,7 +346,7 @@ func (gr *ResampleCommand) Execute(ctx context.Context, now time.Time, vars math
 		}
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Resample(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, timeRange.From, timeRange.To)
+			num, err := v.ResampleWithOptions(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, timeRange.From, timeRange.To, gr.Options)
 			if err != nil {
 				return newRes, err
 			}
@@ -343,6 +383,8 @@ const (
 	TypeThreshold
 	// TypeSQL is the CMDType for running SQL expressions
 	TypeSQL
+	// TypeAnomaly is the CMDType for anomaly detection and forecast expressions.
+	TypeAnomaly
 )
 
 func (gt CommandType) String() string {
@@ -359,6 +401,8 @@ func (gt CommandType) String() string {
 		return "threshold"
 	case TypeSQL:
 		return "sql"
+	case TypeAnomaly:
+		return "anomaly"
 	default:
 		return "unknown"
 	}
@@ -379,6 +423,8 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeThreshold, nil
 	case "sql":
 		return TypeSQL, nil
+	case "anomaly":
+		return TypeAnomaly, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -0,0 +1,239 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// ruleHeaderPrefix is the prefix of the headers that describe the alert rule
+// that issued a query. They differ between rules and are ignored when
+// comparing queries.
+const ruleHeaderPrefix = "http_X-Rule-"
+
+// QueryDeduplicator executes identical data source queries only once and
+// shares the response with every caller that issued them. It is meant to be
+// shared by expressions that are executed at the same time, such as the alert
+// rules evaluated in one scheduler tick, and to be discarded afterwards.
+//
+// Queries are identical when they target the same data source with the same
+// query model, time range, max data points and interval. Failed queries are
+// only shared with the callers that are waiting for them, so that a retry
+// queries the data source again.
+//
+// A query is not cancelled with the caller that started it, because other
+// callers might wait for its response. It is cancelled after the timeout of
+// the deduplicator instead.
+type QueryDeduplicator struct {
+	mtx     sync.Mutex
+	timeout time.Duration
+	queries map[string]*dedupQuery
+}
+
+type dedupQuery struct {
+	done  chan struct{}
+	refID string
+	resp  *backend.QueryDataResponse
+	err   error
+}
+
+// defaultDedupTimeout is the timeout of the shared queries if the
+// deduplicator is created without one.
+const defaultDedupTimeout = 30 * time.Second
+
+// NewQueryDeduplicator creates an empty QueryDeduplicator that cancels the
+// shared queries after the timeout.
+func NewQueryDeduplicator(timeout time.Duration) *QueryDeduplicator {
+	if timeout <= 0 {
+		timeout = defaultDedupTimeout
+	}
+	return &QueryDeduplicator{
+		timeout: timeout,
+		queries: make(map[string]*dedupQuery),
+	}
+}
+
+type queryDeduplicatorKey struct{}
+
+// WithQueryDeduplicator returns a context that makes the data source queries of
+// the expressions executed with it go through the deduplicator.
+func WithQueryDeduplicator(ctx context.Context, d *QueryDeduplicator) context.Context {
+	if d == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, queryDeduplicatorKey{}, d)
+}
+
+func queryDeduplicatorFromContext(ctx context.Context) *QueryDeduplicator {
+	d, _ := ctx.Value(queryDeduplicatorKey{}).(*QueryDeduplicator)
+	return d
+}
+
+// queryData executes the request, or waits for the identical request that is
+// already executed. It returns a copy of the response for the request's refID,
+// and whether the response was shared with another caller.
+func (d *QueryDeduplicator) queryData(ctx context.Context, handler backend.QueryDataHandler, ds *datasources.DataSource, req *backend.QueryDataRequest) (*backend.QueryDataResponse, bool, error) {
+	key, err := dedupKey(ds, req)
+	if err != nil || len(req.Queries) != 1 {
+		resp, err := handler.QueryData(ctx, req)
+		return resp, false, err
+	}
+	refID := req.Queries[0].RefID
+
+	d.mtx.Lock()
+	q, shared := d.queries[key]
+	if !shared {
+		q = &dedupQuery{done: make(chan struct{}), refID: refID}
+		d.queries[key] = q
+	}
+	d.mtx.Unlock()
+
+	if !shared {
+		go d.execute(ctx, key, q, handler, req)
+	}
+
+	select {
+	case <-q.done:
+	case <-ctx.Done():
+		return nil, shared, ctx.Err()
+	}
+
+	if q.err != nil {
+		return nil, shared, q.err
+	}
+	return copyResponse(q.resp, q.refID, refID), shared, nil
+}
+
+// execute runs the shared query with the values of the context of the caller
+// that started it, but not its cancellation.
+func (d *QueryDeduplicator) execute(ctx context.Context, key string, q *dedupQuery, handler backend.QueryDataHandler, req *backend.QueryDataRequest) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), d.timeout)
+	defer cancel()
+
+	q.resp, q.err = handler.QueryData(ctx, req)
+	if q.err != nil || hasResponseErrors(q.resp) {
+		d.mtx.Lock()
+		delete(d.queries, key)
+		d.mtx.Unlock()
+	}
+	close(q.done)
+}
+
+func dedupKey(ds *datasources.DataSource, req *backend.QueryDataRequest) (string, error) {
+	headers := make([]string, 0, len(req.Headers))
+	for k, v := range req.Headers {
+		if strings.HasPrefix(k, ruleHeaderPrefix) {
+			continue
+		}
+		headers = append(headers, k+"="+v)
+	}
+	sort.Strings(headers)
+
+	key := struct {
+		OrgID   int64
+		UID     string
+		Type    string
+		Version int
+		Headers []string
+		Queries []dedupKeyQuery
+	}{
+		OrgID:   ds.OrgID,
+		UID:     ds.UID,
+		Type:    ds.Type,
+		Version: ds.Version,
+		Headers: headers,
+	}
+	for _, q := range req.Queries {
+		key.Queries = append(key.Queries, dedupKeyQuery{
+			QueryType:     q.QueryType,
+			MaxDataPoints: q.MaxDataPoints,
+			Interval:      q.Interval,
+			From:          q.TimeRange.From.UnixNano(),
+			To:            q.TimeRange.To.UnixNano(),
+			JSON:          q.JSON,
+		})
+	}
+	b, err := json.Marshal(key)
+	return string(b), err
+}
+
+type dedupKeyQuery struct {
+	QueryType     string
+	MaxDataPoints int64
+	Interval      time.Duration
+	From, To      int64
+	JSON          json.RawMessage
+}
+
+func hasResponseErrors(resp *backend.QueryDataResponse) bool {
+	if resp == nil {
+		return false
+	}
+	for _, r := range resp.Responses {
+		if r.Error != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// copyResponse returns a deep copy of the response, in which the response of
+// fromRefID is moved to toRefID. Conversion modifies the frames of a response,
+// therefore every caller gets its own copy.
+func copyResponse(resp *backend.QueryDataResponse, fromRefID, toRefID string) *backend.QueryDataResponse {
+	if resp == nil {
+		return nil
+	}
+	c := backend.NewQueryDataResponse()
+	for refID, r := range resp.Responses {
+		frames := make(data.Frames, 0, len(r.Frames))
+		for _, f := range r.Frames {
+			nf := copyFrame(f)
+			if nf.RefID == fromRefID {
+				nf.RefID = toRefID
+			}
+			frames = append(frames, nf)
+		}
+		r.Frames = frames
+		if refID == fromRefID {
+			refID = toRefID
+		}
+		c.Responses[refID] = r
+	}
+	return c
+}
+
+func copyFrame(f *data.Frame) *data.Frame {
+	c := data.NewFrame(f.Name)
+	c.RefID = f.RefID
+	if f.Meta != nil {
+		meta := *f.Meta
+		meta.Notices = append([]data.Notice(nil), f.Meta.Notices...)
+		meta.Stats = append([]data.QueryStat(nil), f.Meta.Stats...)
+		c.Meta = &meta
+	}
+	for _, field := range f.Fields {
+		nf := data.NewFieldFromFieldType(field.Type(), field.Len())
+		nf.Name = field.Name
+		if field.Labels != nil {
+			nf.Labels = field.Labels.Copy()
+		}
+		if field.Config != nil {
+			config := *field.Config
+			nf.Config = &config
+		}
+		for i := 0; i < field.Len(); i++ {
+			nf.Set(i, field.CopyAt(i))
+		}
+		c.Fields = append(c.Fields, nf)
+	}
+	return c
+}
@@ -0,0 +1,220 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+type countingEndpoint struct {
+	mtx     sync.Mutex
+	calls   int
+	release chan struct{}
+	resp    func(refID string) backend.DataResponse
+	err     error
+	// ctxErr is the error of the context of the last query when it returned
+	ctxErr error
+}
+
+func (e *countingEndpoint) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	e.mtx.Lock()
+	e.calls++
+	e.mtx.Unlock()
+	if e.release != nil {
+		select {
+		case <-e.release:
+		case <-ctx.Done():
+		}
+	}
+	e.mtx.Lock()
+	e.ctxErr = ctx.Err()
+	e.mtx.Unlock()
+	if e.ctxErr != nil {
+		return nil, e.ctxErr
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		resp.Responses[q.RefID] = e.resp(q.RefID)
+	}
+	return resp, nil
+}
+
+func (e *countingEndpoint) count() int {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	return e.calls
+}
+
+func framesResponse(refID string) backend.DataResponse {
+	frame := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+		data.NewField("value", data.Labels{"job": "api"}, []*float64{fp(2)}))
+	frame.RefID = refID
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+func dedupRequest(refID, query string, headers map[string]string) *backend.QueryDataRequest {
+	return &backend.QueryDataRequest{
+		Queries: []backend.DataQuery{{
+			RefID:     refID,
+			JSON:      json.RawMessage(query),
+			TimeRange: backend.TimeRange{From: time.Unix(0, 0), To: time.Unix(60, 0)},
+		}},
+		Headers: headers,
+	}
+}
+
+func TestQueryDeduplicator(t *testing.T) {
+	ds := &datasources.DataSource{OrgID: 1, UID: "prom", Type: datasources.DS_PROMETHEUS}
+
+	t.Run("identical queries are executed once", func(t *testing.T) {
+		endpoint := &countingEndpoint{release: make(chan struct{}), resp: framesResponse}
+		d := NewQueryDeduplicator(time.Minute)
+
+		const callers = 5
+		var wg sync.WaitGroup
+		results := make([]*backend.QueryDataResponse, callers)
+		shared := make([]bool, callers)
+		errs := make([]error, callers)
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				refID := string(rune('A' + i))
+				headers := map[string]string{"FromAlert": "true", "http_X-Rule-Uid": refID}
+				results[i], shared[i], errs[i] = d.queryData(context.Background(), endpoint, ds, dedupRequest(refID, `{"expr":"up"}`, headers))
+			}(i)
+		}
+		require.Eventually(t, func() bool { return endpoint.count() == 1 }, time.Second, time.Millisecond)
+		close(endpoint.release)
+		wg.Wait()
+
+		require.Equal(t, 1, endpoint.count())
+		sharedCount := 0
+		for i, resp := range results {
+			require.NoError(t, errs[i])
+			refID := string(rune('A' + i))
+			require.Len(t, resp.Responses, 1)
+			frames := resp.Responses[refID].Frames
+			require.Len(t, frames, 1)
+			require.Equal(t, refID, frames[0].RefID)
+			if shared[i] {
+				sharedCount++
+			}
+		}
+		require.Equal(t, callers-1, sharedCount)
+
+		// every caller gets its own copy of the frames
+		results[0].Responses["A"].Frames[0].Fields[1].Labels["job"] = "changed"
+		require.Equal(t, "api", results[1].Responses["B"].Frames[0].Fields[1].Labels["job"])
+	})
+
+	t.Run("different queries are executed separately", func(t *testing.T) {
+		endpoint := &countingEndpoint{resp: framesResponse}
+		d := NewQueryDeduplicator(time.Minute)
+
+		_, _, err := d.queryData(context.Background(), endpoint, ds, dedupRequest("A", `{"expr":"up"}`, nil))
+		require.NoError(t, err)
+		_, shared, err := d.queryData(context.Background(), endpoint, ds, dedupRequest("A", `{"expr":"down"}`, nil))
+		require.NoError(t, err)
+		require.False(t, shared)
+
+		other := &datasources.DataSource{OrgID: 2, UID: "prom", Type: datasources.DS_PROMETHEUS}
+		_, shared, err = d.queryData(context.Background(), endpoint, other, dedupRequest("A", `{"expr":"up"}`, nil))
+		require.NoError(t, err)
+		require.False(t, shared)
+
+		require.Equal(t, 3, endpoint.count())
+	})
+
+	t.Run("failed queries are executed again", func(t *testing.T) {
+		endpoint := &countingEndpoint{err: errors.New("unavailable")}
+		d := NewQueryDeduplicator(time.Minute)
+
+		_, _, err := d.queryData(context.Background(), endpoint, ds, dedupRequest("A", `{"expr":"up"}`, nil))
+		require.ErrorContains(t, err, "unavailable")
+
+		endpoint.err = nil
+		endpoint.resp = func(refID string) backend.DataResponse {
+			return backend.DataResponse{Error: errors.New("bad query")}
+		}
+		_, shared, err := d.queryData(context.Background(), endpoint, ds, dedupRequest("A", `{"expr":"up"}`, nil))
+		require.NoError(t, err)
+		require.False(t, shared)
+
+		endpoint.resp = framesResponse
+		resp, shared, err := d.queryData(context.Background(), endpoint, ds, dedupRequest("A", `{"expr":"up"}`, nil))
+		require.NoError(t, err)
+		require.False(t, shared)
+		require.NoError(t, resp.Responses["A"].Error)
+		require.Equal(t, 3, endpoint.count())
+	})
+
+	t.Run("query is not cancelled with the caller that started it", func(t *testing.T) {
+		endpoint := &countingEndpoint{release: make(chan struct{}), resp: framesResponse}
+		d := NewQueryDeduplicator(time.Minute)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		leaderErr := make(chan error)
+		go func() {
+			_, _, err := d.queryData(ctx, endpoint, ds, dedupRequest("A", `{"expr":"up"}`, nil))
+			leaderErr <- err
+		}()
+		require.Eventually(t, func() bool { return endpoint.count() == 1 }, time.Second, time.Millisecond)
+		cancel()
+		require.ErrorIs(t, <-leaderErr, context.Canceled)
+
+		type result struct {
+			resp   *backend.QueryDataResponse
+			shared bool
+			err    error
+		}
+		follower := make(chan result)
+		go func() {
+			resp, shared, err := d.queryData(context.Background(), endpoint, ds, dedupRequest("B", `{"expr":"up"}`, nil))
+			follower <- result{resp: resp, shared: shared, err: err}
+		}()
+		close(endpoint.release)
+
+		res := <-follower
+		require.NoError(t, res.err)
+		require.True(t, res.shared)
+		require.Len(t, res.resp.Responses["B"].Frames, 1)
+		require.Equal(t, 1, endpoint.count())
+		require.NoError(t, endpoint.ctxErr)
+	})
+
+	t.Run("query is cancelled after the timeout", func(t *testing.T) {
+		endpoint := &countingEndpoint{release: make(chan struct{}), resp: framesResponse}
+		d := NewQueryDeduplicator(10 * time.Millisecond)
+
+		_, _, err := d.queryData(context.Background(), endpoint, ds, dedupRequest("A", `{"expr":"up"}`, nil))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorIs(t, endpoint.ctxErr, context.DeadlineExceeded)
+
+		// the failed query is not shared with the next caller
+		close(endpoint.release)
+		_, shared, err := d.queryData(context.Background(), endpoint, ds, dedupRequest("A", `{"expr":"up"}`, nil))
+		require.NoError(t, err)
+		require.False(t, shared)
+	})
+
+	t.Run("deduplicator is read from the context", func(t *testing.T) {
+		require.Nil(t, queryDeduplicatorFromContext(context.Background()))
+		require.Nil(t, queryDeduplicatorFromContext(WithQueryDeduplicator(context.Background(), nil)))
+		d := NewQueryDeduplicator(time.Minute)
+		require.Same(t, d, queryDeduplicatorFromContext(WithQueryDeduplicator(context.Background(), d)))
+	})
+}
@@ -0,0 +1,352 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// The anomaly detection or forecasting model
+// +enum
+type AnomalyModel string
+
+const (
+	// Number of standard deviations from the mean
+	AnomalyModelZScore AnomalyModel = "zscore"
+
+	// Modified z-score based on the median absolute deviation
+	AnomalyModelMAD AnomalyModel = "mad"
+
+	// Holt-Winters (triple exponential smoothing) forecast
+	AnomalyModelHoltWinters AnomalyModel = "holt_winters"
+
+	// Difference from the average of the same point in previous seasons
+	AnomalyModelSeasonal AnomalyModel = "seasonal"
+)
+
+// madScale makes the median absolute deviation consistent with the standard
+// deviation of normally distributed data (Iglewicz and Hoaglin).
+const madScale = 0.6745
+
+// AnomalyOptions are the settings of the anomaly and forecast models.
+type AnomalyOptions struct {
+	// Window is the number of points before each point used as its baseline by
+	// the zscore and mad models. When zero the whole series is the baseline.
+	Window int
+
+	// Season is the length of a season for the holt_winters and seasonal models.
+	Season time.Duration
+
+	// Seasons is the number of previous seasons averaged by the seasonal model.
+	Seasons int
+
+	// Alpha, Beta and Gamma are the smoothing factors of the level, trend and
+	// seasonal components of the holt_winters model.
+	Alpha, Beta, Gamma float64
+
+	// Horizon is how far past the last point the holt_winters model forecasts.
+	// It must not span more points than the series has.
+	Horizon time.Duration
+}
+
+// DefaultAnomalyOptions returns the options used for settings that are not set.
+func DefaultAnomalyOptions() AnomalyOptions {
+	return AnomalyOptions{
+		Seasons: 1,
+		Alpha:   0.5,
+		Beta:    0.1,
+		Gamma:   0.1,
+	}
+}
+
+// Validate checks that the options can be used with the model.
+func (o AnomalyOptions) Validate(model AnomalyModel) error {
+	switch model {
+	case AnomalyModelZScore, AnomalyModelMAD:
+		if o.Window < 0 {
+			return fmt.Errorf("window must not be negative, got %d", o.Window)
+		}
+	case AnomalyModelHoltWinters:
+		factors := []struct {
+			name  string
+			value float64
+		}{{"alpha", o.Alpha}, {"beta", o.Beta}, {"gamma", o.Gamma}}
+		for _, f := range factors {
+			if f.value < 0 || f.value > 1 {
+				return fmt.Errorf("%s must be between 0 and 1, got %v", f.name, f.value)
+			}
+		}
+		if o.Season < 0 || o.Horizon < 0 {
+			return fmt.Errorf("season and horizon must not be negative")
+		}
+	case AnomalyModelSeasonal:
+		if o.Season <= 0 {
+			return fmt.Errorf("season must be specified for the %s model", model)
+		}
+		if o.Seasons < 1 {
+			return fmt.Errorf("seasons must be at least 1, got %d", o.Seasons)
+		}
+	default:
+		return fmt.Errorf("unsupported anomaly model %q", model)
+	}
+	return nil
+}
+
+// Anomaly applies the model to the series. The zscore and mad models return the
+// score of each point, holt_winters returns the fitted values followed by the
+// forecast up to the horizon, and seasonal returns the difference of each point
+// from its seasonal baseline. Null values are ignored by all models.
+func (s Series) Anomaly(refID string, model AnomalyModel, opts AnomalyOptions) (Series, error) {
+	if err := opts.Validate(model); err != nil {
+		return s, err
+	}
+	pts := nonNullPoints(s)
+	var out []anomalyPoint
+	var err error
+	switch model {
+	case AnomalyModelZScore:
+		out = trailingScores(pts, opts.Window, zScorer)
+	case AnomalyModelMAD:
+		out = trailingScores(pts, opts.Window, madScorer)
+	case AnomalyModelHoltWinters:
+		out, err = holtWinters(pts, opts)
+	case AnomalyModelSeasonal:
+		out = seasonalDiff(pts, opts)
+	}
+	if err != nil {
+		return s, err
+	}
+
+	newSeries := NewSeries(refID, s.GetLabels(), len(out))
+	for i, p := range out {
+		newSeries.SetPoint(i, p.t, p.v)
+	}
+	return newSeries, nil
+}
+
+type anomalyPoint struct {
+	t time.Time
+	v *float64
+}
+
+type timedValue struct {
+	t time.Time
+	v float64
+}
+
+// nonNullPoints returns the non-null points of the series sorted by time.
+func nonNullPoints(s Series) []timedValue {
+	pts := make([]timedValue, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		if f == nil || math.IsNaN(*f) {
+			continue
+		}
+		pts = append(pts, timedValue{t: t, v: *f})
+	}
+	sort.SliceStable(pts, func(i, j int) bool { return pts[i].t.Before(pts[j].t) })
+	return pts
+}
+
+func pointValues(pts []timedValue) []float64 {
+	vals := make([]float64, len(pts))
+	for i, p := range pts {
+		vals[i] = p.v
+	}
+	return vals
+}
+
+// scorer returns a function that scores a value against the baseline.
+type scorer func(baseline []float64) func(v float64) float64
+
+// trailingScores scores every point against the window of points before it, or
+// against the whole series when window is zero. Points without at least two
+// points in their baseline are null.
+func trailingScores(pts []timedValue, window int, newScore scorer) []anomalyPoint {
+	vals := pointValues(pts)
+	out := make([]anomalyPoint, len(pts))
+	var score func(v float64) float64
+	if window == 0 && len(vals) >= 2 {
+		// the baseline is the same for every point
+		score = newScore(vals)
+	}
+	for i, p := range pts {
+		out[i].t = p.t
+		if window > 0 {
+			baseline := vals[max(0, i-window):i]
+			if len(baseline) < 2 {
+				continue
+			}
+			score = newScore(baseline)
+		}
+		if score == nil {
+			continue
+		}
+		f := score(p.v)
+		out[i].v = &f
+	}
+	return out
+}
+
+func zScorer(baseline []float64) func(v float64) float64 {
+	mean := meanOf(baseline)
+	variance := 0.0
+	for _, b := range baseline {
+		variance += (b - mean) * (b - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(baseline)))
+	return func(v float64) float64 {
+		return deviations(v-mean, stddev)
+	}
+}
+
+func madScorer(baseline []float64) func(v float64) float64 {
+	median := medianOf(baseline)
+	dev := make([]float64, len(baseline))
+	for i, b := range baseline {
+		dev[i] = math.Abs(b - median)
+	}
+	mad := medianOf(dev)
+	return func(v float64) float64 {
+		return deviations(madScale*(v-median), mad)
+	}
+}
+
+// deviations returns diff/spread, and +/-Inf when a non-zero difference is
+// measured against a baseline without any spread.
+func deviations(diff, spread float64) float64 {
+	if spread == 0 {
+		if diff == 0 {
+			return 0
+		}
+		return math.Inf(int(math.Copysign(1, diff)))
+	}
+	return diff / spread
+}
+
+func medianOf(vals []float64) float64 {
+	sorted := make([]float64, len(vals))
+	copy(sorted, vals)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// medianInterval returns the median interval between the points.
+func medianInterval(pts []timedValue) time.Duration {
+	intervals := make([]float64, 0, len(pts)-1)
+	for i := 1; i < len(pts); i++ {
+		intervals = append(intervals, float64(pts[i].t.Sub(pts[i-1].t)))
+	}
+	return time.Duration(medianOf(intervals))
+}
+
+// holtWinters fits an additive Holt-Winters model to the points and returns the
+// one step ahead fitted value of every point after the first season, followed by
+// the forecast up to the horizon. Without a season it is Holt's linear trend model.
+func holtWinters(pts []timedValue, opts AnomalyOptions) ([]anomalyPoint, error) {
+	if len(pts) < 2 {
+		return nil, nil
+	}
+	interval := medianInterval(pts)
+	if interval <= 0 {
+		return nil, fmt.Errorf("holt_winters: the series has duplicate timestamps")
+	}
+
+	seasonLen := int(opts.Season / interval)
+	if opts.Season > 0 && seasonLen < 2 {
+		return nil, fmt.Errorf("holt_winters: the season %v must span at least two points of the series interval %v", opts.Season, interval)
+	}
+
+	var level, trend float64
+	var seasonal []float64
+	start := 1
+	if seasonLen > 0 {
+		if len(pts) < 2*seasonLen {
+			return nil, fmt.Errorf("holt_winters: at least two seasons (%d points) are required, got %d points", 2*seasonLen, len(pts))
+		}
+		vals := pointValues(pts)
+		first, second := meanOf(vals[:seasonLen]), meanOf(vals[seasonLen:2*seasonLen])
+		level = first
+		trend = (second - first) / float64(seasonLen)
+		seasonal = make([]float64, seasonLen)
+		for i := range seasonal {
+			seasonal[i] = vals[i] - first
+		}
+		start = seasonLen
+	} else {
+		level = pts[0].v
+		trend = pts[1].v - pts[0].v
+	}
+
+	season := func(i int) float64 {
+		if seasonLen == 0 {
+			return 0
+		}
+		return seasonal[i%seasonLen]
+	}
+
+	horizon := int(opts.Horizon / interval)
+	if horizon > len(pts) {
+		return nil, fmt.Errorf("holt_winters: the horizon %v spans %d points of the series interval %v, at most %d points of the series are allowed", opts.Horizon, horizon, interval, len(pts))
+	}
+	out := make([]anomalyPoint, 0, len(pts)-start+horizon)
+	for i := start; i < len(pts); i++ {
+		fitted := level + trend + season(i)
+		out = append(out, anomalyPoint{t: pts[i].t, v: &fitted})
+
+		x := pts[i].v
+		prevLevel := level
+		level = opts.Alpha*(x-season(i)) + (1-opts.Alpha)*(level+trend)
+		trend = opts.Beta*(level-prevLevel) + (1-opts.Beta)*trend
+		if seasonLen > 0 {
+			seasonal[i%seasonLen] = opts.Gamma*(x-level) + (1-opts.Gamma)*seasonal[i%seasonLen]
+		}
+	}
+
+	last := pts[len(pts)-1]
+	for h := 1; h <= horizon; h++ {
+		forecast := level + float64(h)*trend + season(len(pts)-1+h)
+		out = append(out, anomalyPoint{t: last.t.Add(time.Duration(h) * interval), v: &forecast})
+	}
+	return out, nil
+}
+
+func meanOf(vals []float64) float64 {
+	sum := 0.0
+	for _, v := range vals {
+		sum += v
+	}
+	return sum / float64(len(vals))
+}
+
+// seasonalDiff returns the difference of every point from the average of the
+// points at the same time in the previous seasons. Points without any previous
+// season are null.
+func seasonalDiff(pts []timedValue, opts AnomalyOptions) []anomalyPoint {
+	byTime := make(map[int64]float64, len(pts))
+	for _, p := range pts {
+		byTime[p.t.UnixNano()] = p.v
+	}
+	out := make([]anomalyPoint, len(pts))
+	for i, p := range pts {
+		out[i].t = p.t
+		sum, n := 0.0, 0
+		for k := 1; k <= opts.Seasons; k++ {
+			if v, ok := byTime[p.t.Add(-time.Duration(k)*opts.Season).UnixNano()]; ok {
+				sum += v
+				n++
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		diff := p.v - sum/float64(n)
+		out[i].v = &diff
+	}
+	return out
+}
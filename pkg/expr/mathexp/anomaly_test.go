@@ -0,0 +1,188 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func secondsSeries(values ...*float64) Series {
+	points := make([]tp, len(values))
+	for i, v := range values {
+		points[i] = tp{time.Unix(int64(i), 0), v}
+	}
+	return makeSeries("", nil, points...)
+}
+
+func TestSeriesAnomaly(t *testing.T) {
+	f := float64Pointer
+	defaults := DefaultAnomalyOptions()
+	withOpts := func(fn func(o *AnomalyOptions)) AnomalyOptions {
+		o := defaults
+		fn(&o)
+		return o
+	}
+
+	var tests = []struct {
+		name     string
+		model    AnomalyModel
+		opts     AnomalyOptions
+		input    Series
+		expected []tp
+	}{
+		{
+			name:  "zscore against the whole series",
+			model: AnomalyModelZScore,
+			opts:  defaults,
+			input: secondsSeries(f(1), f(1), f(1), f(1), f(11)),
+			expected: []tp{
+				{time.Unix(0, 0), f(-0.5)},
+				{time.Unix(1, 0), f(-0.5)},
+				{time.Unix(2, 0), f(-0.5)},
+				{time.Unix(3, 0), f(-0.5)},
+				{time.Unix(4, 0), f(2)},
+			},
+		},
+		{
+			name:  "zscore against a trailing window",
+			model: AnomalyModelZScore,
+			opts:  withOpts(func(o *AnomalyOptions) { o.Window = 2 }),
+			input: secondsSeries(f(1), f(3), f(2), f(10)),
+			expected: []tp{
+				{time.Unix(0, 0), nil},
+				{time.Unix(1, 0), nil},
+				{time.Unix(2, 0), f(0)},
+				{time.Unix(3, 0), f(15)},
+			},
+		},
+		{
+			name:  "mad ignores null values",
+			model: AnomalyModelMAD,
+			opts:  defaults,
+			input: secondsSeries(f(1), f(2), nil, f(3), f(4), f(100)),
+			expected: []tp{
+				{time.Unix(0, 0), f(-1.349)},
+				{time.Unix(1, 0), f(-0.6745)},
+				{time.Unix(3, 0), f(0)},
+				{time.Unix(4, 0), f(0.6745)},
+				{time.Unix(5, 0), f(65.4265)},
+			},
+		},
+		{
+			name:  "holt_winters without a season follows the trend",
+			model: AnomalyModelHoltWinters,
+			opts:  withOpts(func(o *AnomalyOptions) { o.Horizon = 2 * time.Second }),
+			input: secondsSeries(f(1), f(2), f(3), f(4)),
+			expected: []tp{
+				{time.Unix(1, 0), f(2)},
+				{time.Unix(2, 0), f(3)},
+				{time.Unix(3, 0), f(4)},
+				{time.Unix(4, 0), f(5)},
+				{time.Unix(5, 0), f(6)},
+			},
+		},
+		{
+			name:  "holt_winters with a season",
+			model: AnomalyModelHoltWinters,
+			opts: withOpts(func(o *AnomalyOptions) {
+				o.Season = 2 * time.Second
+				o.Horizon = 2 * time.Second
+			}),
+			input: secondsSeries(f(0), f(10), f(0), f(10), f(0), f(10)),
+			expected: []tp{
+				{time.Unix(2, 0), f(0)},
+				{time.Unix(3, 0), f(10)},
+				{time.Unix(4, 0), f(0)},
+				{time.Unix(5, 0), f(10)},
+				{time.Unix(6, 0), f(0)},
+				{time.Unix(7, 0), f(10)},
+			},
+		},
+		{
+			name:  "seasonal difference from the previous season",
+			model: AnomalyModelSeasonal,
+			opts:  withOpts(func(o *AnomalyOptions) { o.Season = 2 * time.Second }),
+			input: secondsSeries(f(1), f(2), f(4), f(6)),
+			expected: []tp{
+				{time.Unix(0, 0), nil},
+				{time.Unix(1, 0), nil},
+				{time.Unix(2, 0), f(3)},
+				{time.Unix(3, 0), f(4)},
+			},
+		},
+		{
+			name:  "seasonal averages several seasons",
+			model: AnomalyModelSeasonal,
+			opts: withOpts(func(o *AnomalyOptions) {
+				o.Season = time.Second
+				o.Seasons = 2
+			}),
+			input: secondsSeries(f(2), f(4), f(9)),
+			expected: []tp{
+				{time.Unix(0, 0), nil},
+				{time.Unix(1, 0), f(2)},
+				{time.Unix(2, 0), f(6)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.input.Anomaly("A", tt.model, tt.opts)
+			require.NoError(t, err)
+			require.Equal(t, len(tt.expected), result.Len())
+			for i, exp := range tt.expected {
+				ts, v := result.GetPoint(i)
+				assert.Equal(t, exp.t, ts)
+				if exp.f == nil {
+					assert.Nil(t, v)
+					continue
+				}
+				require.NotNil(t, v)
+				assert.InDelta(t, *exp.f, *v, 1e-9)
+			}
+		})
+	}
+}
+
+func TestSeriesAnomalyErrors(t *testing.T) {
+	f := float64Pointer
+	input := secondsSeries(f(1), f(2), f(3))
+
+	t.Run("holt_winters requires two seasons of data", func(t *testing.T) {
+		opts := DefaultAnomalyOptions()
+		opts.Season = 2 * time.Second
+		_, err := input.Anomaly("A", AnomalyModelHoltWinters, opts)
+		require.ErrorContains(t, err, "at least two seasons")
+	})
+
+	t.Run("holt_winters horizon must not span more points than the series", func(t *testing.T) {
+		opts := DefaultAnomalyOptions()
+		opts.Horizon = 3 * time.Second
+		_, err := input.Anomaly("A", AnomalyModelHoltWinters, opts)
+		require.NoError(t, err)
+
+		opts.Horizon = 24 * time.Hour
+		_, err = input.Anomaly("A", AnomalyModelHoltWinters, opts)
+		require.ErrorContains(t, err, "at most 3 points of the series are allowed")
+	})
+
+	t.Run("seasonal requires a season", func(t *testing.T) {
+		_, err := input.Anomaly("A", AnomalyModelSeasonal, DefaultAnomalyOptions())
+		require.ErrorContains(t, err, "season must be specified")
+	})
+
+	t.Run("smoothing factors must be between 0 and 1", func(t *testing.T) {
+		opts := DefaultAnomalyOptions()
+		opts.Beta = 2
+		_, err := input.Anomaly("A", AnomalyModelHoltWinters, opts)
+		require.ErrorContains(t, err, "beta must be between 0 and 1")
+	})
+
+	t.Run("unknown model", func(t *testing.T) {
+		_, err := input.Anomaly("A", "prophet", DefaultAnomalyOptions())
+		require.ErrorContains(t, err, "unsupported anomaly model")
+	})
+}
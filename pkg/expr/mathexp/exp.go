@@ -203,39 +203,22 @@ type Union struct {
 // operations. The labels of the Union will the taken from result with a greater
 // number of tags.
 func (e *State) union(aResults, bResults Results, biNode *parse.BinaryNode) []*Union {
+	return e.unionNamed(aResults, bResults, biNode.String(), biNode.Args[0].String(), biNode.Args[1].String())
+}
+
+// unionNamed is like union, but takes the names used to report dropped items
+// directly, for callers that do not have a binary node such as functions.
+func (e *State) unionNamed(aResults, bResults Results, nodeText, aVar, bVar string) []*Union {
 	unions := []*Union{}
 	appendUnions := func(u *Union) {
 		unions = append(unions, u)
 	}
 
-	aVar := biNode.Args[0].String()
-	bVar := biNode.Args[1].String()
-
 	aMatched := make([]bool, len(aResults.Values))
 	bMatched := make([]bool, len(bResults.Values))
 	collectDrops := func() {
-		check := func(v string, matchArray []bool, r *Results) {
-			for i, b := range matchArray {
-				if b {
-					continue
-				}
-				if e.Drops == nil {
-					e.Drops = make(map[string]map[string][]data.Labels)
-				}
-				if e.Drops[biNode.String()] == nil {
-					e.Drops[biNode.String()] = make(map[string][]data.Labels)
-				}
-
-				if r.Values[i].Type() == parse.TypeNoData {
-					continue
-				}
-
-				e.DropCount++
-				e.Drops[biNode.String()][v] = append(e.Drops[biNode.String()][v], r.Values[i].GetLabels())
-			}
-		}
-		check(aVar, aMatched, &aResults)
-		check(bVar, bMatched, &bResults)
+		e.collectDrops(nodeText, aVar, aMatched, aResults)
+		e.collectDrops(nodeText, bVar, bMatched, bResults)
 	}
 
 	aValueLen := len(aResults.Values)
@@ -306,6 +289,163 @@ func (e *State) union(aResults, bResults Results, biNode *parse.BinaryNode) []*U
 	return unions
 }
 
+// collectDrops records the items of r that were not matched in a union, so
+// they can be reported in a notice.
+func (e *State) collectDrops(nodeText, v string, matched []bool, r Results) {
+	for i, b := range matched {
+		if b {
+			continue
+		}
+		if e.Drops == nil {
+			e.Drops = make(map[string]map[string][]data.Labels)
+		}
+		if e.Drops[nodeText] == nil {
+			e.Drops[nodeText] = make(map[string][]data.Labels)
+		}
+
+		if r.Values[i].Type() == parse.TypeNoData {
+			continue
+		}
+
+		e.DropCount++
+		e.Drops[nodeText][v] = append(e.Drops[nodeText][v], r.Values[i].GetLabels())
+	}
+}
+
+// matchUnion creates Union objects for a binary operation with label matching
+// modifiers. Two items join when their labels are equal on the matched labels:
+// the listed labels with on(), or all but the listed labels with ignoring().
+// Unlike union, ambiguous matches are an error: a side can only join several
+// items to one item on the other side when asked to with group_left or group_right.
+func (e *State) matchUnion(aResults, bResults Results, biNode *parse.BinaryNode) ([]*Union, error) {
+	// Scalars and no data carry no labels and join to anything.
+	if !hasOnlyLabeled(aResults) || !hasOnlyLabeled(bResults) {
+		return e.union(aResults, bResults, biNode), nil
+	}
+
+	m := biNode.Matching
+	// The "one" side may only hold a single item per match group. With
+	// one-to-one matching both sides are checked.
+	one, many, oneSide, manySide := bResults, aResults, "right", "left"
+	if m.Card == parse.CardOneToMany {
+		one, many, oneSide, manySide = aResults, bResults, "left", "right"
+	}
+
+	oneIdx := make(map[string]int, len(one.Values))
+	for i, v := range one.Values {
+		sig := matchSignature(v.GetLabels(), m)
+		if _, ok := oneIdx[sig]; ok {
+			return nil, fmt.Errorf("found duplicate items for the match group {%s} on the %s side of %s: many-to-many matching is not allowed", sig, oneSide, biNode)
+		}
+		oneIdx[sig] = i
+	}
+
+	unions := []*Union{}
+	oneMatched := make([]bool, len(one.Values))
+	manyMatched := make([]bool, len(many.Values))
+	manySeen := make(map[string]bool, len(many.Values))
+	for i, v := range many.Values {
+		sig := matchSignature(v.GetLabels(), m)
+		j, ok := oneIdx[sig]
+		if !ok {
+			continue
+		}
+		if m.Card == parse.CardOneToOne {
+			if manySeen[sig] {
+				return nil, fmt.Errorf("found duplicate items for the match group {%s} on the %s side of %s: use group_left or group_right for many-to-one matching", sig, manySide, biNode)
+			}
+			manySeen[sig] = true
+		}
+
+		u := &Union{
+			Labels: matchLabels(v.GetLabels(), one.Values[j].GetLabels(), m),
+			A:      v,
+			B:      one.Values[j],
+		}
+		if m.Card == parse.CardOneToMany {
+			u.A, u.B = u.B, u.A
+		}
+		unions = append(unions, u)
+		manyMatched[i] = true
+		oneMatched[j] = true
+	}
+
+	aMatched, bMatched := manyMatched, oneMatched
+	if m.Card == parse.CardOneToMany {
+		aMatched, bMatched = oneMatched, manyMatched
+	}
+	e.collectDrops(biNode.String(), biNode.Args[0].String(), aMatched, aResults)
+	e.collectDrops(biNode.String(), biNode.Args[1].String(), bMatched, bResults)
+	return unions, nil
+}
+
+// hasOnlyLabeled returns true if all values of r are Numbers or Series.
+func hasOnlyLabeled(r Results) bool {
+	for _, v := range r.Values {
+		switch v.(type) {
+		case Number, Series:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// matchSignature returns the labels that items are matched on, as a string.
+func matchSignature(labels data.Labels, m *parse.VectorMatching) string {
+	sig := data.Labels{}
+	if m.On {
+		for _, l := range m.Labels {
+			if v, ok := labels[l]; ok {
+				sig[l] = v
+			}
+		}
+		return sig.String()
+	}
+	for k, v := range labels {
+		sig[k] = v
+	}
+	for _, l := range m.Labels {
+		delete(sig, l)
+	}
+	return sig.String()
+}
+
+// matchLabels returns the labels of the result of joining the item labeled
+// many to the item labeled one. A one-to-one join keeps the matched labels, and
+// a group join keeps the labels of the "many" side plus the included labels of
+// the "one" side.
+func matchLabels(many, one data.Labels, m *parse.VectorMatching) data.Labels {
+	res := data.Labels{}
+	switch {
+	case m.Card != parse.CardOneToOne:
+		for k, v := range many {
+			res[k] = v
+		}
+		for _, l := range m.Include {
+			if v, ok := one[l]; ok {
+				res[l] = v
+			} else {
+				delete(res, l)
+			}
+		}
+	case m.On:
+		for _, l := range m.Labels {
+			if v, ok := many[l]; ok {
+				res[l] = v
+			}
+		}
+	default:
+		for k, v := range many {
+			res[k] = v
+		}
+		for _, l := range m.Labels {
+			delete(res, l)
+		}
+	}
+	return res
+}
+
 func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	res := Results{Values: Values{}}
 	ar, err := e.walk(node.Args[0])
@@ -316,7 +456,20 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := e.union(ar, br, node)
+	if node.Matching != nil {
+		unions, err := e.matchUnion(ar, br, node)
+		if err != nil {
+			return res, err
+		}
+		return e.biResults(unions, node.OpStr)
+	}
+	return e.biResults(e.union(ar, br, node), node.OpStr)
+}
+
+// biResults applies the binary operator op to the two sides of each union.
+func (e *State) biResults(unions []*Union, op string) (Results, error) {
+	res := Results{Values: Values{}}
+	var err error
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
@@ -332,7 +485,7 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 				}
 				f := math.NaN()
 				if aFloat != nil && bFloat != nil {
-					f, err = binaryOp(node.OpStr, *aFloat, *bFloat)
+					f, err = binaryOp(op, *aFloat, *bFloat)
 					if err != nil {
 						return res, err
 					}
@@ -340,53 +493,53 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 				value = NewScalar(e.RefID, &f)
 			// Scalar op Scalar
 			case Number:
-				value, err = e.biScalarNumber(uni.Labels, node.OpStr, bt, aFloat, false)
+				value, err = e.biScalarNumber(uni.Labels, op, bt, aFloat, false)
 			// Scalar op Series
 			case Series:
-				value, err = e.biSeriesNumber(uni.Labels, node.OpStr, bt, aFloat, false)
+				value, err = e.biSeriesNumber(uni.Labels, op, bt, aFloat, false)
 			case NoData:
 				value = uni.B
 			default:
-				return res, fmt.Errorf("not implemented: binary %v on %T and %T", node.OpStr, uni.A, uni.B)
+				return res, fmt.Errorf("not implemented: binary %v on %T and %T", op, uni.A, uni.B)
 			}
 		case Series:
 			switch bt := uni.B.(type) {
 			// Series Op Scalar
 			case Scalar:
 				bFloat := bt.GetFloat64Value()
-				value, err = e.biSeriesNumber(uni.Labels, node.OpStr, at, bFloat, true)
+				value, err = e.biSeriesNumber(uni.Labels, op, at, bFloat, true)
 			// case Series Op Number
 			case Number:
 				bFloat := bt.GetFloat64Value()
-				value, err = e.biSeriesNumber(uni.Labels, node.OpStr, at, bFloat, true)
+				value, err = e.biSeriesNumber(uni.Labels, op, at, bFloat, true)
 			// case Series op Series
 			case Series:
-				value, err = e.biSeriesSeries(uni.Labels, node.OpStr, at, bt)
+				value, err = e.biSeriesSeries(uni.Labels, op, at, bt)
 			case NoData:
 				value = uni.B
 			default:
-				return res, fmt.Errorf("not implemented: binary %v on %T and %T", node.OpStr, uni.A, uni.B)
+				return res, fmt.Errorf("not implemented: binary %v on %T and %T", op, uni.A, uni.B)
 			}
 		case Number:
 			aFloat := at.GetFloat64Value()
 			switch bt := uni.B.(type) {
 			case Scalar:
 				bFloat := bt.GetFloat64Value()
-				value, err = e.biScalarNumber(uni.Labels, node.OpStr, at, bFloat, true)
+				value, err = e.biScalarNumber(uni.Labels, op, at, bFloat, true)
 			case Number:
 				bFloat := bt.GetFloat64Value()
-				value, err = e.biScalarNumber(uni.Labels, node.OpStr, at, bFloat, true)
+				value, err = e.biScalarNumber(uni.Labels, op, at, bFloat, true)
 			case Series:
-				value, err = e.biSeriesNumber(uni.Labels, node.OpStr, bt, aFloat, false)
+				value, err = e.biSeriesNumber(uni.Labels, op, bt, aFloat, false)
 			case NoData:
 				value = uni.B
 			default:
-				return res, fmt.Errorf("not implemented: binary %v on %T and %T", node.OpStr, uni.A, uni.B)
+				return res, fmt.Errorf("not implemented: binary %v on %T and %T", op, uni.A, uni.B)
 			}
 		case NoData:
 			value = uni.A
 		default:
-			return res, fmt.Errorf("not implemented: binary %v on %T and %T", node.OpStr, uni.A, uni.B)
+			return res, fmt.Errorf("not implemented: binary %v on %T and %T", op, uni.A, uni.B)
 		}
 		if err != nil {
 			return res, err
@@ -1,7 +1,11 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
@@ -68,6 +72,48 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"clamp_min": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMin,
+	},
+	"clamp_max": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMax,
+	},
+	"abs_diff": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             absDiff,
+	},
+	"shift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      shift,
+		Check:  checkShift,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
+	"cumsum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      cumsum,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeScalar},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+		Check:  checkMovingAvg,
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -307,3 +353,227 @@ func floor(e *State, varSet Results) (Results, error) {
 	}
 	return newRes, nil
 }
+
+// clampMin returns the greater of each value in NumberSet, SeriesSet, or Scalar and min.
+// Null values stay null.
+func clampMin(e *State, varSet Results, minSet Results) (Results, error) {
+	lower, err := scalarArg("clamp_min", minSet)
+	if err != nil {
+		return Results{}, err
+	}
+	return perNullableResults(e, varSet, func(f *float64) *float64 {
+		if f == nil {
+			return nil
+		}
+		nF := math.Max(*f, lower)
+		return &nF
+	})
+}
+
+// clampMax returns the lesser of each value in NumberSet, SeriesSet, or Scalar and max.
+// Null values stay null.
+func clampMax(e *State, varSet Results, maxSet Results) (Results, error) {
+	upper, err := scalarArg("clamp_max", maxSet)
+	if err != nil {
+		return Results{}, err
+	}
+	return perNullableResults(e, varSet, func(f *float64) *float64 {
+		if f == nil {
+			return nil
+		}
+		nF := math.Min(*f, upper)
+		return &nF
+	})
+}
+
+// absDiff returns the absolute difference |a - b|. The arguments are joined
+// on their labels in the same way as the operands of a binary operation.
+func absDiff(e *State, aSet Results, bSet Results) (Results, error) {
+	diff, err := e.biResults(e.unionNamed(aSet, bSet, "abs_diff", "a", "b"), "-")
+	if err != nil {
+		return diff, err
+	}
+	return abs(e, diff)
+}
+
+// shift moves every point of each series forward in time by the duration, so
+// that shift($A, "1w") lines up the values of a week ago with the current ones.
+func shift(e *State, varSet Results, rawDuration string) (Results, error) {
+	d, err := gtime.ParseDuration(rawDuration)
+	if err != nil {
+		return Results{}, fmt.Errorf("shift: invalid duration %q: %w", rawDuration, err)
+	}
+	return perSeries(e, "shift", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(d), f)
+		}
+		return newSeries
+	})
+}
+
+func checkShift(_ *parse.Tree, f *parse.FuncNode) error {
+	s, ok := f.Args[1].(*parse.StringNode)
+	if !ok {
+		return fmt.Errorf("shift: expected a duration string, got %v", f.Args[1])
+	}
+	if _, err := gtime.ParseDuration(s.Text); err != nil {
+		return fmt.Errorf("shift: invalid duration %q: %w", s.Text, err)
+	}
+	return nil
+}
+
+// delta returns the difference between each point of each series and the point before it.
+// The first point has no predecessor and is dropped.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "delta", varSet, func(s Series) Series {
+		return pairwise(e, s, func(prev, cur float64, _ time.Duration) float64 {
+			return cur - prev
+		})
+	})
+}
+
+// rate returns the per-second rate of increase between each point of each series
+// and the point before it. A decrease is treated as a counter reset.
+// The first point has no predecessor and is dropped.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "rate", varSet, func(s Series) Series {
+		return pairwise(e, s, func(prev, cur float64, elapsed time.Duration) float64 {
+			if elapsed <= 0 {
+				return math.NaN()
+			}
+			inc := cur - prev
+			if inc < 0 {
+				inc = cur
+			}
+			return inc / elapsed.Seconds()
+		})
+	})
+}
+
+// cumsum returns the running sum of each series. Null points stay null and do
+// not reset the sum.
+func cumsum(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "cumsum", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		sum := float64(0)
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f == nil {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			sum += *f
+			nF := sum
+			newSeries.SetPoint(i, t, &nF)
+		}
+		return newSeries
+	})
+}
+
+// movingAvg returns the average of the last n points of each series at every point.
+// Null points are ignored, and the first n-1 points average fewer values.
+func movingAvg(e *State, varSet Results, nSet Results) (Results, error) {
+	n, err := scalarArg("moving_avg", nSet)
+	if err != nil {
+		return Results{}, err
+	}
+	if n < 1 || n != math.Trunc(n) {
+		return Results{}, fmt.Errorf("moving_avg: window must be a positive integer, got %v", n)
+	}
+	window := int(n)
+	return perSeries(e, "moving_avg", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			sum, count := float64(0), 0
+			for j := max(0, i-window+1); j <= i; j++ {
+				if f := s.GetValue(j); f != nil {
+					sum += *f
+					count++
+				}
+			}
+			var nF *float64
+			if count > 0 {
+				avg := sum / float64(count)
+				nF = &avg
+			}
+			newSeries.SetPoint(i, s.GetTime(i), nF)
+		}
+		return newSeries
+	})
+}
+
+func checkMovingAvg(_ *parse.Tree, f *parse.FuncNode) error {
+	if n, ok := f.Args[1].(*parse.ScalarNode); ok && (n.Float64 < 1 || n.Float64 != math.Trunc(n.Float64)) {
+		return fmt.Errorf("moving_avg: window must be a positive integer, got %v", n)
+	}
+	return nil
+}
+
+// perNullableResults applies perNullableFloat to each value of varSet.
+func perNullableResults(e *State, varSet Results, floatF func(x *float64) *float64) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perNullableFloat(e, res, floatF)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// perSeries passes each Series of varSet to seriesF. NoData is passed through, any
+// other type is an error since the function needs points over time.
+func perSeries(e *State, name string, varSet Results, seriesF func(s Series) Series) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch v := res.(type) {
+		case Series:
+			newRes.Values = append(newRes.Values, seriesF(v))
+		case NoData:
+			newRes.Values = append(newRes.Values, NewNoData())
+		default:
+			return newRes, fmt.Errorf("%s: expected a series, got %v", name, res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// pairwise applies pairF to every point of the series and the point before it,
+// including the time elapsed between them. If either point is null the result is null.
+func pairwise(e *State, s Series, pairF func(prev, cur float64, elapsed time.Duration) float64) Series {
+	size := s.Len() - 1
+	if size < 0 {
+		size = 0
+	}
+	newSeries := NewSeries(e.RefID, s.GetLabels(), size)
+	for i := 1; i < s.Len(); i++ {
+		prevT, prev := s.GetPoint(i - 1)
+		t, cur := s.GetPoint(i)
+		var nF *float64
+		if prev != nil && cur != nil {
+			f := pairF(*prev, *cur, t.Sub(prevT))
+			nF = &f
+		}
+		newSeries.SetPoint(i-1, t, nF)
+	}
+	return newSeries
+}
+
+// scalarArg returns the value of a scalar function argument.
+func scalarArg(name string, r Results) (float64, error) {
+	if len(r.Values) != 1 {
+		return 0, fmt.Errorf("%s: expected a single scalar argument, got %d values", name, len(r.Values))
+	}
+	s, ok := r.Values[0].(Scalar)
+	if !ok {
+		return 0, fmt.Errorf("%s: expected a scalar argument, got %v", name, r.Values[0].Type())
+	}
+	f := s.GetFloat64Value()
+	if f == nil {
+		return 0, fmt.Errorf("%s: scalar argument is null", name)
+	}
+	return *f, nil
+}
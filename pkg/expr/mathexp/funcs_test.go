@@ -140,3 +140,221 @@ func TestIsNumberFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestWindowFuncs(t *testing.T) {
+	series := func(points ...tp) Vars {
+		return Vars{"A": resultValuesNoErr(makeSeries("", nil, points...))}
+	}
+	var tests = []struct {
+		name     string
+		expr     string
+		vars     Vars
+		newErrIs require.ErrorAssertionFunc
+		results  Results
+	}{
+		{
+			name: "shift moves points forward in time",
+			expr: `shift($A, "1h")`,
+			vars: series(
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(60, 0), nil},
+			),
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(makeSeries("", nil,
+				tp{time.Unix(3600, 0), float64Pointer(1)},
+				tp{time.Unix(3660, 0), nil},
+			)),
+		},
+		{
+			name:     "shift with an invalid duration",
+			expr:     `shift($A, "a week")`,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "shift on a scalar",
+			expr:     `shift(1, "1h")`,
+			newErrIs: require.Error,
+		},
+		{
+			name: "delta drops the first point",
+			expr: `delta($A)`,
+			vars: series(
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(10, 0), float64Pointer(4)},
+				tp{time.Unix(20, 0), float64Pointer(2)},
+				tp{time.Unix(30, 0), nil},
+			),
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(makeSeries("", nil,
+				tp{time.Unix(10, 0), float64Pointer(3)},
+				tp{time.Unix(20, 0), float64Pointer(-2)},
+				tp{time.Unix(30, 0), nil},
+			)),
+		},
+		{
+			name: "rate handles counter resets",
+			expr: `rate($A)`,
+			vars: series(
+				tp{time.Unix(0, 0), float64Pointer(10)},
+				tp{time.Unix(10, 0), float64Pointer(30)},
+				tp{time.Unix(20, 0), float64Pointer(5)},
+			),
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(makeSeries("", nil,
+				tp{time.Unix(10, 0), float64Pointer(2)},
+				tp{time.Unix(20, 0), float64Pointer(0.5)},
+			)),
+		},
+		{
+			name: "cumsum skips null points",
+			expr: `cumsum($A)`,
+			vars: series(
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(10, 0), nil},
+				tp{time.Unix(20, 0), float64Pointer(2)},
+			),
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(10, 0), nil},
+				tp{time.Unix(20, 0), float64Pointer(3)},
+			)),
+		},
+		{
+			name: "moving_avg over the last n points",
+			expr: `moving_avg($A, 2)`,
+			vars: series(
+				tp{time.Unix(0, 0), float64Pointer(2)},
+				tp{time.Unix(10, 0), float64Pointer(4)},
+				tp{time.Unix(20, 0), nil},
+				tp{time.Unix(30, 0), float64Pointer(8)},
+			),
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(2)},
+				tp{time.Unix(10, 0), float64Pointer(3)},
+				tp{time.Unix(20, 0), float64Pointer(4)},
+				tp{time.Unix(30, 0), float64Pointer(8)},
+			)),
+		},
+		{
+			name:     "moving_avg with a fractional window",
+			expr:     `moving_avg($A, 1.5)`,
+			newErrIs: require.Error,
+		},
+		{
+			name: "clamp_min and clamp_max on series",
+			expr: `clamp_max(clamp_min($A, 2), 4)`,
+			vars: series(
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(10, 0), float64Pointer(3)},
+				tp{time.Unix(20, 0), float64Pointer(5)},
+				tp{time.Unix(30, 0), nil},
+			),
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(2)},
+				tp{time.Unix(10, 0), float64Pointer(3)},
+				tp{time.Unix(20, 0), float64Pointer(4)},
+				tp{time.Unix(30, 0), nil},
+			)),
+		},
+		{
+			name: "clamp_min on number",
+			expr: `clamp_min($A, 0)`,
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(-3))),
+			},
+			newErrIs: require.NoError,
+			results:  resultValuesNoErr(makeNumber("", nil, float64Pointer(0))),
+		},
+		{
+			name:     "abs_diff on scalars",
+			expr:     `abs_diff(2, 5)`,
+			newErrIs: require.NoError,
+			results:  resultValuesNoErr(NewScalar("", float64Pointer(3))),
+		},
+		{
+			name: "abs_diff against the series of a week ago",
+			expr: `abs_diff($A, shift($A, "1w"))`,
+			vars: series(
+				tp{time.Unix(0, 0), float64Pointer(5)},
+				tp{time.Unix(7*24*3600, 0), float64Pointer(2)},
+			),
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(makeSeries("", nil,
+				tp{time.Unix(7*24*3600, 0), float64Pointer(3)},
+			)),
+		},
+		{
+			name: "abs of abs_diff of a series and a scalar",
+			expr: `abs(abs_diff($A, 2))`,
+			vars: series(
+				tp{time.Unix(0, 0), float64Pointer(5)},
+				tp{time.Unix(60, 0), float64Pointer(-2)},
+			),
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(3)},
+				tp{time.Unix(60, 0), float64Pointer(4)},
+			)),
+		},
+		{
+			name: "abs_diff of a scalar and a series",
+			expr: `abs_diff(1, $A)`,
+			vars: series(
+				tp{time.Unix(0, 0), float64Pointer(5)},
+				tp{time.Unix(60, 0), float64Pointer(-2)},
+			),
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(4)},
+				tp{time.Unix(60, 0), float64Pointer(3)},
+			)),
+		},
+		{
+			name: "nested abs_diff",
+			expr: `abs_diff(abs_diff($A, 1), 10)`,
+			vars: series(
+				tp{time.Unix(0, 0), float64Pointer(5)},
+				tp{time.Unix(60, 0), float64Pointer(-2)},
+			),
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(6)},
+				tp{time.Unix(60, 0), float64Pointer(7)},
+			)),
+		},
+		{
+			name: "abs_diff of a series and a scalar is a series",
+			expr: `shift(abs_diff($A, 1), "1h")`,
+			vars: series(
+				tp{time.Unix(0, 0), float64Pointer(5)},
+			),
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(makeSeries("", nil,
+				tp{time.Unix(3600, 0), float64Pointer(4)},
+			)),
+		},
+		{
+			name: "abs of abs_diff of a number and a scalar",
+			expr: `abs(abs_diff($A, 5))`,
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(-3))),
+			},
+			newErrIs: require.NoError,
+			results:  resultValuesNoErr(makeNumber("", nil, float64Pointer(8))),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+				require.NoError(t, err)
+				require.Equal(t, tt.results, res)
+			}
+		})
+	}
+}
@@ -276,8 +276,8 @@ func lexSymbol(l *lexer) stateFn {
 func lexFunc(l *lexer) stateFn {
 	for {
 		switch r := l.next(); {
-		case unicode.IsLetter(r) || r == '_':
-			// absorb
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			// absorb, digits may follow the first letter as in label names such as k8s_pod
 		default:
 			l.backup()
 			l.emit(itemFunc)
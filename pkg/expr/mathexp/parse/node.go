@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -198,6 +199,12 @@ func (f *FuncNode) Return() ReturnType {
 	return f.F.Return
 }
 
+// variantArg returns whether the function accepts a number set, series set
+// or scalar as its i-th argument.
+func (f *FuncNode) variantArg(i int) bool {
+	return i < len(f.F.Args) && f.F.Args[i] == TypeVariantSet
+}
+
 // ScalarNode holds a number: signed or unsigned integer or float.
 // The value is parsed and stored under all the types that can represent the value.
 // This simulates in a small amount of code the behavior of Go's ideal constants.
@@ -300,6 +307,9 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	// Matching holds the label matching modifiers of the operation, if any.
+	// When nil, the arguments are joined on label subsets.
+	Matching *VectorMatching
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -308,19 +318,87 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.Matching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s(%s, %s)", b.Operator.val, b.Matching, b.Args[0], b.Args[1])
+	}
 	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
 }
 
 // Check performs parse time checking on the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) Check(t *Tree) error {
+	if b.Matching != nil && b.Matching.On {
+		for _, include := range b.Matching.Include {
+			for _, l := range b.Matching.Labels {
+				if include == l {
+					return fmt.Errorf("parse: label %q must not occur in on and %s at once", l, b.Matching.Card.groupName())
+				}
+			}
+		}
+	}
 	return nil
 }
 
+// MatchCardinality is the cardinality of the join between the items on each
+// side of a binary operation.
+type MatchCardinality int
+
+const (
+	// CardOneToOne joins each item on the left to at most one item on the right.
+	CardOneToOne MatchCardinality = iota
+	// CardManyToOne joins many items on the left to one item on the right (group_left).
+	CardManyToOne
+	// CardOneToMany joins one item on the left to many items on the right (group_right).
+	CardOneToMany
+)
+
+func (c MatchCardinality) groupName() string {
+	switch c {
+	case CardManyToOne:
+		return "group_left"
+	case CardOneToMany:
+		return "group_right"
+	default:
+		return ""
+	}
+}
+
+// VectorMatching describes how the items on each side of a binary operation
+// are joined on their labels, e.g. $A / on(host) group_left $B.
+type VectorMatching struct {
+	Card MatchCardinality
+	// On is true if the items are matched on Labels only, and false if they
+	// are matched on all labels except Labels (ignoring).
+	On     bool
+	Labels []string
+	// Include are the labels copied from the "one" side to the result of a
+	// group_left or group_right join.
+	Include []string
+}
+
+// String returns the string representation of the VectorMatching as written in an expression.
+func (m *VectorMatching) String() string {
+	s := "ignoring"
+	if m.On {
+		s = "on"
+	}
+	s += "(" + strings.Join(m.Labels, ", ") + ")"
+	if m.Card != CardOneToOne {
+		s += " " + m.Card.groupName()
+		if len(m.Include) > 0 {
+			s += "(" + strings.Join(m.Include, ", ") + ")"
+		}
+	}
+	return s
+}
+
 // Return returns the result type of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) Return() ReturnType {
 	t0 := b.Args[0].Return()
@@ -175,6 +175,9 @@ F -> v | "(" O ")" | "!" O | "-" O
 v -> number | func(..) | queryVar
 Func -> name "(" param {"," param} ")"
 param -> number | "string" | queryVar
+Binary operators may be followed by label matching modifiers:
+match -> ("on" | "ignoring") labels [("group_left" | "group_right") [labels]]
+labels -> "(" [label {"," label}] ")"
 */
 
 // expr:
@@ -185,7 +188,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(t.next(), n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +201,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(t.next(), n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +214,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(t.next(), n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +227,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(t.next(), n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +240,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(t.next(), n, t.E)
 		default:
 			return n
 		}
@@ -250,7 +253,7 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(t.next(), n, t.F)
 		default:
 			return n
 		}
@@ -275,6 +278,76 @@ func (t *Tree) F() Node {
 	return nil
 }
 
+// binary parses the optional label matching modifiers after a binary operator
+// and then the right hand side of the operation.
+func (t *Tree) binary(operator item, lhs Node, rhs func() Node) *BinaryNode {
+	matching := t.matching()
+	b := newBinary(operator, lhs, rhs())
+	b.Matching = matching
+	return b
+}
+
+// matching is the match rule of the grammar. It returns nil if the next
+// token does not start a match.
+func (t *Tree) matching() *VectorMatching {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "on" && token.val != "ignoring") {
+		return nil
+	}
+	t.next()
+	m := &VectorMatching{
+		Card:   CardOneToOne,
+		On:     token.val == "on",
+		Labels: t.labels(),
+	}
+
+	token = t.peek()
+	if token.typ != itemFunc || (token.val != "group_left" && token.val != "group_right") {
+		return m
+	}
+	t.next()
+	m.Card = CardManyToOne
+	if token.val == "group_right" {
+		m.Card = CardOneToMany
+	}
+	if t.peek().typ == itemLeftParen {
+		m.Include = t.labels()
+	}
+	return m
+}
+
+// labels is the labels rule of the grammar. Label names may be quoted.
+func (t *Tree) labels() []string {
+	t.expect(itemLeftParen, "labels")
+	labels := []string{}
+	if t.peek().typ == itemRightParen {
+		t.next()
+		return labels
+	}
+	for {
+		switch token := t.next(); token.typ {
+		case itemFunc:
+			labels = append(labels, token.val)
+		case itemString:
+			s, err := strconv.Unquote(token.val)
+			if err != nil {
+				t.errorf("Unquoting error: %s", err)
+			}
+			labels = append(labels, s)
+		default:
+			t.unexpected(token, "labels")
+		}
+		switch token := t.next(); token.typ {
+		case itemComma:
+			// continue with the next label
+		case itemRightParen:
+			return labels
+		default:
+			t.unexpected(token, "labels")
+		}
+	}
+}
+
 // V is number | func(..) | queryVar in the grammar.
 func (t *Tree) v() Node {
 	switch token := t.next(); token.typ {
@@ -320,8 +393,12 @@ func (t *Tree) Func() (f *FuncNode) {
 			t.backup()
 			node := t.O()
 			f.append(node)
-			if len(f.Args) == 1 && f.F.VariantReturn {
-				f.F.Return = node.Return()
+			if f.F.VariantReturn && f.variantArg(len(f.Args)-1) {
+				// the function returns the widest type of its variant arguments,
+				// as a binary operation does
+				if r := node.Return(); len(f.Args) == 1 || r > f.F.Return {
+					f.F.Return = r
+				}
 			}
 		case itemString:
 			s, err := strconv.Unquote(token.val)
@@ -332,6 +409,14 @@ func (t *Tree) Func() (f *FuncNode) {
 		case itemRightParen:
 			return
 		}
+		switch token = t.next(); token.typ {
+		case itemComma:
+			// continue with the next argument
+		case itemRightParen:
+			return
+		default:
+			t.unexpected(token, "func")
+		}
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -15,18 +16,42 @@ type ReducerFunc = func(fv *Float64Field) *float64
 type ReducerID string
 
 const (
-	ReducerSum    ReducerID = "sum"
-	ReducerMean   ReducerID = "mean"
-	ReducerMin    ReducerID = "min"
-	ReducerMax    ReducerID = "max"
-	ReducerCount  ReducerID = "count"
-	ReducerLast   ReducerID = "last"
-	ReducerMedian ReducerID = "median"
+	ReducerSum          ReducerID = "sum"
+	ReducerMean         ReducerID = "mean"
+	ReducerMin          ReducerID = "min"
+	ReducerMax          ReducerID = "max"
+	ReducerCount        ReducerID = "count"
+	ReducerLast         ReducerID = "last"
+	ReducerMedian       ReducerID = "median"
+	ReducerFirst        ReducerID = "first"
+	ReducerStdDev       ReducerID = "stddev"
+	ReducerDelta        ReducerID = "delta"
+	ReducerIncrease     ReducerID = "increase"
+	ReducerRate         ReducerID = "rate"
+	ReducerCountNonNull ReducerID = "count_non_null"
+	ReducerP50          ReducerID = "p50"
+	ReducerP75          ReducerID = "p75"
+	ReducerP90          ReducerID = "p90"
+	ReducerP95          ReducerID = "p95"
+	ReducerP99          ReducerID = "p99"
+	ReducerP999         ReducerID = "p99.9"
 )
 
+// percentiles are the percentiles of the percentile reducers.
+var percentiles = map[ReducerID]float64{
+	ReducerP50:  50,
+	ReducerP75:  75,
+	ReducerP90:  90,
+	ReducerP95:  95,
+	ReducerP99:  99,
+	ReducerP999: 99.9,
+}
+
 // GetSupportedReduceFuncs returns collection of supported function names
 func GetSupportedReduceFuncs() []ReducerID {
-	return []ReducerID{ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast, ReducerMedian}
+	return []ReducerID{ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast, ReducerMedian,
+		ReducerFirst, ReducerStdDev, ReducerDelta, ReducerIncrease, ReducerRate, ReducerCountNonNull,
+		ReducerP50, ReducerP75, ReducerP90, ReducerP95, ReducerP99, ReducerP999}
 }
 
 func Sum(fv *Float64Field) *float64 {
@@ -126,6 +151,134 @@ func Median(fv *Float64Field) *float64 {
 	}
 }
 
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// StdDev returns the population standard deviation.
+func StdDev(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	mean := Avg(fv)
+	if math.IsNaN(*mean) {
+		return mean
+	}
+	var sum float64
+	for i := 0; i < fv.Len(); i++ {
+		d := *fv.GetValue(i) - *mean
+		sum += d * d
+	}
+	f := math.Sqrt(sum / float64(fv.Len()))
+	return &f
+}
+
+// Delta returns the difference between the last and the first value.
+func Delta(fv *Float64Field) *float64 {
+	first, last := First(fv), Last(fv)
+	if first == nil || last == nil {
+		nan := math.NaN()
+		return &nan
+	}
+	f := *last - *first
+	return &f
+}
+
+// Increase returns the increase of a counter. A value lower than the previous
+// one is a counter reset, and the counter is assumed to have started from
+// zero.
+func Increase(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	var prev float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			nan := math.NaN()
+			return &nan
+		}
+		switch {
+		case i == 0:
+		case *v >= prev:
+			f += *v - prev
+		default:
+			f += *v
+		}
+		prev = *v
+	}
+	return &f
+}
+
+// Rate returns the per second increase of a counter: its Increase divided by
+// the seconds between the first and the last value, which were observed at the
+// given times. It is NaN for less than two values. The rate reducer of Reduce,
+// of Resample and of classic conditions all use this definition.
+func Rate(fv *Float64Field, first, last time.Time) *float64 {
+	seconds := last.Sub(first).Seconds()
+	if fv.Len() < 2 || seconds <= 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	r := *Increase(fv) / seconds
+	return &r
+}
+
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v != nil && !math.IsNaN(*v) {
+			f++
+		}
+	}
+	return &f
+}
+
+// Percentile returns a reducer for the p-th percentile, 0 <= p <= 100. It
+// interpolates linearly between the closest ranks.
+func Percentile(p float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		values := make([]float64, 0, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			v := fv.GetValue(i)
+			if v == nil || math.IsNaN(*v) {
+				nan := math.NaN()
+				return &nan
+			}
+			values = append(values, *v)
+		}
+		if len(values) == 0 {
+			nan := math.NaN()
+			return &nan
+		}
+		sort.Float64s(values)
+		rank := p / 100 * float64(len(values)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		f := values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+		return &f
+	}
+}
+
+// ParsePercentile returns the percentile of a percentile reducer, e.g. 95 for
+// "p95". Only the percentiles of GetSupportedReduceFuncs are accepted.
+func ParsePercentile(rFunc ReducerID) (float64, bool) {
+	p, ok := percentiles[rFunc]
+	return p, ok
+}
+
+// GetReduceFunc returns the reducer function of the ID. The rate reducer needs
+// the time covered by the values and is returned as its increase; callers
+// divide by the duration.
 func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 	switch rFunc {
 	case ReducerSum:
@@ -142,7 +295,20 @@ func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 		return Last, nil
 	case ReducerMedian:
 		return Median, nil
+	case ReducerFirst:
+		return First, nil
+	case ReducerStdDev:
+		return StdDev, nil
+	case ReducerDelta:
+		return Delta, nil
+	case ReducerIncrease, ReducerRate:
+		return Increase, nil
+	case ReducerCountNonNull:
+		return CountNonNull, nil
 	default:
+		if p, ok := ParsePercentile(rFunc); ok {
+			return Percentile(p), nil
+		}
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
 }
@@ -168,6 +334,9 @@ func (s Series) Reduce(refID string, rFunc ReducerID, mapper ReduceMapper) (Numb
 		return number, fmt.Errorf("invalid expression '%s': %w", refID, err)
 	}
 	f = reduceFunc(&floatField)
+	if rFunc == ReducerRate && series.Len() > 0 {
+		f = Rate(&floatField, series.GetTime(0), series.GetTime(series.Len()-1))
+	}
 	if f != nil && mapper != nil {
 		f = mapper.MapOutput(f)
 	}
@@ -543,3 +543,91 @@ func sortedFloat64(f []float64) []float64 {
 	sort.Float64s(f)
 	return f
 }
+
+func TestSeriesReduceStatistics(t *testing.T) {
+	counter := makeSeries("counter", nil,
+		tp{time.Unix(0, 0), float64Pointer(1)},
+		tp{time.Unix(10, 0), float64Pointer(4)},
+		tp{time.Unix(20, 0), float64Pointer(2)},
+		tp{time.Unix(30, 0), float64Pointer(6)},
+	)
+
+	var tests = []struct {
+		red      ReducerID
+		series   Series
+		expected float64
+	}{
+		{red: ReducerFirst, series: counter, expected: 1},
+		{red: ReducerDelta, series: counter, expected: 5},
+		{red: ReducerIncrease, series: counter, expected: 9},
+		{red: ReducerRate, series: counter, expected: 0.3},
+		{red: ReducerCountNonNull, series: counter, expected: 4},
+		{red: ReducerStdDev, series: counter, expected: math.Sqrt(3.6875)},
+		{red: ReducerP50, series: counter, expected: 3},
+		{red: ReducerP999, series: counter, expected: 5.994},
+		{red: ReducerP90, series: counter, expected: 5.4},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.red), func(t *testing.T) {
+			num, err := tt.series.Reduce("", tt.red, nil)
+			require.NoError(t, err)
+			require.NotNil(t, num.GetFloat64Value())
+			require.InDelta(t, tt.expected, *num.GetFloat64Value(), 1e-9)
+		})
+	}
+
+	t.Run("nil values give NaN in strict mode", func(t *testing.T) {
+		for _, red := range []ReducerID{ReducerStdDev, ReducerDelta, ReducerIncrease, ReducerRate, ReducerP95} {
+			num, err := seriesWithNil["A"].Values[0].(Series).Reduce("", red, nil)
+			require.NoError(t, err)
+			require.True(t, math.IsNaN(*num.GetFloat64Value()), red)
+		}
+	})
+
+	t.Run("rate of a single value is NaN", func(t *testing.T) {
+		single := makeSeries("single", nil, tp{time.Unix(0, 0), float64Pointer(1)})
+		num, err := single.Reduce("", ReducerRate, nil)
+		require.NoError(t, err)
+		require.True(t, math.IsNaN(*num.GetFloat64Value()))
+	})
+}
+
+func TestParsePercentile(t *testing.T) {
+	tests := []struct {
+		red        ReducerID
+		percentile float64
+		ok         bool
+	}{
+		{red: ReducerP50, percentile: 50, ok: true},
+		{red: ReducerP75, percentile: 75, ok: true},
+		{red: ReducerP90, percentile: 90, ok: true},
+		{red: ReducerP95, percentile: 95, ok: true},
+		{red: ReducerP99, percentile: 99, ok: true},
+		{red: ReducerP999, percentile: 99.9, ok: true},
+		// Only the percentiles listed in the query schema are accepted.
+		{red: "p80"},
+		{red: "p99.99"},
+		{red: "p"},
+		{red: "p101"},
+		{red: "p-5"},
+		{red: "pNaN"},
+		{red: ReducerMax},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.red), func(t *testing.T) {
+			p, ok := ParsePercentile(tt.red)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.percentile, p)
+			_, err := GetReduceFunc(tt.red)
+			require.Equal(t, tt.ok || tt.red == ReducerMax, err == nil)
+		})
+	}
+}
+
+func TestSupportedReduceFuncsIncludePercentiles(t *testing.T) {
+	for red := range percentiles {
+		require.Contains(t, GetSupportedReduceFuncs(), red)
+	}
+}
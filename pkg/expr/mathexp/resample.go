@@ -20,21 +20,50 @@ const (
 
 	// Do not fill values (nill)
 	UpsamplerFillNA Upsampler = "fillna"
+
+	// Interpolate linearly between the surrounding values
+	UpsamplerLinear Upsampler = "linear"
+
+	// Fill with a constant value
+	UpsamplerConstant Upsampler = "constant"
 )
 
+// ResampleOptions are the optional settings of a resample.
+type ResampleOptions struct {
+	// FillValue is the value used by the constant upsampler.
+	FillValue float64
+
+	// AlignToInterval anchors the points at multiples of the interval since
+	// the Unix epoch, instead of at the start of the time range. Series
+	// resampled with the same interval then share their timestamps.
+	AlignToInterval bool
+}
+
 // Resample turns the Series into a Number based on the given reduction function
 func (s Series) Resample(refID string, interval time.Duration, downsampler ReducerID, upsampler Upsampler, from, to time.Time) (Series, error) {
+	return s.ResampleWithOptions(refID, interval, downsampler, upsampler, from, to, ResampleOptions{})
+}
+
+// ResampleWithOptions resamples the Series at the given interval over the time range.
+func (s Series) ResampleWithOptions(refID string, interval time.Duration, downsampler ReducerID, upsampler Upsampler, from, to time.Time, opts ResampleOptions) (Series, error) {
 	newSeriesLength := int(float64(to.Sub(from).Nanoseconds()) / float64(interval.Nanoseconds()))
 	if newSeriesLength <= 0 {
 		return s, fmt.Errorf("the series cannot be sampled further; the time range is shorter than the interval")
 	}
+	start := from
+	if opts.AlignToInterval {
+		start = alignToInterval(from, interval)
+		newSeriesLength = int(to.Sub(start) / interval)
+	}
 	resampled := NewSeries(refID, s.GetLabels(), newSeriesLength+1)
 	bookmark := 0
 	var lastSeen *float64
+	var lastSeenTime time.Time
 	idx := 0
-	t := from
+	t := start
 	for !t.After(to) && idx <= newSeriesLength {
 		vals := make([]*float64, 0)
+		var firstTime time.Time
 		sIdx := bookmark
 		for {
 			if sIdx == s.Len() {
@@ -44,9 +73,13 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 			if st.After(t) {
 				break
 			}
+			if len(vals) == 0 {
+				firstTime = st
+			}
 			bookmark++
 			sIdx++
 			lastSeen = v
+			lastSeenTime = st
 			vals = append(vals, v)
 		}
 		var value *float64
@@ -66,30 +99,30 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 				}
 			case UpsamplerFillNA:
 				value = nil
+			case UpsamplerLinear:
+				if lastSeen != nil && sIdx < s.Len() {
+					nextTime, next := s.GetPoint(sIdx)
+					value = interpolate(lastSeenTime, *lastSeen, nextTime, next, t)
+				}
+			case UpsamplerConstant:
+				fill := opts.FillValue
+				value = &fill
 			default:
 				return s, fmt.Errorf("upsampling %v not implemented", upsampler)
 			}
-		} else if len(vals) == 1 {
+		} else if len(vals) == 1 && keepsSingleValue(downsampler) {
 			value = vals[0]
 		} else { // downsampling
 			fVec := data.NewField("", s.GetLabels(), vals)
 			ff := Float64Field(*fVec)
-			var tmp *float64
-			switch downsampler {
-			case ReducerSum:
-				tmp = Sum(&ff)
-			case ReducerMean:
-				tmp = Avg(&ff)
-			case ReducerMin:
-				tmp = Min(&ff)
-			case ReducerMax:
-				tmp = Max(&ff)
-			case ReducerLast:
-				tmp = Last(&ff)
-			default:
+			reduceFunc, err := GetReduceFunc(downsampler)
+			if err != nil {
 				return s, fmt.Errorf("downsampling %v not implemented", downsampler)
 			}
-			value = tmp
+			value = reduceFunc(&ff)
+			if downsampler == ReducerRate {
+				value = Rate(&ff, firstTime, lastSeenTime)
+			}
 		}
 		resampled.SetPoint(idx, t, value)
 		t = t.Add(interval)
@@ -97,3 +130,42 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 	}
 	return resampled, nil
 }
+
+// alignToInterval returns the first multiple of the interval since the Unix
+// epoch that is not before t.
+func alignToInterval(t time.Time, interval time.Duration) time.Time {
+	ns := t.UnixNano()
+	step := interval.Nanoseconds()
+	aligned := ns - ns%step
+	if ns%step < 0 {
+		aligned -= step
+	}
+	if aligned < ns {
+		aligned += step
+	}
+	return time.Unix(0, aligned).In(t.Location())
+}
+
+// interpolate returns the value at t on the line between the two points, or
+// nil if the next value is missing.
+func interpolate(prevTime time.Time, prev float64, nextTime time.Time, next *float64, t time.Time) *float64 {
+	if next == nil {
+		return nil
+	}
+	span := nextTime.Sub(prevTime)
+	if span <= 0 {
+		return nil
+	}
+	v := prev + (*next-prev)*float64(t.Sub(prevTime))/float64(span)
+	return &v
+}
+
+// keepsSingleValue returns true if the reducer returns the value itself when
+// reducing a single value.
+func keepsSingleValue(r ReducerID) bool {
+	switch r {
+	case ReducerCount, ReducerCountNonNull, ReducerStdDev, ReducerDelta, ReducerIncrease, ReducerRate:
+		return false
+	}
+	return true
+}
@@ -290,3 +290,80 @@ func TestResampleSeries(t *testing.T) {
 		})
 	}
 }
+
+func TestResampleSeriesDownsamplers(t *testing.T) {
+	series := makeSeries("", nil,
+		tp{time.Unix(1, 0), float64Pointer(2)},
+		tp{time.Unix(2, 0), float64Pointer(6)},
+		tp{time.Unix(3, 0), float64Pointer(10)},
+		tp{time.Unix(4, 0), float64Pointer(12)},
+	)
+
+	var tests = []struct {
+		downsampler ReducerID
+		expected    []float64
+	}{
+		{downsampler: ReducerP50, expected: []float64{4, 11}},
+		{downsampler: ReducerStdDev, expected: []float64{2, 1}},
+		{downsampler: ReducerIncrease, expected: []float64{4, 2}},
+		// The rate is taken over the time between the first and the last point of a window, as in Reduce.
+		{downsampler: ReducerRate, expected: []float64{4, 2}},
+		{downsampler: ReducerFirst, expected: []float64{2, 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.downsampler), func(t *testing.T) {
+			resampled, err := series.Resample("", 2*time.Second, tt.downsampler, UpsamplerFillNA, time.Unix(0, 0), time.Unix(4, 0))
+			require.NoError(t, err)
+			require.Equal(t, 3, resampled.Len())
+			require.Nil(t, resampled.GetValue(0))
+			for i, v := range tt.expected {
+				require.InDelta(t, v, *resampled.GetValue(i + 1), 1e-9)
+			}
+		})
+	}
+}
+
+func TestResampleSeriesUpsamplers(t *testing.T) {
+	series := makeSeries("", nil,
+		tp{time.Unix(0, 0), float64Pointer(0)},
+		tp{time.Unix(4, 0), float64Pointer(8)},
+	)
+
+	t.Run("linear interpolates between the surrounding points", func(t *testing.T) {
+		resampled, err := series.Resample("", time.Second, ReducerLast, UpsamplerLinear, time.Unix(0, 0), time.Unix(5, 0))
+		require.NoError(t, err)
+		require.Equal(t, 6, resampled.Len())
+		for i, expected := range []float64{0, 2, 4, 6, 8} {
+			require.InDelta(t, expected, *resampled.GetValue(i), 1e-9)
+		}
+		// nothing to interpolate towards after the last point
+		require.Nil(t, resampled.GetValue(5))
+	})
+
+	t.Run("constant fills with the value", func(t *testing.T) {
+		resampled, err := series.ResampleWithOptions("", time.Second, ReducerLast, UpsamplerConstant, time.Unix(0, 0), time.Unix(4, 0),
+			ResampleOptions{FillValue: -1})
+		require.NoError(t, err)
+		require.Equal(t, []*float64{float64Pointer(0), float64Pointer(-1), float64Pointer(-1), float64Pointer(-1), float64Pointer(8)},
+			[]*float64{resampled.GetValue(0), resampled.GetValue(1), resampled.GetValue(2), resampled.GetValue(3), resampled.GetValue(4)})
+	})
+}
+
+func TestResampleSeriesAligned(t *testing.T) {
+	series := makeSeries("", nil,
+		tp{time.Unix(60, 0), float64Pointer(1)},
+		tp{time.Unix(125, 0), float64Pointer(2)},
+		tp{time.Unix(190, 0), float64Pointer(3)},
+	)
+
+	for _, from := range []time.Time{time.Unix(61, 0), time.Unix(95, 0), time.Unix(119, 0)} {
+		resampled, err := series.ResampleWithOptions("", time.Minute, ReducerLast, UpsamplerPad, from, from.Add(2*time.Minute),
+			ResampleOptions{AlignToInterval: true})
+		require.NoError(t, err)
+		require.Equal(t, time.Unix(120, 0), resampled.GetTime(0))
+		for i := 0; i < resampled.Len(); i++ {
+			require.Zero(t, resampled.GetTime(i).Unix()%60)
+		}
+	}
+}
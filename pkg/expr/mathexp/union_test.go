@@ -5,7 +5,9 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_union(t *testing.T) {
@@ -300,3 +302,122 @@ func Test_union(t *testing.T) {
 		})
 	}
 }
+
+func TestLabelMatching(t *testing.T) {
+	number := func(labels data.Labels, f float64) Number {
+		return makeNumber("", labels, float64Pointer(f))
+	}
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name: "on joins different label schemas",
+			expr: "$A / on(host) $B",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					number(data.Labels{"host": "a", "job": "node"}, 10),
+					number(data.Labels{"host": "b", "job": "node"}, 20),
+				),
+				"B": resultValuesNoErr(
+					number(data.Labels{"host": "a", "instance": "a:9100"}, 2),
+					number(data.Labels{"host": "b", "instance": "b:9100"}, 4),
+				),
+			},
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				number(data.Labels{"host": "a"}, 5),
+				number(data.Labels{"host": "b"}, 5),
+			),
+		},
+		{
+			name: "ignoring drops the listed labels",
+			expr: "$A - ignoring(source) $B",
+			vars: Vars{
+				"A": resultValuesNoErr(number(data.Labels{"host": "a", "source": "prom"}, 3)),
+				"B": resultValuesNoErr(number(data.Labels{"host": "a", "source": "influx"}, 1)),
+			},
+			execErrIs: require.NoError,
+			results:   resultValuesNoErr(number(data.Labels{"host": "a"}, 2)),
+		},
+		{
+			name: "group_left joins many to one and includes labels",
+			expr: `$A / on(host) group_left(dc) $B`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					number(data.Labels{"host": "a", "cpu": "0"}, 1),
+					number(data.Labels{"host": "a", "cpu": "1"}, 3),
+				),
+				"B": resultValuesNoErr(number(data.Labels{"host": "a", "dc": "mia"}, 4)),
+			},
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				number(data.Labels{"host": "a", "cpu": "0", "dc": "mia"}, 0.25),
+				number(data.Labels{"host": "a", "cpu": "1", "dc": "mia"}, 0.75),
+			),
+		},
+		{
+			name: "group_right joins one to many",
+			expr: `$B * on(host) group_right $A`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					number(data.Labels{"host": "a", "cpu": "0"}, 1),
+					number(data.Labels{"host": "a", "cpu": "1"}, 3),
+				),
+				"B": resultValuesNoErr(number(data.Labels{"host": "a", "dc": "mia"}, 2)),
+			},
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				number(data.Labels{"host": "a", "cpu": "0"}, 2),
+				number(data.Labels{"host": "a", "cpu": "1"}, 6),
+			),
+		},
+		{
+			name: "many to one without group_left is an error",
+			expr: "$A / on(host) $B",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					number(data.Labels{"host": "a", "cpu": "0"}, 1),
+					number(data.Labels{"host": "a", "cpu": "1"}, 3),
+				),
+				"B": resultValuesNoErr(number(data.Labels{"host": "a"}, 4)),
+			},
+			execErrIs: require.Error,
+		},
+		{
+			name: "duplicates on the one side are an error",
+			expr: "$A / on(host) group_left $B",
+			vars: Vars{
+				"A": resultValuesNoErr(number(data.Labels{"host": "a"}, 1)),
+				"B": resultValuesNoErr(
+					number(data.Labels{"host": "a", "dc": "mia"}, 1),
+					number(data.Labels{"host": "a", "dc": "sjc"}, 1),
+				),
+			},
+			execErrIs: require.Error,
+		},
+		{
+			name: "scalars join to anything",
+			expr: "$A * on(host) 2",
+			vars: Vars{
+				"A": resultValuesNoErr(number(data.Labels{"host": "a"}, 1)),
+			},
+			execErrIs: require.NoError,
+			results:   resultValuesNoErr(number(data.Labels{"host": "a"}, 2)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+			tt.execErrIs(t, err)
+			if err == nil {
+				require.Equal(t, tt.results, res)
+			}
+		})
+	}
+}
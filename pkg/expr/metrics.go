@@ -10,7 +10,8 @@ const (
 )
 
 type metrics struct {
-	dsRequests *prometheus.CounterVec
+	dsRequests             *prometheus.CounterVec
+	dsRequestsDeduplicated *prometheus.CounterVec
 
 	// older metric
 	expressionsQuerySummary *prometheus.SummaryVec
@@ -25,6 +26,13 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 			Help:      "Number of datasource queries made via server side expression requests",
 		}, []string{"error", "dataplane", "datasource_type"}),
 
+		dsRequestsDeduplicated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "ds_queries_deduplicated_total",
+			Help:      "Number of datasource queries that reused the response of an identical query instead of querying the datasource",
+		}, []string{"datasource_type"}),
+
 		// older (No Namespace or Subsystem)
 		expressionsQuerySummary: prometheus.NewSummaryVec(
 			prometheus.SummaryOpts{
@@ -39,6 +47,7 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 	if reg != nil {
 		reg.MustRegister(
 			m.dsRequests,
+			m.dsRequestsDeduplicated,
 			m.expressionsQuerySummary,
 		)
 	}
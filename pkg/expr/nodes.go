@@ -158,6 +158,8 @@ func buildCMDNode(rn *rawNode, toggles featuremgmt.FeatureToggles) (*CMDNode, er
 		node.Command, err = UnmarshalThresholdCommand(rn, toggles)
 	case TypeSQL:
 		node.Command, err = UnmarshalSQLCommand(rn)
+	case TypeAnomaly:
+		node.Command, err = UnmarshalAnomalyCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in expression '%v' not implemented", commandType, rn.RefID)
 	}
@@ -390,7 +392,17 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 		s.metrics.dsRequests.WithLabelValues(respStatus, fmt.Sprintf("%t", useDataplane), dn.datasource.Type).Inc()
 	}()
 
-	resp, err := s.dataService.QueryData(ctx, req)
+	var resp *backend.QueryDataResponse
+	if dedup := queryDeduplicatorFromContext(ctx); dedup != nil {
+		var shared bool
+		resp, shared, err = dedup.queryData(ctx, s.dataService, dn.datasource, req)
+		if shared {
+			span.SetAttributes(attribute.Bool("deduplicated", true))
+			s.metrics.dsRequestsDeduplicated.WithLabelValues(dn.datasource.Type).Inc()
+		}
+	} else {
+		resp, err = s.dataService.QueryData(ctx, req)
+	}
 	if err != nil {
 		return mathexp.Results{}, MakeQueryError(dn.refID, dn.datasource.UID, err)
 	}
@@ -29,6 +29,9 @@ const (
 
 	// SQL query via DuckDB
 	QueryTypeSQL QueryType = "sql"
+
+	// Anomaly detection and forecasting
+	QueryTypeAnomaly QueryType = "anomaly"
 )
 
 type MathQuery struct {
@@ -60,6 +63,43 @@ type ResampleQuery struct {
 
 	// The upsample function
 	Upsampler mathexp.Upsampler `json:"upsampler"`
+
+	// The value used by the constant upsampler
+	FillValue *float64 `json:"fillValue,omitempty"`
+
+	// Align the points to multiples of the window instead of to the start of the time range
+	AlignToWindow bool `json:"alignToWindow,omitempty"`
+}
+
+// QueryType = anomaly
+type AnomalyQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The anomaly detection or forecast model
+	Model mathexp.AnomalyModel `json:"model"`
+
+	// Number of points before each point used as its baseline by the zscore and mad models.
+	// The whole series is used when not set
+	Window int `json:"window,omitempty"`
+
+	// Length of a season for the holt_winters and seasonal models
+	Season string `json:"season,omitempty" jsonschema:"example=1d,example=1w"`
+
+	// Number of previous seasons averaged by the seasonal model, defaults to 1
+	Seasons int `json:"seasons,omitempty"`
+
+	// Smoothing factor of the level for the holt_winters model, defaults to 0.5
+	Alpha *float64 `json:"alpha,omitempty"`
+
+	// Smoothing factor of the trend for the holt_winters model, defaults to 0.1
+	Beta *float64 `json:"beta,omitempty"`
+
+	// Smoothing factor of the season for the holt_winters model, defaults to 0.1
+	Gamma *float64 `json:"gamma,omitempty"`
+
+	// How far past the last point the holt_winters model forecasts, at most as many points as the series has
+	Horizon string `json:"horizon,omitempty" jsonschema:"example=1h"`
 }
 
 type ThresholdQuery struct {
@@ -27,6 +27,7 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				reflect.TypeOf(ReduceModeDrop),       // pick an example value (not the root)
 				reflect.TypeOf(ThresholdIsAbove),
 				reflect.TypeOf(classic.ConditionOperatorAnd),
+				reflect.TypeOf(mathexp.AnomalyModelZScore),
 			},
 		})
 	require.NoError(t, err)
@@ -161,6 +162,29 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				},
 			},
 		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeAnomaly),
+			GoType:         reflect.TypeOf(&AnomalyQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "Robust outlier score of A",
+					SaveModel: data.AsUnstructured(AnomalyQuery{
+						Expression: "$A",
+						Model:      mathexp.AnomalyModelMAD,
+						Window:     60,
+					}),
+				},
+				{
+					Name: "Forecast A one hour ahead",
+					SaveModel: data.AsUnstructured(AnomalyQuery{
+						Expression: "$A",
+						Model:      mathexp.AnomalyModelHoltWinters,
+						Season:     "1d",
+						Horizon:    "1h",
+					}),
+				},
+			},
+		},
 	)
 
 	require.NoError(t, err)
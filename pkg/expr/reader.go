@@ -105,7 +105,8 @@ func (h *ExpressionQueryReader) ReadQuery(
 		if err == nil {
 			tr := gtime.NewTimeRange(common.TimeRange.From, common.TimeRange.To)
 			eq.Properties = q
-			eq.Command, err = NewResampleCommand(common.RefID,
+			var cmd *ResampleCommand
+			cmd, err = NewResampleCommand(common.RefID,
 				q.Window,
 				referenceVar,
 				q.Downsampler,
@@ -115,6 +116,21 @@ func (h *ExpressionQueryReader) ReadQuery(
 					To:   tr.GetToAsTimeUTC(),
 				},
 			)
+			if err == nil {
+				err = cmd.setOptions(q.FillValue, q.AlignToWindow)
+				eq.Command = cmd
+			}
+		}
+
+	case QueryTypeAnomaly:
+		q := &AnomalyQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
+		}
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewAnomalyCommand(common.RefID, referenceVar, *q)
 		}
 
 	case QueryTypeClassic:
@@ -143,7 +159,10 @@ func (h *ExpressionQueryReader) ReadQuery(
 		if err == nil {
 			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
 		}
-		if err == nil {
+		if err == nil && hasSeverityLevels(q.Conditions) {
+			eq.Properties = q
+			eq.Command, err = NewSeverityThresholdCommand(common.RefID, referenceVar, q.Conditions)
+		} else if err == nil {
 			// we only support one condition for now, we might want to turn this in to "OR" expressions later
 			if len(q.Conditions) != 1 {
 				return eq, fmt.Errorf("threshold expression requires exactly one condition")
@@ -160,3 +160,49 @@ func TestReaderReduceMode(t *testing.T) {
 		})
 	}
 }
+
+func TestReaderResampleOptions(t *testing.T) {
+	read := func(t *testing.T, body string) (ExpressionQuery, error) {
+		t.Helper()
+		var q data.DataQuery
+		require.NoError(t, json.Unmarshal([]byte(body), &q))
+		raw, err := json.Marshal(q)
+		require.NoError(t, err)
+		iter, err := jsoniter.ParseBytes(jsoniter.ConfigDefault, raw)
+		require.NoError(t, err)
+		return NewExpressionQueryReader(featuremgmt.WithFeatures()).ReadQuery(q, iter)
+	}
+
+	t.Run("fillValue and alignToWindow are set", func(t *testing.T) {
+		eq, err := read(t, `{
+			"refId": "B",
+			"datasource": {"type": "__expr__", "uid": "__expr__"},
+			"type": "resample",
+			"expression": "A",
+			"window": "1m",
+			"downsampler": "mean",
+			"upsampler": "constant",
+			"fillValue": 5,
+			"alignToWindow": true,
+			"timeRange": {"from": "now-1h", "to": "now"}
+		}`)
+		require.NoError(t, err)
+		cmd, ok := eq.Command.(*ResampleCommand)
+		require.True(t, ok)
+		require.Equal(t, mathexp.ResampleOptions{FillValue: 5, AlignToInterval: true}, cmd.Options)
+	})
+
+	t.Run("constant upsampler requires fillValue", func(t *testing.T) {
+		_, err := read(t, `{
+			"refId": "B",
+			"datasource": {"type": "__expr__", "uid": "__expr__"},
+			"type": "resample",
+			"expression": "A",
+			"window": "1m",
+			"downsampler": "mean",
+			"upsampler": "constant",
+			"timeRange": {"from": "now-1h", "to": "now"}
+		}`)
+		require.ErrorContains(t, err, "fillValue must be specified")
+	})
+}
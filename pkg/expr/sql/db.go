@@ -1,26 +1,497 @@
 package sql
 
 import (
-	"errors"
+	"context"
+	gosql "database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/util/sqliteutil"
 )
 
+// driverName is the sqlite3 driver of the engine. The queries are written by
+// dashboard editors, so the driver restricts every connection to its own
+// in-memory database.
+const driverName = "sqlite3_expr"
+
+func init() {
+	sqliteutil.RegisterRestrictedDriver(driverName)
+}
+
+// DB is an in-process SQL engine. Every call opens a private in-memory
+// SQLite database, so nothing is shared between queries.
 type DB struct {
 }
 
+// TablesList returns the tables referenced by the statement.
 func (db *DB) TablesList(rawSQL string) ([]string, error) {
-	return nil, errors.New("not implemented")
+	return TablesList(rawSQL)
 }
 
+// RunCommands executes the commands in order and returns the rows of the
+// last one as a JSON array of objects.
 func (db *DB) RunCommands(commands []string) (string, error) {
-	return "", errors.New("not implemented")
+	ctx := context.Background()
+	conn, err := db.open()
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = conn.Close() }()
+
+	var out []map[string]any
+	for i, cmd := range commands {
+		if i < len(commands)-1 {
+			if _, err := conn.ExecContext(ctx, cmd); err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		names, columns, err := query(ctx, conn, cmd)
+		if err != nil {
+			return "", err
+		}
+		out = make([]map[string]any, 0)
+		for row := 0; len(columns) > 0 && row < len(columns[0]); row++ {
+			m := make(map[string]any, len(names))
+			for c, name := range names {
+				m[name] = columns[c][row]
+			}
+			out = append(out, m)
+		}
+	}
+
+	b, err := json.Marshal(out)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
 }
 
+// QueryFramesInto loads the frames as tables, named after their RefID, runs
+// the query and writes the result set into f.
 func (db *DB) QueryFramesInto(name string, query string, frames []*data.Frame, f *data.Frame) error {
-	return errors.New("not implemented")
+	ctx := context.Background()
+	conn, err := db.open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	for _, t := range tablesFromFrames(frames) {
+		if err := t.load(ctx, conn); err != nil {
+			return fmt.Errorf("failed to load table %s: %w", t.name, err)
+		}
+	}
+
+	names, columns, err := queryColumns(ctx, conn, query)
+	if err != nil {
+		return err
+	}
+
+	f.Name = name
+	f.Fields = make([]*data.Field, len(names))
+	for i, n := range names {
+		f.Fields[i] = fieldFromValues(n, columns[i])
+	}
+	return nil
+}
+
+func (db *DB) open() (*gosql.DB, error) {
+	conn, err := gosql.Open(driverName, ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	// every connection to :memory: is its own database
+	conn.SetMaxOpenConns(1)
+	return conn, nil
 }
 
 func NewInMemoryDB() *DB {
 	return &DB{}
 }
+
+func query(ctx context.Context, conn *gosql.DB, q string) ([]string, [][]any, error) {
+	names, columns, err := queryColumns(ctx, conn, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, col := range columns {
+		for i, v := range col {
+			if b, ok := v.([]byte); ok {
+				col[i] = string(b)
+			}
+		}
+	}
+	return names, columns, nil
+}
+
+// queryColumns runs the query and returns the column names and the values
+// of each column.
+func queryColumns(ctx context.Context, conn *gosql.DB, q string) ([]string, [][]any, error) {
+	rows, err := conn.QueryContext(ctx, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := make([][]any, len(names))
+	for rows.Next() {
+		row := make([]any, len(names))
+		ptrs := make([]any, len(names))
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, nil, err
+		}
+		for i, v := range row {
+			columns[i] = append(columns[i], v)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return names, columns, nil
+}
+
+// table is a set of frames with the same RefID loaded into one table. Label
+// keys of the fields become text columns.
+type table struct {
+	name    string
+	columns []column
+	frames  []*data.Frame
+}
+
+type column struct {
+	name    string
+	sqlType string
+	isLabel bool
+}
+
+func tablesFromFrames(frames []*data.Frame) []*table {
+	tables := []*table{}
+	byName := map[string]*table{}
+	for _, frame := range frames {
+		if frame == nil || len(frame.Fields) == 0 {
+			continue
+		}
+		t, ok := byName[frame.RefID]
+		if !ok {
+			t = &table{name: frame.RefID}
+			byName[frame.RefID] = t
+			tables = append(tables, t)
+		}
+		t.frames = append(t.frames, frame)
+		for _, field := range frame.Fields {
+			t.addColumn(field.Name, sqlType(field.Type()), false)
+		}
+		for _, field := range frame.Fields {
+			for k := range field.Labels {
+				t.addColumn(k, "TEXT", true)
+			}
+		}
+	}
+	return tables
+}
+
+func (t *table) addColumn(name, typ string, isLabel bool) {
+	for i, c := range t.columns {
+		if c.name != name {
+			continue
+		}
+		if c.isLabel != isLabel {
+			// a field and a label with the same name, the field wins
+			if !isLabel {
+				t.columns[i] = column{name: name, sqlType: typ}
+			}
+			return
+		}
+		if c.sqlType != typ {
+			t.columns[i].sqlType = widenType(c.sqlType, typ)
+		}
+		return
+	}
+	t.columns = append(t.columns, column{name: name, sqlType: typ, isLabel: isLabel})
+}
+
+func (t *table) load(ctx context.Context, conn *gosql.DB) error {
+	defs := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		defs[i] = quoteIdent(c.name) + " " + c.sqlType
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdent(t.name), strings.Join(defs, ", "))); err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, frame := range t.frames {
+		if err := t.insertFrame(ctx, tx, frame); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (t *table) insertFrame(ctx context.Context, tx *gosql.Tx, frame *data.Frame) error {
+	names := make([]string, 0, len(t.columns))
+	fields := make([]*data.Field, 0, len(t.columns))
+	labels := make([]*string, 0, len(t.columns))
+	for _, c := range t.columns {
+		if c.isLabel {
+			if v, ok := labelValue(frame, c.name); ok {
+				names = append(names, quoteIdent(c.name))
+				fields = append(fields, nil)
+				labels = append(labels, &v)
+			}
+			continue
+		}
+		if field, _ := frame.FieldByName(c.name); field != nil {
+			names = append(names, quoteIdent(c.name))
+			fields = append(fields, field)
+			labels = append(labels, nil)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		quoteIdent(t.name), strings.Join(names, ", "), placeholders))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	rows, err := frame.RowLen()
+	if err != nil {
+		return err
+	}
+	args := make([]any, len(names))
+	for row := 0; row < rows; row++ {
+		for i, field := range fields {
+			if field == nil {
+				args[i] = *labels[i]
+				continue
+			}
+			args[i] = sqlValue(field, row)
+		}
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// labelValue returns the value of the label on the first field of the frame
+// that has it.
+func labelValue(frame *data.Frame, key string) (string, bool) {
+	for _, field := range frame.Fields {
+		if v, ok := field.Labels[key]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func sqlType(ft data.FieldType) string {
+	switch ft.NonNullableType() {
+	case data.FieldTypeTime:
+		return "TIMESTAMP"
+	case data.FieldTypeBool:
+		return "BOOLEAN"
+	case data.FieldTypeFloat32, data.FieldTypeFloat64:
+		return "REAL"
+	case data.FieldTypeInt8, data.FieldTypeInt16, data.FieldTypeInt32, data.FieldTypeInt64,
+		data.FieldTypeUint8, data.FieldTypeUint16, data.FieldTypeUint32, data.FieldTypeUint64:
+		return "INTEGER"
+	default:
+		return "TEXT"
+	}
+}
+
+func widenType(a, b string) string {
+	if (a == "INTEGER" && b == "REAL") || (a == "REAL" && b == "INTEGER") {
+		return "REAL"
+	}
+	return "TEXT"
+}
+
+func sqlValue(field *data.Field, idx int) any {
+	v, ok := field.ConcreteAt(idx)
+	if !ok {
+		return nil
+	}
+	switch t := v.(type) {
+	case uint64:
+		if t > 1<<63-1 {
+			return float64(t)
+		}
+		return int64(t)
+	case float32:
+		return float64(t)
+	case json.RawMessage:
+		return string(t)
+	case time.Time:
+		return t.UTC()
+	}
+	return v
+}
+
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// fieldFromValues converts a result column into a field. The field type is
+// taken from the values, since SQLite is dynamically typed. The field is
+// nullable only when the column contains NULL.
+func fieldFromValues(name string, values []any) *data.Field {
+	var (
+		hasNull                                       bool
+		hasTime, hasInt, hasFloat, hasBool, hasString bool
+	)
+	for _, v := range values {
+		switch v.(type) {
+		case nil:
+			hasNull = true
+		case time.Time:
+			hasTime = true
+		case int64:
+			hasInt = true
+		case float64:
+			hasFloat = true
+		case bool:
+			hasBool = true
+		default:
+			hasString = true
+		}
+	}
+
+	kinds := 0
+	for _, b := range []bool{hasTime, hasInt || hasFloat, hasBool, hasString} {
+		if b {
+			kinds++
+		}
+	}
+
+	switch {
+	case kinds != 1 || hasString:
+		return stringField(name, values, hasNull)
+	case hasTime:
+		if hasNull {
+			vals := make([]*time.Time, len(values))
+			for i, v := range values {
+				if t, ok := v.(time.Time); ok {
+					t = t.UTC()
+					vals[i] = &t
+				}
+			}
+			return data.NewField(name, nil, vals)
+		}
+		vals := make([]time.Time, len(values))
+		for i, v := range values {
+			vals[i] = v.(time.Time).UTC()
+		}
+		return data.NewField(name, nil, vals)
+	case hasFloat:
+		if hasNull {
+			vals := make([]*float64, len(values))
+			for i, v := range values {
+				if f, ok := toFloat(v); ok {
+					vals[i] = &f
+				}
+			}
+			return data.NewField(name, nil, vals)
+		}
+		vals := make([]float64, len(values))
+		for i, v := range values {
+			vals[i], _ = toFloat(v)
+		}
+		return data.NewField(name, nil, vals)
+	case hasInt:
+		if hasNull {
+			vals := make([]*int64, len(values))
+			for i, v := range values {
+				if n, ok := v.(int64); ok {
+					vals[i] = &n
+				}
+			}
+			return data.NewField(name, nil, vals)
+		}
+		vals := make([]int64, len(values))
+		for i, v := range values {
+			vals[i] = v.(int64)
+		}
+		return data.NewField(name, nil, vals)
+	default:
+		if hasNull {
+			vals := make([]*bool, len(values))
+			for i, v := range values {
+				if b, ok := v.(bool); ok {
+					vals[i] = &b
+				}
+			}
+			return data.NewField(name, nil, vals)
+		}
+		vals := make([]bool, len(values))
+		for i, v := range values {
+			vals[i] = v.(bool)
+		}
+		return data.NewField(name, nil, vals)
+	}
+}
+
+func stringField(name string, values []any, nullable bool) *data.Field {
+	toString := func(v any) string {
+		switch t := v.(type) {
+		case []byte:
+			return string(t)
+		case string:
+			return t
+		case time.Time:
+			return t.UTC().Format(time.RFC3339Nano)
+		default:
+			return fmt.Sprint(t)
+		}
+	}
+	if nullable {
+		vals := make([]*string, len(values))
+		for i, v := range values {
+			if v != nil {
+				s := toString(v)
+				vals[i] = &s
+			}
+		}
+		return data.NewField(name, nil, vals)
+	}
+	vals := make([]string, len(values))
+	for i, v := range values {
+		vals[i] = toString(v)
+	}
+	return data.NewField(name, nil, vals)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case int64:
+		return float64(t), true
+	}
+	return 0, false
+}
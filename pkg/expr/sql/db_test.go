@@ -0,0 +1,134 @@
+package sql
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryFramesInto(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	hosts := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b", "c"}),
+		data.NewField("cpu", nil, []float64{1.5, 2.5, 3}),
+	)
+	hosts.RefID = "A"
+
+	owners := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b"}),
+		data.NewField("team", nil, []string{"x", "y"}),
+	)
+	owners.RefID = "B"
+
+	series := func(host string, vals ...float64) *data.Frame {
+		times := make([]time.Time, len(vals))
+		for i := range vals {
+			times[i] = t0.Add(time.Duration(i) * time.Minute)
+		}
+		f := data.NewFrame("",
+			data.NewField("Time", nil, times),
+			data.NewField("C", data.Labels{"host": host}, vals),
+		)
+		f.RefID = "C"
+		return f
+	}
+
+	t.Run("join", func(t *testing.T) {
+		db := NewInMemoryDB()
+		f := &data.Frame{}
+		err := db.QueryFramesInto("res", `SELECT A.host, team, cpu FROM A JOIN B ON A.host = B.host ORDER BY A.host`,
+			[]*data.Frame{hosts, owners}, f)
+		require.NoError(t, err)
+
+		require.Equal(t, "res", f.Name)
+		require.Len(t, f.Fields, 3)
+		require.Equal(t, 2, f.Rows())
+		require.Equal(t, "b", f.Fields[0].At(1))
+		require.Equal(t, "y", f.Fields[1].At(1))
+		require.Equal(t, 2.5, f.Fields[2].At(1))
+	})
+
+	t.Run("labels become columns", func(t *testing.T) {
+		db := NewInMemoryDB()
+		f := &data.Frame{}
+		err := db.QueryFramesInto("res", `SELECT host, count(*) AS n, sum(C) AS total FROM C GROUP BY host ORDER BY host`,
+			[]*data.Frame{series("a", 1, 2, 3), series("b", 4, 5)}, f)
+		require.NoError(t, err)
+
+		require.Equal(t, 2, f.Rows())
+		require.Equal(t, data.FieldTypeInt64, f.Fields[1].Type())
+		require.Equal(t, int64(3), f.Fields[1].At(0))
+		require.Equal(t, 6.0, f.Fields[2].At(0))
+		require.Equal(t, 9.0, f.Fields[2].At(1))
+	})
+
+	t.Run("window functions and time", func(t *testing.T) {
+		db := NewInMemoryDB()
+		f := &data.Frame{}
+		err := db.QueryFramesInto("res", `SELECT Time, C - lag(C) OVER (ORDER BY Time) AS diff FROM C ORDER BY Time`,
+			[]*data.Frame{series("a", 1, 3, 6)}, f)
+		require.NoError(t, err)
+
+		require.Equal(t, 3, f.Rows())
+		require.Equal(t, data.FieldTypeTime, f.Fields[0].Type())
+		require.Equal(t, t0.Add(time.Minute), f.Fields[0].At(1))
+		require.Equal(t, data.FieldTypeNullableFloat64, f.Fields[1].Type())
+		require.Nil(t, f.Fields[1].At(0))
+		require.Equal(t, 3.0, *(f.Fields[1].At(2).(*float64)))
+	})
+
+	t.Run("unknown table", func(t *testing.T) {
+		db := NewInMemoryDB()
+		err := db.QueryFramesInto("res", `SELECT * FROM Z`, []*data.Frame{hosts}, &data.Frame{})
+		require.Error(t, err)
+	})
+}
+
+func TestRunCommands(t *testing.T) {
+	db := NewInMemoryDB()
+	out, err := db.RunCommands([]string{
+		"CREATE TABLE t (a INTEGER, b TEXT)",
+		"INSERT INTO t VALUES (1, 'x')",
+		"SELECT a, b FROM t",
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"a":1,"b":"x"}]`, out)
+}
+
+func TestRestrictedStatements(t *testing.T) {
+	dir := t.TempDir()
+	other := filepath.Join(dir, "grafana.db")
+	frame := data.NewFrame("", data.NewField("v", nil, []float64{1}))
+	frame.RefID = "A"
+
+	testCases := []struct {
+		name  string
+		query string
+	}{
+		{name: "attach", query: fmt.Sprintf(`ATTACH DATABASE '%s' AS g`, other)},
+		{name: "attach after another statement", query: fmt.Sprintf(`SELECT 1;/**/attach/**/database '%s' as g`, other)},
+		{name: "attach in a later statement of the query", query: fmt.Sprintf(`SELECT * FROM A; ATTACH DATABASE '%s' AS g; SELECT 1`, other)},
+		{name: "vacuum into a file", query: fmt.Sprintf(`VACUUM INTO '%s'`, other)},
+		{name: "load extension", query: `SELECT load_extension('/tmp/ext.so')`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := NewInMemoryDB()
+
+			err := db.QueryFramesInto("res", tc.query, []*data.Frame{frame}, &data.Frame{})
+			require.Error(t, err)
+
+			_, err = db.RunCommands([]string{tc.query})
+			require.Error(t, err)
+
+			_, err = os.Stat(other)
+			require.ErrorIs(t, err, os.ErrNotExist)
+		})
+	}
+}
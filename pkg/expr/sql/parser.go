@@ -1,82 +1,271 @@
 package sql
 
 import (
-	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/grafana/grafana/pkg/infra/log"
-	"github.com/jeremywohl/flatten"
-)
-
-const (
-	TABLE_NAME    = "table_name"
-	ERROR         = ".error"
-	ERROR_MESSAGE = ".error_message"
 )
 
 var logger = log.New("sql_expr")
 
 // TablesList returns a list of tables for the sql statement
 func TablesList(rawSQL string) ([]string, error) {
-	duckDB := NewInMemoryDB()
-	rawSQL = strings.Replace(rawSQL, "'", "''", -1)
-	cmd := fmt.Sprintf("SELECT json_serialize_sql('%s')", rawSQL)
-	ret, err := duckDB.RunCommands([]string{cmd})
+	tokens, err := tokenize(rawSQL)
 	if err != nil {
-		logger.Error("error serializing sql", "error", err.Error(), "sql", rawSQL, "cmd", cmd)
-		return nil, fmt.Errorf("error serializing sql: %s", err.Error())
+		logger.Error("error tokenizing sql", "error", err.Error(), "sql", rawSQL)
+		return nil, fmt.Errorf("error tokenizing sql: %s", err.Error())
 	}
 
-	ast := []map[string]any{}
-	err = json.Unmarshal([]byte(ret), &ast)
+	tables, err := tablesFromTokens(tokens)
 	if err != nil {
-		logger.Error("error converting json sql to ast", "error", err.Error(), "ret", ret)
-		return nil, fmt.Errorf("error converting json to ast: %s", err.Error())
+		logger.Error("error in sql", "error", err.Error(), "sql", rawSQL)
+		return nil, fmt.Errorf("error in sql: %s", err.Error())
 	}
+	sort.Strings(tables)
 
-	return tablesFromAST(ast)
+	logger.Debug("tables found in sql", "tables", tables)
+
+	return tables, nil
 }
 
-// tablesFromAST returns a list of tables from the ast
-func tablesFromAST(ast []map[string]any) ([]string, error) {
-	flat, err := flatten.Flatten(ast[0], "", flatten.DotStyle)
-	if err != nil {
-		logger.Error("error flattening ast", "error", err.Error(), "ast", ast)
-		return nil, fmt.Errorf("error flattening ast: %s", err.Error())
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenQuoted
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func (t token) is(kind tokenKind, text string) bool {
+	return t.kind == kind && strings.EqualFold(t.text, text)
+}
+
+func (t token) isKeyword(words ...string) bool {
+	if t.kind != tokenWord {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(t.text, w) {
+			return true
+		}
 	}
+	return false
+}
 
-	tables := []string{}
-	for k, v := range flat {
-		if strings.HasSuffix(k, ERROR) {
-			v, ok := v.(bool)
-			if ok && v {
-				logger.Error("error in sql", "error", k)
-				return nil, astError(k, flat)
+// tokenize splits the statement into words, quoted identifiers, literals and
+// symbols. Comments and whitespace are dropped.
+func tokenize(rawSQL string) ([]token, error) {
+	src := []rune(rawSQL)
+	tokens := []token{}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '-' && i+1 < len(src) && src[i+1] == '-':
+			for i < len(src) && src[i] != '\n' {
+				i++
 			}
-		}
-		if strings.Contains(k, TABLE_NAME) {
-			table, ok := v.(string)
-			if ok && !existsInList(table, tables) {
-				tables = append(tables, v.(string))
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := -1
+			for j := i + 2; j+1 < len(src); j++ {
+				if src[j] == '*' && src[j+1] == '/' {
+					end = j
+					break
+				}
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i = end + 2
+		case c == '\'':
+			text, n, err := readQuoted(src[i:], '\'', '\'')
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text})
+			i += n
+		case c == '"' || c == '`':
+			text, n, err := readQuoted(src[i:], c, c)
+			if err != nil {
+				return nil, err
 			}
+			tokens = append(tokens, token{kind: tokenQuoted, text: text})
+			i += n
+		case c == '[':
+			text, n, err := readQuoted(src[i:], '[', ']')
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenQuoted, text: text})
+			i += n
+		case isWordStart(c):
+			start := i
+			for i < len(src) && isWordPart(src[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(src[start:i])})
+		case unicode.IsDigit(c):
+			start := i
+			for i < len(src) && (unicode.IsDigit(src[i]) || src[i] == '.' || unicode.IsLetter(src[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(src[start:i])})
+		default:
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(c)})
+			i++
 		}
 	}
-	sort.Strings(tables)
 
-	logger.Debug("tables found in sql", "tables", tables)
+	return tokens, nil
+}
+
+// readQuoted reads a quoted token starting at src[0]. A doubled closing
+// quote is an escaped quote. It returns the unquoted text and the number of
+// runes consumed.
+func readQuoted(src []rune, open, closing rune) (string, int, error) {
+	var sb strings.Builder
+	for i := 1; i < len(src); i++ {
+		if src[i] != closing {
+			sb.WriteRune(src[i])
+			continue
+		}
+		if open == closing && i+1 < len(src) && src[i+1] == closing {
+			sb.WriteRune(closing)
+			i++
+			continue
+		}
+		return sb.String(), i + 1, nil
+	}
+	return "", 0, fmt.Errorf("unterminated quote %q", string(open))
+}
+
+func isWordStart(c rune) bool {
+	return c == '_' || unicode.IsLetter(c)
+}
+
+func isWordPart(c rune) bool {
+	return c == '_' || c == '$' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// clauseKeywords are the words that may follow a table reference (and its
+// alias) in a FROM clause.
+var clauseKeywords = []string{
+	"WHERE", "GROUP", "ORDER", "LIMIT", "OFFSET", "HAVING", "WINDOW",
+	"JOIN", "INNER", "LEFT", "RIGHT", "FULL", "CROSS", "OUTER", "NATURAL",
+	"ON", "USING", "UNION", "EXCEPT", "INTERSECT", "RETURNING",
+}
+
+// tablesFromTokens returns the names referenced as tables in FROM and JOIN
+// clauses. Names of common table expressions are included, as they can not
+// be told apart from tables without resolving the query.
+func tablesFromTokens(tokens []token) ([]string, error) {
+	tables := []string{}
+	add := func(name string) {
+		if !existsInList(name, tables) {
+			tables = append(tables, name)
+		}
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		switch {
+		case tokens[i].isKeyword("FROM"):
+			next, err := readTableList(tokens, i+1, true, add)
+			if err != nil {
+				return nil, err
+			}
+			i = next - 1
+		case tokens[i].isKeyword("JOIN"):
+			next, err := readTableList(tokens, i+1, false, add)
+			if err != nil {
+				return nil, err
+			}
+			i = next - 1
+		}
+	}
 
 	return tables, nil
 }
 
-func astError(k string, flat map[string]any) error {
-	key := strings.Replace(k, ERROR, "", 1)
-	message, ok := flat[key+ERROR_MESSAGE]
-	if !ok {
-		message = "unknown error in sql"
+// readTableList reads the table references starting at pos. When list is
+// true, comma separated references are read. It returns the position of the
+// first token that is not part of the references.
+func readTableList(tokens []token, pos int, list bool, add func(string)) (int, error) {
+	for {
+		next, err := readTableRef(tokens, pos, add)
+		if err != nil {
+			return 0, err
+		}
+		pos = next
+		if !list || pos >= len(tokens) || !tokens[pos].is(tokenSymbol, ",") {
+			return pos, nil
+		}
+		pos++
+	}
+}
+
+func readTableRef(tokens []token, pos int, add func(string)) (int, error) {
+	if pos >= len(tokens) {
+		return pos, fmt.Errorf("missing table name")
+	}
+
+	t := tokens[pos]
+	if t.is(tokenSymbol, "(") {
+		// Either a subquery, which is picked up by the outer scan, or a
+		// parenthesized join of table references.
+		if pos+1 < len(tokens) && (tokens[pos+1].kind == tokenQuoted ||
+			(tokens[pos+1].kind == tokenWord && !tokens[pos+1].isKeyword("SELECT", "WITH", "VALUES"))) {
+			return readTableList(tokens, pos+1, true, add)
+		}
+		return pos + 1, nil
+	}
+	if t.kind != tokenWord && t.kind != tokenQuoted {
+		return pos, fmt.Errorf("unexpected %q after FROM or JOIN", t.text)
+	}
+
+	name := t.text
+	pos++
+	for pos+1 < len(tokens) && tokens[pos].is(tokenSymbol, ".") &&
+		(tokens[pos+1].kind == tokenWord || tokens[pos+1].kind == tokenQuoted) {
+		name += "." + tokens[pos+1].text
+		pos += 2
+	}
+
+	// Table valued functions such as json_each(...) are not tables.
+	if pos < len(tokens) && tokens[pos].is(tokenSymbol, "(") {
+		return pos, nil
 	}
-	return fmt.Errorf("error in sql: %s", message)
+	add(name)
+
+	// Optional alias.
+	if pos < len(tokens) && tokens[pos].isKeyword("AS") {
+		pos++
+		if pos >= len(tokens) || (tokens[pos].kind != tokenWord && tokens[pos].kind != tokenQuoted) {
+			return pos, fmt.Errorf("missing alias for table %q", name)
+		}
+		pos++
+	} else if pos < len(tokens) && (tokens[pos].kind == tokenQuoted ||
+		(tokens[pos].kind == tokenWord && !tokens[pos].isKeyword(clauseKeywords...))) {
+		pos++
+	}
+
+	if pos < len(tokens) && (tokens[pos].kind == tokenQuoted ||
+		(tokens[pos].kind == tokenWord && !tokens[pos].isKeyword(clauseKeywords...))) {
+		return pos, fmt.Errorf("syntax error near %q", tokens[pos].text)
+	}
+
+	return pos, nil
 }
 
 func existsInList(table string, list []string) bool {
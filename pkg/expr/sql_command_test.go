@@ -6,7 +6,6 @@ import (
 )
 
 func TestNewCommand(t *testing.T) {
-	t.Skip()
 	cmd, err := NewSQLCommand("a", "select a from foo, bar")
 	if err != nil && strings.Contains(err.Error(), "feature is not enabled") {
 		return
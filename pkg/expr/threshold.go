@@ -98,6 +98,10 @@ func UnmarshalThresholdCommand(rn *rawNode, features featuremgmt.FeatureToggles)
 	}
 	referenceVar := cmdConfig.Expression
 
+	if hasSeverityLevels(cmdConfig.Conditions) {
+		return NewSeverityThresholdCommand(rn.RefID, referenceVar, cmdConfig.Conditions)
+	}
+
 	// we only support one condition for now, we might want to turn this in to "OR" expressions later
 	if len(cmdConfig.Conditions) != 1 {
 		return nil, fmt.Errorf("threshold expression requires exactly one condition")
@@ -137,33 +141,41 @@ func (tc *ThresholdCommand) Execute(_ context.Context, _ time.Time, vars mathexp
 		if maybeValue == nil {
 			return nil
 		}
-		result := tc.predicate.Eval(*maybeValue)
-		if tc.Invert {
-			result = !result
-		}
-		if result {
+		if tc.eval(*maybeValue) {
 			return util.Pointer(float64(1))
 		}
 		return util.Pointer(float64(0))
 	}
+	return mapThresholdValues(tc.RefID, vars[tc.ReferenceVar], eval)
+}
+
+func (tc *ThresholdCommand) eval(f float64) bool {
+	result := tc.predicate.Eval(f)
+	if tc.Invert {
+		result = !result
+	}
+	return result
+}
 
-	refVarResult := vars[tc.ReferenceVar]
+// mapThresholdValues applies eval to the value of every number, scalar and
+// series point of the results.
+func mapThresholdValues(refID string, refVarResult mathexp.Results, eval func(*float64) *float64) (mathexp.Results, error) {
 	newRes := mathexp.Results{Values: make(mathexp.Values, 0, len(refVarResult.Values))}
 	for _, val := range refVarResult.Values {
 		switch v := val.(type) {
 		case mathexp.Series:
-			s := mathexp.NewSeries(tc.RefID, v.GetLabels(), v.Len())
+			s := mathexp.NewSeries(refID, v.GetLabels(), v.Len())
 			for i := 0; i < v.Len(); i++ {
 				t, value := v.GetPoint(i)
 				s.SetPoint(i, t, eval(value))
 			}
 			newRes.Values = append(newRes.Values, s)
 		case mathexp.Number:
-			copyV := mathexp.NewNumber(tc.RefID, v.GetLabels())
+			copyV := mathexp.NewNumber(refID, v.GetLabels())
 			copyV.SetValue(eval(v.GetFloat64Value()))
 			newRes.Values = append(newRes.Values, copyV)
 		case mathexp.Scalar:
-			copyV := mathexp.NewScalar(tc.RefID, eval(v.GetFloat64Value()))
+			copyV := mathexp.NewScalar(refID, eval(v.GetFloat64Value()))
 			newRes.Values = append(newRes.Values, copyV)
 		case mathexp.NoData:
 			newRes.Values = append(newRes.Values, mathexp.NewNoData())
@@ -198,6 +210,8 @@ type ThresholdConditionJSON struct {
 	Evaluator        ConditionEvalJSON  `json:"evaluator"`
 	UnloadEvaluator  *ConditionEvalJSON `json:"unloadEvaluator,omitempty"`
 	LoadedDimensions *data.Frame        `json:"loadedDimensions,omitempty"`
+	// Severity level reported when the condition is met, ordered from the least to the most severe
+	Severity string `json:"severity,omitempty"`
 }
 
 // IsHysteresisExpression returns true if the raw model describes a hysteresis command:
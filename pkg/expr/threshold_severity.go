@@ -0,0 +1,110 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// SeverityThresholdCommand is a ThresholdCommand with several thresholds, each
+// naming a severity level. Levels are ordered from the least to the most severe.
+// The result of the execution of the command is, for each value, the position of
+// the most severe level whose threshold is met, starting at 1, or 0 if none is met.
+// The result is therefore non-zero whenever any threshold is met, and can be used
+// as an alert condition. Use Severity to map the result back to a name.
+type SeverityThresholdCommand struct {
+	RefID        string
+	ReferenceVar string
+	Levels       []SeverityLevel
+}
+
+// SeverityLevel is a single level of a SeverityThresholdCommand.
+type SeverityLevel struct {
+	Severity  string
+	Threshold ThresholdCommand
+}
+
+// NewSeverityThresholdCommand creates a SeverityThresholdCommand from threshold
+// conditions that all have a distinct severity.
+func NewSeverityThresholdCommand(refID, referenceVar string, conditions []ThresholdConditionJSON) (*SeverityThresholdCommand, error) {
+	if len(conditions) == 0 {
+		return nil, fmt.Errorf("threshold expression requires at least one condition")
+	}
+	levels := make([]SeverityLevel, 0, len(conditions))
+	seen := make(map[string]struct{}, len(conditions))
+	for i, c := range conditions {
+		if c.Severity == "" {
+			return nil, fmt.Errorf("invalid condition %d: all conditions require a severity when one of them has a severity", i)
+		}
+		if _, ok := seen[c.Severity]; ok {
+			return nil, fmt.Errorf("invalid condition %d: duplicate severity %q", i, c.Severity)
+		}
+		seen[c.Severity] = struct{}{}
+		if c.UnloadEvaluator != nil {
+			return nil, fmt.Errorf("invalid condition %d: recovery thresholds are not supported with severity levels", i)
+		}
+		threshold, err := NewThresholdCommand(refID, referenceVar, c.Evaluator.Type, c.Evaluator.Params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid condition %d: %w", i, err)
+		}
+		levels = append(levels, SeverityLevel{Severity: c.Severity, Threshold: *threshold})
+	}
+	return &SeverityThresholdCommand{
+		RefID:        refID,
+		ReferenceVar: referenceVar,
+		Levels:       levels,
+	}, nil
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (sc *SeverityThresholdCommand) NeedsVars() []string {
+	return []string{sc.ReferenceVar}
+}
+
+func (sc *SeverityThresholdCommand) Execute(_ context.Context, _ time.Time, vars mathexp.Vars, _ tracing.Tracer) (mathexp.Results, error) {
+	eval := func(maybeValue *float64) *float64 {
+		if maybeValue == nil {
+			return nil
+		}
+		return util.Pointer(float64(sc.level(*maybeValue)))
+	}
+	return mapThresholdValues(sc.RefID, vars[sc.ReferenceVar], eval)
+}
+
+// level returns the position of the most severe level met by f, or 0.
+func (sc *SeverityThresholdCommand) level(f float64) int {
+	for i := len(sc.Levels) - 1; i >= 0; i-- {
+		if sc.Levels[i].Threshold.eval(f) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Severity returns the name of the severity level of a result of the command,
+// or an empty string if the result does not meet any level.
+func (sc *SeverityThresholdCommand) Severity(result float64) string {
+	level := int(result)
+	if level < 1 || level > len(sc.Levels) {
+		return ""
+	}
+	return sc.Levels[level-1].Severity
+}
+
+func (sc *SeverityThresholdCommand) Type() string {
+	return TypeThreshold.String()
+}
+
+func hasSeverityLevels(conditions []ThresholdConditionJSON) bool {
+	for _, c := range conditions {
+		if c.Severity != "" {
+			return true
+		}
+	}
+	return false
+}
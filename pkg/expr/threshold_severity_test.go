@@ -0,0 +1,154 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const severityThresholdQuery = `{
+	"expression": "A",
+	"type": "threshold",
+	"conditions": [
+		{"evaluator": {"type": "gt", "params": [80]}, "severity": "warning"},
+		{"evaluator": {"type": "gt", "params": [90]}, "severity": "critical"}
+	]
+}`
+
+func unmarshalThreshold(t *testing.T, query string) (Command, error) {
+	t.Helper()
+	qmap := make(map[string]any)
+	require.NoError(t, json.Unmarshal([]byte(query), &qmap))
+	return UnmarshalThresholdCommand(&rawNode{
+		RefID:    "",
+		Query:    qmap,
+		QueryRaw: []byte(query),
+	}, featuremgmt.WithFeatures(featuremgmt.FlagRecoveryThreshold))
+}
+
+func TestUnmarshalSeverityThresholdCommand(t *testing.T) {
+	t.Run("conditions with severities", func(t *testing.T) {
+		cmd, err := unmarshalThreshold(t, severityThresholdQuery)
+		require.NoError(t, err)
+		require.IsType(t, &SeverityThresholdCommand{}, cmd)
+		sc := cmd.(*SeverityThresholdCommand)
+		require.Equal(t, []string{"A"}, sc.NeedsVars())
+		require.Len(t, sc.Levels, 2)
+		require.Equal(t, "warning", sc.Levels[0].Severity)
+		require.Equal(t, greaterThanPredicate{80}, sc.Levels[0].Threshold.predicate)
+		require.Equal(t, "critical", sc.Levels[1].Severity)
+		require.Equal(t, greaterThanPredicate{90}, sc.Levels[1].Threshold.predicate)
+	})
+
+	testCases := []struct {
+		name          string
+		query         string
+		expectedError string
+	}{
+		{
+			name: "missing severity",
+			query: `{"expression": "A", "type": "threshold", "conditions": [
+				{"evaluator": {"type": "gt", "params": [80]}, "severity": "warning"},
+				{"evaluator": {"type": "gt", "params": [90]}}
+			]}`,
+			expectedError: "all conditions require a severity",
+		},
+		{
+			name: "duplicate severity",
+			query: `{"expression": "A", "type": "threshold", "conditions": [
+				{"evaluator": {"type": "gt", "params": [80]}, "severity": "warning"},
+				{"evaluator": {"type": "gt", "params": [90]}, "severity": "warning"}
+			]}`,
+			expectedError: "duplicate severity",
+		},
+		{
+			name: "recovery threshold",
+			query: `{"expression": "A", "type": "threshold", "conditions": [
+				{"evaluator": {"type": "gt", "params": [80]}, "unloadEvaluator": {"type": "lt", "params": [70]}, "severity": "warning"}
+			]}`,
+			expectedError: "recovery thresholds are not supported",
+		},
+		{
+			name: "several conditions without severities",
+			query: `{"expression": "A", "type": "threshold", "conditions": [
+				{"evaluator": {"type": "gt", "params": [80]}},
+				{"evaluator": {"type": "gt", "params": [90]}}
+			]}`,
+			expectedError: "threshold expression requires exactly one condition",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, err := unmarshalThreshold(t, tc.query)
+			require.Nil(t, cmd)
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestSeverityThresholdExecute(t *testing.T) {
+	cmd, err := unmarshalThreshold(t, severityThresholdQuery)
+	require.NoError(t, err)
+
+	labels := data.Labels{"host": "a"}
+	testCases := []struct {
+		name     string
+		input    mathexp.Value
+		expected mathexp.Value
+	}{
+		{
+			name:     "below all thresholds",
+			input:    newNumber(labels, util.Pointer(float64(50))),
+			expected: newNumber(labels, util.Pointer(float64(0))),
+		},
+		{
+			name:     "warning",
+			input:    newNumber(labels, util.Pointer(float64(85))),
+			expected: newNumber(labels, util.Pointer(float64(1))),
+		},
+		{
+			name:     "critical",
+			input:    newNumber(labels, util.Pointer(float64(95))),
+			expected: newNumber(labels, util.Pointer(float64(2))),
+		},
+		{
+			name:     "null",
+			input:    newNumber(labels, nil),
+			expected: newNumber(labels, nil),
+		},
+		{
+			name:     "series",
+			input:    newSeries(50, 85, 95),
+			expected: newSeries(0, 1, 2),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+				"A": newResults(tc.input),
+			}, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			require.Equal(t, newResults(tc.expected), res)
+		})
+	}
+}
+
+func TestSeverityThresholdCommandSeverity(t *testing.T) {
+	cmd, err := unmarshalThreshold(t, severityThresholdQuery)
+	require.NoError(t, err)
+	sc := cmd.(*SeverityThresholdCommand)
+
+	require.Equal(t, "warning", sc.Severity(1))
+	require.Equal(t, "critical", sc.Severity(2))
+	require.Equal(t, "", sc.Severity(0))
+	require.Equal(t, "", sc.Severity(3))
+}
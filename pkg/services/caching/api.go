@@ -0,0 +1,39 @@
+package caching
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *OSSCachingService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	uidScope := datasources.ScopeProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+
+	s.routeRegister.Group("/api/datasources/uid/:uid/cache", func(r routing.RouteRegister) {
+		r.Post("/clean", middleware.ReqSignedIn, authorize(ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(s.cleanHandler))
+	})
+}
+
+// swagger:route POST /datasources/uid/{uid}/cache/clean datasources cleanDataSourceCache
+//
+// Clean the query and resource cache of a data source.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *OSSCachingService) cleanHandler(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if err := s.InvalidateDatasource(c.Req.Context(), uid); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to clean data source cache", err)
+	}
+	return response.Success("Data source cache cleaned")
+}
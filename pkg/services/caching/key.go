@@ -0,0 +1,102 @@
+package caching
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+const keyPrefix = "caching:"
+
+type queryKeyData struct {
+	OrgID             int64            `json:"orgId"`
+	DatasourceUID     string           `json:"datasourceUid"`
+	DatasourceUpdated int64            `json:"datasourceUpdated"`
+	Generation        string           `json:"generation"`
+	Queries           []queryKeyEntity `json:"queries"`
+}
+
+type queryKeyEntity struct {
+	RefID         string          `json:"refId"`
+	QueryType     string          `json:"queryType"`
+	MaxDataPoints int64           `json:"maxDataPoints"`
+	Interval      time.Duration   `json:"interval"`
+	From          int64           `json:"from"`
+	To            int64           `json:"to"`
+	JSON          json.RawMessage `json:"json"`
+}
+
+type resourceKeyData struct {
+	OrgID             int64  `json:"orgId"`
+	PluginID          string `json:"pluginId"`
+	DatasourceUID     string `json:"datasourceUid"`
+	DatasourceUpdated int64  `json:"datasourceUpdated"`
+	Generation        string `json:"generation"`
+	Path              string `json:"path"`
+	URL               string `json:"url"`
+	Body              []byte `json:"body"`
+}
+
+// queryKey returns the cache key of a query request. It is derived from the
+// data source, the query models and the time ranges aligned to alignment.
+func queryKey(req *backend.QueryDataRequest, generation string, alignment time.Duration) (string, error) {
+	ds := req.PluginContext.DataSourceInstanceSettings
+	k := queryKeyData{
+		OrgID:             req.PluginContext.OrgID,
+		DatasourceUID:     ds.UID,
+		DatasourceUpdated: ds.Updated.UnixNano(),
+		Generation:        generation,
+		Queries:           make([]queryKeyEntity, 0, len(req.Queries)),
+	}
+	for _, q := range req.Queries {
+		k.Queries = append(k.Queries, queryKeyEntity{
+			RefID:         q.RefID,
+			QueryType:     q.QueryType,
+			MaxDataPoints: q.MaxDataPoints,
+			Interval:      q.Interval,
+			From:          alignTime(q.TimeRange.From, alignment),
+			To:            alignTime(q.TimeRange.To, alignment),
+			JSON:          q.JSON,
+		})
+	}
+	return hashKey(cacheTypeQuery, ds.UID, k)
+}
+
+// resourceKey returns the cache key of a resource request.
+func resourceKey(req *backend.CallResourceRequest, generation string) (string, error) {
+	ds := req.PluginContext.DataSourceInstanceSettings
+	k := resourceKeyData{
+		OrgID:             req.PluginContext.OrgID,
+		PluginID:          req.PluginContext.PluginID,
+		DatasourceUID:     ds.UID,
+		DatasourceUpdated: ds.Updated.UnixNano(),
+		Generation:        generation,
+		Path:              req.Path,
+		URL:               req.URL,
+		Body:              req.Body,
+	}
+	return hashKey(cacheTypeResource, ds.UID, k)
+}
+
+func hashKey(cacheType, uid string, v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return keyPrefix + cacheType + ":" + uid + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+func generationKey(uid string) string {
+	return keyPrefix + "generation:" + uid
+}
+
+func alignTime(t time.Time, alignment time.Duration) int64 {
+	if alignment > 0 {
+		t = t.Truncate(alignment)
+	}
+	return t.UnixMilli()
+}
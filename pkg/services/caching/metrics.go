@@ -0,0 +1,33 @@
+package caching
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	requests      *prometheus.CounterVec
+	invalidations prometheus.Counter
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "caching",
+			Name:      "requests_total",
+			Help:      "Number of query and resource requests checked against the cache, by cache status.",
+		}, []string{"type", "datasource_type", "status"}),
+		invalidations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "caching",
+			Name:      "invalidations_total",
+			Help:      "Number of data source cache invalidations.",
+		}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.requests, m.invalidations)
+	}
+
+	return m
+}
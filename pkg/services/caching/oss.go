@@ -0,0 +1,296 @@
+package caching
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	cacheTypeQuery    = "query"
+	cacheTypeResource = "resource"
+
+	// generations are kept much longer than any entry they version
+	generationTTL = 30 * 24 * time.Hour
+)
+
+func ProvideCachingService(cfg *setting.Cfg, cache remotecache.CacheStorage, routeRegister routing.RouteRegister,
+	accessControl ac.AccessControl, reg prometheus.Registerer) *OSSCachingService {
+	s := &OSSCachingService{
+		cfg:           cfg.Caching,
+		cache:         cache,
+		routeRegister: routeRegister,
+		accessControl: accessControl,
+		metrics:       newMetrics(reg),
+		log:           log.New("caching"),
+		now:           time.Now,
+	}
+
+	if s.cfg.Enabled {
+		s.registerAPIEndpoints()
+	}
+
+	return s
+}
+
+// OSSCachingService caches data source query and resource responses in the
+// remote cache. The zero value is a disabled cache.
+type OSSCachingService struct {
+	cfg           setting.CachingSettings
+	cache         remotecache.CacheStorage
+	routeRegister routing.RouteRegister
+	accessControl ac.AccessControl
+	metrics       *metrics
+	log           log.Logger
+	now           func() time.Time
+}
+
+// datasourceSettings are the caching options a data source can set in its
+// JSON data.
+type datasourceSettings struct {
+	Enabled        *bool `json:"cachingEnabled,omitempty"`
+	TTLQueriesMs   int64 `json:"cachingTTLQueriesMs,omitempty"`
+	TTLResourcesMs int64 `json:"cachingTTLResourcesMs,omitempty"`
+	OAuthPassThru  bool  `json:"oauthPassThru,omitempty"`
+}
+
+func (s *OSSCachingService) HandleQueryRequest(ctx context.Context, req *backend.QueryDataRequest) (bool, CachedQueryDataResponse) {
+	ds := req.PluginContext.DataSourceInstanceSettings
+	if !s.enabled() || ds == nil {
+		return false, CachedQueryDataResponse{}
+	}
+
+	dsSettings, status := s.check(ctx, ds)
+	if status != "" {
+		s.finish(ctx, cacheTypeQuery, ds.Type, status)
+		return false, CachedQueryDataResponse{}
+	}
+
+	ttl := s.cfg.TTL
+	if dsSettings.TTLQueriesMs > 0 {
+		ttl = time.Duration(dsSettings.TTLQueriesMs) * time.Millisecond
+	}
+
+	generation, err := s.generation(ctx, ds.UID)
+	if err != nil {
+		s.log.Warn("Failed to read cache generation", "datasource", ds.UID, "error", err)
+		s.finish(ctx, cacheTypeQuery, ds.Type, StatusError)
+		return false, CachedQueryDataResponse{}
+	}
+
+	key, err := queryKey(req, generation, s.cfg.TimeRangeAlignment)
+	if err != nil {
+		s.log.Warn("Failed to build query cache key", "datasource", ds.UID, "error", err)
+		s.finish(ctx, cacheTypeQuery, ds.Type, StatusError)
+		return false, CachedQueryDataResponse{}
+	}
+
+	updateFn := func(ctx context.Context, resp *backend.QueryDataResponse) {
+		if resp == nil {
+			return
+		}
+		for _, r := range resp.Responses {
+			if r.Error != nil {
+				return
+			}
+		}
+		b, err := json.Marshal(resp)
+		if err != nil {
+			s.log.Warn("Failed to marshal query response", "datasource", ds.UID, "error", err)
+			return
+		}
+		s.store(ctx, key, b, ttl)
+	}
+
+	b, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			s.log.Warn("Failed to read query cache", "datasource", ds.UID, "error", err)
+		}
+		s.finish(ctx, cacheTypeQuery, ds.Type, StatusMiss)
+		return false, CachedQueryDataResponse{UpdateCacheFn: updateFn}
+	}
+
+	resp := &backend.QueryDataResponse{}
+	if err := json.Unmarshal(b, resp); err != nil {
+		s.log.Warn("Failed to unmarshal cached query response", "datasource", ds.UID, "error", err)
+		s.finish(ctx, cacheTypeQuery, ds.Type, StatusMiss)
+		return false, CachedQueryDataResponse{UpdateCacheFn: updateFn}
+	}
+
+	s.finish(ctx, cacheTypeQuery, ds.Type, StatusHit)
+	return true, CachedQueryDataResponse{Response: resp}
+}
+
+func (s *OSSCachingService) HandleResourceRequest(ctx context.Context, req *backend.CallResourceRequest) (bool, CachedResourceDataResponse) {
+	ds := req.PluginContext.DataSourceInstanceSettings
+	if !s.enabled() || ds == nil {
+		return false, CachedResourceDataResponse{}
+	}
+
+	// only idempotent requests are cached
+	if req.Method != http.MethodGet {
+		return false, CachedResourceDataResponse{}
+	}
+
+	dsSettings, status := s.check(ctx, ds)
+	if status != "" {
+		s.finish(ctx, cacheTypeResource, ds.Type, status)
+		return false, CachedResourceDataResponse{}
+	}
+
+	ttl := s.cfg.ResourcesTTL
+	if dsSettings.TTLResourcesMs > 0 {
+		ttl = time.Duration(dsSettings.TTLResourcesMs) * time.Millisecond
+	}
+
+	generation, err := s.generation(ctx, ds.UID)
+	if err != nil {
+		s.log.Warn("Failed to read cache generation", "datasource", ds.UID, "error", err)
+		s.finish(ctx, cacheTypeResource, ds.Type, StatusError)
+		return false, CachedResourceDataResponse{}
+	}
+
+	key, err := resourceKey(req, generation)
+	if err != nil {
+		s.log.Warn("Failed to build resource cache key", "datasource", ds.UID, "error", err)
+		s.finish(ctx, cacheTypeResource, ds.Type, StatusError)
+		return false, CachedResourceDataResponse{}
+	}
+
+	// A plugin may stream several responses for one request. Only single
+	// responses are cached, so a second response removes the first one.
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	updateFn := func(ctx context.Context, resp *backend.CallResourceResponse) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls > 1 {
+			if calls == 2 {
+				if err := s.cache.Delete(ctx, key); err != nil && !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+					s.log.Warn("Failed to delete streamed resource response", "datasource", ds.UID, "error", err)
+				}
+			}
+			return
+		}
+		if resp == nil || resp.Status != http.StatusOK {
+			return
+		}
+		b, err := json.Marshal(resp)
+		if err != nil {
+			s.log.Warn("Failed to marshal resource response", "datasource", ds.UID, "error", err)
+			return
+		}
+		s.store(ctx, key, b, ttl)
+	}
+
+	b, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			s.log.Warn("Failed to read resource cache", "datasource", ds.UID, "error", err)
+		}
+		s.finish(ctx, cacheTypeResource, ds.Type, StatusMiss)
+		return false, CachedResourceDataResponse{UpdateCacheFn: updateFn}
+	}
+
+	resp := &backend.CallResourceResponse{}
+	if err := json.Unmarshal(b, resp); err != nil {
+		s.log.Warn("Failed to unmarshal cached resource response", "datasource", ds.UID, "error", err)
+		s.finish(ctx, cacheTypeResource, ds.Type, StatusMiss)
+		return false, CachedResourceDataResponse{UpdateCacheFn: updateFn}
+	}
+
+	s.finish(ctx, cacheTypeResource, ds.Type, StatusHit)
+	return true, CachedResourceDataResponse{Response: resp}
+}
+
+// InvalidateDatasource drops every cached response of the data source. Keys
+// embed a per data source generation, so bumping it orphans the old entries
+// which then expire on their own.
+func (s *OSSCachingService) InvalidateDatasource(ctx context.Context, uid string) error {
+	if !s.enabled() {
+		return nil
+	}
+	gen := strconv.FormatInt(s.now().UnixNano(), 10)
+	if err := s.cache.Set(ctx, generationKey(uid), []byte(gen), generationTTL); err != nil {
+		return err
+	}
+	s.metrics.invalidations.Inc()
+	return nil
+}
+
+func (s *OSSCachingService) enabled() bool {
+	return s.cfg.Enabled && s.cache != nil
+}
+
+// check returns the data source caching options, and a cache status when the
+// request must not use the cache.
+func (s *OSSCachingService) check(ctx context.Context, ds *backend.DataSourceInstanceSettings) (datasourceSettings, string) {
+	dsSettings := datasourceSettings{}
+	if len(ds.JSONData) > 0 {
+		if err := json.Unmarshal(ds.JSONData, &dsSettings); err != nil {
+			s.log.Debug("Failed to read data source caching settings", "datasource", ds.UID, "error", err)
+		}
+	}
+
+	if dsSettings.Enabled != nil && !*dsSettings.Enabled {
+		return dsSettings, StatusDisabled
+	}
+	// responses of data sources that forward the user identity are per user
+	if dsSettings.OAuthPassThru {
+		return dsSettings, StatusBypass
+	}
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.SkipQueryCache {
+		return dsSettings, StatusBypass
+	}
+	return dsSettings, ""
+}
+
+func (s *OSSCachingService) generation(ctx context.Context, uid string) (string, error) {
+	b, err := s.cache.Get(ctx, generationKey(uid))
+	if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		return "0", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (s *OSSCachingService) store(ctx context.Context, key string, b []byte, ttl time.Duration) {
+	if s.cfg.MaxValueSize > 0 && len(b) > s.cfg.MaxValueSize {
+		s.log.Debug("Response too large to cache", "key", key, "size", len(b))
+		return
+	}
+	if err := s.cache.Set(ctx, key, b, ttl); err != nil {
+		s.log.Warn("Failed to write cache", "key", key, "error", err)
+	}
+}
+
+// finish records the cache status in the metrics and in the X-Cache header of
+// the HTTP response, if there is one.
+func (s *OSSCachingService) finish(ctx context.Context, cacheType, dsType, status string) {
+	s.metrics.requests.WithLabelValues(cacheType, dsType, status).Inc()
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.Resp != nil {
+		reqCtx.Resp.Header().Set(XCacheHeader, status)
+	}
+}
+
+var _ CachingService = &OSSCachingService{}
@@ -0,0 +1,169 @@
+package caching
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newTestService(t *testing.T) *OSSCachingService {
+	t.Helper()
+	return &OSSCachingService{
+		cfg: setting.CachingSettings{
+			Enabled:            true,
+			TTL:                time.Minute,
+			ResourcesTTL:       time.Minute,
+			TimeRangeAlignment: 10 * time.Second,
+		},
+		cache:   remotecache.NewFakeCacheStorage(),
+		metrics: newMetrics(prometheus.NewRegistry()),
+		log:     log.NewNopLogger(),
+		now:     time.Now,
+	}
+}
+
+func queryRequest(jsonData string, from time.Time) *backend.QueryDataRequest {
+	return &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{
+			OrgID: 1,
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+				UID:      "ds-uid",
+				Type:     "prometheus",
+				JSONData: json.RawMessage(jsonData),
+			},
+		},
+		Queries: []backend.DataQuery{{
+			RefID:     "A",
+			JSON:      json.RawMessage(`{"expr":"up"}`),
+			TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)},
+		}},
+	}
+}
+
+func queryResponse() *backend.QueryDataResponse {
+	return &backend.QueryDataResponse{
+		Responses: backend.Responses{
+			"A": {Frames: data.Frames{data.NewFrame("up", data.NewField("value", nil, []float64{1}))}},
+		},
+	}
+}
+
+func TestHandleQueryRequest(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("miss then hit within the aligned time range", func(t *testing.T) {
+		s := newTestService(t)
+		ctx := context.Background()
+
+		hit, cr := s.HandleQueryRequest(ctx, queryRequest(`{}`, now))
+		require.False(t, hit)
+		require.NotNil(t, cr.UpdateCacheFn)
+		cr.UpdateCacheFn(ctx, queryResponse())
+
+		hit, cr = s.HandleQueryRequest(ctx, queryRequest(`{}`, now.Add(3*time.Second)))
+		require.True(t, hit)
+		require.Len(t, cr.Response.Responses["A"].Frames, 1)
+		require.Equal(t, "up", cr.Response.Responses["A"].Frames[0].Name)
+
+		hit, _ = s.HandleQueryRequest(ctx, queryRequest(`{}`, now.Add(time.Minute)))
+		require.False(t, hit)
+	})
+
+	t.Run("responses with errors are not cached", func(t *testing.T) {
+		s := newTestService(t)
+		ctx := context.Background()
+
+		_, cr := s.HandleQueryRequest(ctx, queryRequest(`{}`, now))
+		cr.UpdateCacheFn(ctx, &backend.QueryDataResponse{Responses: backend.Responses{"A": {Error: context.DeadlineExceeded}}})
+
+		hit, _ := s.HandleQueryRequest(ctx, queryRequest(`{}`, now))
+		require.False(t, hit)
+	})
+
+	t.Run("data source can disable caching", func(t *testing.T) {
+		s := newTestService(t)
+		hit, cr := s.HandleQueryRequest(context.Background(), queryRequest(`{"cachingEnabled":false}`, now))
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+	})
+
+	t.Run("invalidation drops cached responses", func(t *testing.T) {
+		s := newTestService(t)
+		ctx := context.Background()
+
+		_, cr := s.HandleQueryRequest(ctx, queryRequest(`{}`, now))
+		cr.UpdateCacheFn(ctx, queryResponse())
+		require.NoError(t, s.InvalidateDatasource(ctx, "ds-uid"))
+
+		hit, _ := s.HandleQueryRequest(ctx, queryRequest(`{}`, now))
+		require.False(t, hit)
+	})
+
+	t.Run("zero value is disabled", func(t *testing.T) {
+		s := &OSSCachingService{}
+		hit, cr := s.HandleQueryRequest(context.Background(), queryRequest(`{}`, now))
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+	})
+}
+
+func TestHandleResourceRequest(t *testing.T) {
+	req := func(method string) *backend.CallResourceRequest {
+		return &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:    1,
+				PluginID: "prometheus",
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+					UID:  "ds-uid",
+					Type: "prometheus",
+				},
+			},
+			Path:   "api/v1/labels",
+			Method: method,
+			URL:    "api/v1/labels?match=up",
+		}
+	}
+
+	t.Run("caches a single OK response", func(t *testing.T) {
+		s := newTestService(t)
+		ctx := context.Background()
+
+		hit, cr := s.HandleResourceRequest(ctx, req(http.MethodGet))
+		require.False(t, hit)
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte(`["job"]`)})
+
+		hit, cr = s.HandleResourceRequest(ctx, req(http.MethodGet))
+		require.True(t, hit)
+		require.Equal(t, []byte(`["job"]`), cr.Response.Body)
+	})
+
+	t.Run("streamed responses are not cached", func(t *testing.T) {
+		s := newTestService(t)
+		ctx := context.Background()
+
+		_, cr := s.HandleResourceRequest(ctx, req(http.MethodGet))
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte(`a`)})
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte(`b`)})
+
+		hit, _ := s.HandleResourceRequest(ctx, req(http.MethodGet))
+		require.False(t, hit)
+	})
+
+	t.Run("non GET requests bypass the cache", func(t *testing.T) {
+		s := newTestService(t)
+		hit, cr := s.HandleResourceRequest(context.Background(), req(http.MethodPost))
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+	})
+}
@@ -5,35 +5,62 @@ import (
 	"fmt"
 
 	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/otlp"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/prometheus"
 	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
 )
 
 type Converter struct {
-	telegrafConverterWide         *telegraf.Converter
-	telegrafConverterLabelsColumn *telegraf.Converter
+	telegrafConverterWide           *telegraf.Converter
+	telegrafConverterLabelsColumn   *telegraf.Converter
+	prometheusConverterWide         *prometheus.Converter
+	prometheusConverterLabelsColumn *prometheus.Converter
+	otlpConverterWide               *otlp.Converter
+	otlpConverterLabelsColumn       *otlp.Converter
 }
 
 func NewConverter() *Converter {
+	wideOpts := []telegraf.ConverterOption{
+		telegraf.WithFloat64Numbers(true),
+	}
+	labelsColumnOpts := []telegraf.ConverterOption{
+		telegraf.WithUseLabelsColumn(true),
+		telegraf.WithFloat64Numbers(true),
+	}
 	return &Converter{
-		telegrafConverterWide: telegraf.NewConverter(
-			telegraf.WithFloat64Numbers(true),
-		),
-		telegrafConverterLabelsColumn: telegraf.NewConverter(
-			telegraf.WithUseLabelsColumn(true),
-			telegraf.WithFloat64Numbers(true),
-		),
+		telegrafConverterWide:           telegraf.NewConverter(wideOpts...),
+		telegrafConverterLabelsColumn:   telegraf.NewConverter(labelsColumnOpts...),
+		prometheusConverterWide:         prometheus.NewConverter(wideOpts...),
+		prometheusConverterLabelsColumn: prometheus.NewConverter(labelsColumnOpts...),
+		otlpConverterWide:               otlp.NewConverter(wideOpts...),
+		otlpConverterLabelsColumn:       otlp.NewConverter(labelsColumnOpts...),
 	}
 }
 
 var ErrUnsupportedFrameFormat = errors.New("unsupported frame format")
 
+// Convert converts Influx line protocol.
 func (c *Converter) Convert(data []byte, frameFormat string) ([]telemetry.FrameWrapper, error) {
+	return convert(data, frameFormat, c.telegrafConverterWide, c.telegrafConverterLabelsColumn)
+}
+
+// ConvertPrometheus converts Prometheus text exposition format.
+func (c *Converter) ConvertPrometheus(data []byte, frameFormat string) ([]telemetry.FrameWrapper, error) {
+	return convert(data, frameFormat, c.prometheusConverterWide, c.prometheusConverterLabelsColumn)
+}
+
+// ConvertOTLP converts OTLP/HTTP metrics export requests.
+func (c *Converter) ConvertOTLP(data []byte, frameFormat string) ([]telemetry.FrameWrapper, error) {
+	return convert(data, frameFormat, c.otlpConverterWide, c.otlpConverterLabelsColumn)
+}
+
+func convert(data []byte, frameFormat string, wide telemetry.Converter, labelsColumn telemetry.Converter) ([]telemetry.FrameWrapper, error) {
 	var converter telemetry.Converter
 	switch frameFormat {
 	case "wide":
-		converter = c.telegrafConverterWide
+		converter = wide
 	case "labels_column":
-		converter = c.telegrafConverterLabelsColumn
+		converter = labelsColumn
 	default:
 		return nil, ErrUnsupportedFrameFormat
 	}
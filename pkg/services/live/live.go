@@ -98,6 +98,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		usageStatsService: usageStatsService,
 		orgService:        orgService,
 		keyPrefix:         "gf_live",
+		pipelineStorage:   pipeline.NewSQLStorage(sqlStore, secretsService),
 	}
 
 	if cfg.LiveHAPrefix != "" {
@@ -154,22 +155,48 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		}
 	}
 
+	bufferEnabled := g.Cfg.LiveManagedStreamBufferMaxFrames > 0 || g.Cfg.LiveManagedStreamBufferMaxAge > 0
 	if redisClient != nil {
+		var frameBuffer managedstream.FrameBuffer
+		if bufferEnabled {
+			frameBuffer = managedstream.NewRedisFrameBuffer(redisClient, g.keyPrefix, g.Cfg.LiveManagedStreamBufferMaxFrames, g.Cfg.LiveManagedStreamBufferMaxAge)
+		}
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
 			managedstream.NewRedisFrameCache(redisClient, g.keyPrefix),
+			frameBuffer,
 		)
 	} else {
+		var frameBuffer managedstream.FrameBuffer
+		if bufferEnabled {
+			frameBuffer = managedstream.NewMemoryFrameBuffer(g.Cfg.LiveManagedStreamBufferMaxFrames, g.Cfg.LiveManagedStreamBufferMaxAge)
+		}
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
 			managedstream.NewMemoryFrameCache(),
+			frameBuffer,
 		)
 	}
 
 	g.ManagedStreamRunner = managedStreamRunner
 
+	// Data published to the channels matching a channel rule of the pipeline storage is
+	// processed by the pipeline, the other channels are handled as before.
+	g.channelRuleCache = pipeline.NewCacheSegmentedTree(&pipeline.StorageRuleBuilder{
+		Node:                 node,
+		ManagedStream:        managedStreamRunner,
+		FrameStorage:         pipeline.NewFrameStorage(),
+		Storage:              g.pipelineStorage,
+		ChannelHandlerGetter: g,
+		SecretsService:       g.SecretsService,
+	})
+	g.Pipeline, err = pipeline.New(g.channelRuleCache)
+	if err != nil {
+		return nil, err
+	}
+
 	g.contextGetter = liveplugin.NewContextGetter(g.PluginContextProvider, g.DataSourceCache)
 	pipelinedChannelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, g.Pipeline)
 	numLocalSubscribersGetter := liveplugin.NewNumLocalSubscribersGetter(node)
@@ -188,6 +215,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	g.GrafanaScope.Features["broadcast"] = features.NewBroadcastRunner(g.storage)
 
 	g.surveyCaller = survey.NewCaller(managedStreamRunner, node)
+	g.surveyCaller.OnInvalidateChannelRules(g.channelRuleCache.Invalidate)
 	err = g.surveyCaller.SetupHandlers()
 	if err != nil {
 		return nil, err
@@ -410,6 +438,9 @@ type GrafanaLive struct {
 	ManagedStreamRunner *managedstream.Runner
 	Pipeline            *pipeline.Pipeline
 	pipelineStorage     pipeline.Storage
+	// channelRuleCache is the channel rule cache of Pipeline, built from pipelineStorage and
+	// invalidated on every node when the channel rules or write configs change.
+	channelRuleCache *pipeline.CacheSegmentedTree
 
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
@@ -470,6 +501,30 @@ func (g *GrafanaLive) Run(ctx context.Context) error {
 		})
 	}
 
+	if g.Cfg != nil && g.Cfg.LiveMQTTURL != "" {
+		if g.Pipeline == nil {
+			logger.Warn("MQTT input requires Live pipeline, not subscribing to MQTT topics")
+		} else {
+			mqttInput := pipeline.NewMQTTInput(pipeline.MQTTInputConfig{
+				URL:           g.Cfg.LiveMQTTURL,
+				ClientID:      g.Cfg.LiveMQTTClientID,
+				Username:      g.Cfg.LiveMQTTUsername,
+				Password:      g.Cfg.LiveMQTTPassword,
+				Topics:        g.Cfg.LiveMQTTTopics,
+				ChannelPrefix: g.Cfg.LiveMQTTChannelPrefix,
+				OrgID:         g.Cfg.LiveMQTTOrgID,
+			}, g.Pipeline)
+			eGroup.Go(func() error {
+				err := mqttInput.Run(eCtx)
+				if err != nil && !errors.Is(err, context.Canceled) {
+					// MQTT input problems should not stop Live.
+					logger.Error("MQTT input error", "error", err)
+				}
+				return nil
+			})
+		}
+	}
+
 	return eGroup.Wait()
 }
 
@@ -1179,6 +1234,7 @@ func (g *GrafanaLive) HandleChannelRulesPostHTTP(c *contextmodel.ReqContext) res
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to create channel rule", err)
 	}
+	g.invalidateChannelRules(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"rule": rule,
 	})
@@ -1202,6 +1258,7 @@ func (g *GrafanaLive) HandleChannelRulesPutHTTP(c *contextmodel.ReqContext) resp
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to update channel rule", err)
 	}
+	g.invalidateChannelRules(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"rule": rule,
 	})
@@ -1225,9 +1282,20 @@ func (g *GrafanaLive) HandleChannelRulesDeleteHTTP(c *contextmodel.ReqContext) r
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to delete channel rule", err)
 	}
+	g.invalidateChannelRules(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{})
 }
 
+// invalidateChannelRules makes all nodes rebuild the channel rules of an organization,
+// nodes that miss the notification rebuild them once their cached rules expire.
+func (g *GrafanaLive) invalidateChannelRules(orgID int64) {
+	// The local node does not depend on the survey reaching it.
+	g.channelRuleCache.Invalidate(orgID)
+	if err := g.surveyCaller.CallInvalidateChannelRules(orgID); err != nil {
+		logger.Warn("Failed to invalidate channel rules on all nodes", "orgId", orgID, "error", err)
+	}
+}
+
 // HandlePipelineEntitiesListHTTP ...
 func (g *GrafanaLive) HandlePipelineEntitiesListHTTP(_ *contextmodel.ReqContext) response.Response {
 	return response.JSON(http.StatusOK, util.DynMap{
@@ -1269,6 +1337,7 @@ func (g *GrafanaLive) HandleWriteConfigsPostHTTP(c *contextmodel.ReqContext) res
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to create write config", err)
 	}
+	g.invalidateChannelRules(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"writeConfig": pipeline.WriteConfigToDto(result),
 	})
@@ -1313,6 +1382,7 @@ func (g *GrafanaLive) HandleWriteConfigsPutHTTP(c *contextmodel.ReqContext) resp
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to update write config", err)
 	}
+	g.invalidateChannelRules(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"writeConfig": pipeline.WriteConfigToDto(result),
 	})
@@ -1336,6 +1406,7 @@ func (g *GrafanaLive) HandleWriteConfigsDeleteHTTP(c *contextmodel.ReqContext) r
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to delete write config", err)
 	}
+	g.invalidateChannelRules(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{})
 }
 
@@ -17,6 +17,9 @@ import (
 	"github.com/grafana/grafana/pkg/services/authz/zanzana"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/live/pipeline/mqtttest"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
 )
@@ -43,6 +46,99 @@ func Test_provideLiveService_RedisUnavailable(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestIntegrationLivePipelineChannelRules(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+
+	g := setupLiveServiceForTest(t, setting.NewCfg())
+	require.NotNil(t, g.Pipeline)
+
+	_, ok, err := g.Pipeline.Get(1, "stream/test/cpu")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = g.pipelineStorage.CreateChannelRule(ctx, 1, pipeline.ChannelRuleCreateCmd{
+		Pattern: "stream/test/cpu",
+		Settings: pipeline.ChannelRuleSettings{
+			Converter: &pipeline.ConverterConfig{Type: pipeline.ConverterTypeJsonAuto},
+		},
+	})
+	require.NoError(t, err)
+	g.invalidateChannelRules(1)
+
+	rule, ok, err := g.Pipeline.Get(1, "stream/test/cpu")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NotNil(t, rule.Converter)
+
+	_, err = g.pipelineStorage.UpdateChannelRule(ctx, 1, pipeline.ChannelRuleUpdateCmd{Pattern: "stream/test/cpu"})
+	require.NoError(t, err)
+	g.invalidateChannelRules(1)
+
+	rule, ok, err = g.Pipeline.Get(1, "stream/test/cpu")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Nil(t, rule.Converter)
+
+	require.NoError(t, g.pipelineStorage.DeleteChannelRule(ctx, 1, pipeline.ChannelRuleDeleteCmd{Pattern: "stream/test/cpu"}))
+	g.invalidateChannelRules(1)
+
+	_, ok, err = g.Pipeline.Get(1, "stream/test/cpu")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestIntegrationLiveMQTTInput(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	broker := mqtttest.NewBroker(t)
+	cfg := setting.NewCfg()
+	cfg.LiveMQTTURL = broker.URL()
+	cfg.LiveMQTTClientID = "grafana"
+	cfg.LiveMQTTTopics = []string{"sensors/#"}
+	cfg.LiveMQTTChannelPrefix = "stream/mqtt"
+	cfg.LiveMQTTOrgID = 1
+	g := setupLiveServiceForTest(t, cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- g.Run(ctx)
+	}()
+
+	select {
+	case topics := <-broker.Subscribed:
+		require.Equal(t, []string{"sensors/#"}, topics)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for subscription")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for Live to stop")
+	}
+}
+
+func setupLiveServiceForTest(t *testing.T, cfg *setting.Cfg) *GrafanaLive {
+	t.Helper()
+	g, err := ProvideService(nil, cfg,
+		routing.NewRouteRegister(),
+		nil, nil, nil, nil,
+		db.InitTestDB(t),
+		fakes.NewFakeSecretsService(),
+		&usagestats.UsageStatsMock{T: t},
+		nil,
+		featuremgmt.WithFeatures(), acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil)
+	require.NoError(t, err)
+	return g
+}
+
 func Test_runConcurrentlyIfNeeded_Concurrent(t *testing.T) {
 	doneCh := make(chan struct{})
 	f := func() {
@@ -0,0 +1,83 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// FrameBuffer keeps recent frames of channels, so that subscribers can get
+// recent history on subscribe instead of the last frame only.
+type FrameBuffer interface {
+	// Add appends a frame pushed at tm to the channel buffer, frames over the
+	// count or age limit of the buffer are dropped.
+	Add(ctx context.Context, orgID int64, channel string, frameJson data.FrameJSONCache, tm time.Time) error
+	// GetFrames returns full JSON frames for a channel in org pushed since
+	// the given time, oldest first.
+	GetFrames(ctx context.Context, orgID int64, channel string, since time.Time) ([]json.RawMessage, error)
+}
+
+// replayRequest is the subscription data to request buffered frames, e.g.
+// {"replay": "5m"} for frames pushed during the last 5 minutes.
+type replayRequest struct {
+	Replay string `json:"replay"`
+}
+
+// parseReplay returns the duration of history requested by a subscriber.
+func parseReplay(subscriptionData json.RawMessage) (time.Duration, bool, error) {
+	if len(subscriptionData) == 0 {
+		return 0, false, nil
+	}
+	var req replayRequest
+	if err := json.Unmarshal(subscriptionData, &req); err != nil {
+		return 0, false, fmt.Errorf("invalid subscription data: %w", err)
+	}
+	if req.Replay == "" {
+		return 0, false, nil
+	}
+	d, err := time.ParseDuration(req.Replay)
+	if err != nil || d <= 0 {
+		return 0, false, fmt.Errorf("invalid replay duration: %s", req.Replay)
+	}
+	return d, true, nil
+}
+
+// mergeFrames concatenates the rows of buffered frames into a single frame.
+// When the schema changes only the frames with the latest schema are merged.
+func mergeFrames(frames []json.RawMessage) (json.RawMessage, error) {
+	var merged *data.Frame
+	for _, frameJSON := range frames {
+		var frame data.Frame
+		if err := json.Unmarshal(frameJSON, &frame); err != nil {
+			return nil, err
+		}
+		if merged == nil || !sameFields(merged, &frame) {
+			merged = &frame
+			continue
+		}
+		for i, field := range frame.Fields {
+			for j := 0; j < field.Len(); j++ {
+				merged.Fields[i].Append(field.At(j))
+			}
+		}
+	}
+	if merged == nil {
+		return nil, nil
+	}
+	return data.FrameToJSON(merged, data.IncludeAll)
+}
+
+func sameFields(a *data.Frame, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type() != b.Fields[i].Type() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type bufferedFrame struct {
+	time  time.Time
+	frame json.RawMessage
+}
+
+// MemoryFrameBuffer keeps recent frames of each channel in memory.
+type MemoryFrameBuffer struct {
+	mu        sync.RWMutex
+	frames    map[int64]map[string][]bufferedFrame
+	maxFrames int
+	maxAge    time.Duration
+}
+
+// NewMemoryFrameBuffer creates a buffer keeping up to maxFrames frames not
+// older than maxAge per channel, zero means no limit.
+func NewMemoryFrameBuffer(maxFrames int, maxAge time.Duration) *MemoryFrameBuffer {
+	return &MemoryFrameBuffer{
+		frames:    map[int64]map[string][]bufferedFrame{},
+		maxFrames: maxFrames,
+		maxAge:    maxAge,
+	}
+}
+
+func (b *MemoryFrameBuffer) Add(_ context.Context, orgID int64, channel string, frameJson data.FrameJSONCache, tm time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.frames[orgID]; !ok {
+		b.frames[orgID] = map[string][]bufferedFrame{}
+	}
+	frames := append(b.frames[orgID][channel], bufferedFrame{time: tm, frame: frameJson.Bytes(data.IncludeAll)})
+	if b.maxFrames > 0 && len(frames) > b.maxFrames {
+		frames = frames[len(frames)-b.maxFrames:]
+	}
+	if b.maxAge > 0 {
+		minTime := tm.Add(-b.maxAge)
+		i := 0
+		for i < len(frames) && frames[i].time.Before(minTime) {
+			i++
+		}
+		frames = frames[i:]
+	}
+	b.frames[orgID][channel] = frames
+	return nil
+}
+
+func (b *MemoryFrameBuffer) GetFrames(_ context.Context, orgID int64, channel string, since time.Time) ([]json.RawMessage, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var frames []json.RawMessage
+	for _, f := range b.frames[orgID][channel] {
+		if !f.time.Before(since) {
+			frames = append(frames, f.frame)
+		}
+	}
+	return frames, nil
+}
@@ -0,0 +1,102 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func testFrameBuffer(t *testing.T, newBuffer func(maxFrames int, maxAge time.Duration) FrameBuffer) {
+	ctx := context.Background()
+	now := time.Now()
+
+	addFrames := func(t *testing.T, b FrameBuffer, orgID int64, n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			frameJsonCache, err := data.FrameToJSONCache(data.NewFrame("test", data.NewField("value", nil, []int64{int64(i)})))
+			require.NoError(t, err)
+			require.NoError(t, b.Add(ctx, orgID, "test", frameJsonCache, now.Add(time.Duration(i-n+1)*time.Minute)))
+		}
+	}
+	frameValues := func(t *testing.T, frames []json.RawMessage) []int64 {
+		t.Helper()
+		values := make([]int64, 0, len(frames))
+		for _, frameJSON := range frames {
+			var f data.Frame
+			require.NoError(t, json.Unmarshal(frameJSON, &f))
+			values = append(values, f.Fields[0].At(0).(int64))
+		}
+		return values
+	}
+
+	t.Run("limited by count", func(t *testing.T) {
+		b := newBuffer(3, 0)
+		addFrames(t, b, 1, 5)
+		frames, err := b.GetFrames(ctx, 1, "test", now.Add(-time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, []int64{2, 3, 4}, frameValues(t, frames))
+
+		// Only frames pushed since the requested time.
+		frames, err = b.GetFrames(ctx, 1, "test", now.Add(-time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, []int64{3, 4}, frameValues(t, frames))
+	})
+
+	t.Run("limited by age", func(t *testing.T) {
+		b := newBuffer(0, 2*time.Minute)
+		addFrames(t, b, 1, 5)
+		frames, err := b.GetFrames(ctx, 1, "test", now.Add(-time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, []int64{2, 3, 4}, frameValues(t, frames))
+	})
+
+	t.Run("per org", func(t *testing.T) {
+		b := newBuffer(10, 0)
+		addFrames(t, b, 1, 2)
+		frames, err := b.GetFrames(ctx, 2, "test", now.Add(-time.Hour))
+		require.NoError(t, err)
+		require.Empty(t, frames)
+	})
+}
+
+func TestMemoryFrameBuffer(t *testing.T) {
+	testFrameBuffer(t, func(maxFrames int, maxAge time.Duration) FrameBuffer {
+		return NewMemoryFrameBuffer(maxFrames, maxAge)
+	})
+}
+
+func TestMergeFrames(t *testing.T) {
+	frameJSON := func(name string, values ...float64) json.RawMessage {
+		frameJSON, err := data.FrameToJSON(data.NewFrame("test", data.NewField(name, nil, values)), data.IncludeAll)
+		require.NoError(t, err)
+		return frameJSON
+	}
+
+	merged, err := mergeFrames([]json.RawMessage{frameJSON("a", 1), frameJSON("b", 2), frameJSON("b", 3, 4)})
+	require.NoError(t, err)
+
+	var f data.Frame
+	require.NoError(t, json.Unmarshal(merged, &f))
+	require.Len(t, f.Fields, 1)
+	require.Equal(t, "b", f.Fields[0].Name)
+	require.Equal(t, 3, f.Fields[0].Len())
+	require.Equal(t, 4.0, f.Fields[0].At(2))
+}
+
+func TestParseReplay(t *testing.T) {
+	d, ok, err := parseReplay(json.RawMessage(`{"replay": "5m"}`))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 5*time.Minute, d)
+
+	_, ok, err = parseReplay(nil)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, _, err = parseReplay(json.RawMessage(`{"replay": "soon"}`))
+	require.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// RedisFrameBuffer keeps recent frames of each channel in a Redis sorted set
+// scored by push time, so that the buffer is shared by all Grafana instances.
+type RedisFrameBuffer struct {
+	redisClient *redis.Client
+	keyPrefix   string
+	maxFrames   int
+	maxAge      time.Duration
+}
+
+// NewRedisFrameBuffer creates a buffer keeping up to maxFrames frames not
+// older than maxAge per channel, zero means no limit.
+func NewRedisFrameBuffer(redisClient *redis.Client, keyPrefix string, maxFrames int, maxAge time.Duration) *RedisFrameBuffer {
+	return &RedisFrameBuffer{
+		redisClient: redisClient,
+		keyPrefix:   keyPrefix,
+		maxFrames:   maxFrames,
+		maxAge:      maxAge,
+	}
+}
+
+func (b *RedisFrameBuffer) Add(ctx context.Context, orgID int64, channel string, frameJson data.FrameJSONCache, tm time.Time) error {
+	key := b.getBufferKey(orgchannel.PrependOrgID(orgID, channel))
+
+	pipe := b.redisClient.TxPipeline()
+	defer func() { _ = pipe.Close() }()
+
+	// Members of a sorted set are unique, so identical frames are prefixed with an ID.
+	pipe.ZAdd(ctx, key, &redis.Z{
+		Score:  float64(tm.UnixMilli()),
+		Member: util.GenerateShortUID() + ":" + string(frameJson.Bytes(data.IncludeAll)),
+	})
+	if b.maxAge > 0 {
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(tm.Add(-b.maxAge).UnixMilli(), 10))
+	}
+	if b.maxFrames > 0 {
+		pipe.ZRemRangeByRank(ctx, key, 0, -int64(b.maxFrames)-1)
+	}
+	ttl := frameCacheTTL
+	if b.maxAge > 0 {
+		ttl = b.maxAge
+	}
+	pipe.Expire(ctx, key, ttl)
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (b *RedisFrameBuffer) GetFrames(ctx context.Context, orgID int64, channel string, since time.Time) ([]json.RawMessage, error) {
+	key := b.getBufferKey(orgchannel.PrependOrgID(orgID, channel))
+	members, err := b.redisClient.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	frames := make([]json.RawMessage, 0, len(members))
+	for _, member := range members {
+		_, frame, ok := strings.Cut(member, ":")
+		if !ok {
+			continue
+		}
+		frames = append(frames, json.RawMessage(frame))
+	}
+	return frames, nil
+}
+
+func (b *RedisFrameBuffer) getBufferKey(channelID string) string {
+	return b.keyPrefix + ".managed_stream_buffer." + channelID
+}
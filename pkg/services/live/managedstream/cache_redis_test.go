@@ -4,6 +4,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
@@ -52,6 +53,36 @@ func TestIntegrationRedisCacheStorage(t *testing.T) {
 	}
 }
 
+func TestIntegrationRedisFrameBuffer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	u, ok := os.LookupEnv("REDIS_URL")
+	if !ok || u == "" {
+		t.Skip("No redis URL supplied")
+	}
+
+	addr := u
+	db := 0
+	parsed, err := redis.ParseURL(u)
+	if err == nil {
+		addr = parsed.Addr
+		db = parsed.DB
+	}
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: addr,
+		DB:   db,
+	})
+
+	testFrameBuffer(t, func(maxFrames int, maxAge time.Duration) FrameBuffer {
+		prefix := uuid.New().String()
+		t.Cleanup(redisCleanup(t, redisClient, prefix))
+		return NewRedisFrameBuffer(redisClient, prefix, maxFrames, maxAge)
+	})
+}
+
 func redisCleanup(t *testing.T, redisClient *redis.Client, prefix string) func() {
 	return func() {
 		keys, err := redisClient.Keys(redisClient.Context(), prefix+"*").Result()
@@ -42,19 +42,22 @@ type Runner struct {
 	publisher      model.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	frameBuffer    FrameBuffer
 }
 
 type LocalPublisher interface {
 	PublishLocal(channel string, data []byte) error
 }
 
-// NewRunner creates new Runner.
-func NewRunner(publisher model.ChannelPublisher, localPublisher LocalPublisher, frameCache FrameCache) *Runner {
+// NewRunner creates new Runner. frameBuffer is optional, without it
+// subscribers only get the last frame of a channel.
+func NewRunner(publisher model.ChannelPublisher, localPublisher LocalPublisher, frameCache FrameCache, frameBuffer FrameBuffer) *Runner {
 	return &Runner{
 		publisher:      publisher,
 		localPublisher: localPublisher,
 		streams:        map[int64]map[string]*NamespaceStream{},
 		frameCache:     frameCache,
+		frameBuffer:    frameBuffer,
 	}
 }
 
@@ -125,7 +128,7 @@ func (r *Runner) GetOrCreateStream(orgID int64, scope string, namespace string)
 	prefix := scope + "/" + namespace
 	s, ok := r.streams[orgID][prefix]
 	if !ok {
-		s = NewNamespaceStream(orgID, scope, namespace, r.publisher, r.localPublisher, r.frameCache)
+		s = NewNamespaceStream(orgID, scope, namespace, r.publisher, r.localPublisher, r.frameCache, r.frameBuffer)
 		r.streams[orgID][prefix] = s
 	}
 	return s, nil
@@ -139,6 +142,7 @@ type NamespaceStream struct {
 	publisher      model.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	frameBuffer    FrameBuffer
 	rateMu         sync.RWMutex
 	rates          map[string][60]rateEntry
 }
@@ -156,7 +160,7 @@ type ManagedChannel struct {
 }
 
 // NewNamespaceStream creates new NamespaceStream.
-func NewNamespaceStream(orgID int64, scope string, namespace string, publisher model.ChannelPublisher, localPublisher LocalPublisher, schemaUpdater FrameCache) *NamespaceStream {
+func NewNamespaceStream(orgID int64, scope string, namespace string, publisher model.ChannelPublisher, localPublisher LocalPublisher, schemaUpdater FrameCache, frameBuffer FrameBuffer) *NamespaceStream {
 	return &NamespaceStream{
 		orgID:          orgID,
 		scope:          scope,
@@ -164,6 +168,7 @@ func NewNamespaceStream(orgID int64, scope string, namespace string, publisher m
 		publisher:      publisher,
 		localPublisher: localPublisher,
 		frameCache:     schemaUpdater,
+		frameBuffer:    frameBuffer,
 		rates:          map[string][60]rateEntry{},
 	}
 }
@@ -185,6 +190,12 @@ func (s *NamespaceStream) Push(ctx context.Context, path string, frame *data.Fra
 		logger.Error("Error updating managed stream schema", "error", err)
 		return err
 	}
+	if s.frameBuffer != nil {
+		if err := s.frameBuffer.Add(ctx, s.orgID, channel, jsonFrameCache, time.Now()); err != nil {
+			// Subscribers still get the last frame from cache.
+			logger.Error("Error adding frame to managed stream buffer", "error", err, "channel", channel)
+		}
+	}
 
 	// When the schema has not changed, just send the data.
 	include := data.IncludeDataOnly
@@ -241,6 +252,25 @@ func (s *NamespaceStream) GetHandlerForPath(_ string) (model.ChannelHandler, err
 
 func (s *NamespaceStream) OnSubscribe(ctx context.Context, u identity.Requester, e model.SubscribeEvent) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
 	reply := model.SubscribeReply{}
+	if s.frameBuffer != nil {
+		replay, ok, err := parseReplay(e.Data)
+		if err != nil {
+			return reply, 0, err
+		}
+		if ok {
+			frames, err := s.frameBuffer.GetFrames(ctx, u.GetOrgID(), e.Channel, time.Now().Add(-replay))
+			if err != nil {
+				return reply, 0, err
+			}
+			if len(frames) > 0 {
+				reply.Data, err = mergeFrames(frames)
+				if err != nil {
+					return reply, 0, err
+				}
+				return reply, backend.SubscribeStreamStatusOK, nil
+			}
+		}
+	}
 	frameJSON, ok, err := s.frameCache.GetFrame(ctx, u.GetOrgID(), e.Channel)
 	if err != nil {
 		return reply, 0, err
@@ -2,11 +2,16 @@ package managedstream
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/model"
+	"github.com/grafana/grafana/pkg/services/user"
 )
 
 type testPublisher struct {
@@ -19,13 +24,13 @@ func (p *testPublisher) publish(_ int64, _ string, _ []byte) error {
 
 func TestNewManagedStream(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(), nil)
 	require.NotNil(t, c)
 }
 
 func TestManagedStreamMinuteRate(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(), nil)
 	require.NotNil(t, c)
 
 	c.incRate("test1", time.Now().Unix())
@@ -48,7 +53,7 @@ func TestManagedStreamMinuteRate(t *testing.T) {
 func TestGetManagedStreams(t *testing.T) {
 	publisher := &testPublisher{t: t}
 	frameCache := NewMemoryFrameCache()
-	runner := NewRunner(publisher.publish, nil, frameCache)
+	runner := NewRunner(publisher.publish, nil, frameCache, nil)
 	s1, err := runner.GetOrCreateStream(1, "stream", "test1")
 	require.NoError(t, err)
 	s2, err := runner.GetOrCreateStream(1, "stream", "test2")
@@ -83,3 +88,30 @@ func TestGetManagedStreams(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, managedChannels, 7) // Not affected by other org.
 }
+
+func TestNamespaceStream_OnSubscribeReplay(t *testing.T) {
+	publisher := &testPublisher{t: t}
+	s := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(), NewMemoryFrameBuffer(10, 0))
+	for i := 0; i < 3; i++ {
+		err := s.Push(context.Background(), "cpu", data.NewFrame("cpu", data.NewField("value", nil, []float64{float64(i)})))
+		require.NoError(t, err)
+	}
+	u := &user.SignedInUser{UserID: 2, OrgID: 1}
+
+	frameLength := func(t *testing.T, reply model.SubscribeReply) int {
+		t.Helper()
+		var f data.Frame
+		require.NoError(t, json.Unmarshal(reply.Data, &f))
+		return f.Fields[0].Len()
+	}
+
+	reply, status, err := s.OnSubscribe(context.Background(), u, model.SubscribeEvent{Channel: "stream/a/cpu"})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusOK, status)
+	require.Equal(t, 1, frameLength(t, reply))
+
+	reply, status, err = s.OnSubscribe(context.Background(), u, model.SubscribeEvent{Channel: "stream/a/cpu", Data: json.RawMessage(`{"replay": "5m"}`)})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusOK, status)
+	require.Equal(t, 3, frameLength(t, reply))
+}
@@ -40,6 +40,8 @@ type ConverterConfig struct {
 	ExactJsonConverterConfig  *ExactJsonConverterConfig  `json:"jsonExact,omitempty"`
 	AutoInfluxConverterConfig *AutoInfluxConverterConfig `json:"influxAuto,omitempty"`
 	JsonFrameConverterConfig  *JsonFrameConverterConfig  `json:"jsonFrame,omitempty"`
+	PrometheusConverterConfig *PrometheusConverterConfig `json:"prometheus,omitempty"`
+	OTLPConverterConfig       *OTLPConverterConfig       `json:"otlp,omitempty"`
 }
 
 type DropFieldsFrameProcessorConfig struct {
@@ -152,4 +154,12 @@ type AutoInfluxConverterConfig struct {
 
 type JsonFrameConverterConfig struct{}
 
+type PrometheusConverterConfig struct {
+	FrameFormat string `json:"frameFormat"`
+}
+
+type OTLPConverterConfig struct {
+	FrameFormat string `json:"frameFormat"`
+}
+
 type ManagedStreamOutputConfig struct{}
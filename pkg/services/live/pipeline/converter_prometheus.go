@@ -0,0 +1,41 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/live/convert"
+)
+
+// PrometheusConverter decodes Prometheus text exposition format input and
+// transforms it to several ChannelFrame objects where Channel is constructed
+// from original channel + / + <metric_name>.
+type PrometheusConverter struct {
+	config    PrometheusConverterConfig
+	converter *convert.Converter
+}
+
+// NewPrometheusConverter creates new PrometheusConverter.
+func NewPrometheusConverter(config PrometheusConverterConfig) *PrometheusConverter {
+	return &PrometheusConverter{config: config, converter: convert.NewConverter()}
+}
+
+const ConverterTypePrometheus = "prometheus"
+
+func (c *PrometheusConverter) Type() string {
+	return ConverterTypePrometheus
+}
+
+func (c *PrometheusConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	frameWrappers, err := c.converter.ConvertPrometheus(body, c.config.FrameFormat)
+	if err != nil {
+		return nil, err
+	}
+	channelFrames := make([]*ChannelFrame, 0, len(frameWrappers))
+	for _, fw := range frameWrappers {
+		channelFrames = append(channelFrames, &ChannelFrame{
+			Channel: vars.Channel + "/" + fw.Key(),
+			Frame:   fw.Frame(),
+		})
+	}
+	return channelFrames, nil
+}
@@ -0,0 +1,117 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/at-wat/mqtt-go"
+)
+
+// InputProcessor processes data received for a channel, see Pipeline.ProcessInput.
+type InputProcessor interface {
+	ProcessInput(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error)
+}
+
+type MQTTInputConfig struct {
+	// URL of the broker, e.g. mqtt://localhost:1883 or mqtts://broker:8883.
+	URL      string
+	ClientID string
+	Username string
+	Password string
+	// Topics are topic filters to subscribe to, wildcards and shared
+	// subscriptions are supported.
+	Topics []string
+	// ChannelPrefix is prepended to the topic of a message to get its channel,
+	// e.g. topic plant/line1/temperature is mapped to channel
+	// stream/mqtt/plant/line1/temperature with the stream/mqtt prefix.
+	ChannelPrefix string
+	// OrgID is the organization whose channel rules process the messages.
+	OrgID int64
+}
+
+// MQTTInput subscribes to MQTT topics and processes every received message as
+// an input of the channel the topic is mapped to. Messages are processed by
+// the channel rules of the channel, same as data pushed over HTTP or WebSocket.
+type MQTTInput struct {
+	config    MQTTInputConfig
+	processor InputProcessor
+}
+
+// NewMQTTInput creates new MQTTInput.
+func NewMQTTInput(config MQTTInputConfig, processor InputProcessor) *MQTTInput {
+	return &MQTTInput{config: config, processor: processor}
+}
+
+const (
+	mqttKeepAliveSeconds  = 30
+	mqttTimeout           = 10 * time.Second
+	mqttReconnectWaitBase = time.Second
+	mqttReconnectWaitMax  = 30 * time.Second
+)
+
+// Run connects to the broker and processes messages until ctx is done. The
+// connection is re-established and topics are re-subscribed after failures.
+func (in *MQTTInput) Run(ctx context.Context) error {
+	if len(in.config.Topics) == 0 {
+		return errors.New("no MQTT topics to subscribe to")
+	}
+
+	cli, err := mqtt.NewReconnectClient(
+		&mqtt.URLDialer{URL: in.config.URL},
+		mqtt.WithPingInterval(mqttKeepAliveSeconds*time.Second),
+		mqtt.WithTimeout(mqttTimeout),
+		mqtt.WithReconnectWait(mqttReconnectWaitBase, mqttReconnectWaitMax),
+	)
+	if err != nil {
+		return err
+	}
+	cli.Handle(mqtt.HandlerFunc(func(msg *mqtt.Message) {
+		in.handleMessage(ctx, msg)
+	}))
+
+	opts := []mqtt.ConnectOption{mqtt.WithKeepAlive(mqttKeepAliveSeconds)}
+	if in.config.Username != "" {
+		opts = append(opts, mqtt.WithUserNamePassword(in.config.Username, in.config.Password))
+	}
+	if _, err := cli.Connect(ctx, in.config.ClientID, opts...); err != nil {
+		return err
+	}
+	defer func() {
+		disconnectCtx, cancel := context.WithTimeout(context.Background(), mqttTimeout)
+		defer cancel()
+		if err := cli.Disconnect(disconnectCtx); err != nil {
+			logger.Warn("Error disconnecting from MQTT broker", "error", err)
+		}
+	}()
+
+	subscriptions := make([]mqtt.Subscription, 0, len(in.config.Topics))
+	for _, topic := range in.config.Topics {
+		subscriptions = append(subscriptions, mqtt.Subscription{Topic: topic, QoS: mqtt.QoS1})
+	}
+	if _, err := cli.Subscribe(ctx, subscriptions...); err != nil {
+		return err
+	}
+	logger.Info("Subscribed to MQTT topics", "url", in.config.URL, "topics", in.config.Topics)
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (in *MQTTInput) handleMessage(ctx context.Context, msg *mqtt.Message) {
+	channelID := in.channelID(msg.Topic)
+	ruleFound, err := in.processor.ProcessInput(ctx, in.config.OrgID, channelID, msg.Payload)
+	if err != nil {
+		logger.Error("Pipeline input processing error", "error", err, "topic", msg.Topic, "channel", channelID)
+		return
+	}
+	if !ruleFound {
+		logger.Debug("No conversion rule for a channel", "topic", msg.Topic, "channel", channelID)
+	}
+}
+
+// channelID maps a topic to a channel.
+func (in *MQTTInput) channelID(topic string) string {
+	return strings.TrimSuffix(in.config.ChannelPrefix, "/") + "/" + strings.Trim(topic, "/")
+}
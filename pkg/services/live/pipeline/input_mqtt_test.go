@@ -0,0 +1,88 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline/mqtttest"
+)
+
+type processedInput struct {
+	orgID     int64
+	channelID string
+	body      string
+}
+
+type fakeInputProcessor struct {
+	inputs chan processedInput
+}
+
+func (p *fakeInputProcessor) ProcessInput(_ context.Context, orgID int64, channelID string, body []byte) (bool, error) {
+	p.inputs <- processedInput{orgID: orgID, channelID: channelID, body: string(body)}
+	return true, nil
+}
+
+func TestMQTTInput_Run(t *testing.T) {
+	broker := mqtttest.NewBroker(t)
+	processor := &fakeInputProcessor{inputs: make(chan processedInput, 10)}
+	input := NewMQTTInput(MQTTInputConfig{
+		URL:           broker.URL(),
+		ClientID:      "grafana",
+		Topics:        []string{"sensors/#", "plant/+/temperature"},
+		ChannelPrefix: "stream/mqtt",
+		OrgID:         2,
+	}, processor)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- input.Run(ctx)
+	}()
+
+	select {
+	case topics := <-broker.Subscribed:
+		require.Equal(t, []string{"sensors/#", "plant/+/temperature"}, topics)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for subscription")
+	}
+
+	broker.Publish(t, "sensors/line1/temperature", []byte(`{"value": 21.5}`))
+	select {
+	case in := <-processor.inputs:
+		require.Equal(t, processedInput{orgID: 2, channelID: "stream/mqtt/sensors/line1/temperature", body: `{"value": 21.5}`}, in)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for message")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for input to stop")
+	}
+}
+
+func TestMQTTInput_Run_NoTopics(t *testing.T) {
+	input := NewMQTTInput(MQTTInputConfig{URL: "mqtt://127.0.0.1:1883"}, &fakeInputProcessor{})
+	require.Error(t, input.Run(context.Background()))
+}
+
+func TestMQTTInput_channelID(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		topic    string
+		expected string
+	}{
+		{prefix: "stream/mqtt", topic: "sensors/temperature", expected: "stream/mqtt/sensors/temperature"},
+		{prefix: "stream/mqtt/", topic: "/sensors/temperature", expected: "stream/mqtt/sensors/temperature"},
+		{prefix: "stream/plant", topic: "temperature", expected: "stream/plant/temperature"},
+	}
+	for _, tt := range tests {
+		input := NewMQTTInput(MQTTInputConfig{ChannelPrefix: tt.prefix}, nil)
+		require.Equal(t, tt.expected, input.channelID(tt.topic))
+	}
+}
@@ -0,0 +1,108 @@
+// Package mqtttest provides a fake MQTT broker for tests.
+package mqtttest
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Broker is a stand-in for an MQTT 3.1.1 broker, which accepts
+// connections and subscriptions and sends QoS 0 publications to subscribers.
+type Broker struct {
+	listener net.Listener
+	// Subscribed receives the topics of every subscription.
+	Subscribed chan []string
+
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+// NewBroker starts a Broker listening on a random local port, closed at the end of the test.
+func NewBroker(t *testing.T) *Broker {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	b := &Broker{listener: listener, Subscribed: make(chan []string, 10)}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+// URL is the address to connect to the broker.
+func (b *Broker) URL() string {
+	return "mqtt://" + b.listener.Addr().String()
+}
+
+func (b *Broker) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	for {
+		packetType, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+		length, err := binary.ReadUvarint(r)
+		if err != nil {
+			return
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return
+		}
+
+		switch packetType >> 4 {
+		case 1: // CONNECT
+			_, _ = conn.Write([]byte{0x20, 0x02, 0x00, 0x00})
+		case 8: // SUBSCRIBE
+			var topics []string
+			granted := []byte{}
+			for payload := body[2:]; len(payload) > 2; {
+				topicLength := int(binary.BigEndian.Uint16(payload))
+				topics = append(topics, string(payload[2:2+topicLength]))
+				granted = append(granted, payload[2+topicLength])
+				payload = payload[3+topicLength:]
+			}
+			_, _ = conn.Write(append([]byte{0x90, byte(2 + len(granted)), body[0], body[1]}, granted...))
+			b.mu.Lock()
+			b.conns = append(b.conns, conn)
+			b.mu.Unlock()
+			b.Subscribed <- topics
+		case 12: // PINGREQ
+			_, _ = conn.Write([]byte{0xd0, 0x00})
+		case 14: // DISCONNECT
+			return
+		}
+	}
+}
+
+// Publish sends a QoS 0 publication to all subscribed connections.
+func (b *Broker) Publish(t *testing.T, topic string, payload []byte) {
+	t.Helper()
+	body := binary.BigEndian.AppendUint16(nil, uint16(len(topic)))
+	body = append(body, topic...)
+	body = append(body, payload...)
+	packet := binary.AppendUvarint([]byte{0x30}, uint64(len(body)))
+	packet = append(packet, body...)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, conn := range b.conns {
+		_, err := conn.Write(packet)
+		require.NoError(t, err)
+	}
+}
@@ -66,6 +66,20 @@ var ConvertersRegistry = []EntityInfo{
 		Type:        ConverterTypeJsonFrame,
 		Description: "JSON-encoded Grafana data frame",
 	},
+	{
+		Type:        ConverterTypePrometheus,
+		Description: "accept Prometheus text exposition format",
+		Example: PrometheusConverterConfig{
+			FrameFormat: "labels_column",
+		},
+	},
+	{
+		Type:        ConverterTypeOTLP,
+		Description: "accept OTLP/HTTP metrics, protobuf or JSON encoded",
+		Example: OTLPConverterConfig{
+			FrameFormat: "labels_column",
+		},
+	},
 }
 
 var FrameProcessorsRegistry = []EntityInfo{
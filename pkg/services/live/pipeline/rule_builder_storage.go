@@ -69,6 +69,16 @@ func (f *StorageRuleBuilder) extractConverter(config *ConverterConfig) (Converte
 			return nil, missingConfiguration
 		}
 		return NewAutoInfluxConverter(*config.AutoInfluxConverterConfig), nil
+	case ConverterTypePrometheus:
+		if config.PrometheusConverterConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewPrometheusConverter(*config.PrometheusConverterConfig), nil
+	case ConverterTypeOTLP:
+		if config.OTLPConverterConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewOTLPConverter(*config.OTLPConverterConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown converter type: %s", config.Type)
 	}
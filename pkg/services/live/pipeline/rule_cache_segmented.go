@@ -9,20 +9,34 @@ import (
 	"github.com/grafana/grafana/pkg/services/live/pipeline/tree"
 )
 
+// ruleCacheTTL is how long the channel rules of an organization are served
+// from the cache before Get builds them again from the rule storage.
+const ruleCacheTTL = time.Minute
+
 // CacheSegmentedTree provides a fast access to channel rule configuration.
 type CacheSegmentedTree struct {
 	radixMu     sync.RWMutex
 	radix       map[int64]*tree.Node
+	filledAt    map[int64]time.Time
 	ruleBuilder RuleBuilder
+	ttl         time.Duration
+	now         func() time.Time
 }
 
 func NewCacheSegmentedTree(storage RuleBuilder) *CacheSegmentedTree {
-	s := &CacheSegmentedTree{
+	s := newCacheSegmentedTree(storage, ruleCacheTTL, time.Now)
+	go s.updatePeriodically()
+	return s
+}
+
+func newCacheSegmentedTree(storage RuleBuilder, ttl time.Duration, now func() time.Time) *CacheSegmentedTree {
+	return &CacheSegmentedTree{
 		radix:       map[int64]*tree.Node{},
+		filledAt:    map[int64]time.Time{},
 		ruleBuilder: storage,
+		ttl:         ttl,
+		now:         now,
 	}
-	go s.updatePeriodically()
-	return s
 }
 
 func (s *CacheSegmentedTree) updatePeriodically() {
@@ -53,17 +67,28 @@ func (s *CacheSegmentedTree) fillOrg(orgID int64) error {
 	s.radixMu.Lock()
 	defer s.radixMu.Unlock()
 	s.radix[orgID] = tree.New()
+	s.filledAt[orgID] = s.now()
 	for _, ch := range channels {
 		s.radix[orgID].AddRoute("/"+ch.Pattern, ch)
 	}
 	return nil
 }
 
+// Invalidate drops the cached channel rules of an organization, so they are
+// built again from the rule storage on the next Get.
+func (s *CacheSegmentedTree) Invalidate(orgID int64) {
+	s.radixMu.Lock()
+	defer s.radixMu.Unlock()
+	delete(s.radix, orgID)
+	delete(s.filledAt, orgID)
+}
+
 func (s *CacheSegmentedTree) Get(orgID int64, channel string) (*LiveChannelRule, bool, error) {
 	s.radixMu.RLock()
 	_, ok := s.radix[orgID]
+	expired := s.now().Sub(s.filledAt[orgID]) >= s.ttl
 	s.radixMu.RUnlock()
-	if !ok {
+	if !ok || expired {
 		err := s.fillOrg(orgID)
 		if err != nil {
 			return nil, false, fmt.Errorf("error filling org: %w", err)
@@ -2,7 +2,9 @@ package pipeline
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -53,6 +55,67 @@ func TestStorage_Get(t *testing.T) {
 	require.Equal(t, "stream/boom:er", rule.Pattern)
 }
 
+type switchingBuilder struct {
+	mu      sync.Mutex
+	pattern string
+}
+
+func (t *switchingBuilder) BuildRules(_ context.Context, _ int64) ([]*LiveChannelRule, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return []*LiveChannelRule{{OrgId: 1, Pattern: t.pattern}}, nil
+}
+
+func TestStorage_Invalidate(t *testing.T) {
+	builder := &switchingBuilder{pattern: "stream/telegraf/cpu"}
+	s := NewCacheSegmentedTree(builder)
+
+	_, ok, err := s.Get(1, "stream/telegraf/cpu")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	builder.mu.Lock()
+	builder.pattern = "stream/telegraf/mem"
+	builder.mu.Unlock()
+	s.Invalidate(1)
+
+	_, ok, err = s.Get(1, "stream/telegraf/cpu")
+	require.NoError(t, err)
+	require.False(t, ok)
+	rule, ok, err := s.Get(1, "stream/telegraf/mem")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "stream/telegraf/mem", rule.Pattern)
+}
+
+func TestStorage_Expire(t *testing.T) {
+	builder := &switchingBuilder{pattern: "stream/telegraf/cpu"}
+	now := time.Now()
+	s := newCacheSegmentedTree(builder, time.Minute, func() time.Time { return now })
+
+	_, ok, err := s.Get(1, "stream/telegraf/cpu")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	builder.mu.Lock()
+	builder.pattern = "stream/telegraf/mem"
+	builder.mu.Unlock()
+
+	now = now.Add(30 * time.Second)
+	_, ok, err = s.Get(1, "stream/telegraf/cpu")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	now = now.Add(30 * time.Second)
+	_, ok, err = s.Get(1, "stream/telegraf/cpu")
+	require.NoError(t, err)
+	require.False(t, ok)
+	rule, ok, err := s.Get(1, "stream/telegraf/mem")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "stream/telegraf/mem", rule.Pattern)
+}
+
 func BenchmarkRuleGet(b *testing.B) {
 	s := NewCacheSegmentedTree(&testBuilder{})
 	for i := 0; i < b.N; i++ {
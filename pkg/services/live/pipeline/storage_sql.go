@@ -0,0 +1,327 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// SQLStorage keeps channel rules and write configs in the Grafana database,
+// so they are shared by all Grafana instances.
+type SQLStorage struct {
+	store          db.DB
+	secretsService secrets.Service
+}
+
+func NewSQLStorage(store db.DB, secretsService secrets.Service) *SQLStorage {
+	return &SQLStorage{store: store, secretsService: secretsService}
+}
+
+type channelRuleRow struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	OrgID     int64  `xorm:"org_id"`
+	Pattern   string `xorm:"pattern"`
+	Settings  string `xorm:"settings"`
+	CreatedAt int64  `xorm:"created_at"`
+	UpdatedAt int64  `xorm:"updated_at"`
+}
+
+func (channelRuleRow) TableName() string {
+	return "live_channel_rule"
+}
+
+type writeConfigRow struct {
+	ID             int64  `xorm:"pk autoincr 'id'"`
+	OrgID          int64  `xorm:"org_id"`
+	UID            string `xorm:"uid"`
+	Settings       string `xorm:"settings"`
+	SecureSettings string `xorm:"secure_settings"`
+	CreatedAt      int64  `xorm:"created_at"`
+	UpdatedAt      int64  `xorm:"updated_at"`
+}
+
+func (writeConfigRow) TableName() string {
+	return "live_write_config"
+}
+
+func (s *SQLStorage) ListWriteConfigs(ctx context.Context, orgID int64) ([]WriteConfig, error) {
+	var rows []writeConfigRow
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("uid").Find(&rows)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't read write configs: %w", err)
+	}
+	configs := make([]WriteConfig, 0, len(rows))
+	for _, row := range rows {
+		config, err := row.toWriteConfig()
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+func (s *SQLStorage) GetWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigGetCmd) (WriteConfig, bool, error) {
+	row := writeConfigRow{}
+	var found bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		found, err = sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Get(&row)
+		return err
+	})
+	if err != nil || !found {
+		return WriteConfig{}, false, err
+	}
+	config, err := row.toWriteConfig()
+	return config, err == nil, err
+}
+
+func (s *SQLStorage) CreateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigCreateCmd) (WriteConfig, error) {
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	}
+	config, row, err := s.newWriteConfigRow(ctx, orgID, cmd.UID, cmd.Settings, cmd.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, err
+	}
+
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, row.UID).Exist(&writeConfigRow{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("backend already exists in org: %s", row.UID)
+		}
+		_, err = sess.Insert(row)
+		return err
+	})
+	return config, err
+}
+
+// UpdateWriteConfig replaces the write config, or creates it when it does not exist yet.
+func (s *SQLStorage) UpdateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigUpdateCmd) (WriteConfig, error) {
+	config, row, err := s.newWriteConfigRow(ctx, orgID, cmd.UID, cmd.Settings, cmd.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, err
+	}
+
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := writeConfigRow{}
+		found, err := sess.Where("org_id = ? AND uid = ?", orgID, row.UID).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !found {
+			_, err = sess.Insert(row)
+			return err
+		}
+		_, err = sess.ID(existing.ID).Cols("settings", "secure_settings", "updated_at").Update(row)
+		return err
+	})
+	return config, err
+}
+
+func (s *SQLStorage) DeleteWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigDeleteCmd) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Delete(&writeConfigRow{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return errors.New("write config not found")
+		}
+		return nil
+	})
+}
+
+func (s *SQLStorage) ListChannelRules(ctx context.Context, orgID int64) ([]ChannelRule, error) {
+	var rules []ChannelRule
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		rules, err = listChannelRules(sess, orgID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't read channel rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (s *SQLStorage) CreateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error) {
+	rule := ChannelRule{
+		OrgId:    orgID,
+		Pattern:  cmd.Pattern,
+		Settings: cmd.Settings,
+	}
+	ok, reason := rule.Valid()
+	if !ok {
+		return rule, fmt.Errorf("invalid channel rule: %s", reason)
+	}
+
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		rules, err := listChannelRules(sess, orgID)
+		if err != nil {
+			return err
+		}
+		for _, existingRule := range rules {
+			if existingRule.Pattern == rule.Pattern {
+				return fmt.Errorf("pattern already exists in org: %s", rule.Pattern)
+			}
+		}
+		if ok, reason := checkRulesValid(orgID, append(rules, rule)); !ok {
+			return errors.New(reason)
+		}
+
+		row, err := newChannelRuleRow(rule)
+		if err != nil {
+			return err
+		}
+		_, err = sess.Insert(row)
+		return err
+	})
+	return rule, err
+}
+
+// UpdateChannelRule replaces the channel rule, or creates it when it does not exist yet.
+func (s *SQLStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleUpdateCmd) (ChannelRule, error) {
+	rule := ChannelRule{
+		OrgId:    orgID,
+		Pattern:  cmd.Pattern,
+		Settings: cmd.Settings,
+	}
+	ok, reason := rule.Valid()
+	if !ok {
+		return rule, fmt.Errorf("invalid channel rule: %s", reason)
+	}
+
+	row, err := newChannelRuleRow(rule)
+	if err != nil {
+		return rule, err
+	}
+
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := channelRuleRow{}
+		found, err := sess.Where("org_id = ? AND pattern = ?", orgID, rule.Pattern).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !found {
+			rules, err := listChannelRules(sess, orgID)
+			if err != nil {
+				return err
+			}
+			if ok, reason := checkRulesValid(orgID, append(rules, rule)); !ok {
+				return errors.New(reason)
+			}
+			_, err = sess.Insert(row)
+			return err
+		}
+		_, err = sess.ID(existing.ID).Cols("settings", "updated_at").Update(row)
+		return err
+	})
+	return rule, err
+}
+
+func (s *SQLStorage) DeleteChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleDeleteCmd) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND pattern = ?", orgID, cmd.Pattern).Delete(&channelRuleRow{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return errors.New("rule not found")
+		}
+		return nil
+	})
+}
+
+func listChannelRules(sess *db.Session, orgID int64) ([]ChannelRule, error) {
+	var rows []channelRuleRow
+	if err := sess.Where("org_id = ?", orgID).Asc("pattern").Find(&rows); err != nil {
+		return nil, err
+	}
+	rules := make([]ChannelRule, 0, len(rows))
+	for _, row := range rows {
+		rule := ChannelRule{OrgId: row.OrgID, Pattern: row.Pattern}
+		if err := json.Unmarshal([]byte(row.Settings), &rule.Settings); err != nil {
+			return nil, fmt.Errorf("can't unmarshal settings of channel rule %s: %w", row.Pattern, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func newChannelRuleRow(rule ChannelRule) (*channelRuleRow, error) {
+	settings, err := json.Marshal(rule.Settings)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UnixMilli()
+	return &channelRuleRow{
+		OrgID:     rule.OrgId,
+		Pattern:   rule.Pattern,
+		Settings:  string(settings),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// newWriteConfigRow encrypts the secure settings of a write config. Encryption
+// must happen outside of database transactions.
+func (s *SQLStorage) newWriteConfigRow(ctx context.Context, orgID int64, uid string, settings WriteSettings, secureSettings map[string]string) (WriteConfig, *writeConfigRow, error) {
+	encrypted, err := s.secretsService.EncryptJsonData(ctx, secureSettings, secrets.WithoutScope())
+	if err != nil {
+		return WriteConfig{}, nil, fmt.Errorf("error encrypting data: %w", err)
+	}
+
+	config := WriteConfig{
+		OrgId:          orgID,
+		UID:            uid,
+		Settings:       settings,
+		SecureSettings: encrypted,
+	}
+	ok, reason := config.Valid()
+	if !ok {
+		return WriteConfig{}, nil, fmt.Errorf("invalid write config: %s", reason)
+	}
+
+	settingsJSON, err := json.Marshal(config.Settings)
+	if err != nil {
+		return WriteConfig{}, nil, err
+	}
+	secureSettingsJSON, err := json.Marshal(config.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, nil, err
+	}
+	now := time.Now().UnixMilli()
+	return config, &writeConfigRow{
+		OrgID:          orgID,
+		UID:            uid,
+		Settings:       string(settingsJSON),
+		SecureSettings: string(secureSettingsJSON),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+func (row writeConfigRow) toWriteConfig() (WriteConfig, error) {
+	config := WriteConfig{OrgId: row.OrgID, UID: row.UID}
+	if err := json.Unmarshal([]byte(row.Settings), &config.Settings); err != nil {
+		return WriteConfig{}, fmt.Errorf("can't unmarshal settings of write config %s: %w", row.UID, err)
+	}
+	if row.SecureSettings != "" {
+		if err := json.Unmarshal([]byte(row.SecureSettings), &config.SecureSettings); err != nil {
+			return WriteConfig{}, fmt.Errorf("can't unmarshal secure settings of write config %s: %w", row.UID, err)
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,120 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationSQLStorageChannelRules(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	storage := NewSQLStorage(db.InitTestDB(t), fakes.NewFakeSecretsService())
+
+	rule, err := storage.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{
+		Pattern: "stream/telegraf/:metric",
+		Settings: ChannelRuleSettings{
+			Converter: &ConverterConfig{Type: ConverterTypeJsonAuto},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rule.OrgId)
+
+	_, err = storage.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{Pattern: "stream/telegraf/:metric"})
+	require.ErrorContains(t, err, "pattern already exists")
+
+	// Conflicting patterns are rejected.
+	_, err = storage.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{Pattern: "stream/telegraf/:other"})
+	require.Error(t, err)
+
+	// The same pattern can exist in another organization.
+	_, err = storage.CreateChannelRule(ctx, 2, ChannelRuleCreateCmd{Pattern: "stream/telegraf/:metric"})
+	require.NoError(t, err)
+
+	_, err = storage.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{Pattern: "stream/telegraf/:metric"})
+	require.NoError(t, err)
+	_, err = storage.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{Pattern: "stream/influx/cpu"})
+	require.NoError(t, err)
+
+	rules, err := storage.ListChannelRules(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	require.Equal(t, "stream/influx/cpu", rules[0].Pattern)
+	require.Equal(t, "stream/telegraf/:metric", rules[1].Pattern)
+	require.Nil(t, rules[1].Settings.Converter)
+
+	require.NoError(t, storage.DeleteChannelRule(ctx, 1, ChannelRuleDeleteCmd{Pattern: "stream/influx/cpu"}))
+	require.ErrorContains(t, storage.DeleteChannelRule(ctx, 1, ChannelRuleDeleteCmd{Pattern: "stream/influx/cpu"}), "rule not found")
+
+	rules, err = storage.ListChannelRules(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, ConverterTypeJsonAuto, rules[0].Settings.Converter.Type)
+}
+
+func TestIntegrationSQLStorageWriteConfigs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	secretsService := fakes.NewFakeSecretsService()
+	storage := NewSQLStorage(db.InitTestDB(t), secretsService)
+
+	_, err := storage.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{UID: "remote"})
+	require.ErrorContains(t, err, "endpoint required")
+
+	created, err := storage.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{
+		UID:            "remote",
+		Settings:       WriteSettings{Endpoint: "http://localhost:9090/api/v1/write", BasicAuth: &BasicAuth{User: "admin"}},
+		SecureSettings: map[string]string{"basicAuthPassword": "secret"},
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, []byte("secret"), created.SecureSettings["basicAuthPassword"])
+
+	_, err = storage.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{
+		UID:      "remote",
+		Settings: WriteSettings{Endpoint: "http://localhost:9090/api/v1/write"},
+	})
+	require.ErrorContains(t, err, "already exists")
+
+	config, ok, err := storage.GetWriteConfig(ctx, 1, WriteConfigGetCmd{UID: "remote"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "admin", config.Settings.BasicAuth.User)
+	secureSettings, err := secretsService.DecryptJsonData(ctx, config.SecureSettings)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"basicAuthPassword": "secret"}, secureSettings)
+
+	// Write configs are scoped to their organization.
+	_, ok, err = storage.GetWriteConfig(ctx, 2, WriteConfigGetCmd{UID: "remote"})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = storage.UpdateWriteConfig(ctx, 1, WriteConfigUpdateCmd{
+		UID:      "remote",
+		Settings: WriteSettings{Endpoint: "http://remote:9090/api/v1/write"},
+	})
+	require.NoError(t, err)
+
+	configs, err := storage.ListWriteConfigs(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	require.Equal(t, "http://remote:9090/api/v1/write", configs[0].Settings.Endpoint)
+	require.Empty(t, configs[0].SecureSettings)
+
+	require.NoError(t, storage.DeleteWriteConfig(ctx, 1, WriteConfigDeleteCmd{UID: "remote"}))
+	configs, err = storage.ListWriteConfigs(ctx, 1)
+	require.NoError(t, err)
+	require.Empty(t, configs)
+}
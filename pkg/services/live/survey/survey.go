@@ -15,12 +15,14 @@ import (
 )
 
 type Caller struct {
-	managedStreamRunner *managedstream.Runner
-	node                *centrifuge.Node
+	managedStreamRunner      *managedstream.Runner
+	node                     *centrifuge.Node
+	onInvalidateChannelRules func(orgID int64)
 }
 
 const (
-	managedStreamsCall = "managed_streams"
+	managedStreamsCall         = "managed_streams"
+	invalidateChannelRulesCall = "invalidate_channel_rules"
 )
 
 func NewCaller(managedStreamRunner *managedstream.Runner, node *centrifuge.Node) *Caller {
@@ -32,6 +34,12 @@ func (c *Caller) SetupHandlers() error {
 	return nil
 }
 
+// OnInvalidateChannelRules sets the handler called on every node when the
+// pipeline channel rules of an organization changed.
+func (c *Caller) OnInvalidateChannelRules(handler func(orgID int64)) {
+	c.onInvalidateChannelRules = handler
+}
+
 type NodeManagedChannelsRequest struct {
 	OrgID int64 `json:"orgId"`
 }
@@ -40,6 +48,10 @@ type NodeManagedChannelsResponse struct {
 	Channels []*managedstream.ManagedChannel `json:"channels"`
 }
 
+type NodeInvalidateChannelRulesRequest struct {
+	OrgID int64 `json:"orgId"`
+}
+
 func (c *Caller) handleSurvey(e centrifuge.SurveyEvent, cb centrifuge.SurveyCallback) {
 	var (
 		resp any
@@ -48,6 +60,8 @@ func (c *Caller) handleSurvey(e centrifuge.SurveyEvent, cb centrifuge.SurveyCall
 	switch e.Op {
 	case managedStreamsCall:
 		resp, err = c.handleManagedStreams(e.Data)
+	case invalidateChannelRulesCall:
+		resp, err = c.handleInvalidateChannelRules(e.Data)
 	default:
 		err = errors.New("method not found")
 	}
@@ -81,6 +95,41 @@ func (c *Caller) handleManagedStreams(data []byte) (any, error) {
 	}, nil
 }
 
+func (c *Caller) handleInvalidateChannelRules(data []byte) (any, error) {
+	var req NodeInvalidateChannelRulesRequest
+	err := json.Unmarshal(data, &req)
+	if err != nil {
+		return nil, err
+	}
+	if c.onInvalidateChannelRules != nil {
+		c.onInvalidateChannelRules(req.OrgID)
+	}
+	return struct{}{}, nil
+}
+
+// CallInvalidateChannelRules makes all nodes drop their cached pipeline
+// channel rules of an organization.
+func (c *Caller) CallInvalidateChannelRules(orgID int64) error {
+	req := NodeInvalidateChannelRulesRequest{OrgID: orgID}
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	resp, err := c.node.Survey(ctx, invalidateChannelRulesCall, jsonData, "")
+	if err != nil {
+		return err
+	}
+	for nodeID, result := range resp {
+		if result.Code != 0 {
+			return fmt.Errorf("unexpected survey code from node %s: %d", nodeID, result.Code)
+		}
+	}
+	return nil
+}
+
 func (c *Caller) CallManagedStreams(orgID int64) ([]*managedstream.ManagedChannel, error) {
 	req := NodeManagedChannelsRequest{OrgID: orgID}
 	jsonData, err := json.Marshal(req)
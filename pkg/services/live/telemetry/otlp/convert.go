@@ -0,0 +1,195 @@
+package otlp
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+
+	influx "github.com/influxdata/line-protocol"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+// Converter converts OTLP metrics export requests to Grafana frames.
+type Converter struct {
+	metricConverter *telegraf.Converter
+}
+
+// NewConverter creates new Converter from OTLP/HTTP metrics payloads, either
+// protobuf or JSON encoded, to Grafana Data Frames. Every data point becomes a
+// metric with a single value field labeled with the resource and data point
+// attributes, which is then converted the same way as Telegraf metrics.
+func NewConverter(opts ...telegraf.ConverterOption) *Converter {
+	return &Converter{
+		metricConverter: telegraf.NewConverter(opts...),
+	}
+}
+
+// Convert metrics.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	req := pmetricotlp.NewExportRequest()
+	var err error
+	if isJSON(body) {
+		err = req.UnmarshalJSON(body)
+	} else {
+		err = req.UnmarshalProto(body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metrics: %w", err)
+	}
+
+	now := time.Now()
+	var metrics []influx.Metric
+	resourceMetrics := req.Metrics().ResourceMetrics()
+	for i := 0; i < resourceMetrics.Len(); i++ {
+		rm := resourceMetrics.At(i)
+		scopeMetrics := rm.ScopeMetrics()
+		for j := 0; j < scopeMetrics.Len(); j++ {
+			ms := scopeMetrics.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				samples, err := metricSamples(ms.At(k), rm.Resource().Attributes(), now)
+				if err != nil {
+					return nil, err
+				}
+				metrics = append(metrics, samples...)
+			}
+		}
+	}
+	return c.metricConverter.ConvertMetrics(metrics)
+}
+
+func isJSON(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+type sampleBuilder struct {
+	resourceAttributes pcommon.Map
+	now                time.Time
+	samples            []influx.Metric
+}
+
+// add appends a sample labeled with the resource attributes, the data point
+// attributes and the optional extra label.
+func (b *sampleBuilder) add(name string, attributes pcommon.Map, ts pcommon.Timestamp, extraLabel string, extraValue string, value float64) error {
+	tags := make(map[string]string, b.resourceAttributes.Len()+attributes.Len()+1)
+	b.resourceAttributes.Range(func(k string, v pcommon.Value) bool {
+		tags[k] = v.AsString()
+		return true
+	})
+	attributes.Range(func(k string, v pcommon.Value) bool {
+		tags[k] = v.AsString()
+		return true
+	})
+	if extraLabel != "" {
+		tags[extraLabel] = extraValue
+	}
+
+	tm := b.now
+	if ts != 0 {
+		tm = ts.AsTime()
+	}
+	sample, err := influx.New(name, tags, map[string]any{"value": value}, tm)
+	if err != nil {
+		return fmt.Errorf("error creating metric %s: %w", name, err)
+	}
+	b.samples = append(b.samples, sample)
+	return nil
+}
+
+// metricSamples returns the samples of a metric following the Prometheus
+// naming, e.g. histograms are split into _bucket, _sum and _count.
+func metricSamples(m pmetric.Metric, resourceAttributes pcommon.Map, now time.Time) ([]influx.Metric, error) {
+	b := &sampleBuilder{resourceAttributes: resourceAttributes, now: now}
+	name := m.Name()
+
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		if err := b.addNumberDataPoints(name, m.Gauge().DataPoints()); err != nil {
+			return nil, err
+		}
+	case pmetric.MetricTypeSum:
+		if err := b.addNumberDataPoints(name, m.Sum().DataPoints()); err != nil {
+			return nil, err
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := m.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			bounds := dp.ExplicitBounds().AsRaw()
+			var cumulativeCount uint64
+			for j, count := range dp.BucketCounts().AsRaw() {
+				cumulativeCount += count
+				le := "+Inf"
+				if j < len(bounds) {
+					le = strconv.FormatFloat(bounds[j], 'g', -1, 64)
+				}
+				if err := b.add(name+"_bucket", dp.Attributes(), dp.Timestamp(), "le", le, float64(cumulativeCount)); err != nil {
+					return nil, err
+				}
+			}
+			if err := b.addSumAndCount(name, dp.Attributes(), dp.Timestamp(), dp.HasSum(), dp.Sum(), dp.Count()); err != nil {
+				return nil, err
+			}
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := m.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			if err := b.addSumAndCount(name, dp.Attributes(), dp.Timestamp(), dp.HasSum(), dp.Sum(), dp.Count()); err != nil {
+				return nil, err
+			}
+		}
+	case pmetric.MetricTypeSummary:
+		dps := m.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			quantiles := dp.QuantileValues()
+			for j := 0; j < quantiles.Len(); j++ {
+				q := quantiles.At(j)
+				if err := b.add(name, dp.Attributes(), dp.Timestamp(), "quantile", strconv.FormatFloat(q.Quantile(), 'g', -1, 64), q.Value()); err != nil {
+					return nil, err
+				}
+			}
+			if err := b.addSumAndCount(name, dp.Attributes(), dp.Timestamp(), true, dp.Sum(), dp.Count()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b.samples, nil
+}
+
+func (b *sampleBuilder) addNumberDataPoints(name string, dps pmetric.NumberDataPointSlice) error {
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		var value float64
+		switch dp.ValueType() {
+		case pmetric.NumberDataPointValueTypeInt:
+			value = float64(dp.IntValue())
+		case pmetric.NumberDataPointValueTypeDouble:
+			value = dp.DoubleValue()
+		default:
+			continue
+		}
+		if err := b.add(name, dp.Attributes(), dp.Timestamp(), "", "", value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *sampleBuilder) addSumAndCount(name string, attributes pcommon.Map, ts pcommon.Timestamp, hasSum bool, sum float64, count uint64) error {
+	if hasSum {
+		if err := b.add(name+"_sum", attributes, ts, "", "", sum); err != nil {
+			return err
+		}
+	}
+	return b.add(name+"_count", attributes, ts, "", "", float64(count))
+}
@@ -0,0 +1,90 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
+)
+
+var testTime = time.Unix(1700000000, 0)
+
+func testExportRequest() pmetricotlp.ExportRequest {
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "plc")
+	ms := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+	gauge := ms.AppendEmpty()
+	gauge.SetName("temperature")
+	dp := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(testTime))
+	dp.SetDoubleValue(21.5)
+	dp.Attributes().PutStr("sensor", "a")
+
+	histogram := ms.AppendEmpty()
+	histogram.SetName("duration")
+	hdp := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+	hdp.SetTimestamp(pcommon.NewTimestampFromTime(testTime))
+	hdp.ExplicitBounds().FromRaw([]float64{0.5})
+	hdp.BucketCounts().FromRaw([]uint64{24, 6})
+	hdp.SetCount(30)
+	hdp.SetSum(17.5)
+
+	return pmetricotlp.NewExportRequestFromMetrics(metrics)
+}
+
+func TestConverter_Convert(t *testing.T) {
+	protoBody, err := testExportRequest().MarshalProto()
+	require.NoError(t, err)
+	jsonBody, err := testExportRequest().MarshalJSON()
+	require.NoError(t, err)
+
+	for name, body := range map[string][]byte{"protobuf": protoBody, "json": jsonBody} {
+		t.Run(name, func(t *testing.T) {
+			c := NewConverter(telegraf.WithUseLabelsColumn(true), telegraf.WithFloat64Numbers(true))
+			frameWrappers, err := c.Convert(body)
+			require.NoError(t, err)
+
+			keys := make([]string, 0, len(frameWrappers))
+			for _, fw := range frameWrappers {
+				keys = append(keys, fw.Key())
+			}
+			require.Equal(t, []string{"temperature", "duration_bucket", "duration_sum", "duration_count"}, keys)
+
+			frame := frameWrappers[0].Frame()
+			require.Equal(t, "sensor=a, service.name=plc", frame.Fields[0].At(0))
+			require.True(t, testTime.Equal(frame.Fields[1].At(0).(time.Time)))
+			require.Equal(t, 21.5, *frame.Fields[2].At(0).(*float64))
+
+			// Bucket counts are cumulative, like in Prometheus.
+			bucketFrame := frameWrappers[1].Frame()
+			require.Equal(t, "le=0.5, service.name=plc", bucketFrame.Fields[0].At(0))
+			require.Equal(t, 24.0, *bucketFrame.Fields[2].At(0).(*float64))
+			require.Equal(t, "le=+Inf, service.name=plc", bucketFrame.Fields[0].At(1))
+			require.Equal(t, 30.0, *bucketFrame.Fields[2].At(1).(*float64))
+		})
+	}
+}
+
+func TestConverter_Convert_Wide(t *testing.T) {
+	body, err := testExportRequest().MarshalProto()
+	require.NoError(t, err)
+
+	frameWrappers, err := NewConverter(telegraf.WithFloat64Numbers(true)).Convert(body)
+	require.NoError(t, err)
+	frame := frameWrappers[0].Frame()
+	require.Len(t, frame.Fields, 2)
+	require.Equal(t, data.Labels{"sensor": "a", "service.name": "plc"}, frame.Fields[1].Labels)
+}
+
+func TestConverter_Convert_Invalid(t *testing.T) {
+	_, err := NewConverter().Convert([]byte(`{"resourceMetrics": 1}`))
+	require.Error(t, err)
+}
@@ -0,0 +1,135 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	influx "github.com/influxdata/line-protocol"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+// Converter converts metrics in Prometheus text exposition format to Grafana frames.
+type Converter struct {
+	metricConverter *telegraf.Converter
+}
+
+// NewConverter creates new Converter from Prometheus text exposition format to
+// Grafana Data Frames. Every sample becomes a metric with a single value field,
+// which is then converted the same way as Telegraf metrics.
+func NewConverter(opts ...telegraf.ConverterOption) *Converter {
+	return &Converter{
+		metricConverter: telegraf.NewConverter(opts...),
+	}
+}
+
+// Convert metrics.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metrics: %w", err)
+	}
+
+	// Samples without a timestamp are the current value of a scrape.
+	now := time.Now()
+	// Maintain a stable order of frames, families are returned as a map.
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var metrics []influx.Metric
+	for _, name := range names {
+		family := families[name]
+		for _, m := range family.GetMetric() {
+			samples, err := familySamples(family, m, now)
+			if err != nil {
+				return nil, err
+			}
+			metrics = append(metrics, samples...)
+		}
+	}
+	return c.metricConverter.ConvertMetrics(metrics)
+}
+
+// familySamples returns the samples of a metric the way they appear in the
+// exposition format, e.g. histograms are split into _bucket, _sum and _count.
+func familySamples(family *dto.MetricFamily, m *dto.Metric, now time.Time) ([]influx.Metric, error) {
+	tm := now
+	if m.TimestampMs != nil {
+		tm = time.UnixMilli(m.GetTimestampMs())
+	}
+	labels := make(map[string]string, len(m.GetLabel()))
+	for _, label := range m.GetLabel() {
+		labels[label.GetName()] = label.GetValue()
+	}
+	name := family.GetName()
+
+	var samples []influx.Metric
+	add := func(name string, extraLabel string, extraValue string, value float64) error {
+		tags := labels
+		if extraLabel != "" {
+			tags = make(map[string]string, len(labels)+1)
+			for k, v := range labels {
+				tags[k] = v
+			}
+			tags[extraLabel] = extraValue
+		}
+		sample, err := influx.New(name, tags, map[string]any{"value": value}, tm)
+		if err != nil {
+			return fmt.Errorf("error creating metric %s: %w", name, err)
+		}
+		samples = append(samples, sample)
+		return nil
+	}
+
+	var err error
+	switch family.GetType() {
+	case dto.MetricType_COUNTER:
+		err = add(name, "", "", m.GetCounter().GetValue())
+	case dto.MetricType_GAUGE:
+		err = add(name, "", "", m.GetGauge().GetValue())
+	case dto.MetricType_SUMMARY:
+		summary := m.GetSummary()
+		for _, q := range summary.GetQuantile() {
+			if err = add(name, "quantile", formatFloat(q.GetQuantile()), q.GetValue()); err != nil {
+				return nil, err
+			}
+		}
+		if err = add(name+"_sum", "", "", summary.GetSampleSum()); err != nil {
+			return nil, err
+		}
+		err = add(name+"_count", "", "", float64(summary.GetSampleCount()))
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		histogram := m.GetHistogram()
+		for _, b := range histogram.GetBucket() {
+			if err = add(name+"_bucket", "le", formatFloat(b.GetUpperBound()), float64(b.GetCumulativeCount())); err != nil {
+				return nil, err
+			}
+		}
+		if err = add(name+"_sum", "", "", histogram.GetSampleSum()); err != nil {
+			return nil, err
+		}
+		err = add(name+"_count", "", "", float64(histogram.GetSampleCount()))
+	default:
+		err = add(name, "", "", m.GetUntyped().GetValue())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,72 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
+)
+
+const exposition = `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027 1395066363000
+http_requests_total{method="post",code="400"} 3 1395066363000
+# TYPE temperature gauge
+temperature{sensor="a"} 21.5 1395066363000
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.5"} 24 1395066363000
+request_duration_seconds_bucket{le="+Inf"} 30 1395066363000
+request_duration_seconds_sum 17.5 1395066363000
+request_duration_seconds_count 30 1395066363000
+`
+
+func TestConverter_Convert_LabelsColumn(t *testing.T) {
+	c := NewConverter(telegraf.WithUseLabelsColumn(true), telegraf.WithFloat64Numbers(true))
+	frameWrappers, err := c.Convert([]byte(exposition))
+	require.NoError(t, err)
+
+	keys := make([]string, 0, len(frameWrappers))
+	for _, fw := range frameWrappers {
+		keys = append(keys, fw.Key())
+	}
+	require.Equal(t, []string{
+		"http_requests_total",
+		"request_duration_seconds_bucket",
+		"request_duration_seconds_sum",
+		"request_duration_seconds_count",
+		"temperature",
+	}, keys)
+
+	frame := frameWrappers[0].Frame()
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, "labels", frame.Fields[0].Name)
+	require.Equal(t, "code=200, method=post", frame.Fields[0].At(0))
+	require.Equal(t, "code=400, method=post", frame.Fields[0].At(1))
+	require.Equal(t, time.UnixMilli(1395066363000), frame.Fields[1].At(0))
+	require.Equal(t, "value", frame.Fields[2].Name)
+	require.Equal(t, 1027.0, *frame.Fields[2].At(0).(*float64))
+
+	bucketFrame := frameWrappers[1].Frame()
+	require.Equal(t, "le=+Inf", bucketFrame.Fields[0].At(1))
+	require.Equal(t, 30.0, *bucketFrame.Fields[2].At(1).(*float64))
+}
+
+func TestConverter_Convert_Wide(t *testing.T) {
+	c := NewConverter(telegraf.WithFloat64Numbers(true))
+	frameWrappers, err := c.Convert([]byte("# TYPE up gauge\nup{job=\"node\"} 1\nup{job=\"grafana\"} 0\n"))
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 1)
+
+	frame := frameWrappers[0].Frame()
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, data.Labels{"job": "node"}, frame.Fields[1].Labels)
+	require.Equal(t, data.Labels{"job": "grafana"}, frame.Fields[2].Labels)
+}
+
+func TestConverter_Convert_Invalid(t *testing.T) {
+	_, err := NewConverter().Convert([]byte("# TYPE up gauge\nup{job=} 1\n"))
+	require.Error(t, err)
+}
@@ -57,12 +57,21 @@ type RulerSrv struct {
 	amConfigStore  AMConfigStore
 	amRefresher    AMRefresher
 	featureManager featuremgmt.FeatureToggles
+	backfiller     RuleBackfiller
+	recordTargets  RecordTargetValidator
 }
 
 var (
 	errProvisionedResource = errors.New("request affects resources created via provisioning API")
 )
 
+// ruleLimits returns the limits of the rules that are created or updated through the API.
+func (srv RulerSrv) ruleLimits() RuleLimits {
+	limits := RuleLimitsFromConfig(srv.cfg, srv.featureManager)
+	limits.RecordTargetValidator = srv.recordTargets
+	return limits
+}
+
 // ignore fields that are not part of the rule definition
 var ignoreFieldsForValidate = [...]string{"RuleGroupIndex"}
 
@@ -338,7 +347,7 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *contextmodel.ReqContext, ruleGro
 		return ErrResp(http.StatusBadRequest, err, "")
 	}
 
-	rules, err := ValidateRuleGroup(&ruleGroupConfig, c.SignedInUser.GetOrgID(), namespace.UID, RuleLimitsFromConfig(srv.cfg, srv.featureManager))
+	rules, err := ValidateRuleGroup(&ruleGroupConfig, c.SignedInUser.GetOrgID(), namespace.UID, srv.ruleLimits())
 	if err != nil {
 		return ErrResp(http.StatusBadRequest, err, "")
 	}
@@ -366,148 +375,176 @@ func (srv RulerSrv) checkGroupLimits(group apimodels.PostableRuleGroupConfig) er
 
 // updateAlertRulesInGroup calculates changes (rules to add,update,delete), verifies that the user is authorized to do the calculated changes and updates database.
 // All operations are performed in a single transaction
-//
-//nolint:gocyclo
 func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals) response.Response {
 	var finalChanges *store.GroupDelta
 	var dbConfig *ngmodels.AlertConfiguration
 	err := srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
-		id, _ := c.SignedInUser.GetInternalID()
-		userNamespace := c.SignedInUser.GetIdentityType()
+		var err error
+		finalChanges, dbConfig, err = srv.applyRuleGroupChanges(tranCtx, c, groupKey, rules)
+		return err
+	})
+	if err != nil {
+		return ruleGroupChangesErrorToResponse(err)
+	}
 
-		logger := srv.log.New("namespace_uid", groupKey.NamespaceUID, "group",
-			groupKey.RuleGroup, "org_id", groupKey.OrgID, "user_id", id, "userNamespace", userNamespace)
-		groupChanges, err := store.CalculateChanges(tranCtx, srv.store, groupKey, rules)
-		if err != nil {
-			return err
-		}
+	srv.refreshAlertmanagerConfig(c, groupKey.OrgID, dbConfig)
 
-		if groupChanges.IsEmpty() {
-			finalChanges = groupChanges
-			logger.Info("No changes detected in the request. Do nothing")
-			return nil
-		}
+	return changesToResponse(finalChanges)
+}
+
+// applyRuleGroupChanges calculates changes (rules to add,update,delete) of the group, verifies that the user is authorized to do them and updates database.
+// It must be called in a transaction. Returns the applied changes and, if notification settings of rules changed, the Alertmanager configuration they were validated against.
+func (srv RulerSrv) applyRuleGroupChanges(tranCtx context.Context, c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals) (*store.GroupDelta, *ngmodels.AlertConfiguration, error) {
+	groupChanges, err := store.CalculateChanges(tranCtx, srv.store, groupKey, rules)
+	if err != nil {
+		return nil, nil, err
+	}
+	return srv.applyRuleGroupDelta(tranCtx, c, groupChanges)
+}
 
-		err = srv.authz.AuthorizeRuleChanges(c.Req.Context(), c.SignedInUser, groupChanges)
+// applyRuleGroupDelta verifies that the user is authorized to do the calculated changes of the group and updates database.
+// It must be called in a transaction.
+//
+//nolint:gocyclo
+func (srv RulerSrv) applyRuleGroupDelta(tranCtx context.Context, c *contextmodel.ReqContext, groupChanges *store.GroupDelta) (*store.GroupDelta, *ngmodels.AlertConfiguration, error) {
+	id, _ := c.SignedInUser.GetInternalID()
+	userNamespace := c.SignedInUser.GetIdentityType()
+
+	groupKey := groupChanges.GroupKey
+	logger := srv.log.New("namespace_uid", groupKey.NamespaceUID, "group",
+		groupKey.RuleGroup, "org_id", groupKey.OrgID, "user_id", id, "userNamespace", userNamespace)
+
+	if groupChanges.IsEmpty() {
+		logger.Info("No changes detected in the request. Do nothing")
+		return groupChanges, nil, nil
+	}
+
+	err = srv.authz.AuthorizeRuleChanges(c.Req.Context(), c.SignedInUser, groupChanges)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateQueries(c.Req.Context(), groupChanges, srv.conditionValidator, c.SignedInUser); err != nil {
+		return nil, nil, err
+	}
+
+	var dbConfig *ngmodels.AlertConfiguration
+	newOrUpdatedNotificationSettings := groupChanges.NewOrUpdatedNotificationSettings()
+	if len(newOrUpdatedNotificationSettings) > 0 {
+		dbConfig, err = srv.amConfigStore.GetLatestAlertmanagerConfiguration(c.Req.Context(), groupChanges.GroupKey.OrgID)
 		if err != nil {
-			return err
+			return nil, nil, fmt.Errorf("failed to get latest configuration: %w", err)
 		}
-
-		if err := validateQueries(c.Req.Context(), groupChanges, srv.conditionValidator, c.SignedInUser); err != nil {
-			return err
+		cfg, err := notifier.Load([]byte(dbConfig.AlertmanagerConfiguration))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse configuration: %w", err)
 		}
-
-		newOrUpdatedNotificationSettings := groupChanges.NewOrUpdatedNotificationSettings()
-		if len(newOrUpdatedNotificationSettings) > 0 {
-			dbConfig, err = srv.amConfigStore.GetLatestAlertmanagerConfiguration(c.Req.Context(), groupChanges.GroupKey.OrgID)
-			if err != nil {
-				return fmt.Errorf("failed to get latest configuration: %w", err)
-			}
-			cfg, err := notifier.Load([]byte(dbConfig.AlertmanagerConfiguration))
-			if err != nil {
-				return fmt.Errorf("failed to parse configuration: %w", err)
-			}
-			validator := notifier.NewNotificationSettingsValidator(&cfg.AlertmanagerConfig)
-			for _, s := range newOrUpdatedNotificationSettings {
-				if err := validator.Validate(s); err != nil {
-					return errors.Join(ngmodels.ErrAlertRuleFailedValidation, err)
-				}
+		validator := notifier.NewNotificationSettingsValidator(&cfg.AlertmanagerConfig)
+		for _, s := range newOrUpdatedNotificationSettings {
+			if err := validator.Validate(s); err != nil {
+				return nil, nil, errors.Join(ngmodels.ErrAlertRuleFailedValidation, err)
 			}
 		}
+	}
 
-		if err := verifyProvisionedRulesNotAffected(c.Req.Context(), srv.provenanceStore, c.SignedInUser.GetOrgID(), groupChanges); err != nil {
-			return err
-		}
-
-		finalChanges = store.UpdateCalculatedRuleFields(groupChanges)
-		logger.Debug("Updating database with the authorized changes", "add", len(finalChanges.New), "update", len(finalChanges.New), "delete", len(finalChanges.Delete))
+	if err := verifyProvisionedRulesNotAffected(c.Req.Context(), srv.provenanceStore, c.SignedInUser.GetOrgID(), groupChanges); err != nil {
+		return nil, nil, err
+	}
 
-		// Delete first as this could prevent future unique constraint violations.
-		if len(finalChanges.Delete) > 0 {
-			UIDs := make([]string, 0, len(finalChanges.Delete))
-			for _, rule := range finalChanges.Delete {
-				UIDs = append(UIDs, rule.UID)
-			}
+	finalChanges := store.UpdateCalculatedRuleFields(groupChanges)
+	updatedBy := ngmodels.NewUserUID(c.SignedInUser)
+	logger.Debug("Updating database with the authorized changes", "add", len(finalChanges.New), "update", len(finalChanges.New), "delete", len(finalChanges.Delete))
 
-			if err = srv.store.DeleteAlertRulesByUID(tranCtx, c.SignedInUser.GetOrgID(), UIDs...); err != nil {
-				return fmt.Errorf("failed to delete rules: %w", err)
-			}
+	// Delete first as this could prevent future unique constraint violations.
+	if len(finalChanges.Delete) > 0 {
+		UIDs := make([]string, 0, len(finalChanges.Delete))
+		for _, rule := range finalChanges.Delete {
+			UIDs = append(UIDs, rule.UID)
 		}
 
-		if len(finalChanges.Update) > 0 {
-			updates := make([]ngmodels.UpdateRule, 0, len(finalChanges.Update))
-			for _, update := range finalChanges.Update {
-				logger.Debug("Updating rule", "rule_uid", update.New.UID, "diff", update.Diff.String())
-				updates = append(updates, ngmodels.UpdateRule{
-					Existing: update.Existing,
-					New:      *update.New,
-				})
-			}
-			err = srv.store.UpdateAlertRules(tranCtx, updates)
-			if err != nil {
-				return fmt.Errorf("failed to update rules: %w", err)
-			}
+		if err = srv.store.DeleteAlertRulesByUID(tranCtx, c.SignedInUser.GetOrgID(), UIDs...); err != nil {
+			return nil, nil, fmt.Errorf("failed to delete rules: %w", err)
 		}
+	}
 
-		if len(finalChanges.New) > 0 {
-			inserts := make([]ngmodels.AlertRule, 0, len(finalChanges.New))
-			for _, rule := range finalChanges.New {
-				inserts = append(inserts, *rule)
-			}
-			added, err := srv.store.InsertAlertRules(tranCtx, inserts)
-			if err != nil {
-				return fmt.Errorf("failed to add rules: %w", err)
-			}
-			if len(added) != len(finalChanges.New) {
-				logger.Error("Cannot match inserted rules with final changes", "insertedCount", len(added), "changes", len(finalChanges.New))
-			} else {
-				for i, newRule := range finalChanges.New {
-					newRule.ID = added[i].ID
-					newRule.UID = added[i].UID
-				}
+	if len(finalChanges.Update) > 0 {
+		updates := make([]ngmodels.UpdateRule, 0, len(finalChanges.Update))
+		for _, update := range finalChanges.Update {
+			logger.Debug("Updating rule", "rule_uid", update.New.UID, "diff", update.Diff.String())
+			if len(update.Diff) > 0 {
+				update.New.UpdatedBy = updatedBy
 			}
+			updates = append(updates, ngmodels.UpdateRule{
+				Existing: update.Existing,
+				New:      *update.New,
+			})
+		}
+		err = srv.store.UpdateAlertRules(tranCtx, updates)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to update rules: %w", err)
 		}
+	}
 
-		if len(finalChanges.New) > 0 {
-			userID, _ := identity.UserIdentifier(c.SignedInUser.GetID())
-			limitReached, err := srv.QuotaService.CheckQuotaReached(tranCtx, ngmodels.QuotaTargetSrv, &quota.ScopeParameters{
-				OrgID:  c.SignedInUser.GetOrgID(),
-				UserID: userID,
-			}) // alert rule is table name
-			if err != nil {
-				return fmt.Errorf("failed to get alert rules quota: %w", err)
-			}
-			if limitReached {
-				return ngmodels.ErrQuotaReached
+	if len(finalChanges.New) > 0 {
+		inserts := make([]ngmodels.AlertRule, 0, len(finalChanges.New))
+		for _, rule := range finalChanges.New {
+			rule.UpdatedBy = updatedBy
+			inserts = append(inserts, *rule)
+		}
+		added, err := srv.store.InsertAlertRules(tranCtx, inserts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to add rules: %w", err)
+		}
+		if len(added) != len(finalChanges.New) {
+			logger.Error("Cannot match inserted rules with final changes", "insertedCount", len(added), "changes", len(finalChanges.New))
+		} else {
+			for i, newRule := range finalChanges.New {
+				newRule.ID = added[i].ID
+				newRule.UID = added[i].UID
 			}
 		}
-		return nil
-	})
+	}
 
-	if err != nil {
-		if errors.As(err, &errutil.Error{}) {
-			return response.Err(err)
-		} else if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-			return ErrResp(http.StatusNotFound, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, errProvisionedResource) {
-			return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrQuotaReached) {
-			return ErrResp(http.StatusForbidden, err, "")
-		} else if errors.Is(err, store.ErrOptimisticLock) {
-			return ErrResp(http.StatusConflict, err, "")
+	if len(finalChanges.New) > 0 {
+		userID, _ := identity.UserIdentifier(c.SignedInUser.GetID())
+		limitReached, err := srv.QuotaService.CheckQuotaReached(tranCtx, ngmodels.QuotaTargetSrv, &quota.ScopeParameters{
+			OrgID:  c.SignedInUser.GetOrgID(),
+			UserID: userID,
+		}) // alert rule is table name
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get alert rules quota: %w", err)
+		}
+		if limitReached {
+			return nil, nil, ngmodels.ErrQuotaReached
 		}
-		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 	}
+	return finalChanges, dbConfig, nil
+}
 
+func ruleGroupChangesErrorToResponse(err error) response.Response {
+	if errors.As(err, &errutil.Error{}) {
+		return response.Err(err)
+	} else if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		return ErrResp(http.StatusNotFound, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, errProvisionedResource) {
+		return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrQuotaReached) {
+		return ErrResp(http.StatusForbidden, err, "")
+	} else if errors.Is(err, store.ErrOptimisticLock) {
+		return ErrResp(http.StatusConflict, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
+}
+
+// refreshAlertmanagerConfig applies the configuration to the Alertmanager of the organization if notification settings of rules changed.
+func (srv RulerSrv) refreshAlertmanagerConfig(c *contextmodel.ReqContext, orgID int64, dbConfig *ngmodels.AlertConfiguration) {
 	if srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingSimplifiedRouting) && dbConfig != nil {
 		// This isn't strictly necessary since the alertmanager config is periodically synced.
-		err := srv.amRefresher.ApplyConfig(c.Req.Context(), groupKey.OrgID, dbConfig)
+		err := srv.amRefresher.ApplyConfig(c.Req.Context(), orgID, dbConfig)
 		if err != nil {
 			srv.log.Warn("Failed to refresh Alertmanager config for org after change in notification settings", "org", c.SignedInUser.GetOrgID(), "error", err)
 		}
 	}
-
-	return changesToResponse(finalChanges)
 }
 
 func changesToResponse(finalChanges *store.GroupDelta) response.Response {
@@ -578,6 +615,9 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, provenanceRecords map[stri
 			Metadata:             AlertRuleMetadataFromModelMetadata(r.Metadata),
 		},
 	}
+	if r.UpdatedBy != nil {
+		gettableExtendedRuleNode.GrafanaManagedAlert.UpdatedBy = string(*r.UpdatedBy)
+	}
 	forDuration := model.Duration(r.For)
 	gettableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
 		For:         &forDuration,
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type RuleBackfiller interface {
+	Start(user identity.Requester, rule *ngmodels.AlertRule, from, to time.Time) (backtesting.BackfillJob, error)
+	Get(key ngmodels.AlertRuleKey) (backtesting.BackfillJob, error)
+	Cancel(key ngmodels.AlertRuleKey) (backtesting.BackfillJob, error)
+}
+
+var errRecordingRulesNotEnabled = errors.New("writing of recording rules is not enabled")
+
+// RouteStartRuleBackfill starts the backfill of the recording rule with the given UID in the background.
+// The queries of the rule are executed on behalf of the user, who therefore must have access to the data sources of the rule.
+func (srv RulerSrv) RouteStartRuleBackfill(c *contextmodel.ReqContext, body apimodels.PostableRuleBackfill, ruleUID string) response.Response {
+	if !srv.cfg.RecordingRules.Enabled {
+		return ErrResp(http.StatusBadRequest, errRecordingRulesNotEnabled, "")
+	}
+	ctx := c.Req.Context()
+	rule, err := srv.getAuthorizedRuleByUid(ctx, c, ruleUID)
+	if err != nil {
+		return backfillErrorToResponse(err)
+	}
+	if err := srv.authz.AuthorizeDatasourceAccessForRule(ctx, c.SignedInUser, &rule); err != nil {
+		return backfillErrorToResponse(err)
+	}
+
+	job, err := srv.backfiller.Start(c.SignedInUser, &rule, body.From, body.To)
+	if err != nil {
+		return backfillErrorToResponse(err)
+	}
+	return response.JSON(http.StatusAccepted, toRuleBackfillStatus(job))
+}
+
+// RouteGetRuleBackfill returns the status of the running or recently finished backfill of the rule with the given UID.
+func (srv RulerSrv) RouteGetRuleBackfill(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	rule, err := srv.getAuthorizedRuleByUid(c.Req.Context(), c, ruleUID)
+	if err != nil {
+		return backfillErrorToResponse(err)
+	}
+	job, err := srv.backfiller.Get(rule.GetKey())
+	if err != nil {
+		return backfillErrorToResponse(err)
+	}
+	return response.JSON(http.StatusOK, toRuleBackfillStatus(job))
+}
+
+// RouteCancelRuleBackfill cancels the running backfill of the rule with the given UID.
+func (srv RulerSrv) RouteCancelRuleBackfill(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	rule, err := srv.getAuthorizedRuleByUid(c.Req.Context(), c, ruleUID)
+	if err != nil {
+		return backfillErrorToResponse(err)
+	}
+	job, err := srv.backfiller.Cancel(rule.GetKey())
+	if err != nil {
+		return backfillErrorToResponse(err)
+	}
+	return response.JSON(http.StatusOK, toRuleBackfillStatus(job))
+}
+
+func backfillErrorToResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, ngmodels.ErrAlertRuleNotFound), errors.Is(err, backtesting.ErrBackfillNotFound):
+		return ErrResp(http.StatusNotFound, err, "")
+	case errors.Is(err, backtesting.ErrInvalidInputData):
+		return ErrResp(http.StatusBadRequest, err, "")
+	case errors.Is(err, backtesting.ErrBackfillInProgress):
+		return ErrResp(http.StatusConflict, err, "")
+	case errors.Is(err, backtesting.ErrBackfillLimit):
+		return ErrResp(http.StatusTooManyRequests, err, "")
+	case errors.Is(err, backtesting.ErrBackfillerStopped):
+		return ErrResp(http.StatusServiceUnavailable, err, "")
+	}
+	return response.ErrOrFallback(http.StatusInternalServerError, "failed to backfill rule", err)
+}
+
+func toRuleBackfillStatus(job backtesting.BackfillJob) apimodels.RuleBackfillStatus {
+	result := apimodels.RuleBackfillStatus{
+		RuleUID: job.RuleKey.UID,
+		From:    job.From,
+		To:      job.To,
+		State:   string(job.State),
+		Progress: apimodels.RuleBackfillProgress{
+			Evaluations: job.Progress.Evaluations,
+			Total:       job.Progress.Total,
+			Written:     job.Progress.Written,
+			NoData:      job.Progress.NoData,
+			Failed:      job.Progress.Failed,
+		},
+		Error:   job.Error,
+		Started: job.Started,
+	}
+	if !job.Progress.LastEvaluation.IsZero() {
+		result.Progress.LastEvaluation = &job.Progress.LastEvaluation
+	}
+	if !job.Finished.IsZero() {
+		result.Finished = &job.Finished
+	}
+	return result
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+func TestRouteRuleBackfill(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	type fixture struct {
+		orgID      int64
+		rule       *models.AlertRule
+		backfiller *fakeRuleBackfiller
+		svc        *RulerSrv
+	}
+	setup := func(t *testing.T) fixture {
+		orgID := rand.Int63()
+		folder := randFolder()
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		gen := models.RuleGen
+		rule := gen.With(
+			gen.WithOrgID(orgID),
+			gen.WithNamespace(folder),
+			gen.WithAllRecordingRules(),
+		).GenerateRef()
+		ruleStore.PutRule(context.Background(), rule)
+
+		backfiller := &fakeRuleBackfiller{jobs: map[models.AlertRuleKey]backtesting.BackfillJob{}}
+		svc := createService(ruleStore)
+		svc.cfg.RecordingRules.Enabled = true
+		svc.backfiller = backfiller
+		return fixture{orgID: orgID, rule: rule, backfiller: backfiller, svc: svc}
+	}
+	parseStatus := func(t *testing.T, body []byte) apimodels.RuleBackfillStatus {
+		t.Helper()
+		var result apimodels.RuleBackfillStatus
+		require.NoError(t, json.Unmarshal(body, &result))
+		return result
+	}
+
+	t.Run("should start backfill of the rule", func(t *testing.T) {
+		f := setup(t)
+		req := createRequestContextWithPerms(f.orgID, createPermissionsForRules([]*models.AlertRule{f.rule}, f.orgID), nil)
+
+		response := f.svc.RouteStartRuleBackfill(req, apimodels.PostableRuleBackfill{From: from, To: to}, f.rule.UID)
+
+		require.Equalf(t, http.StatusAccepted, response.Status(), string(response.Body()))
+		result := parseStatus(t, response.Body())
+		require.Equal(t, f.rule.UID, result.RuleUID)
+		require.Equal(t, string(backtesting.BackfillRunning), result.State)
+		require.Equal(t, from, result.From)
+		require.Equal(t, to, result.To)
+		require.Nil(t, result.Finished)
+		require.Equal(t, f.orgID, f.backfiller.startedBy.GetOrgID())
+	})
+
+	t.Run("should return 400 if recording rules are not enabled", func(t *testing.T) {
+		f := setup(t)
+		f.svc.cfg.RecordingRules.Enabled = false
+		req := createRequestContextWithPerms(f.orgID, createPermissionsForRules([]*models.AlertRule{f.rule}, f.orgID), nil)
+
+		response := f.svc.RouteStartRuleBackfill(req, apimodels.PostableRuleBackfill{From: from, To: to}, f.rule.UID)
+
+		require.Equalf(t, http.StatusBadRequest, response.Status(), string(response.Body()))
+		require.Empty(t, f.backfiller.jobs)
+	})
+
+	t.Run("should return 400 if request is invalid", func(t *testing.T) {
+		f := setup(t)
+		req := createRequestContextWithPerms(f.orgID, createPermissionsForRules([]*models.AlertRule{f.rule}, f.orgID), nil)
+
+		response := f.svc.RouteStartRuleBackfill(req, apimodels.PostableRuleBackfill{From: to, To: from}, f.rule.UID)
+
+		require.Equalf(t, http.StatusBadRequest, response.Status(), string(response.Body()))
+	})
+
+	t.Run("should return 409 if backfill is in progress", func(t *testing.T) {
+		f := setup(t)
+		f.backfiller.jobs[f.rule.GetKey()] = backtesting.BackfillJob{RuleKey: f.rule.GetKey(), State: backtesting.BackfillRunning}
+		req := createRequestContextWithPerms(f.orgID, createPermissionsForRules([]*models.AlertRule{f.rule}, f.orgID), nil)
+
+		response := f.svc.RouteStartRuleBackfill(req, apimodels.PostableRuleBackfill{From: from, To: to}, f.rule.UID)
+
+		require.Equalf(t, http.StatusConflict, response.Status(), string(response.Body()))
+	})
+
+	t.Run("should return 429 if too many backfills are running", func(t *testing.T) {
+		f := setup(t)
+		f.backfiller.startErr = backtesting.ErrBackfillLimit
+		req := createRequestContextWithPerms(f.orgID, createPermissionsForRules([]*models.AlertRule{f.rule}, f.orgID), nil)
+
+		response := f.svc.RouteStartRuleBackfill(req, apimodels.PostableRuleBackfill{From: from, To: to}, f.rule.UID)
+
+		require.Equalf(t, http.StatusTooManyRequests, response.Status(), string(response.Body()))
+	})
+
+	t.Run("should return 403 if user cannot query data sources of the rule", func(t *testing.T) {
+		f := setup(t)
+		perms := createPermissionsForRules([]*models.AlertRule{f.rule}, f.orgID)
+		delete(perms[f.orgID], datasources.ActionQuery)
+		req := createRequestContextWithPerms(f.orgID, perms, nil)
+
+		response := f.svc.RouteStartRuleBackfill(req, apimodels.PostableRuleBackfill{From: from, To: to}, f.rule.UID)
+
+		require.Equalf(t, http.StatusForbidden, response.Status(), string(response.Body()))
+		require.Empty(t, f.backfiller.jobs)
+	})
+
+	t.Run("should return status of the backfill", func(t *testing.T) {
+		f := setup(t)
+		finished := to.Add(time.Minute)
+		f.backfiller.jobs[f.rule.GetKey()] = backtesting.BackfillJob{
+			RuleKey:  f.rule.GetKey(),
+			From:     from,
+			To:       to,
+			State:    backtesting.BackfillCompleted,
+			Progress: backtesting.BackfillProgress{Evaluations: 10, Total: 10, Written: 8, NoData: 2, LastEvaluation: to.Add(-time.Minute)},
+			Started:  to,
+			Finished: finished,
+		}
+		req := createRequestContextWithPerms(f.orgID, createPermissionsForRules([]*models.AlertRule{f.rule}, f.orgID), nil)
+
+		response := f.svc.RouteGetRuleBackfill(req, f.rule.UID)
+
+		require.Equalf(t, http.StatusOK, response.Status(), string(response.Body()))
+		result := parseStatus(t, response.Body())
+		require.Equal(t, string(backtesting.BackfillCompleted), result.State)
+		require.Equal(t, apimodels.RuleBackfillProgress{Evaluations: 10, Total: 10, Written: 8, NoData: 2, LastEvaluation: result.Progress.LastEvaluation}, result.Progress)
+		require.Equal(t, to.Add(-time.Minute), *result.Progress.LastEvaluation)
+		require.Equal(t, finished, *result.Finished)
+	})
+
+	t.Run("should return 404 if there is no backfill", func(t *testing.T) {
+		f := setup(t)
+		req := createRequestContextWithPerms(f.orgID, createPermissionsForRules([]*models.AlertRule{f.rule}, f.orgID), nil)
+
+		require.Equal(t, http.StatusNotFound, f.svc.RouteGetRuleBackfill(req, f.rule.UID).Status())
+		require.Equal(t, http.StatusNotFound, f.svc.RouteCancelRuleBackfill(req, f.rule.UID).Status())
+	})
+
+	t.Run("should return 404 if rule does not exist", func(t *testing.T) {
+		f := setup(t)
+		req := createRequestContextWithPerms(f.orgID, createPermissionsForRules([]*models.AlertRule{f.rule}, f.orgID), nil)
+
+		require.Equal(t, http.StatusNotFound, f.svc.RouteGetRuleBackfill(req, "unknown").Status())
+	})
+
+	t.Run("should cancel backfill", func(t *testing.T) {
+		f := setup(t)
+		f.backfiller.jobs[f.rule.GetKey()] = backtesting.BackfillJob{RuleKey: f.rule.GetKey(), State: backtesting.BackfillRunning}
+		req := createRequestContextWithPerms(f.orgID, createPermissionsForRules([]*models.AlertRule{f.rule}, f.orgID), nil)
+
+		response := f.svc.RouteCancelRuleBackfill(req, f.rule.UID)
+
+		require.Equalf(t, http.StatusOK, response.Status(), string(response.Body()))
+		require.Equal(t, []models.AlertRuleKey{f.rule.GetKey()}, f.backfiller.cancelled)
+	})
+}
+
+type fakeRuleBackfiller struct {
+	jobs      map[models.AlertRuleKey]backtesting.BackfillJob
+	startedBy identity.Requester
+	cancelled []models.AlertRuleKey
+	startErr  error
+}
+
+func (f *fakeRuleBackfiller) Start(user identity.Requester, rule *models.AlertRule, from, to time.Time) (backtesting.BackfillJob, error) {
+	if f.startErr != nil {
+		return backtesting.BackfillJob{}, f.startErr
+	}
+	if !from.Before(to) {
+		return backtesting.BackfillJob{}, backtesting.ErrInvalidInputData
+	}
+	if job, ok := f.jobs[rule.GetKey()]; ok && job.State == backtesting.BackfillRunning {
+		return backtesting.BackfillJob{}, backtesting.ErrBackfillInProgress
+	}
+	job := backtesting.BackfillJob{RuleKey: rule.GetKey(), From: from, To: to, State: backtesting.BackfillRunning, Started: time.Now()}
+	f.jobs[rule.GetKey()] = job
+	f.startedBy = user
+	return job, nil
+}
+
+func (f *fakeRuleBackfiller) Get(key models.AlertRuleKey) (backtesting.BackfillJob, error) {
+	job, ok := f.jobs[key]
+	if !ok {
+		return backtesting.BackfillJob{}, backtesting.ErrBackfillNotFound
+	}
+	return job, nil
+}
+
+func (f *fakeRuleBackfiller) Cancel(key models.AlertRuleKey) (backtesting.BackfillJob, error) {
+	job, ok := f.jobs[key]
+	if !ok {
+		return backtesting.BackfillJob{}, backtesting.ErrBackfillNotFound
+	}
+	f.cancelled = append(f.cancelled, key)
+	return job, nil
+}
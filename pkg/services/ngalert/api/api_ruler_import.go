@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// RouteImportPrometheusRules converts the Prometheus rule file in the request body to Grafana-managed rules that query the data source
+// and saves them to the folder. Every rule group of the file replaces the rule group with the same name in the folder. The imported rules get
+// stable UIDs derived from the folder and their titles, so the rules that were imported before are updated in place and the same file can be
+// imported again. Importing a rule whose title is taken by a rule that was not imported is a conflict.
+// All groups are imported in a single transaction.
+func (srv RulerSrv) RouteImportPrometheusRules(c *contextmodel.ReqContext, ds *datasources.DataSource, namespaceUID string) response.Response {
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+	namespace, err := srv.store.GetNamespaceByUID(ctx, namespaceUID, orgID, c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	body, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read the request body")
+	}
+	file, err := prom.ParseRulesFile(body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	isPaused := c.QueryBool("paused")
+	converter, err := prom.NewConverter(prom.Config{
+		DatasourceUID:   ds.UID,
+		DatasourceType:  ds.Type,
+		DefaultInterval: srv.cfg.DefaultRuleEvaluationInterval,
+		IsPaused:        isPaused,
+	})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to create the rule converter")
+	}
+	groups, warnings, err := converter.PrometheusRulesFileToGrafana(orgID, namespace.UID, file)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	limits := srv.ruleLimits()
+	rulesByGroup := make([][]*ngmodels.AlertRuleWithOptionals, 0, len(groups))
+	for _, group := range groups {
+		ruleGroupConfig := toPostableRuleGroupConfig(group, isPaused)
+		if err := srv.checkGroupLimits(ruleGroupConfig); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		rules, err := ValidateRuleGroup(&ruleGroupConfig, orgID, namespace.UID, limits)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("rule group %q: %w", group.Title, err), "")
+		}
+		rulesByGroup = append(rulesByGroup, rules)
+	}
+
+	result := apimodels.ImportPrometheusRulesResponse{
+		Message:  "rules imported successfully",
+		Groups:   make([]apimodels.ImportedRuleGroup, 0, len(groups)),
+		Warnings: make([]string, 0, len(warnings)),
+	}
+	for _, w := range warnings {
+		result.Warnings = append(result.Warnings, w.String())
+	}
+
+	var dbConfig *ngmodels.AlertConfiguration
+	err = srv.xactManager.InTransaction(ctx, func(tranCtx context.Context) error {
+		existing, err := srv.store.ListAlertRules(tranCtx, &ngmodels.ListAlertRulesQuery{
+			OrgID:         orgID,
+			NamespaceUIDs: []string{namespace.UID},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get rules in the folder: %w", err)
+		}
+		existingByTitle := make(map[string]*ngmodels.AlertRule, len(existing))
+		for _, r := range existing {
+			existingByTitle[r.Title] = r
+		}
+
+		for i, group := range groups {
+			rules := rulesByGroup[i]
+			for _, r := range rules {
+				uid := prom.RuleUID(orgID, namespace.UID, r.Title)
+				if e, ok := existingByTitle[r.Title]; ok {
+					if e.UID != uid {
+						return fmt.Errorf("rule group %q: %w", group.Title, ngmodels.ErrAlertRuleConflict(*e, errors.New("a rule with the same title exists in the folder and it was not imported")))
+					}
+					r.UID = uid
+				}
+			}
+			groupKey := ngmodels.AlertRuleGroupKey{
+				OrgID:        orgID,
+				NamespaceUID: namespace.UID,
+				RuleGroup:    group.Title,
+			}
+			delta, err := store.CalculateChanges(tranCtx, srv.store, groupKey, rules)
+			if err != nil {
+				return fmt.Errorf("rule group %q: %w", group.Title, err)
+			}
+			// new rules are created with the UID so that they are matched when the file is imported again
+			for _, r := range delta.New {
+				r.UID = prom.RuleUID(orgID, namespace.UID, r.Title)
+			}
+			changes, cfg, err := srv.applyRuleGroupDelta(tranCtx, c, delta)
+			if err != nil {
+				return fmt.Errorf("rule group %q: %w", group.Title, err)
+			}
+			if cfg != nil {
+				dbConfig = cfg
+			}
+			result.Groups = append(result.Groups, importedRuleGroupFromChanges(group.Title, changes))
+		}
+		return nil
+	})
+	if err != nil {
+		return ruleGroupChangesErrorToResponse(err)
+	}
+
+	srv.refreshAlertmanagerConfig(c, orgID, dbConfig)
+
+	return response.JSON(http.StatusAccepted, result)
+}
+
+// toPostableRuleGroupConfig converts the rule group to the API model, so that imported rules are validated the same way as rules
+// that are created via the API.
+func toPostableRuleGroupConfig(group *ngmodels.AlertRuleGroup, isPaused bool) apimodels.PostableRuleGroupConfig {
+	result := apimodels.PostableRuleGroupConfig{
+		Name:     group.Title,
+		Interval: model.Duration(time.Duration(group.Interval) * time.Second),
+		Rules:    make([]apimodels.PostableExtendedRuleNode, 0, len(group.Rules)),
+	}
+	for _, r := range group.Rules {
+		node := apimodels.PostableExtendedRuleNode{
+			ApiRuleNode: &apimodels.ApiRuleNode{
+				Labels:      r.Labels,
+				Annotations: r.Annotations,
+			},
+			GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+				Title:        r.Title,
+				Condition:    r.Condition,
+				Data:         ApiAlertQueriesFromAlertQueries(r.Data),
+				NoDataState:  apimodels.NoDataState(r.NoDataState),
+				ExecErrState: apimodels.ExecutionErrorState(r.ExecErrState),
+				Record:       ApiRecordFromModelRecord(r.Record),
+			},
+		}
+		if r.Record == nil {
+			forDuration := model.Duration(r.For)
+			node.ApiRuleNode.For = &forDuration
+		}
+		// the pause state of rules that were imported before is kept unless the rules are explicitly imported paused
+		if isPaused {
+			node.GrafanaManagedAlert.IsPaused = &isPaused
+		}
+		result.Rules = append(result.Rules, node)
+	}
+	return result
+}
+
+func importedRuleGroupFromChanges(name string, changes *store.GroupDelta) apimodels.ImportedRuleGroup {
+	result := apimodels.ImportedRuleGroup{Name: name}
+	for _, r := range changes.New {
+		result.Created = append(result.Created, r.UID)
+	}
+	for _, r := range changes.Update {
+		result.Updated = append(result.Updated, r.Existing.UID)
+	}
+	for _, r := range changes.Delete {
+		result.Deleted = append(result.Deleted, r.UID)
+	}
+	return result
+}
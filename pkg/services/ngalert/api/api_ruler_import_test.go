@@ -0,0 +1,215 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+)
+
+const importTestRulesFile = `
+groups:
+  - name: imported
+    interval: 1m
+    rules:
+      - alert: InstanceDown
+        expr: up == 0
+        for: 5m
+        keep_firing_for: 1m
+        labels:
+          severity: critical
+        annotations:
+          summary: Instance {{ $labels.instance }} is down
+      - record: job:up:sum
+        expr: sum by (job) (up)
+`
+
+func TestRouteImportPrometheusRules(t *testing.T) {
+	ds := &datasources.DataSource{UID: "prom-uid", Type: datasources.DS_PROMETHEUS}
+
+	type fixture struct {
+		orgID    int64
+		store    *fakes.RuleStore
+		existing *models.AlertRule
+		svc      *RulerSrv
+	}
+	setup := func(t *testing.T) fixture {
+		orgID := rand.Int63()
+		folder := randFolder()
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		gen := models.RuleGen
+		existing := gen.With(
+			gen.WithOrgID(orgID),
+			gen.WithNamespace(folder),
+			gen.WithGroupName("old"),
+			gen.WithTitle("InstanceDown"),
+			gen.WithNoNotificationSettings(),
+			gen.WithIsPaused(false),
+		).GenerateRef()
+		// the rule was imported before
+		existing.UID = prom.RuleUID(orgID, existing.NamespaceUID, existing.Title)
+		ruleStore.PutRule(context.Background(), existing)
+
+		svc := createService(ruleStore)
+		svc.cfg.DefaultRuleEvaluationInterval = time.Minute
+		svc.conditionValidator = &recordingConditionValidator{}
+		svc.QuotaService = quotatest.New(false, nil)
+		return fixture{orgID: orgID, store: ruleStore, existing: existing, svc: svc}
+	}
+	permissionsToImport := func(f fixture) map[int64]map[string][]string {
+		perms := createPermissionsForRules([]*models.AlertRule{f.existing}, f.orgID)
+		scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(f.existing.NamespaceUID)
+		perms[f.orgID][ac.ActionAlertingRuleCreate] = []string{scope}
+		perms[f.orgID][ac.ActionAlertingRuleUpdate] = []string{scope}
+		perms[f.orgID][ac.ActionAlertingRuleDelete] = []string{scope}
+		perms[f.orgID][datasources.ActionQuery] = append(perms[f.orgID][datasources.ActionQuery], datasources.ScopeProvider.GetResourceScopeUID(ds.UID))
+		return perms
+	}
+	newRequest := func(orgID int64, perms map[int64]map[string][]string, body string, query map[string]string) *contextmodel.ReqContext {
+		req := createRequestContextWithPerms(orgID, perms, nil)
+		req.Req.Method = http.MethodPost
+		req.Req.Body = io.NopCloser(strings.NewReader(body))
+		for k, v := range query {
+			req.Req.Form.Set(k, v)
+		}
+		return req
+	}
+	recordedInserts := func(store *fakes.RuleStore) []models.AlertRule {
+		var result []models.AlertRule
+		for _, cmd := range store.GetRecordedCommands(func(cmd any) (any, bool) {
+			c, ok := cmd.([]models.AlertRule)
+			return c, ok
+		}) {
+			result = append(result, cmd.([]models.AlertRule)...)
+		}
+		return result
+	}
+
+	t.Run("should create the rules and update the rules that were imported before", func(t *testing.T) {
+		f := setup(t)
+		req := newRequest(f.orgID, permissionsToImport(f), importTestRulesFile, nil)
+
+		response := f.svc.RouteImportPrometheusRules(req, ds, f.existing.NamespaceUID)
+
+		require.Equalf(t, http.StatusAccepted, response.Status(), string(response.Body()))
+		var result apimodels.ImportPrometheusRulesResponse
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result.Groups, 1)
+		require.Equal(t, "imported", result.Groups[0].Name)
+		require.Equal(t, []string{f.existing.UID}, result.Groups[0].Updated)
+		require.Len(t, result.Groups[0].Created, 1)
+		require.Equal(t, []string{`rule group "imported", rule "InstanceDown": 'keep_firing_for' is not supported and is ignored`}, result.Warnings)
+
+		inserts := recordedInserts(f.store)
+		require.Len(t, inserts, 1)
+		recording := inserts[0]
+		require.Equal(t, "job:up:sum", recording.Title)
+		require.Equal(t, prom.RuleUID(f.orgID, f.existing.NamespaceUID, "job:up:sum"), recording.UID)
+		require.Equal(t, []string{recording.UID}, result.Groups[0].Created)
+		require.Equal(t, f.existing.NamespaceUID, recording.NamespaceUID)
+		require.Equal(t, "imported", recording.RuleGroup)
+		require.EqualValues(t, 60, recording.IntervalSeconds)
+		require.Equal(t, &models.Record{Metric: "job:up:sum", From: "query"}, recording.Record)
+		require.Equal(t, ds.UID, recording.Data[0].DatasourceUID)
+
+		updates := f.store.GetRecordedCommands(func(cmd any) (any, bool) {
+			c, ok := cmd.([]models.UpdateRule)
+			return c, ok
+		})
+		require.Len(t, updates, 1)
+		alert := updates[0].([]models.UpdateRule)[0].New
+		require.Equal(t, f.existing.UID, alert.UID)
+		require.Equal(t, "imported", alert.RuleGroup)
+		require.Equal(t, 5*time.Minute, alert.For)
+		require.Equal(t, map[string]string{"severity": "critical"}, alert.Labels)
+		require.Equal(t, "threshold", alert.Condition)
+		require.Len(t, alert.Data, 3)
+		require.False(t, alert.IsPaused)
+	})
+
+	t.Run("should return 409 if a rule with the same title was not imported", func(t *testing.T) {
+		f := setup(t)
+		gen := models.RuleGen
+		other := gen.With(
+			gen.WithOrgID(f.orgID),
+			gen.WithNamespaceUID(f.existing.NamespaceUID),
+			gen.WithGroupName("manual"),
+			gen.WithTitle("job:up:sum"),
+		).GenerateRef()
+		f.store.PutRule(context.Background(), other)
+		req := newRequest(f.orgID, permissionsToImport(f), importTestRulesFile, nil)
+
+		response := f.svc.RouteImportPrometheusRules(req, ds, f.existing.NamespaceUID)
+
+		require.Equalf(t, http.StatusConflict, response.Status(), string(response.Body()))
+		require.Contains(t, string(response.Body()), other.UID)
+		require.Empty(t, recordedInserts(f.store))
+	})
+
+	t.Run("should create rules paused if requested", func(t *testing.T) {
+		f := setup(t)
+		req := newRequest(f.orgID, permissionsToImport(f), importTestRulesFile, map[string]string{"paused": "true"})
+
+		response := f.svc.RouteImportPrometheusRules(req, ds, f.existing.NamespaceUID)
+
+		require.Equalf(t, http.StatusAccepted, response.Status(), string(response.Body()))
+		inserts := recordedInserts(f.store)
+		require.Len(t, inserts, 1)
+		require.True(t, inserts[0].IsPaused)
+	})
+
+	t.Run("should return 400 if the file is invalid", func(t *testing.T) {
+		f := setup(t)
+		req := newRequest(f.orgID, permissionsToImport(f), "groups:\n  - name: a\n    rules:\n      - alert: A", nil)
+
+		response := f.svc.RouteImportPrometheusRules(req, ds, f.existing.NamespaceUID)
+
+		require.Equalf(t, http.StatusBadRequest, response.Status(), string(response.Body()))
+		require.Empty(t, recordedInserts(f.store))
+	})
+
+	t.Run("should return 400 if the expression is not valid PromQL", func(t *testing.T) {
+		f := setup(t)
+		req := newRequest(f.orgID, permissionsToImport(f), "groups:\n  - name: a\n    rules:\n      - alert: A\n        expr: sum(", nil)
+
+		response := f.svc.RouteImportPrometheusRules(req, ds, f.existing.NamespaceUID)
+
+		require.Equalf(t, http.StatusBadRequest, response.Status(), string(response.Body()))
+	})
+
+	t.Run("should return 400 if the interval is not a multiple of the base interval", func(t *testing.T) {
+		f := setup(t)
+		req := newRequest(f.orgID, permissionsToImport(f), "groups:\n  - name: a\n    interval: 15s\n    rules:\n      - alert: A\n        expr: up", nil)
+
+		response := f.svc.RouteImportPrometheusRules(req, ds, f.existing.NamespaceUID)
+
+		require.Equalf(t, http.StatusBadRequest, response.Status(), string(response.Body()))
+	})
+
+	t.Run("should return 403 if user is not authorized to create rules", func(t *testing.T) {
+		f := setup(t)
+		req := newRequest(f.orgID, createPermissionsForRules([]*models.AlertRule{f.existing}, f.orgID), importTestRulesFile, nil)
+
+		response := f.svc.RouteImportPrometheusRules(req, ds, f.existing.NamespaceUID)
+
+		require.Equalf(t, http.StatusForbidden, response.Status(), string(response.Body()))
+		require.Empty(t, recordedInserts(f.store))
+	})
+}
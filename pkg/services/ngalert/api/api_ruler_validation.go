@@ -22,6 +22,13 @@ type RuleLimits struct {
 	BaseInterval time.Duration
 	// Whether recording rules are allowed.
 	RecordingRulesAllowed bool
+	// Validates the targets of recording rules. If nil, the targets are not validated.
+	RecordTargetValidator RecordTargetValidator
+}
+
+// RecordTargetValidator validates the targets of recording rules against the configured writers.
+type RecordTargetValidator interface {
+	ValidateTarget(target string) error
 }
 
 func RuleLimitsFromConfig(cfg *setting.UnifiedAlertingSettings, toggles featuremgmt.FeatureToggles) RuleLimits {
@@ -177,6 +184,11 @@ func validateRecordingRuleFields(in *apimodels.PostableExtendedRuleNode, newRule
 	if !prommodels.IsValidMetricName(metricName) {
 		return ngmodels.AlertRule{}, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, "metric name for recording rule must be a valid Prometheus metric name")
 	}
+	if limits.RecordTargetValidator != nil {
+		if err := limits.RecordTargetValidator.ValidateTarget(in.GrafanaManagedAlert.Record.Target); err != nil {
+			return ngmodels.AlertRule{}, err
+		}
+	}
 	newRule.Record = ModelRecordFromApiRecord(in.GrafanaManagedAlert.Record)
 
 	newRule.NoDataState = ""
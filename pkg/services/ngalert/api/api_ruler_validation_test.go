@@ -17,6 +17,7 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -53,6 +54,20 @@ func allowRecording(lim RuleLimits) *RuleLimits {
 	return &lim
 }
 
+// allowRecordingTo allows recording rules that write to one of the targets.
+func allowRecordingTo(t *testing.T, lim RuleLimits, targets ...string) *RuleLimits {
+	t.Helper()
+	writers := make(map[string]writer.Writer, len(targets))
+	for _, target := range targets {
+		writers[target] = writer.NoopWriter{}
+	}
+	router, err := writer.NewRouter(targets[0], writers)
+	require.NoError(t, err)
+	lim.RecordingRulesAllowed = true
+	lim.RecordTargetValidator = router
+	return &lim
+}
+
 func validRule() apimodels.PostableExtendedRuleNode {
 	forDuration := model.Duration(rand.Int63n(1000))
 	uid := util.GenerateShortUID()
@@ -411,10 +426,10 @@ func TestValidateRuleNode_NoUID(t *testing.T) {
 		},
 		{
 			name:   "accepts and converts recording rule when toggle is enabled",
-			limits: allowRecording(limits),
+			limits: allowRecordingTo(t, limits, models.RecordTargetPrometheus, models.RecordTargetSQL),
 			rule: func() *apimodels.PostableExtendedRuleNode {
 				r := validRule()
-				r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "some_metric", From: "A"}
+				r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "some_metric", From: "A", Target: models.RecordTargetSQL}
 				r.GrafanaManagedAlert.Condition = ""
 				r.GrafanaManagedAlert.NoDataState = ""
 				r.GrafanaManagedAlert.ExecErrState = ""
@@ -447,6 +462,7 @@ func TestValidateRuleNode_NoUID(t *testing.T) {
 				// Recording fields
 				require.Equal(t, api.GrafanaManagedAlert.Record.From, alert.Record.From)
 				require.Equal(t, api.GrafanaManagedAlert.Record.Metric, alert.Record.Metric)
+				require.Equal(t, api.GrafanaManagedAlert.Record.Target, alert.Record.Target)
 			},
 		},
 		{
@@ -690,6 +706,21 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 			},
 			expErr: "NOTEXIST does not exist",
 		},
+		{
+			name:   "rejects recording rule with target that is not configured",
+			limits: allowRecordingTo(t, limits, models.RecordTargetPrometheus),
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "my_metric", From: "A", Target: models.RecordTargetSQL}
+				r.GrafanaManagedAlert.Condition = ""
+				r.GrafanaManagedAlert.NoDataState = ""
+				r.GrafanaManagedAlert.ExecErrState = ""
+				r.GrafanaManagedAlert.NotificationSettings = nil
+				r.ApiRuleNode.For = nil
+				return &r
+			},
+			expErr: "unknown target",
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ignoredFieldsInVersionsDiff are the fields of the grafana_alert object that change with every version and therefore are not compared.
+var ignoredFieldsInVersionsDiff = []string{"id", "version", "updated", "updated_by", "provenance"}
+
+// RouteGetRuleVersionsByUID returns the versions of the alert rule with the given UID, the most recent first.
+func (srv RulerSrv) RouteGetRuleVersionsByUID(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	ctx := c.Req.Context()
+	rule, versions, err := srv.getAuthorizedRuleVersions(ctx, c, ruleUID)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return response.Empty(http.StatusNotFound)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule versions", err)
+	}
+
+	provenance, err := srv.provenanceStore.GetProvenance(ctx, &rule, c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule provenance", err)
+	}
+	provenanceRecords := map[string]ngmodels.Provenance{rule.ResourceID(): provenance}
+
+	result := make(apimodels.GettableRuleVersions, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, toGettableExtendedRuleNode(*v, provenanceRecords))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RouteGetRuleVersionsDiff returns the changes between two versions of the alert rule with the given UID.
+// The versions are set by the query parameters "from" and "to". By default, the current version is compared to the previous one.
+func (srv RulerSrv) RouteGetRuleVersionsDiff(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	rule, versions, err := srv.getAuthorizedRuleVersions(c.Req.Context(), c, ruleUID)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return response.Empty(http.StatusNotFound)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule versions", err)
+	}
+
+	toVersion := c.QueryInt64("to")
+	if toVersion == 0 {
+		toVersion = rule.Version
+	}
+	to := findRuleVersion(versions, toVersion)
+	if to == nil {
+		return ErrResp(http.StatusNotFound, fmt.Errorf("%w: version %d", ngmodels.ErrAlertRuleNotFound, toVersion), "")
+	}
+
+	var from *ngmodels.AlertRule
+	fromVersion := c.QueryInt64("from")
+	if fromVersion == 0 {
+		// versions are sorted from the most recent, so the previous version follows
+		for _, v := range versions {
+			if v.Version < toVersion {
+				from = v
+				break
+			}
+		}
+		if from == nil {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("there is no version before %d", toVersion), "")
+		}
+	} else if from = findRuleVersion(versions, fromVersion); from == nil {
+		return ErrResp(http.StatusNotFound, fmt.Errorf("%w: version %d", ngmodels.ErrAlertRuleNotFound, fromVersion), "")
+	}
+
+	changes, err := diffRuleVersions(toGettableExtendedRuleNode(*from, nil), toGettableExtendedRuleNode(*to, nil))
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to compare rule versions")
+	}
+	return response.JSON(http.StatusOK, apimodels.RuleVersionsDiff{
+		From:    from.Version,
+		To:      to.Version,
+		Changes: changes,
+	})
+}
+
+// RouteRestoreRuleVersion updates the alert rule with the given UID to the definition it had at the given version.
+// The rule stays in its current folder and group and keeps its pause state. The update follows the same rules as
+// updates of the rule group, i.e. it is rejected if the group contains provisioned rules.
+func (srv RulerSrv) RouteRestoreRuleVersion(c *contextmodel.ReqContext, ruleUID string, version string) response.Response {
+	restoreVersion, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid version %q: %w", version, err), "")
+	}
+
+	ctx := c.Req.Context()
+	rule, versions, err := srv.getAuthorizedRuleVersions(ctx, c, ruleUID)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return response.Empty(http.StatusNotFound)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule versions", err)
+	}
+	restored := findRuleVersion(versions, restoreVersion)
+	if restored == nil {
+		return ErrResp(http.StatusNotFound, fmt.Errorf("%w: version %d", ngmodels.ErrAlertRuleNotFound, restoreVersion), "")
+	}
+
+	groupKey := rule.GetGroupKey()
+	group, err := srv.getAuthorizedRuleGroup(ctx, c, groupKey)
+	if err != nil {
+		return errorToResponse(err)
+	}
+
+	rules := make([]*ngmodels.AlertRuleWithOptionals, 0, len(group))
+	for _, r := range group {
+		if r.UID == rule.UID {
+			rules = append(rules, &ngmodels.AlertRuleWithOptionals{AlertRule: restoreRuleVersion(*r, *restored), HasPause: true})
+			continue
+		}
+		rules = append(rules, &ngmodels.AlertRuleWithOptionals{AlertRule: *r, HasPause: true})
+	}
+	return srv.updateAlertRulesInGroup(c, groupKey, rules)
+}
+
+// getAuthorizedRuleVersions fetches the rule by uid, checks whether the user is authorized to read it and returns it with its versions.
+// Versions of the rule are empty if the history of the rule was not kept.
+func (srv RulerSrv) getAuthorizedRuleVersions(ctx context.Context, c *contextmodel.ReqContext, ruleUID string) (ngmodels.AlertRule, []*ngmodels.AlertRule, error) {
+	rule, err := srv.getAuthorizedRuleByUid(ctx, c, ruleUID)
+	if err != nil {
+		return ngmodels.AlertRule{}, nil, err
+	}
+	versions, err := srv.store.GetAlertRuleVersions(ctx, rule.OrgID, rule.UID)
+	if err != nil && !errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		return ngmodels.AlertRule{}, nil, err
+	}
+	return rule, versions, nil
+}
+
+func findRuleVersion(versions []*ngmodels.AlertRule, version int64) *ngmodels.AlertRule {
+	for _, v := range versions {
+		if v.Version == version {
+			return v
+		}
+	}
+	return nil
+}
+
+// restoreRuleVersion returns the current rule with the definition of the version.
+// Fields that are not kept in the history, and those that define where the rule is, are taken from the current rule.
+func restoreRuleVersion(current ngmodels.AlertRule, version ngmodels.AlertRule) ngmodels.AlertRule {
+	restored := version
+	restored.ID = current.ID
+	restored.OrgID = current.OrgID
+	restored.UID = current.UID
+	restored.Version = current.Version
+	restored.Updated = current.Updated
+	restored.UpdatedBy = current.UpdatedBy
+	restored.NamespaceUID = current.NamespaceUID
+	restored.RuleGroup = current.RuleGroup
+	restored.RuleGroupIndex = current.RuleGroupIndex
+	restored.IntervalSeconds = current.IntervalSeconds
+	restored.DashboardUID = current.DashboardUID
+	restored.PanelID = current.PanelID
+	restored.IsPaused = current.IsPaused
+	return restored
+}
+
+// diffRuleVersions compares two versions of a rule in the API format and returns the fields that differ, sorted by path.
+func diffRuleVersions(from, to apimodels.GettableExtendedRuleNode) ([]apimodels.RuleVersionChange, error) {
+	fromValue, err := ruleVersionToMap(from)
+	if err != nil {
+		return nil, err
+	}
+	toValue, err := ruleVersionToMap(to)
+	if err != nil {
+		return nil, err
+	}
+	return diffValues("", fromValue, toValue, make([]apimodels.RuleVersionChange, 0)), nil
+}
+
+func ruleVersionToMap(rule apimodels.GettableExtendedRuleNode) (map[string]any, error) {
+	b, err := json.Marshal(rule)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]any
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, err
+	}
+	if grafanaAlert, ok := result["grafana_alert"].(map[string]any); ok {
+		for _, field := range ignoredFieldsInVersionsDiff {
+			delete(grafanaAlert, field)
+		}
+	}
+	return result, nil
+}
+
+// diffValues appends the differences between two decoded JSON values to changes.
+// Objects and arrays are compared field by field, other values are compared as a whole.
+func diffValues(path string, from, to any, changes []apimodels.RuleVersionChange) []apimodels.RuleVersionChange {
+	switch fromValue := from.(type) {
+	case map[string]any:
+		toValue, ok := to.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(fromValue)+len(toValue))
+		for k := range fromValue {
+			keys = append(keys, k)
+		}
+		for k := range toValue {
+			if _, ok := fromValue[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			changes = diffField(p, fromValue, toValue, k, changes)
+		}
+		return changes
+	case []any:
+		toValue, ok := to.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < len(fromValue) || i < len(toValue); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(toValue):
+				changes = append(changes, apimodels.RuleVersionChange{Path: p, From: fromValue[i]})
+			case i >= len(fromValue):
+				changes = append(changes, apimodels.RuleVersionChange{Path: p, To: toValue[i]})
+			default:
+				changes = diffValues(p, fromValue[i], toValue[i], changes)
+			}
+		}
+		return changes
+	}
+	if !reflect.DeepEqual(from, to) {
+		changes = append(changes, apimodels.RuleVersionChange{Path: path, From: from, To: to})
+	}
+	return changes
+}
+
+func diffField(path string, from, to map[string]any, key string, changes []apimodels.RuleVersionChange) []apimodels.RuleVersionChange {
+	fromValue, inFrom := from[key]
+	toValue, inTo := to[key]
+	switch {
+	case !inFrom:
+		return append(changes, apimodels.RuleVersionChange{Path: path, To: toValue})
+	case !inTo:
+		return append(changes, apimodels.RuleVersionChange{Path: path, From: fromValue})
+	}
+	return diffValues(path, fromValue, toValue, changes)
+}
@@ -0,0 +1,333 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+type ruleVersionsFixture struct {
+	orgID    int64
+	store    *fakes.RuleStore
+	rule     *models.AlertRule
+	other    *models.AlertRule
+	versions []*models.AlertRule
+}
+
+// setupRuleVersions creates a group of two rules. The first rule has three versions, the last one is the current rule.
+func setupRuleVersions(t *testing.T) ruleVersionsFixture {
+	t.Helper()
+	orgID := rand.Int63()
+	folder := randFolder()
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+	groupKey := models.GenerateGroupKey(orgID)
+	groupKey.NamespaceUID = folder.UID
+	gen := models.RuleGen.With(
+		models.RuleGen.WithGroupKey(groupKey),
+		models.RuleGen.WithUniqueGroupIndex(),
+		models.RuleGen.WithUniqueID(),
+		models.RuleGen.WithNoNotificationSettings(),
+		models.RuleGen.WithIsPaused(false),
+	)
+	rules := gen.GenerateManyRef(2)
+
+	editor := models.UserUID("user:editor")
+	current := models.CopyRule(rules[0], gen.WithTitle("current"), gen.WithLabels(map[string]string{"team": "b"}), gen.WithUpdatedBy(&editor))
+	current.Version = 3
+	v1 := models.CopyRule(current, gen.WithTitle("first"), gen.WithLabels(map[string]string{"team": "a"}), gen.WithUpdatedBy(nil))
+	v1.Version = 1
+	v1.Updated = current.Updated.Add(-2 * time.Hour)
+	v2 := models.CopyRule(current, gen.WithLabels(map[string]string{"team": "a"}))
+	v2.Version = 2
+	v2.Updated = current.Updated.Add(-time.Hour)
+
+	ruleStore.PutRule(context.Background(), current, rules[1])
+	ruleStore.Versions[orgID] = []*models.AlertRule{v2, current, v1}
+
+	return ruleVersionsFixture{
+		orgID:    orgID,
+		store:    ruleStore,
+		rule:     current,
+		other:    rules[1],
+		versions: []*models.AlertRule{current, v2, v1},
+	}
+}
+
+func TestRouteGetRuleVersionsByUID(t *testing.T) {
+	t.Run("should return the versions of the rule, the latest first", func(t *testing.T) {
+		f := setupRuleVersions(t)
+		req := createRequestContextWithPerms(f.orgID, createPermissionsForRules([]*models.AlertRule{f.rule}, f.orgID), nil)
+
+		response := createService(f.store).RouteGetRuleVersionsByUID(req, f.rule.UID)
+
+		require.Equalf(t, http.StatusOK, response.Status(), string(response.Body()))
+		var result apimodels.GettableRuleVersions
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result, 3)
+		for i, expected := range f.versions {
+			actual := result[i].GrafanaManagedAlert
+			require.Equal(t, expected.UID, actual.UID)
+			require.Equal(t, expected.Version, actual.Version)
+			require.Equal(t, expected.Title, actual.Title)
+			require.True(t, expected.Updated.Equal(actual.Updated))
+		}
+		require.Equal(t, "user:editor", result[0].GrafanaManagedAlert.UpdatedBy)
+		require.Empty(t, result[2].GrafanaManagedAlert.UpdatedBy)
+	})
+
+	t.Run("should return 404 if rule does not exist", func(t *testing.T) {
+		f := setupRuleVersions(t)
+		req := createRequestContextWithPerms(f.orgID, createPermissionsForRules([]*models.AlertRule{f.rule}, f.orgID), nil)
+
+		response := createService(f.store).RouteGetRuleVersionsByUID(req, "foobar")
+
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should return 403 if user is not authorized to access the rule", func(t *testing.T) {
+		f := setupRuleVersions(t)
+		req := createRequestContextWithPerms(f.orgID, map[int64]map[string][]string{}, nil)
+
+		response := createService(f.store).RouteGetRuleVersionsByUID(req, f.rule.UID)
+
+		require.Equal(t, http.StatusForbidden, response.Status())
+	})
+}
+
+func TestRouteGetRuleVersionsDiff(t *testing.T) {
+	testCases := []struct {
+		name            string
+		from, to        int64
+		expectedStatus  int
+		expectedFrom    int64
+		expectedTo      int64
+		expectedChanges []apimodels.RuleVersionChange
+	}{
+		{
+			name:           "current and previous version by default",
+			expectedStatus: http.StatusOK,
+			expectedFrom:   2,
+			expectedTo:     3,
+			expectedChanges: []apimodels.RuleVersionChange{
+				{Path: "labels.team", From: "a", To: "b"},
+			},
+		},
+		{
+			name:           "version and the one before it",
+			to:             2,
+			expectedStatus: http.StatusOK,
+			expectedFrom:   1,
+			expectedTo:     2,
+			expectedChanges: []apimodels.RuleVersionChange{
+				{Path: "grafana_alert.title", From: "first", To: "current"},
+			},
+		},
+		{
+			name:           "any two versions",
+			from:           3,
+			to:             1,
+			expectedStatus: http.StatusOK,
+			expectedFrom:   3,
+			expectedTo:     1,
+			expectedChanges: []apimodels.RuleVersionChange{
+				{Path: "grafana_alert.title", From: "current", To: "first"},
+				{Path: "labels.team", From: "b", To: "a"},
+			},
+		},
+		{
+			name:           "no changes between the same version",
+			from:           2,
+			to:             2,
+			expectedStatus: http.StatusOK,
+			expectedFrom:   2,
+			expectedTo:     2,
+		},
+		{
+			name:           "400 if there is no version before",
+			to:             1,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "404 if version does not exist",
+			from:           1,
+			to:             10,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := setupRuleVersions(t)
+			req := createRequestContextWithPerms(f.orgID, createPermissionsForRules([]*models.AlertRule{f.rule}, f.orgID), nil)
+			if tc.from != 0 {
+				req.Req.Form.Set("from", strconv.FormatInt(tc.from, 10))
+			}
+			if tc.to != 0 {
+				req.Req.Form.Set("to", strconv.FormatInt(tc.to, 10))
+			}
+
+			response := createService(f.store).RouteGetRuleVersionsDiff(req, f.rule.UID)
+
+			require.Equalf(t, tc.expectedStatus, response.Status(), string(response.Body()))
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			var result apimodels.RuleVersionsDiff
+			require.NoError(t, json.Unmarshal(response.Body(), &result))
+			require.Equal(t, tc.expectedFrom, result.From)
+			require.Equal(t, tc.expectedTo, result.To)
+			if tc.expectedChanges == nil {
+				require.Empty(t, result.Changes)
+			} else {
+				require.Equal(t, tc.expectedChanges, result.Changes)
+			}
+		})
+	}
+}
+
+func TestDiffValues(t *testing.T) {
+	testCases := []struct {
+		name     string
+		from, to string
+		expected []apimodels.RuleVersionChange
+	}{
+		{
+			name:     "equal values",
+			from:     `{"a": {"b": [1, 2]}}`,
+			to:       `{"a": {"b": [1, 2]}}`,
+			expected: []apimodels.RuleVersionChange{},
+		},
+		{
+			name: "nested fields",
+			from: `{"a": {"b": "x", "c": 1}}`,
+			to:   `{"a": {"b": "y", "d": true}}`,
+			expected: []apimodels.RuleVersionChange{
+				{Path: "a.b", From: "x", To: "y"},
+				{Path: "a.c", From: float64(1)},
+				{Path: "a.d", To: true},
+			},
+		},
+		{
+			name: "array items",
+			from: `{"data": [{"model": {"expr": "up"}}]}`,
+			to:   `{"data": [{"model": {"expr": "down"}}, {"model": {}}]}`,
+			expected: []apimodels.RuleVersionChange{
+				{Path: "data[0].model.expr", From: "up", To: "down"},
+				{Path: "data[1]", To: map[string]any{"model": map[string]any{}}},
+			},
+		},
+		{
+			name: "different types",
+			from: `{"a": [1]}`,
+			to:   `{"a": {"b": 1}}`,
+			expected: []apimodels.RuleVersionChange{
+				{Path: "a", From: []any{float64(1)}, To: map[string]any{"b": float64(1)}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var from, to any
+			require.NoError(t, json.Unmarshal([]byte(tc.from), &from))
+			require.NoError(t, json.Unmarshal([]byte(tc.to), &to))
+			require.Equal(t, tc.expected, diffValues("", from, to, make([]apimodels.RuleVersionChange, 0)))
+		})
+	}
+}
+
+func TestRouteRestoreRuleVersion(t *testing.T) {
+	permissionsToUpdate := func(f ruleVersionsFixture) map[int64]map[string][]string {
+		perms := createPermissionsForRules([]*models.AlertRule{f.rule, f.other}, f.orgID)
+		perms[f.orgID][ac.ActionAlertingRuleUpdate] = []string{dashboards.ScopeFoldersProvider.GetResourceScopeUID(f.rule.NamespaceUID)}
+		return perms
+	}
+
+	t.Run("should update the rule to the definition of the version", func(t *testing.T) {
+		f := setupRuleVersions(t)
+		svc := createService(f.store)
+		svc.conditionValidator = &recordingConditionValidator{}
+		req := createRequestContextWithPerms(f.orgID, permissionsToUpdate(f), nil)
+
+		response := svc.RouteRestoreRuleVersion(req, f.rule.UID, "1")
+
+		require.Equalf(t, http.StatusAccepted, response.Status(), string(response.Body()))
+		var result apimodels.UpdateRuleGroupResponse
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Contains(t, result.Updated, f.rule.UID)
+
+		updates := f.store.GetRecordedCommands(func(cmd any) (any, bool) {
+			c, ok := cmd.([]models.UpdateRule)
+			return c, ok
+		})
+		require.Len(t, updates, 1)
+		var restored models.AlertRule
+		for _, u := range updates[0].([]models.UpdateRule) {
+			if u.New.UID == f.rule.UID {
+				restored = u.New
+			} else {
+				require.Equal(t, u.Existing.UpdatedBy, u.New.UpdatedBy, "rules that did not change should keep their author")
+			}
+		}
+		require.Equal(t, f.rule.UID, restored.UID)
+		require.Equal(t, f.rule.GetGroupKey(), restored.GetGroupKey())
+		require.Equal(t, f.rule.RuleGroupIndex, restored.RuleGroupIndex)
+		require.Equal(t, "first", restored.Title)
+		require.Equal(t, map[string]string{"team": "a"}, restored.Labels)
+		require.NotNil(t, restored.UpdatedBy)
+		require.Equal(t, models.UserUID(req.SignedInUser.GetUID()), *restored.UpdatedBy)
+	})
+
+	t.Run("should return 400 if the rule group is provisioned", func(t *testing.T) {
+		f := setupRuleVersions(t)
+		provisioningStore := fakes.NewFakeProvisioningStore()
+		require.NoError(t, provisioningStore.SetProvenance(context.Background(), f.other, f.orgID, models.ProvenanceAPI))
+		svc := createServiceWithProvenanceStore(f.store, provisioningStore)
+		svc.conditionValidator = &recordingConditionValidator{}
+		req := createRequestContextWithPerms(f.orgID, permissionsToUpdate(f), nil)
+
+		response := svc.RouteRestoreRuleVersion(req, f.rule.UID, "1")
+
+		require.Equalf(t, http.StatusBadRequest, response.Status(), string(response.Body()))
+	})
+
+	t.Run("should return 403 if user is not authorized to update the rule", func(t *testing.T) {
+		f := setupRuleVersions(t)
+		req := createRequestContextWithPerms(f.orgID, createPermissionsForRules([]*models.AlertRule{f.rule, f.other}, f.orgID), nil)
+
+		response := createService(f.store).RouteRestoreRuleVersion(req, f.rule.UID, "1")
+
+		require.Equalf(t, http.StatusForbidden, response.Status(), string(response.Body()))
+	})
+
+	t.Run("should return 404 if version does not exist", func(t *testing.T) {
+		f := setupRuleVersions(t)
+		req := createRequestContextWithPerms(f.orgID, permissionsToUpdate(f), nil)
+
+		response := createService(f.store).RouteRestoreRuleVersion(req, f.rule.UID, "10")
+
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should return 400 if version is not a number", func(t *testing.T) {
+		f := setupRuleVersions(t)
+		req := createRequestContextWithPerms(f.orgID, permissionsToUpdate(f), nil)
+
+		response := createService(f.store).RouteRestoreRuleVersion(req, f.rule.UID, "first")
+
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+}
@@ -497,10 +497,14 @@ func AlertRuleRecordExportFromRecord(r *models.Record) *definitions.AlertRuleRec
 	if r == nil {
 		return nil
 	}
-	return &definitions.AlertRuleRecordExport{
+	result := &definitions.AlertRuleRecordExport{
 		Metric: r.Metric,
 		From:   r.From,
 	}
+	if r.Target != "" {
+		result.Target = &r.Target
+	}
+	return result
 }
 
 func ModelRecordFromApiRecord(r *definitions.Record) *models.Record {
@@ -510,6 +514,7 @@ func ModelRecordFromApiRecord(r *definitions.Record) *models.Record {
 	return &models.Record{
 		Metric: r.Metric,
 		From:   r.From,
+		Target: r.Target,
 	}
 }
 
@@ -520,6 +525,7 @@ func ApiRecordFromModelRecord(r *models.Record) *definitions.Record {
 	return &definitions.Record{
 		Metric: r.Metric,
 		From:   r.From,
+		Target: r.Target,
 	}
 }
 
@@ -1,6 +1,9 @@
 package api
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/grafana/grafana/pkg/api/response"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -97,6 +100,45 @@ func (f *RulerApiHandler) handleRouteGetRuleByUID(ctx *contextmodel.ReqContext,
 	return f.GrafanaRuler.RouteGetRuleByUID(ctx, ruleUID)
 }
 
+func (f *RulerApiHandler) handleRouteGetRuleVersionsByUID(ctx *contextmodel.ReqContext, ruleUID string) response.Response {
+	return f.GrafanaRuler.RouteGetRuleVersionsByUID(ctx, ruleUID)
+}
+
+func (f *RulerApiHandler) handleRouteGetRuleVersionsDiff(ctx *contextmodel.ReqContext, ruleUID string) response.Response {
+	return f.GrafanaRuler.RouteGetRuleVersionsDiff(ctx, ruleUID)
+}
+
+func (f *RulerApiHandler) handleRouteRestoreRuleVersion(ctx *contextmodel.ReqContext, ruleUID, version string) response.Response {
+	return f.GrafanaRuler.RouteRestoreRuleVersion(ctx, ruleUID, version)
+}
+
+func (f *RulerApiHandler) handleRouteStartRuleBackfill(ctx *contextmodel.ReqContext, body apimodels.PostableRuleBackfill, ruleUID string) response.Response {
+	return f.GrafanaRuler.RouteStartRuleBackfill(ctx, body, ruleUID)
+}
+
+func (f *RulerApiHandler) handleRouteGetRuleBackfill(ctx *contextmodel.ReqContext, ruleUID string) response.Response {
+	return f.GrafanaRuler.RouteGetRuleBackfill(ctx, ruleUID)
+}
+
+func (f *RulerApiHandler) handleRouteCancelRuleBackfill(ctx *contextmodel.ReqContext, ruleUID string) response.Response {
+	return f.GrafanaRuler.RouteCancelRuleBackfill(ctx, ruleUID)
+}
+
+func (f *RulerApiHandler) handleRouteImportPrometheusRules(ctx *contextmodel.ReqContext, namespace string) response.Response {
+	dsUID := ctx.Query("datasourceUid")
+	if dsUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("query parameter 'datasourceUid' is required"), "")
+	}
+	ds, err := f.DatasourceCache.GetDatasourceByUID(ctx.Req.Context(), dsUID, ctx.SignedInUser, ctx.SkipDSCache)
+	if err != nil {
+		return errorToResponse(err)
+	}
+	if ds.Type != datasources.DS_PROMETHEUS && ds.Type != datasources.DS_LOKI {
+		return errorToResponse(unexpectedDatasourceTypeError(ds.Type, "loki, prometheus"))
+	}
+	return f.GrafanaRuler.RouteImportPrometheusRules(ctx, ds, namespace)
+}
+
 func (f *RulerApiHandler) handleRoutePostNameGrafanaRulesConfig(ctx *contextmodel.ReqContext, conf apimodels.PostableRuleGroupConfig, namespace string) response.Response {
 	payloadType := conf.Type()
 	if payloadType != apimodels.GrafanaBackend {
@@ -20,6 +20,86 @@ import (
 //       403: ForbiddenError
 //       404: description: Not found.
 
+// swagger:route Get /ruler/grafana/api/v1/rule/{RuleUID}/versions ruler RouteGetRuleVersionsByUID
+//
+// List the versions of a rule, the most recent first
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRuleVersions
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route Get /ruler/grafana/api/v1/rule/{RuleUID}/versions/diff ruler RouteGetRuleVersionsDiff
+//
+// Show the changes between two versions of a rule
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleVersionsDiff
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route POST /ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/restore ruler RouteRestoreRuleVersion
+//
+// Restore the definition of a rule to a previous version. The rule stays in its current folder and group.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       202: UpdateRuleGroupResponse
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route POST /ruler/grafana/api/v1/rule/{RuleUID}/backfill ruler RouteStartRuleBackfill
+//
+// Start the backfill of a recording rule. The rule is evaluated at every interval in the range [from, to)
+// and the results are written to the target data source with the time of the evaluation.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       202: RuleBackfillStatus
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+//       409: description: Backfill of the rule is already in progress.
+
+// swagger:route Get /ruler/grafana/api/v1/rule/{RuleUID}/backfill ruler RouteGetRuleBackfill
+//
+// Get the status of the running or recently finished backfill of a recording rule
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleBackfillStatus
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route Delete /ruler/grafana/api/v1/rule/{RuleUID}/backfill ruler RouteCancelRuleBackfill
+//
+// Cancel the running backfill of a recording rule. The points that are already written are kept.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleBackfillStatus
+//       403: ForbiddenError
+//       404: description: Not found.
+
 // swagger:route Get /ruler/grafana/api/v1/rules ruler RouteGetGrafanaRulesConfig
 //
 // List rule groups
@@ -92,6 +172,23 @@ import (
 //       403: ForbiddenError
 //       404: description: Not found.
 
+// swagger:route POST /ruler/grafana/api/v1/rules/{Namespace}/import/prometheus ruler RouteImportPrometheusRules
+//
+// Imports a Prometheus rule file as Grafana-managed alert and recording rules. Rule groups of the file replace the rule groups with the same name in the folder.
+//
+//     Consumes:
+//     - application/yaml
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       202: ImportPrometheusRulesResponse
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+
 // swagger:route POST /ruler/{DatasourceUID}/api/v1/rules/{Namespace} ruler RoutePostNameRulesConfig
 //
 // Creates or updates a rule group
@@ -194,6 +291,21 @@ type NamespaceConfig struct {
 	Body PostableRuleGroupConfig
 }
 
+// swagger:parameters RouteImportPrometheusRules
+type ImportPrometheusRulesParams struct {
+	// The UID of the rule folder
+	// in: path
+	Namespace string
+	// The UID of the Prometheus or Loki data source that the imported rules query.
+	// in: query
+	// required: true
+	DatasourceUID string `json:"datasourceUid"`
+	// Whether the imported rules are created paused.
+	// in: query
+	// required: false
+	Paused bool `json:"paused"`
+}
+
 // swagger:parameters RouteGetNamespaceRulesConfig RouteDeleteNamespaceRulesConfig RouteGetNamespaceGrafanaRulesConfig RouteDeleteNamespaceGrafanaRulesConfig
 type PathNamespaceConfig struct {
 	// The UID of the rule folder
@@ -218,12 +330,93 @@ type PathGetRulesParams struct {
 	PanelID int64
 }
 
-// swagger:parameters RouteGetRuleByUID
+// swagger:parameters RouteGetRuleByUID RouteGetRuleVersionsByUID RouteGetRuleBackfill RouteCancelRuleBackfill
 type PathGetRuleByUIDParams struct {
 	// in: path
 	RuleUID string
 }
 
+// swagger:parameters RouteGetRuleVersionsDiff
+type GetRuleVersionsDiffParams struct {
+	// in: path
+	RuleUID string
+	// The version to compare from. Defaults to the version before To.
+	// in: query
+	// required: false
+	From int64 `json:"from"`
+	// The version to compare to. Defaults to the current version.
+	// in: query
+	// required: false
+	To int64 `json:"to"`
+}
+
+// swagger:parameters RouteRestoreRuleVersion
+type RestoreRuleVersionParams struct {
+	// in: path
+	RuleUID string
+	// in: path
+	Version int64
+}
+
+// swagger:parameters RouteStartRuleBackfill
+type StartRuleBackfillParams struct {
+	// in: path
+	RuleUID string
+	// in: body
+	Body PostableRuleBackfill
+}
+
+// swagger:model
+type PostableRuleBackfill struct {
+	// required: true
+	From time.Time `json:"from"`
+	// required: true
+	To time.Time `json:"to"`
+}
+
+// swagger:model
+type RuleBackfillStatus struct {
+	RuleUID string    `json:"rule_uid"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	// State of the backfill: running, completed, failed or cancelled.
+	State    string               `json:"state"`
+	Progress RuleBackfillProgress `json:"progress"`
+	// Error is the reason of the failure of the backfill.
+	Error    string     `json:"error,omitempty"`
+	Started  time.Time  `json:"started"`
+	Finished *time.Time `json:"finished,omitempty"`
+}
+
+type RuleBackfillProgress struct {
+	Evaluations    int        `json:"evaluations"`
+	Total          int        `json:"total"`
+	Written        int        `json:"written"`
+	NoData         int        `json:"no_data"`
+	Failed         int        `json:"failed"`
+	LastEvaluation *time.Time `json:"last_evaluation,omitempty"`
+}
+
+// swagger:model
+type GettableRuleVersions []GettableExtendedRuleNode
+
+// swagger:model
+type RuleVersionsDiff struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+	// Changes are the fields that differ between the versions, in the format of GettableExtendedRuleNode.
+	Changes []RuleVersionChange `json:"changes"`
+}
+
+type RuleVersionChange struct {
+	// Path to the field separated by period. Array indexes are designated by square brackets.
+	Path string `json:"path"`
+	// From is the value in the older version. It is missing if the field was added.
+	From any `json:"from,omitempty"`
+	// To is the value in the newer version. It is missing if the field was removed.
+	To any `json:"to,omitempty"`
+}
+
 // swagger:model
 type RuleGroupConfigResponse struct {
 	GettableRuleGroupConfig
@@ -516,6 +709,10 @@ type Record struct {
 	// required: true
 	// example: A
 	From string `json:"from" yaml:"from"`
+	// Where the recorded metric is written to. If empty, the default target of the instance is used.
+	// enum: prometheus,influxdb,sql,loki
+	// example: influxdb
+	Target string `json:"target,omitempty" yaml:"target,omitempty"`
 }
 
 // swagger:model
@@ -552,6 +749,7 @@ type GettableGrafanaRule struct {
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
 	Record               *Record                        `json:"record,omitempty" yaml:"record,omitempty"`
 	Metadata             *AlertRuleMetadata             `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	UpdatedBy            string                         `json:"updated_by,omitempty" yaml:"updated_by,omitempty"`
 }
 
 // AlertQuery represents a single query associated with an alert definition.
@@ -628,3 +826,18 @@ type UpdateRuleGroupResponse struct {
 	Updated []string `json:"updated,omitempty"`
 	Deleted []string `json:"deleted,omitempty"`
 }
+
+// swagger:model
+type ImportPrometheusRulesResponse struct {
+	Message string              `json:"message"`
+	Groups  []ImportedRuleGroup `json:"groups"`
+	// Warnings describe the parts of the Prometheus rules that were not imported as is.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type ImportedRuleGroup struct {
+	Name    string   `json:"name"`
+	Created []string `json:"created,omitempty"`
+	Updated []string `json:"updated,omitempty"`
+	Deleted []string `json:"deleted,omitempty"`
+}
@@ -0,0 +1,334 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	ErrBackfillInProgress = errors.New("backfill of the rule is already in progress")
+	ErrBackfillNotFound   = errors.New("backfill of the rule is not found")
+	ErrBackfillLimit      = errors.New("too many backfills are running")
+	ErrBackfillerStopped  = errors.New("backfills are not accepted because the service is stopping")
+)
+
+// finishedBackfillRetention is how long the status of a finished backfill is kept after it finished.
+const finishedBackfillRetention = time.Hour
+
+type BackfillState string
+
+const (
+	BackfillRunning   BackfillState = "running"
+	BackfillCompleted BackfillState = "completed"
+	BackfillFailed    BackfillState = "failed"
+	BackfillCancelled BackfillState = "cancelled"
+)
+
+// BackfillProgress describes how far a backfill got.
+type BackfillProgress struct {
+	// Evaluations is the number of evaluations that are done.
+	Evaluations int
+	// Total is the number of evaluations in the time range.
+	Total int
+	// Written is the number of evaluations whose results were written.
+	Written int
+	// NoData is the number of evaluations that returned no data, so there was nothing to write.
+	NoData int
+	// Failed is the number of evaluations that returned an error. They are skipped and the backfill continues.
+	Failed int
+	// LastEvaluation is the time of the last evaluation that is done.
+	LastEvaluation time.Time
+}
+
+// BackfillJob is a snapshot of the backfill of a recording rule.
+type BackfillJob struct {
+	RuleKey  models.AlertRuleKey
+	From     time.Time
+	To       time.Time
+	State    BackfillState
+	Progress BackfillProgress
+	Error    string
+	Started  time.Time
+	Finished time.Time
+}
+
+// Backfill evaluates the recording rule at every interval in the range [from, to) and writes the results
+// through the writer with the time of the evaluation. The evaluations that fail are counted and skipped,
+// but an error of the writer stops the backfill. The progress function, if set, is called after every evaluation.
+func (e *Engine) Backfill(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time, writer schedule.RecordingWriter, progress func(BackfillProgress)) (BackfillProgress, error) {
+	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
+	logger := logger.FromContext(ruleCtx)
+
+	if err := validateBackfill(rule, from, to); err != nil {
+		return BackfillProgress{}, err
+	}
+	interval := time.Duration(rule.IntervalSeconds) * time.Second
+	result := BackfillProgress{
+		Total: backfillEvaluations(rule, from, to),
+	}
+
+	evaluator, err := e.evalFactory.Create(eval.NewContext(ruleCtx, user), rule.GetEvalCondition().WithSource("backfill"))
+	if err != nil {
+		return result, errors.Join(ErrInvalidInputData, err)
+	}
+
+	logger.Info("Start backfilling recording rule", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluations", result.Total)
+	start := time.Now()
+
+	for idx := 0; idx < result.Total; idx++ {
+		if err := ruleCtx.Err(); err != nil {
+			logger.Info("Backfill of recording rule is cancelled", "evaluations", result.Evaluations, "duration", time.Since(start))
+			return result, err
+		}
+		now := from.Add(time.Duration(idx) * interval)
+
+		frames, err := evaluateRecordingRule(ruleCtx, evaluator, rule, now)
+		switch {
+		case err != nil:
+			logger.Warn("Failed to evaluate recording rule", "evaluationTime", now, "error", err)
+			result.Failed++
+		case len(frames) == 0:
+			result.NoData++
+		default:
+			if err := writer.WriteTo(ruleCtx, rule.Record.Target, rule.Record.Metric, now, frames, rule.OrgID, rule.Labels); err != nil {
+				return result, fmt.Errorf("failed to write the results of the evaluation at %s: %w", now.Format(time.RFC3339), err)
+			}
+			result.Written++
+		}
+
+		result.Evaluations++
+		result.LastEvaluation = now
+		if progress != nil {
+			progress(result)
+		}
+	}
+
+	logger.Info("Recording rule backfill finished successfully", "duration", time.Since(start), "written", result.Written, "noData", result.NoData, "failed", result.Failed)
+	return result, nil
+}
+
+func validateBackfill(rule *models.AlertRule, from, to time.Time) error {
+	if rule.Type() != models.RuleTypeRecording {
+		return fmt.Errorf("%w: only recording rules can be backfilled", ErrInvalidInputData)
+	}
+	if rule.IntervalSeconds <= 0 {
+		return fmt.Errorf("%w: invalid evaluation interval of the rule [%ds]", ErrInvalidInputData, rule.IntervalSeconds)
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("%w: invalid interval of the backfill [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
+	}
+	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
+		return fmt.Errorf("%w: interval of the backfill [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
+	}
+	return nil
+}
+
+// backfillEvaluations returns the number of evaluations of the rule in the range [from, to).
+func backfillEvaluations(rule *models.AlertRule, from, to time.Time) int {
+	return int(to.Sub(from) / (time.Duration(rule.IntervalSeconds) * time.Second))
+}
+
+// evaluateRecordingRule returns the frames of the query the recording rule records. It returns no frames if the query returned no data.
+func evaluateRecordingRule(ctx context.Context, evaluator eval.ConditionEvaluator, rule *models.AlertRule, now time.Time) (data.Frames, error) {
+	resp, err := evaluator.EvaluateRaw(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := eval.FindConditionError(resp, rule.Record.From); err != nil {
+		return nil, err
+	}
+	return recordedFrames(rule.Record.From, resp), nil
+}
+
+func recordedFrames(refID string, resp *backend.QueryDataResponse) data.Frames {
+	if resp == nil {
+		return nil
+	}
+	node, ok := resp.Responses[refID]
+	if !ok || eval.IsNoData(node) {
+		return nil
+	}
+	return node.Frames
+}
+
+type backfillJob struct {
+	BackfillJob
+	cancel context.CancelFunc
+}
+
+// Backfiller runs backfills of recording rules in the background and keeps track of their progress.
+// There can be only one backfill per rule at a time, and at most MaxConcurrent backfills in total.
+// The backfills are cancelled when the service stops.
+type Backfiller struct {
+	engine   *Engine
+	writer   schedule.RecordingWriter
+	settings setting.RecordingRuleBackfillSettings
+
+	// ctx is the parent of the contexts of all backfills. It is cancelled by Run when the service stops.
+	ctx  context.Context
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+
+	mtx  sync.Mutex
+	jobs map[models.AlertRuleKey]*backfillJob
+
+	// used by tests
+	now func() time.Time
+}
+
+func NewBackfiller(engine *Engine, writer schedule.RecordingWriter, settings setting.RecordingRuleBackfillSettings) *Backfiller {
+	ctx, stop := context.WithCancel(context.Background())
+	return &Backfiller{
+		engine:   engine,
+		writer:   writer,
+		settings: settings,
+		ctx:      ctx,
+		stop:     stop,
+		jobs:     make(map[models.AlertRuleKey]*backfillJob),
+		now:      time.Now,
+	}
+}
+
+// Run blocks until the context is cancelled, then cancels the running backfills and waits for them to stop.
+func (b *Backfiller) Run(ctx context.Context) error {
+	<-ctx.Done()
+
+	b.mtx.Lock()
+	b.stop()
+	b.mtx.Unlock()
+
+	b.wg.Wait()
+	return nil
+}
+
+// Start validates the request and starts the backfill of the recording rule in the background.
+// The backfill runs on behalf of the user, and it is not bound to the context of the request.
+func (b *Backfiller) Start(user identity.Requester, rule *models.AlertRule, from, to time.Time) (BackfillJob, error) {
+	if err := validateBackfill(rule, from, to); err != nil {
+		return BackfillJob{}, err
+	}
+	if to.After(b.now()) {
+		return BackfillJob{}, fmt.Errorf("%w: the end of the backfill must not be in the future", ErrInvalidInputData)
+	}
+	total := backfillEvaluations(rule, from, to)
+	if total > b.settings.MaxEvaluations {
+		return BackfillJob{}, fmt.Errorf("%w: the backfill requires %d evaluations of the rule, at most %d are allowed", ErrInvalidInputData, total, b.settings.MaxEvaluations)
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.ctx.Err() != nil {
+		return BackfillJob{}, ErrBackfillerStopped
+	}
+	b.cleanup()
+
+	key := rule.GetKey()
+	if job, ok := b.jobs[key]; ok && job.State == BackfillRunning {
+		return BackfillJob{}, ErrBackfillInProgress
+	}
+	if b.running() >= b.settings.MaxConcurrent {
+		return BackfillJob{}, fmt.Errorf("%w: at most %d backfills can run at the same time", ErrBackfillLimit, b.settings.MaxConcurrent)
+	}
+
+	ctx, cancel := context.WithCancel(b.ctx)
+	job := &backfillJob{
+		BackfillJob: BackfillJob{
+			RuleKey: key,
+			From:    from,
+			To:      to,
+			State:   BackfillRunning,
+			Progress: BackfillProgress{
+				Total: total,
+			},
+			Started: b.now(),
+		},
+		cancel: cancel,
+	}
+	b.jobs[key] = job
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer cancel()
+		progress, err := b.engine.Backfill(ctx, user, rule, from, to, b.writer, func(p BackfillProgress) {
+			b.mtx.Lock()
+			defer b.mtx.Unlock()
+			job.Progress = p
+		})
+
+		b.mtx.Lock()
+		defer b.mtx.Unlock()
+		job.Progress = progress
+		job.Finished = b.now()
+		switch {
+		case errors.Is(err, context.Canceled):
+			job.State = BackfillCancelled
+		case err != nil:
+			job.State = BackfillFailed
+			job.Error = err.Error()
+		default:
+			job.State = BackfillCompleted
+		}
+	}()
+
+	return job.BackfillJob, nil
+}
+
+// Get returns the status of the running or recently finished backfill of the rule.
+func (b *Backfiller) Get(key models.AlertRuleKey) (BackfillJob, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.cleanup()
+
+	job, ok := b.jobs[key]
+	if !ok {
+		return BackfillJob{}, ErrBackfillNotFound
+	}
+	return job.BackfillJob, nil
+}
+
+// Cancel stops the running backfill of the rule. The points that are already written are not removed.
+func (b *Backfiller) Cancel(key models.AlertRuleKey) (BackfillJob, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	job, ok := b.jobs[key]
+	if !ok {
+		return BackfillJob{}, ErrBackfillNotFound
+	}
+	job.cancel()
+	return job.BackfillJob, nil
+}
+
+// running returns the number of running backfills. Must be called under the lock.
+func (b *Backfiller) running() int {
+	n := 0
+	for _, job := range b.jobs {
+		if job.State == BackfillRunning {
+			n++
+		}
+	}
+	return n
+}
+
+// cleanup removes the backfills that finished more than finishedBackfillRetention ago. Must be called under the lock.
+func (b *Backfiller) cleanup() {
+	now := b.now()
+	for key, job := range b.jobs {
+		if job.State != BackfillRunning && now.Sub(job.Finished) > finishedBackfillRetention {
+			delete(b.jobs, key)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestEngineBackfill(t *testing.T) {
+	gen := models.RuleGen
+	rule := gen.With(gen.WithAllRecordingRules(), gen.WithInterval(time.Minute)).GenerateRef()
+	ruleInterval := time.Duration(rule.IntervalSeconds) * time.Second
+	from := time.Unix(0, 0).UTC()
+	to := from.Add(5 * ruleInterval)
+
+	frameResponse := func(now time.Time) *backend.QueryDataResponse {
+		frame := data.NewFrame("", data.NewField("Value", data.Labels{"t": now.String()}, []float64{1}))
+		return &backend.QueryDataResponse{Responses: backend.Responses{rule.Record.From: {Frames: data.Frames{frame}}}}
+	}
+	newEngine := func(evalRaw func(now time.Time) (*backend.QueryDataResponse, error)) *Engine {
+		return &Engine{
+			evalFactory: eval_mocks.NewEvaluatorFactory(&fakeConditionEvaluator{evalRaw: evalRaw}),
+		}
+	}
+
+	t.Run("should write the results with the time of the evaluation", func(t *testing.T) {
+		engine := newEngine(func(now time.Time) (*backend.QueryDataResponse, error) {
+			return frameResponse(now), nil
+		})
+		var written []time.Time
+		w := writer.FakeWriter{WriteFunc: func(_ context.Context, name string, ts time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+			require.Equal(t, rule.Record.Metric, name)
+			require.Equal(t, rule.OrgID, orgID)
+			require.Equal(t, rule.Labels, extraLabels)
+			require.Equal(t, ts.String(), frames[0].Fields[0].Labels["t"])
+			written = append(written, ts)
+			return nil
+		}}
+		var reported []BackfillProgress
+
+		progress, err := engine.Backfill(context.Background(), nil, rule, from, to, w, func(p BackfillProgress) {
+			reported = append(reported, p)
+		})
+
+		require.NoError(t, err)
+		expected := make([]time.Time, 0, 5)
+		for i := 0; i < 5; i++ {
+			expected = append(expected, from.Add(time.Duration(i)*ruleInterval))
+		}
+		require.Equal(t, expected, written)
+		require.Equal(t, BackfillProgress{Evaluations: 5, Total: 5, Written: 5, LastEvaluation: expected[4]}, progress)
+		require.Len(t, reported, 5)
+		require.Equal(t, 1, reported[0].Evaluations)
+		require.Equal(t, progress, reported[4])
+	})
+
+	t.Run("should skip evaluations that failed or have no data", func(t *testing.T) {
+		engine := newEngine(func(now time.Time) (*backend.QueryDataResponse, error) {
+			switch now {
+			case from.Add(ruleInterval):
+				return nil, errors.New("test")
+			case from.Add(2 * ruleInterval):
+				return &backend.QueryDataResponse{Responses: backend.Responses{rule.Record.From: {Error: errors.New("test")}}}, nil
+			case from.Add(3 * ruleInterval):
+				return &backend.QueryDataResponse{Responses: backend.Responses{rule.Record.From: {}}}, nil
+			}
+			return frameResponse(now), nil
+		})
+		var written []time.Time
+		w := writer.FakeWriter{WriteFunc: func(_ context.Context, _ string, ts time.Time, _ data.Frames, _ int64, _ map[string]string) error {
+			written = append(written, ts)
+			return nil
+		}}
+
+		progress, err := engine.Backfill(context.Background(), nil, rule, from, to, w, nil)
+
+		require.NoError(t, err)
+		require.Equal(t, []time.Time{from, from.Add(4 * ruleInterval)}, written)
+		require.Equal(t, BackfillProgress{Evaluations: 5, Total: 5, Written: 2, NoData: 1, Failed: 2, LastEvaluation: from.Add(4 * ruleInterval)}, progress)
+	})
+
+	t.Run("should stop if writer fails", func(t *testing.T) {
+		engine := newEngine(func(now time.Time) (*backend.QueryDataResponse, error) {
+			return frameResponse(now), nil
+		})
+		expectedErr := errors.New("test")
+		w := writer.FakeWriter{WriteFunc: func(_ context.Context, _ string, ts time.Time, _ data.Frames, _ int64, _ map[string]string) error {
+			if ts.Equal(from.Add(2 * ruleInterval)) {
+				return expectedErr
+			}
+			return nil
+		}}
+
+		progress, err := engine.Backfill(context.Background(), nil, rule, from, to, w, nil)
+
+		require.ErrorIs(t, err, expectedErr)
+		require.Equal(t, 2, progress.Written)
+		require.Equal(t, 2, progress.Evaluations)
+	})
+
+	t.Run("should stop if context is cancelled", func(t *testing.T) {
+		engine := newEngine(func(now time.Time) (*backend.QueryDataResponse, error) {
+			return frameResponse(now), nil
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		w := writer.FakeWriter{WriteFunc: func(_ context.Context, _ string, ts time.Time, _ data.Frames, _ int64, _ map[string]string) error {
+			if ts.Equal(from.Add(ruleInterval)) {
+				cancel()
+			}
+			return nil
+		}}
+
+		progress, err := engine.Backfill(ctx, nil, rule, from, to, w, nil)
+
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 2, progress.Evaluations)
+	})
+
+	t.Run("should fail if evaluator cannot be created", func(t *testing.T) {
+		engine := &Engine{evalFactory: eval_mocks.NewFailingEvaluatorFactory(nil)}
+
+		_, err := engine.Backfill(context.Background(), nil, rule, from, to, writer.FakeWriter{}, nil)
+
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+
+	t.Run("should validate input", func(t *testing.T) {
+		engine := newEngine(func(now time.Time) (*backend.QueryDataResponse, error) {
+			t.Fatal("rule should not be evaluated")
+			return nil, nil
+		})
+		alertRule := gen.With(gen.WithInterval(time.Minute)).GenerateRef()
+
+		testCases := []struct {
+			name     string
+			rule     *models.AlertRule
+			from, to time.Time
+		}{
+			{name: "alerting rule", rule: alertRule, from: from, to: to},
+			{name: "from is after to", rule: rule, from: to, to: from},
+			{name: "range is less than interval", rule: rule, from: from, to: from.Add(ruleInterval - time.Second)},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := engine.Backfill(context.Background(), nil, tc.rule, tc.from, tc.to, writer.FakeWriter{}, nil)
+				require.ErrorIs(t, err, ErrInvalidInputData)
+			})
+		}
+	})
+}
+
+func TestBackfiller(t *testing.T) {
+	gen := models.RuleGen
+	rule := gen.With(gen.WithAllRecordingRules(), gen.WithInterval(time.Minute)).GenerateRef()
+	ruleInterval := time.Duration(rule.IntervalSeconds) * time.Second
+	from := time.Now().Add(-10 * ruleInterval)
+	to := from.Add(5 * ruleInterval)
+
+	engine := &Engine{
+		evalFactory: eval_mocks.NewEvaluatorFactory(&fakeConditionEvaluator{evalRaw: func(now time.Time) (*backend.QueryDataResponse, error) {
+			frame := data.NewFrame("", data.NewField("Value", nil, []float64{1}))
+			return &backend.QueryDataResponse{Responses: backend.Responses{rule.Record.From: {Frames: data.Frames{frame}}}}, nil
+		}}),
+	}
+
+	settings := setting.RecordingRuleBackfillSettings{MaxEvaluations: 10, MaxConcurrent: 1}
+
+	// newBlockingWriter returns a writer that blocks every write until the channel is closed or the backfill is cancelled.
+	newBlockingWriter := func() (writer.FakeWriter, chan struct{}) {
+		release := make(chan struct{})
+		return writer.FakeWriter{WriteFunc: func(ctx context.Context, _ string, _ time.Time, _ data.Frames, _ int64, _ map[string]string) error {
+			select {
+			case <-release:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}}, release
+	}
+	waitForState := func(t *testing.T, b *Backfiller, state BackfillState) BackfillJob {
+		t.Helper()
+		var job BackfillJob
+		require.Eventually(t, func() bool {
+			var err error
+			job, err = b.Get(rule.GetKey())
+			require.NoError(t, err)
+			return job.State == state
+		}, 5*time.Second, 10*time.Millisecond)
+		return job
+	}
+
+	t.Run("should run backfill in background and report progress", func(t *testing.T) {
+		w, release := newBlockingWriter()
+		b := NewBackfiller(engine, w, settings)
+
+		job, err := b.Start(nil, rule, from, to)
+		require.NoError(t, err)
+		require.Equal(t, BackfillRunning, job.State)
+		require.Equal(t, rule.GetKey(), job.RuleKey)
+		require.Equal(t, 5, job.Progress.Total)
+
+		_, err = b.Start(nil, rule, from, to)
+		require.ErrorIs(t, err, ErrBackfillInProgress)
+
+		close(release)
+		job = waitForState(t, b, BackfillCompleted)
+		require.Equal(t, 5, job.Progress.Written)
+		require.False(t, job.Finished.IsZero())
+
+		_, err = b.Start(nil, rule, from, to)
+		require.NoError(t, err)
+	})
+
+	t.Run("should cancel backfill", func(t *testing.T) {
+		w, _ := newBlockingWriter()
+		b := NewBackfiller(engine, w, settings)
+
+		_, err := b.Start(nil, rule, from, to)
+		require.NoError(t, err)
+		_, err = b.Cancel(rule.GetKey())
+		require.NoError(t, err)
+
+		job := waitForState(t, b, BackfillCancelled)
+		require.Zero(t, job.Progress.Written)
+		require.Empty(t, job.Error)
+	})
+
+	t.Run("should report error of writer", func(t *testing.T) {
+		b := NewBackfiller(engine, writer.FakeWriter{WriteFunc: func(_ context.Context, _ string, _ time.Time, _ data.Frames, _ int64, _ map[string]string) error {
+			return errors.New("test")
+		}}, settings)
+
+		_, err := b.Start(nil, rule, from, to)
+		require.NoError(t, err)
+
+		job := waitForState(t, b, BackfillFailed)
+		require.Contains(t, job.Error, "test")
+	})
+
+	t.Run("should remove finished backfills after retention", func(t *testing.T) {
+		b := NewBackfiller(engine, writer.FakeWriter{}, settings)
+		_, err := b.Start(nil, rule, from, to)
+		require.NoError(t, err)
+		waitForState(t, b, BackfillCompleted)
+
+		b.now = func() time.Time {
+			return time.Now().Add(finishedBackfillRetention + time.Minute)
+		}
+		_, err = b.Get(rule.GetKey())
+		require.ErrorIs(t, err, ErrBackfillNotFound)
+	})
+
+	t.Run("should fail if backfill does not exist", func(t *testing.T) {
+		b := NewBackfiller(engine, writer.FakeWriter{}, settings)
+		_, err := b.Get(rule.GetKey())
+		require.ErrorIs(t, err, ErrBackfillNotFound)
+		_, err = b.Cancel(rule.GetKey())
+		require.ErrorIs(t, err, ErrBackfillNotFound)
+	})
+
+	t.Run("should fail if end of the range is in the future", func(t *testing.T) {
+		b := NewBackfiller(engine, writer.FakeWriter{}, settings)
+		_, err := b.Start(nil, rule, from, time.Now().Add(time.Hour))
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+
+	t.Run("should fail if backfill requires too many evaluations", func(t *testing.T) {
+		b := NewBackfiller(engine, writer.FakeWriter{}, setting.RecordingRuleBackfillSettings{MaxEvaluations: 4, MaxConcurrent: 1})
+		_, err := b.Start(nil, rule, from, to)
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+
+	t.Run("should limit the number of concurrent backfills", func(t *testing.T) {
+		w, release := newBlockingWriter()
+		b := NewBackfiller(engine, w, settings)
+
+		_, err := b.Start(nil, rule, from, to)
+		require.NoError(t, err)
+
+		other := models.CopyRule(rule)
+		other.UID = "other"
+		_, err = b.Start(nil, other, from, to)
+		require.ErrorIs(t, err, ErrBackfillLimit)
+
+		close(release)
+		waitForState(t, b, BackfillCompleted)
+		_, err = b.Start(nil, other, from, to)
+		require.NoError(t, err)
+	})
+
+	t.Run("should cancel backfills when the service stops", func(t *testing.T) {
+		w, _ := newBlockingWriter()
+		b := NewBackfiller(engine, w, settings)
+
+		_, err := b.Start(nil, rule, from, to)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.NoError(t, b.Run(ctx))
+
+		job, err := b.Get(rule.GetKey())
+		require.NoError(t, err)
+		require.Equal(t, BackfillCancelled, job.State)
+
+		_, err = b.Start(nil, rule, from, to)
+		require.ErrorIs(t, err, ErrBackfillerStopped)
+	})
+}
+
+type fakeConditionEvaluator struct {
+	evalRaw func(now time.Time) (*backend.QueryDataResponse, error)
+}
+
+func (f *fakeConditionEvaluator) EvaluateRaw(_ context.Context, now time.Time) (*backend.QueryDataResponse, error) {
+	return f.evalRaw(now)
+}
+
+func (f *fakeConditionEvaluator) Evaluate(ctx context.Context, now time.Time) (eval.Results, error) {
+	return nil, errors.New("not implemented")
+}
@@ -99,7 +99,27 @@ func (r *conditionEvaluator) Evaluate(ctx context.Context, now time.Time) (Resul
 	if err != nil {
 		return nil, err
 	}
-	return EvaluateAlert(response, r.condition, now), nil
+	results := EvaluateAlert(response, r.condition, now)
+	r.setSeverities(results)
+	return results, nil
+}
+
+// setSeverities sets the severity of the alerting results if the condition is a threshold
+// expression with severity levels. The levels are read from the command in the pipeline, so
+// that the model of the condition is not parsed again.
+func (r *conditionEvaluator) setSeverities(results Results) {
+	for _, cmd := range expr.GetCommandsFromPipeline[*expr.SeverityThresholdCommand](r.pipeline) {
+		if cmd.RefID != r.condition.Condition {
+			continue
+		}
+		for i, result := range results {
+			v, ok := result.Values[r.condition.Condition]
+			if result.State != Alerting || !ok || v.Value == nil {
+				continue
+			}
+			results[i].Severity = cmd.Severity(*v.Value)
+		}
+	}
 }
 
 type evaluatorImpl struct {
@@ -260,6 +280,10 @@ type Result struct {
 	// as EvalMatches (from "classic condition"), and in the future from operations
 	// like SSE "math".
 	EvaluationString string
+
+	// Severity is the severity level of an Alerting result if the condition is a threshold
+	// expression with severity levels.
+	Severity string
 }
 
 func NewResultFromError(err error, evaluatedAt time.Time, duration time.Duration) Result {
@@ -1004,6 +1004,51 @@ func TestEvaluate(t *testing.T) {
 	}
 }
 
+func TestEvaluateSeverity(t *testing.T) {
+	cmd, err := expr.NewSeverityThresholdCommand("B", "A", []expr.ThresholdConditionJSON{
+		{Evaluator: expr.ConditionEvalJSON{Type: expr.ThresholdIsAbove, Params: []float64{80}}, Severity: "warning"},
+		{Evaluator: expr.ConditionEvalJSON{Type: expr.ThresholdIsAbove, Params: []float64{90}}, Severity: "critical"},
+	})
+	require.NoError(t, err)
+
+	frame := func(refID, host string, v float64) *data.Frame {
+		return &data.Frame{RefID: refID, Fields: []*data.Field{
+			data.NewField("Value", data.Labels{"host": host}, []*float64{util.Pointer(v)}),
+		}}
+	}
+	resp := backend.QueryDataResponse{Responses: backend.Responses{
+		"B": {Frames: []*data.Frame{frame("B", "a", 0), frame("B", "b", 1), frame("B", "c", 2)}},
+	}}
+
+	ev := conditionEvaluator{
+		pipeline: expr.DataPipeline{&expr.CMDNode{CMDType: expr.TypeThreshold, Command: cmd}},
+		expressionService: &fakeExpressionService{
+			hook: func(ctx context.Context, now time.Time, pipeline expr.DataPipeline) (*backend.QueryDataResponse, error) {
+				return &resp, nil
+			},
+		},
+		condition: models.Condition{Condition: "B"},
+	}
+	results, err := ev.Evaluate(context.Background(), time.Now())
+	require.NoError(t, err)
+
+	severities := make(map[string]string, len(results))
+	for _, r := range results {
+		severities[r.Instance["host"]] = r.Severity
+	}
+	require.Equal(t, map[string]string{"a": "", "b": "warning", "c": "critical"}, severities)
+
+	t.Run("results of other conditions have no severity", func(t *testing.T) {
+		ev.condition = models.Condition{Condition: "C"}
+		resp.Responses = backend.Responses{"C": {Frames: []*data.Frame{frame("C", "a", 1)}}}
+		results, err := ev.Evaluate(context.Background(), time.Now())
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.Equal(t, Alerting, results[0].State)
+		require.Empty(t, results[0].Severity)
+	})
+}
+
 func TestEvaluateRaw(t *testing.T) {
 	t.Run("should timeout if request takes too long", func(t *testing.T) {
 		unexpectedResponse := &backend.QueryDataResponse{}
@@ -32,6 +32,8 @@ type Scheduler struct {
 	Ticker                              *ticker.Metrics
 	EvaluationMissed                    *prometheus.CounterVec
 	SimplifiedEditorRules               *prometheus.GaugeVec
+	ShardingMembers                     prometheus.Gauge
+	ShardingOwnedRuleGroups             prometheus.Gauge
 }
 
 func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
@@ -157,6 +159,20 @@ func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 				Help:      "The number of alert rules that could be considered for evaluation at the next tick.",
 			},
 		),
+		ShardingMembers: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "scheduler_sharding_members",
+				Help:      "The number of instances that share the evaluation of the alert rules.",
+			}),
+		ShardingOwnedRuleGroups: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "scheduler_sharding_owned_rule_groups",
+				Help:      "The number of rule groups evaluated by this instance when the evaluation is sharded.",
+			}),
 		SchedulableAlertRulesHash: promauto.With(r).NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: Namespace,
@@ -270,6 +270,17 @@ type AlertRule struct {
 	IsPaused             bool
 	NotificationSettings []NotificationSettings
 	Metadata             AlertRuleMetadata
+	// UpdatedBy is the identity that made the last change to the rule. It is nil if it is not known.
+	UpdatedBy *UserUID
+}
+
+// UserUID is the typed UID of an identity, e.g. user:abc.
+type UserUID string
+
+// NewUserUID returns the UID of the requester.
+func NewUserUID(requester interface{ GetUID() string }) *UserUID {
+	uid := UserUID(requester.GetUID())
+	return &uid
 }
 
 type AlertRuleMetadata struct {
@@ -876,8 +887,17 @@ type Record struct {
 	Metric string
 	// From contains a query RefID, indicating which expression node is the output of the recording rule.
 	From string
+	// Target is the name of the writer the results are sent to. If empty, the default writer is used.
+	Target string `json:",omitempty"`
 }
 
+const (
+	RecordTargetPrometheus = "prometheus"
+	RecordTargetInfluxDB   = "influxdb"
+	RecordTargetSQL        = "sql"
+	RecordTargetLoki       = "loki"
+)
+
 func (r *Record) Fingerprint() data.Fingerprint {
 	h := fnv.New64()
 
@@ -890,6 +910,10 @@ func (r *Record) Fingerprint() data.Fingerprint {
 
 	writeString(r.Metric)
 	writeString(r.From)
+	// the target is optional, so it does not change the fingerprint of the rules that do not set it
+	if r.Target != "" {
+		writeString(r.Target)
+	}
 	return data.Fingerprint(h.Sum64())
 }
 
@@ -18,6 +18,17 @@ func (s Silence) GetRuleUID() *string {
 	return getRuleUIDLabelValue(s.Silence)
 }
 
+func (s *Silence) ResourceType() string {
+	return "silence"
+}
+
+func (s *Silence) ResourceID() string {
+	if s.ID == nil {
+		return ""
+	}
+	return *s.ID
+}
+
 // getRuleUIDLabelValue returns the value of the RuleUIDLabel matcher in the given silence, if it exists.
 func getRuleUIDLabelValue(silence notify.Silence) *string {
 	for _, m := range silence.Matchers {
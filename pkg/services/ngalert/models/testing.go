@@ -508,6 +508,12 @@ func (a *AlertRuleMutators) WithRandomRecordingRules() AlertRuleMutator {
 	}
 }
 
+func (a *AlertRuleMutators) WithUpdatedBy(uid *UserUID) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.UpdatedBy = uid
+	}
+}
+
 func (a *AlertRuleMutators) WithAllRecordingRules() AlertRuleMutator {
 	return func(rule *AlertRule) {
 		ConvertToRecordingRule(rule)
@@ -624,6 +630,11 @@ func CopyRule(r *AlertRule, mutators ...AlertRuleMutator) *AlertRule {
 		Record:          r.Record,
 	}
 
+	if r.UpdatedBy != nil {
+		updatedBy := *r.UpdatedBy
+		result.UpdatedBy = &updatedBy
+	}
+
 	if r.DashboardUID != nil {
 		dash := *r.DashboardUID
 		result.DashboardUID = &dash
@@ -30,6 +30,7 @@ import (
 	ac "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
@@ -138,6 +139,7 @@ type AlertNG struct {
 	renderService       rendering.Service
 	ImageService        image.ImageService
 	RecordingWriter     schedule.RecordingWriter
+	backfiller          *backtesting.Backfiller
 	schedule            schedule.ScheduleService
 	stateManager        *state.Manager
 	folderService       folder.Service
@@ -367,7 +369,7 @@ func (ng *AlertNG) init() error {
 		// Force-disable the feature if the feature toggle is not on - sets us up for feature toggle removal.
 		ng.Cfg.UnifiedAlerting.RecordingRules.Enabled = false
 	}
-	recordingWriter, err := createRecordingWriter(ng.FeatureToggles, ng.Cfg.UnifiedAlerting.RecordingRules, ng.httpClientProvider, clk, ng.Metrics.GetRemoteWriterMetrics())
+	recordingWriter, err := createRecordingWriter(ng.FeatureToggles, ng.Cfg.UnifiedAlerting.RecordingRules, ng.httpClientProvider, ng.SQLStore, clk, ng.Metrics.GetRemoteWriterMetrics())
 	if err != nil {
 		return fmt.Errorf("failed to initialize recording writer: %w", err)
 	}
@@ -380,6 +382,8 @@ func (ng *AlertNG) init() error {
 		MinRuleInterval:      ng.Cfg.UnifiedAlerting.MinInterval,
 		DisableGrafanaFolder: ng.Cfg.UnifiedAlerting.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel),
 		JitterEvaluations:    schedule.JitterStrategyFrom(ng.Cfg.UnifiedAlerting, ng.FeatureToggles),
+		DeduplicateQueries:   ng.Cfg.UnifiedAlerting.DeduplicateQueries,
+		EvaluationTimeout:    ng.Cfg.UnifiedAlerting.EvaluationTimeout,
 		AppURL:               appUrl,
 		EvaluatorFactory:     evalFactory,
 		RuleStore:            ng.store,
@@ -390,6 +394,14 @@ func (ng *AlertNG) init() error {
 		Log:                  log.New("ngalert.scheduler"),
 		RecordingWriter:      ng.RecordingWriter,
 	}
+	if sharding := ng.Cfg.UnifiedAlerting.EvaluationSharding; sharding.Enabled {
+		schedCfg.Sharding = &schedule.ShardingCfg{
+			MemberID:          sharding.InstanceID,
+			HeartbeatInterval: sharding.HeartbeatInterval,
+			HeartbeatTimeout:  sharding.HeartbeatTimeout,
+			Store:             ng.store,
+		}
+	}
 
 	// There are a set of feature toggles available that act as short-circuits for common configurations.
 	// If any are set, override the config accordingly.
@@ -416,7 +428,11 @@ func (ng *AlertNG) init() error {
 	}
 	logger := log.New("ngalert.state.manager.persist")
 	statePersister := state.NewSyncStatePersisiter(logger, cfg)
-	if ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStatePeriodic) {
+	if ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStatePeriodic) && schedCfg.Sharding != nil {
+		// The periodic save replaces all instances in the database with the ones in the cache,
+		// which only contains the rules evaluated by this instance when the evaluation is sharded.
+		ng.Log.Warn("Periodic save of the alert state is not supported with evaluation sharding, the state is saved after every evaluation instead")
+	} else if ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStatePeriodic) {
 		ticker := clock.New().Ticker(ng.Cfg.UnifiedAlerting.StatePeriodicSaveInterval)
 		statePersister = state.NewAsyncStatePersister(logger, ticker, cfg)
 	}
@@ -464,7 +480,9 @@ func (ng *AlertNG) init() error {
 		int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
 		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()),
 		ng.Cfg.UnifiedAlerting.RulesPerRuleGroupLimit, ng.Log, notifier.NewNotificationSettingsValidationService(ng.store),
-		ac.NewRuleService(ng.accesscontrol))
+		ng.RecordingWriter, ac.NewRuleService(ng.accesscontrol))
+
+	ng.backfiller = backtesting.NewBackfiller(backtesting.NewEngine(appUrl, evalFactory, ng.tracer), ng.RecordingWriter, ng.Cfg.UnifiedAlerting.RecordingRules.Backfill)
 
 	ng.Api = &api.API{
 		Cfg:                  ng.Cfg,
@@ -496,6 +514,8 @@ func (ng *AlertNG) init() error {
 		Historian:            history,
 		Hooks:                api.NewHooks(ng.Log),
 		Tracer:               ng.tracer,
+		RecordingWriter:      ng.RecordingWriter,
+		Backfiller:           ng.backfiller,
 	}
 	ng.Api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
@@ -541,6 +561,9 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.AlertsRouter.Run(subCtx)
 	})
+	children.Go(func() error {
+		return ng.backfiller.Run(subCtx)
+	})
 
 	if ng.Cfg.UnifiedAlerting.ExecuteAlerts {
 		// Only Warm() the state manager if we are actually executing alerts.
@@ -692,12 +715,43 @@ func createRemoteAlertmanager(cfg remote.AlertmanagerConfig, kvstore kvstore.KVS
 	return remote.NewAlertmanager(cfg, notifier.NewFileStore(cfg.OrgID, kvstore), decryptFn, autogenFn, m, tracer)
 }
 
-func createRecordingWriter(featureToggles featuremgmt.FeatureToggles, settings setting.RecordingRuleSettings, httpClientProvider httpclient.Provider, clock clock.Clock, m *metrics.RemoteWriter) (schedule.RecordingWriter, error) {
+func createRecordingWriter(featureToggles featuremgmt.FeatureToggles, settings setting.RecordingRuleSettings, httpClientProvider httpclient.Provider, store db.DB, clock clock.Clock, m *metrics.RemoteWriter) (schedule.RecordingWriter, error) {
 	logger := log.New("ngalert.writer")
 
-	if settings.Enabled {
-		return writer.NewPrometheusWriter(settings, httpClientProvider, clock, logger, m)
+	if !settings.Enabled {
+		return writer.NoopWriter{}, nil
+	}
+
+	writers := make(map[string]writer.Writer)
+	// The Prometheus writer is configured in the main section, so it is the only one that does not have its own enabled flag.
+	if settings.URL != "" || settings.DefaultTarget == models.RecordTargetPrometheus {
+		w, err := writer.NewPrometheusWriter(settings, httpClientProvider, clock, logger, m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Prometheus writer: %w", err)
+		}
+		writers[models.RecordTargetPrometheus] = w
+	}
+	if settings.InfluxDB.Enabled {
+		w, err := writer.NewInfluxDBWriter(settings.InfluxDB, httpClientProvider, clock, logger, m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize InfluxDB writer: %w", err)
+		}
+		writers[models.RecordTargetInfluxDB] = w
+	}
+	if settings.SQL.Enabled {
+		writers[models.RecordTargetSQL] = writer.NewSQLWriter(settings.SQL, store, clock, logger, m)
+	}
+	if settings.Loki.Enabled {
+		w, err := writer.NewLokiWriter(settings.Loki, httpClientProvider, clock, logger, m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Loki writer: %w", err)
+		}
+		writers[models.RecordTargetLoki] = w
 	}
 
-	return writer.NoopWriter{}, nil
+	router, err := writer.NewRouter(settings.DefaultTarget, writers)
+	if err != nil {
+		return nil, err
+	}
+	return router, nil
 }
@@ -0,0 +1,281 @@
+package prom
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	queryRefID     = "query"
+	mathRefID      = "prometheus_math"
+	thresholdRefID = "threshold"
+
+	defaultFromTimeRange = 10 * time.Minute
+)
+
+// valueTemplateRegexp matches the references to the value of the alert in templates.
+// In Grafana, $value is the value of the condition, which is always 1 for converted rules.
+var valueTemplateRegexp = regexp.MustCompile(`\$value\b|\.Value\b`)
+
+// Config controls how Prometheus rules are converted to Grafana rules.
+type Config struct {
+	// DatasourceUID is the data source that the converted rules query.
+	DatasourceUID string
+	// DatasourceType is the type of the data source, either prometheus or loki.
+	DatasourceType string
+	// DefaultInterval is the evaluation interval of the groups that do not define one.
+	DefaultInterval time.Duration
+	// FromTimeRange is how far back the queries look. Defaults to 10 minutes.
+	FromTimeRange time.Duration
+	// IsPaused creates the rules paused, e.g. while the same rules are still evaluated by Prometheus.
+	IsPaused bool
+}
+
+// Warning describes a part of a Prometheus rule that could not be converted as is.
+type Warning struct {
+	Group   string
+	Rule    string
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.Rule == "" {
+		return fmt.Sprintf("rule group %q: %s", w.Group, w.Message)
+	}
+	return fmt.Sprintf("rule group %q, rule %q: %s", w.Group, w.Rule, w.Message)
+}
+
+// Converter converts Prometheus rule groups to Grafana rule groups.
+type Converter struct {
+	cfg Config
+}
+
+func NewConverter(cfg Config) (*Converter, error) {
+	if cfg.DatasourceUID == "" {
+		return nil, fmt.Errorf("data source UID is required")
+	}
+	if cfg.DatasourceType != "prometheus" && cfg.DatasourceType != "loki" {
+		return nil, fmt.Errorf("unsupported data source type %q, expected prometheus or loki", cfg.DatasourceType)
+	}
+	if cfg.DefaultInterval <= 0 {
+		return nil, fmt.Errorf("default interval must be positive")
+	}
+	if cfg.FromTimeRange == 0 {
+		cfg.FromTimeRange = defaultFromTimeRange
+	}
+	return &Converter{cfg: cfg}, nil
+}
+
+// PrometheusRulesFileToGrafana converts all groups of the file to Grafana rule groups in the folder.
+// Grafana requires the titles of the rules to be unique in a folder, so duplicated alert names get a numeric suffix.
+func (c *Converter) PrometheusRulesFileToGrafana(orgID int64, namespaceUID string, file *PrometheusRulesFile) ([]*models.AlertRuleGroup, []Warning, error) {
+	var warnings []Warning
+	groups := make([]*models.AlertRuleGroup, 0, len(file.Groups))
+	titles := make(map[string]int)
+	for _, g := range file.Groups {
+		group, groupWarnings, err := c.PrometheusRuleGroupToGrafana(orgID, namespaceUID, g)
+		if err != nil {
+			return nil, nil, err
+		}
+		warnings = append(warnings, groupWarnings...)
+		for i := range group.Rules {
+			rule := &group.Rules[i]
+			titles[rule.Title]++
+			if n := titles[rule.Title]; n > 1 {
+				title := fmt.Sprintf("%s (%d)", rule.Title, n)
+				warnings = append(warnings, Warning{Group: g.Name, Rule: rule.Title, Message: fmt.Sprintf("the name is used by another rule, the rule is renamed to %q", title)})
+				rule.Title = title
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups, warnings, nil
+}
+
+// PrometheusRuleGroupToGrafana converts a Prometheus rule group to a Grafana rule group in the folder.
+// The rules do not have UIDs. The parts of the rules that Grafana does not support are dropped and reported as warnings.
+func (c *Converter) PrometheusRuleGroupToGrafana(orgID int64, namespaceUID string, group PrometheusRuleGroup) (*models.AlertRuleGroup, []Warning, error) {
+	var warnings []Warning
+	warn := func(rule, format string, args ...any) {
+		warnings = append(warnings, Warning{Group: group.Name, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	interval := time.Duration(group.Interval)
+	if interval == 0 {
+		interval = c.cfg.DefaultInterval
+	}
+	var queryOffset time.Duration
+	if group.QueryOffset != nil {
+		queryOffset = time.Duration(*group.QueryOffset)
+	}
+	if group.Limit > 0 {
+		warn("", "the limit of alerts and series is not supported and is ignored")
+	}
+
+	result := &models.AlertRuleGroup{
+		Title:     group.Name,
+		FolderUID: namespaceUID,
+		Interval:  int64(interval.Seconds()),
+		Rules:     make([]models.AlertRule, 0, len(group.Rules)),
+	}
+	for i, r := range group.Rules {
+		if c.cfg.DatasourceType == "prometheus" {
+			if _, err := parser.ParseExpr(r.Expr); err != nil {
+				return nil, nil, fmt.Errorf("rule group %q, rule %q: could not parse expression: %w", group.Name, r.Name(), err)
+			}
+		}
+		if r.KeepFiringFor != nil && *r.KeepFiringFor > 0 {
+			warn(r.Name(), "'keep_firing_for' is not supported and is ignored")
+		}
+
+		labels := make(map[string]string, len(group.Labels)+len(r.Labels))
+		maps.Copy(labels, group.Labels)
+		maps.Copy(labels, r.Labels)
+		for _, k := range sortedKeys(labels) {
+			if valueTemplateRegexp.MatchString(labels[k]) {
+				warn(r.Name(), "label %q refers to the value of the alert, which is the value of the condition in Grafana. Use $values.%s.Value instead", k, queryRefID)
+			}
+		}
+		for _, k := range sortedKeys(r.Annotations) {
+			if valueTemplateRegexp.MatchString(r.Annotations[k]) {
+				warn(r.Name(), "annotation %q refers to the value of the alert, which is the value of the condition in Grafana. Use $values.%s.Value instead", k, queryRefID)
+			}
+		}
+
+		rule := models.AlertRule{
+			OrgID:           orgID,
+			Title:           r.Name(),
+			IntervalSeconds: result.Interval,
+			NamespaceUID:    namespaceUID,
+			RuleGroup:       group.Name,
+			RuleGroupIndex:  i + 1,
+			Labels:          labels,
+			Annotations:     r.Annotations,
+			IsPaused:        c.cfg.IsPaused,
+		}
+		query, err := c.createQuery(r.Expr, queryOffset)
+		if err != nil {
+			return nil, nil, err
+		}
+		if r.Record != "" {
+			rule.Data = []models.AlertQuery{query}
+			rule.Record = &models.Record{Metric: r.Record, From: queryRefID}
+		} else {
+			math, err := createMathExpression()
+			if err != nil {
+				return nil, nil, err
+			}
+			threshold, err := createThresholdExpression()
+			if err != nil {
+				return nil, nil, err
+			}
+			rule.Data = []models.AlertQuery{query, math, threshold}
+			rule.Condition = thresholdRefID
+			// A Prometheus rule does not fire when the query returns nothing or fails.
+			rule.NoDataState = models.OK
+			rule.ExecErrState = models.ErrorErrState
+			if r.For != nil {
+				rule.For = time.Duration(*r.For)
+			}
+		}
+		result.Rules = append(result.Rules, rule)
+	}
+	return result, warnings, nil
+}
+
+// RuleUID returns a stable UID for the converted rule, so that the rule is updated when the same rules are converted again.
+// Titles of rules are unique in a folder, so the rule keeps its UID when it is moved to another group.
+func RuleUID(orgID int64, folder, title string) string {
+	sum := fnv.New64()
+	for _, str := range []string{strconv.FormatInt(orgID, 10), folder, title} {
+		_, _ = sum.Write([]byte(str))
+		_, _ = sum.Write([]byte{255})
+	}
+	return fmt.Sprintf("prom-%016x", sum.Sum64())
+}
+
+func (c *Converter) createQuery(expression string, offset time.Duration) (models.AlertQuery, error) {
+	model, err := json.Marshal(map[string]any{
+		"datasource": map[string]any{
+			"type": c.cfg.DatasourceType,
+			"uid":  c.cfg.DatasourceUID,
+		},
+		"expr":    expression,
+		"instant": true,
+		"range":   false,
+		"refId":   queryRefID,
+	})
+	if err != nil {
+		return models.AlertQuery{}, err
+	}
+	return models.AlertQuery{
+		RefID:         queryRefID,
+		DatasourceUID: c.cfg.DatasourceUID,
+		RelativeTimeRange: models.RelativeTimeRange{
+			From: models.Duration(c.cfg.FromTimeRange + offset),
+			To:   models.Duration(offset),
+		},
+		Model: model,
+	}, nil
+}
+
+// createMathExpression returns an expression that turns every series returned by the query into 1,
+// because a Prometheus alert fires for every series that the query returns regardless of its value.
+func createMathExpression() (models.AlertQuery, error) {
+	return createExpression(mathRefID, map[string]any{
+		"type":       "math",
+		"expression": fmt.Sprintf("is_number($%[1]s) || is_nan($%[1]s) || is_inf($%[1]s)", queryRefID),
+	})
+}
+
+func createThresholdExpression() (models.AlertQuery, error) {
+	return createExpression(thresholdRefID, map[string]any{
+		"type":       "threshold",
+		"expression": mathRefID,
+		"conditions": []any{
+			map[string]any{
+				"evaluator": map[string]any{
+					"params": []float64{0},
+					"type":   "gt",
+				},
+			},
+		},
+	})
+}
+
+func createExpression(refID string, props map[string]any) (models.AlertQuery, error) {
+	props["refId"] = refID
+	props["datasource"] = map[string]any{
+		"type": expr.DatasourceType,
+		"uid":  expr.DatasourceUID,
+	}
+	model, err := json.Marshal(props)
+	if err != nil {
+		return models.AlertQuery{}, err
+	}
+	return models.AlertQuery{
+		RefID:         refID,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         model,
+	}, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,235 @@
+package prom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const testRulesFile = `
+groups:
+  - name: node
+    interval: 30s
+    query_offset: 1m
+    labels:
+      team: infra
+    rules:
+      - alert: HighCPU
+        expr: avg by (instance) (rate(node_cpu_seconds_total{mode!="idle"}[5m])) > 0.9
+        for: 5m
+        labels:
+          severity: warning
+        annotations:
+          summary: CPU usage on {{ $labels.instance }} is {{ $value | humanizePercentage }}
+      - alert: HighCPU
+        expr: avg by (instance) (rate(node_cpu_seconds_total{mode!="idle"}[5m])) > 0.98
+        keep_firing_for: 10m
+        labels:
+          severity: critical
+      - record: instance:node_cpu:rate5m
+        expr: avg by (instance) (rate(node_cpu_seconds_total{mode!="idle"}[5m]))
+  - name: empty
+    limit: 10
+    rules: []
+`
+
+func newTestConverter(t *testing.T) *Converter {
+	t.Helper()
+	c, err := NewConverter(Config{
+		DatasourceUID:   "prom-uid",
+		DatasourceType:  "prometheus",
+		DefaultInterval: time.Minute,
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func TestParseRulesFile(t *testing.T) {
+	t.Run("parses the rule groups", func(t *testing.T) {
+		file, err := ParseRulesFile([]byte(testRulesFile))
+		require.NoError(t, err)
+		require.Len(t, file.Groups, 2)
+		require.Equal(t, "node", file.Groups[0].Name)
+		require.Len(t, file.Groups[0].Rules, 3)
+		require.Equal(t, "instance:node_cpu:rate5m", file.Groups[0].Rules[2].Name())
+	})
+
+	testCases := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{
+			name:          "unknown field",
+			content:       "groups:\n  - name: a\n    evaluation_interval: 1m\n    rules: []",
+			expectedError: "field evaluation_interval not found",
+		},
+		{
+			name:          "group without name",
+			content:       "groups:\n  - rules: []",
+			expectedError: "rule group name cannot be empty",
+		},
+		{
+			name:          "duplicated group",
+			content:       "groups:\n  - name: a\n  - name: a",
+			expectedError: `rule group "a" is defined more than once`,
+		},
+		{
+			name:          "rule without alert and record",
+			content:       "groups:\n  - name: a\n    rules:\n      - expr: up",
+			expectedError: "one of 'alert' or 'record' must be set",
+		},
+		{
+			name:          "rule without expression",
+			content:       "groups:\n  - name: a\n    rules:\n      - alert: Down",
+			expectedError: "field 'expr' must be set",
+		},
+		{
+			name:          "recording rule with invalid metric name",
+			content:       "groups:\n  - name: a\n    rules:\n      - record: 1metric\n        expr: up",
+			expectedError: "invalid recording rule name",
+		},
+		{
+			name:          "recording rule with for",
+			content:       "groups:\n  - name: a\n    rules:\n      - record: metric\n        expr: up\n        for: 1m",
+			expectedError: "invalid field 'for' in recording rule",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseRulesFile([]byte(tc.content))
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestNewConverter(t *testing.T) {
+	_, err := NewConverter(Config{DatasourceType: "prometheus", DefaultInterval: time.Minute})
+	require.Error(t, err)
+	_, err = NewConverter(Config{DatasourceUID: "uid", DatasourceType: "influxdb", DefaultInterval: time.Minute})
+	require.ErrorContains(t, err, "unsupported data source type")
+	_, err = NewConverter(Config{DatasourceUID: "uid", DatasourceType: "loki"})
+	require.Error(t, err)
+}
+
+func TestPrometheusRulesFileToGrafana(t *testing.T) {
+	file, err := ParseRulesFile([]byte(testRulesFile))
+	require.NoError(t, err)
+
+	groups, warnings, err := newTestConverter(t).PrometheusRulesFileToGrafana(1, "folder-uid", file)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+
+	group := groups[0]
+	require.Equal(t, "node", group.Title)
+	require.Equal(t, "folder-uid", group.FolderUID)
+	require.EqualValues(t, 30, group.Interval)
+	require.Len(t, group.Rules, 3)
+
+	t.Run("alert rules", func(t *testing.T) {
+		rule := group.Rules[0]
+		require.Equal(t, "HighCPU", rule.Title)
+		require.EqualValues(t, 1, rule.OrgID)
+		require.Equal(t, "folder-uid", rule.NamespaceUID)
+		require.Equal(t, "node", rule.RuleGroup)
+		require.Equal(t, 1, rule.RuleGroupIndex)
+		require.EqualValues(t, 30, rule.IntervalSeconds)
+		require.Equal(t, 5*time.Minute, rule.For)
+		require.Equal(t, map[string]string{"team": "infra", "severity": "warning"}, rule.Labels)
+		require.Equal(t, "CPU usage on {{ $labels.instance }} is {{ $value | humanizePercentage }}", rule.Annotations["summary"])
+		require.Equal(t, models.OK, rule.NoDataState)
+		require.Equal(t, models.ErrorErrState, rule.ExecErrState)
+		require.Nil(t, rule.Record)
+		require.False(t, rule.IsPaused)
+
+		require.Equal(t, thresholdRefID, rule.Condition)
+		require.Len(t, rule.Data, 3)
+		query := rule.Data[0]
+		require.Equal(t, queryRefID, query.RefID)
+		require.Equal(t, "prom-uid", query.DatasourceUID)
+		require.Equal(t, models.Duration(11*time.Minute), query.RelativeTimeRange.From)
+		require.Equal(t, models.Duration(time.Minute), query.RelativeTimeRange.To)
+		var model map[string]any
+		require.NoError(t, json.Unmarshal(query.Model, &model))
+		require.Equal(t, `avg by (instance) (rate(node_cpu_seconds_total{mode!="idle"}[5m])) > 0.9`, model["expr"])
+		require.Equal(t, true, model["instant"])
+		require.Equal(t, map[string]any{"type": "prometheus", "uid": "prom-uid"}, model["datasource"])
+
+		for _, e := range rule.Data[1:] {
+			require.Equal(t, expr.DatasourceUID, e.DatasourceUID)
+			isExpr, err := e.IsExpression()
+			require.NoError(t, err)
+			require.True(t, isExpr)
+		}
+		require.Equal(t, mathRefID, rule.Data[1].RefID)
+		require.Equal(t, thresholdRefID, rule.Data[2].RefID)
+	})
+
+	t.Run("duplicated names are renamed", func(t *testing.T) {
+		require.Equal(t, "HighCPU (2)", group.Rules[1].Title)
+		require.Equal(t, "critical", group.Rules[1].Labels["severity"])
+		require.Zero(t, group.Rules[1].For)
+	})
+
+	t.Run("recording rules", func(t *testing.T) {
+		rule := group.Rules[2]
+		require.Equal(t, "instance:node_cpu:rate5m", rule.Title)
+		require.Equal(t, &models.Record{Metric: "instance:node_cpu:rate5m", From: queryRefID}, rule.Record)
+		require.Len(t, rule.Data, 1)
+		require.Equal(t, queryRefID, rule.Data[0].RefID)
+		require.Equal(t, map[string]string{"team": "infra"}, rule.Labels)
+	})
+
+	t.Run("groups without interval use the default one", func(t *testing.T) {
+		require.Equal(t, "empty", groups[1].Title)
+		require.EqualValues(t, 60, groups[1].Interval)
+		require.Empty(t, groups[1].Rules)
+	})
+
+	t.Run("parts that do not translate are reported", func(t *testing.T) {
+		messages := make([]string, 0, len(warnings))
+		for _, w := range warnings {
+			messages = append(messages, w.String())
+		}
+		require.Equal(t, []string{
+			`rule group "node", rule "HighCPU": annotation "summary" refers to the value of the alert, which is the value of the condition in Grafana. Use $values.query.Value instead`,
+			`rule group "node", rule "HighCPU": 'keep_firing_for' is not supported and is ignored`,
+			`rule group "node", rule "HighCPU": the name is used by another rule, the rule is renamed to "HighCPU (2)"`,
+			`rule group "empty": the limit of alerts and series is not supported and is ignored`,
+		}, messages)
+	})
+}
+
+func TestPrometheusRuleGroupToGrafana(t *testing.T) {
+	t.Run("rules are paused if configured", func(t *testing.T) {
+		c, err := NewConverter(Config{DatasourceUID: "loki-uid", DatasourceType: "loki", DefaultInterval: time.Minute, IsPaused: true})
+		require.NoError(t, err)
+		group, _, err := c.PrometheusRuleGroupToGrafana(1, "folder", PrometheusRuleGroup{
+			Name:  "logs",
+			Rules: []PrometheusRule{{Alert: "Errors", Expr: `sum(rate({app="api"} |= "error" [5m])) > 1`}},
+		})
+		require.NoError(t, err)
+		require.True(t, group.Rules[0].IsPaused)
+	})
+
+	t.Run("invalid PromQL is rejected", func(t *testing.T) {
+		_, _, err := newTestConverter(t).PrometheusRuleGroupToGrafana(1, "folder", PrometheusRuleGroup{
+			Name:  "broken",
+			Rules: []PrometheusRule{{Alert: "Broken", Expr: `sum(rate(`}},
+		})
+		require.ErrorContains(t, err, "could not parse expression")
+	})
+}
+
+func TestRuleUID(t *testing.T) {
+	uid := RuleUID(1, "prometheus", "InstanceDown")
+	require.Equal(t, uid, RuleUID(1, "prometheus", "InstanceDown"))
+	require.NotEqual(t, uid, RuleUID(2, "prometheus", "InstanceDown"))
+	require.NotEqual(t, uid, RuleUID(1, "other", "InstanceDown"))
+	require.LessOrEqual(t, len(uid), 40)
+}
@@ -0,0 +1,105 @@
+package prom
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	prommodel "github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+)
+
+// PrometheusRulesFile is a rule file in the format of Prometheus, see
+// https://prometheus.io/docs/prometheus/latest/configuration/alerting_rules/
+type PrometheusRulesFile struct {
+	Groups []PrometheusRuleGroup `yaml:"groups"`
+}
+
+type PrometheusRuleGroup struct {
+	Name        string              `yaml:"name"`
+	Interval    prommodel.Duration  `yaml:"interval,omitempty"`
+	QueryOffset *prommodel.Duration `yaml:"query_offset,omitempty"`
+	Limit       int                 `yaml:"limit,omitempty"`
+	Labels      map[string]string   `yaml:"labels,omitempty"`
+	Rules       []PrometheusRule    `yaml:"rules"`
+}
+
+type PrometheusRule struct {
+	Alert         string              `yaml:"alert,omitempty"`
+	Record        string              `yaml:"record,omitempty"`
+	Expr          string              `yaml:"expr"`
+	For           *prommodel.Duration `yaml:"for,omitempty"`
+	KeepFiringFor *prommodel.Duration `yaml:"keep_firing_for,omitempty"`
+	Labels        map[string]string   `yaml:"labels,omitempty"`
+	Annotations   map[string]string   `yaml:"annotations,omitempty"`
+}
+
+// ParseRulesFile reads a Prometheus rule file in YAML or JSON format and validates it.
+// Unknown fields are rejected, the same way Prometheus does.
+func ParseRulesFile(content []byte) (*PrometheusRulesFile, error) {
+	var file PrometheusRulesFile
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid rule file: %w", err)
+	}
+	if err := file.Validate(); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// Validate checks the structure of the groups and rules of the file.
+// The expressions are not parsed because the file can contain Loki rules as well.
+func (f *PrometheusRulesFile) Validate() error {
+	var errs []error
+	names := make(map[string]struct{}, len(f.Groups))
+	for _, g := range f.Groups {
+		if g.Name == "" {
+			errs = append(errs, errors.New("rule group name cannot be empty"))
+			continue
+		}
+		if _, ok := names[g.Name]; ok {
+			errs = append(errs, fmt.Errorf("rule group %q is defined more than once", g.Name))
+		}
+		names[g.Name] = struct{}{}
+		for i, r := range g.Rules {
+			if err := r.validate(); err != nil {
+				errs = append(errs, fmt.Errorf("rule group %q, rule %d: %w", g.Name, i+1, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r PrometheusRule) validate() error {
+	switch {
+	case r.Alert == "" && r.Record == "":
+		return errors.New("one of 'alert' or 'record' must be set")
+	case r.Alert != "" && r.Record != "":
+		return errors.New("only one of 'alert' and 'record' can be set")
+	case r.Expr == "":
+		return errors.New("field 'expr' must be set")
+	}
+	if r.Record != "" {
+		if len(r.Annotations) > 0 {
+			return errors.New("invalid field 'annotations' in recording rule")
+		}
+		if r.For != nil {
+			return errors.New("invalid field 'for' in recording rule")
+		}
+		if !prommodel.IsValidMetricName(prommodel.LabelValue(r.Record)) {
+			return fmt.Errorf("invalid recording rule name: %s", r.Record)
+		}
+	}
+	return nil
+}
+
+// Name returns the name of the alert or the metric of the recording rule.
+func (r PrometheusRule) Name() string {
+	if r.Record != "" {
+		return r.Record
+	}
+	return r.Alert
+}
@@ -33,6 +33,11 @@ type NotificationSettingsValidatorProvider interface {
 	Validator(ctx context.Context, orgID int64) (notifier.NotificationSettingsValidator, error)
 }
 
+// RecordTargetValidator validates the targets of recording rules against the configured writers.
+type RecordTargetValidator interface {
+	ValidateTarget(target string) error
+}
+
 type AlertRuleService struct {
 	defaultIntervalSeconds int64
 	baseIntervalSeconds    int64
@@ -44,6 +49,7 @@ type AlertRuleService struct {
 	xact                   TransactionManager
 	log                    log.Logger
 	nsValidatorProvider    NotificationSettingsValidatorProvider
+	recordTargets          RecordTargetValidator
 	authz                  ruleAccessControlService
 }
 
@@ -57,6 +63,7 @@ func NewAlertRuleService(ruleStore RuleStore,
 	rulesPerRuleGroupLimit int64,
 	log log.Logger,
 	ns NotificationSettingsValidatorProvider,
+	recordTargets RecordTargetValidator,
 	authz RuleAccessControlService,
 ) *AlertRuleService {
 	return &AlertRuleService{
@@ -70,6 +77,7 @@ func NewAlertRuleService(ruleStore RuleStore,
 		xact:                   xact,
 		log:                    log,
 		nsValidatorProvider:    ns,
+		recordTargets:          recordTargets,
 		authz:                  newRuleAccessControlService(authz),
 	}
 }
@@ -220,6 +228,9 @@ func (service *AlertRuleService) CreateAlertRule(ctx context.Context, user ident
 		return models.AlertRule{}, err
 	}
 	rule.Updated = time.Now()
+	if err := service.validateRecordTarget(&rule); err != nil {
+		return models.AlertRule{}, err
+	}
 	if len(rule.NotificationSettings) > 0 {
 		validator, err := service.nsValidatorProvider.Validator(ctx, rule.OrgID)
 		if err != nil {
@@ -231,6 +242,7 @@ func (service *AlertRuleService) CreateAlertRule(ctx context.Context, user ident
 			}
 		}
 	}
+	rule.UpdatedBy = models.NewUserUID(user)
 	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		ids, err := service.ruleStore.InsertAlertRules(ctx, []models.AlertRule{
 			rule,
@@ -321,6 +333,7 @@ func (service *AlertRuleService) UpdateRuleGroup(ctx context.Context, user ident
 			}
 			newRule := *rule
 			newRule.IntervalSeconds = intervalSeconds
+			newRule.UpdatedBy = models.NewUserUID(user)
 			updateRules = append(updateRules, models.UpdateRule{
 				Existing: rule,
 				New:      newRule,
@@ -389,6 +402,17 @@ func (service *AlertRuleService) ReplaceRuleGroup(ctx context.Context, user iden
 		}
 	}
 
+	for _, rule := range delta.New {
+		if err := service.validateRecordTarget(rule); err != nil {
+			return err
+		}
+	}
+	for _, d := range delta.Update {
+		if err := service.validateRecordTarget(d.New); err != nil {
+			return err
+		}
+	}
+
 	newOrUpdatedNotificationSettings := delta.NewOrUpdatedNotificationSettings()
 	if len(newOrUpdatedNotificationSettings) > 0 {
 		validator, err := service.nsValidatorProvider.Validator(ctx, delta.GroupKey.OrgID)
@@ -477,6 +501,7 @@ func (service *AlertRuleService) calcDelta(ctx context.Context, user identity.Re
 }
 
 func (service *AlertRuleService) persistDelta(ctx context.Context, user identity.Requester, delta *store.GroupDelta, provenance models.Provenance) error {
+	updatedBy := models.NewUserUID(user)
 	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		// Delete first as this could prevent future unique constraint violations.
 		if len(delta.Delete) > 0 {
@@ -506,6 +531,7 @@ func (service *AlertRuleService) persistDelta(ctx context.Context, user identity
 				if canUpdate := validation.CanUpdateProvenanceInRuleGroup(storedProvenance, provenance); !canUpdate {
 					return fmt.Errorf("cannot update with provided provenance '%s', needs '%s'", provenance, storedProvenance)
 				}
+				update.New.UpdatedBy = updatedBy
 				updates = append(updates, models.UpdateRule{
 					Existing: update.Existing,
 					New:      *update.New,
@@ -522,6 +548,11 @@ func (service *AlertRuleService) persistDelta(ctx context.Context, user identity
 		}
 
 		if len(delta.New) > 0 {
+			for _, rule := range delta.New {
+				if rule != nil {
+					rule.UpdatedBy = updatedBy
+				}
+			}
 			uids, err := service.ruleStore.InsertAlertRules(ctx, withoutNilAlertRules(delta.New))
 			if err != nil {
 				return fmt.Errorf("failed to insert alert rules: %w", err)
@@ -587,6 +618,9 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, user ident
 	if storedProvenance != provenance && storedProvenance != models.ProvenanceNone {
 		return models.AlertRule{}, fmt.Errorf("cannot change provenance from '%s' to '%s'", storedProvenance, provenance)
 	}
+	if err := service.validateRecordTarget(&rule); err != nil {
+		return models.AlertRule{}, err
+	}
 	if len(rule.NotificationSettings) > 0 {
 		validator, err := service.nsValidatorProvider.Validator(ctx, rule.OrgID)
 		if err != nil {
@@ -599,6 +633,7 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, user ident
 		}
 	}
 	rule.Updated = time.Now()
+	rule.UpdatedBy = models.NewUserUID(user)
 	rule.ID = storedRule.ID
 	rule.IntervalSeconds = storedRule.IntervalSeconds
 
@@ -827,6 +862,14 @@ func withoutNilAlertRules(ptrs []*models.AlertRule) []models.AlertRule {
 	return result
 }
 
+// validateRecordTarget returns an error if the rule is a recording rule whose target has no configured writer.
+func (service *AlertRuleService) validateRecordTarget(rule *models.AlertRule) error {
+	if rule.Record == nil || service.recordTargets == nil {
+		return nil
+	}
+	return service.recordTargets.ValidateTarget(rule.Record.Target)
+}
+
 func (service *AlertRuleService) checkGroupLimits(group models.AlertRuleGroup) error {
 	if service.rulesPerRuleGroupLimit > 0 && int64(len(group.Rules)) > service.rulesPerRuleGroupLimit {
 		service.log.Warn("Large rule group was edited. Large groups are discouraged and may be rejected in the future.",
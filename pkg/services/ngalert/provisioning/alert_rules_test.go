@@ -609,6 +609,28 @@ func TestAlertRuleService(t *testing.T) {
 		require.ErrorIs(t, err, models.ErrQuotaReached)
 	})
 
+	t.Run("recording rules with targets that are not configured are rejected", func(t *testing.T) {
+		ruleService := createAlertRuleService(t, nil)
+		ruleService.recordTargets = fakeRecordTargets{models.RecordTargetPrometheus}
+		record := &models.Record{Metric: "my_metric", From: "A", Target: models.RecordTargetSQL}
+
+		rule := dummyRule("record#1", orgID)
+		rule.Record = record
+		_, err := ruleService.CreateAlertRule(context.Background(), u, rule, models.ProvenanceNone)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+
+		created, err := ruleService.CreateAlertRule(context.Background(), u, dummyRule("record#2", orgID), models.ProvenanceNone)
+		require.NoError(t, err)
+		created.Record = record
+		_, err = ruleService.UpdateAlertRule(context.Background(), u, created, models.ProvenanceNone)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+
+		group := createDummyGroup("record-group", orgID)
+		group.Rules[0].Record = record
+		err = ruleService.ReplaceRuleGroup(context.Background(), u, group, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
 	t.Run("quota met causes group write to be rejected", func(t *testing.T) {
 		ruleService := createAlertRuleService(t, nil)
 		checker := &MockQuotaChecker{}
@@ -624,7 +646,7 @@ func TestAlertRuleService(t *testing.T) {
 
 func TestCreateAlertRule(t *testing.T) {
 	orgID := rand.Int63()
-	u := &user.SignedInUser{OrgID: orgID}
+	u := &user.SignedInUser{OrgID: orgID, UserUID: "test-user"}
 	groupKey := models.GenerateGroupKey(orgID)
 	groupIntervalSeconds := int64(30)
 	gen := models.RuleGen
@@ -669,6 +691,7 @@ func TestCreateAlertRule(t *testing.T) {
 				require.Len(t, inserts, 1)
 				cmd := inserts[0].([]models.AlertRule)
 				require.Len(t, cmd, 1)
+				require.Equal(t, models.NewUserUID(u), cmd[0].UpdatedBy)
 			})
 
 			t.Run("set correct provenance", func(t *testing.T) {
@@ -871,7 +894,7 @@ func TestCreateAlertRule(t *testing.T) {
 
 func TestUpdateAlertRule(t *testing.T) {
 	orgID := rand.Int63()
-	u := &user.SignedInUser{OrgID: orgID}
+	u := &user.SignedInUser{OrgID: orgID, UserUID: "test-user"}
 	groupKey := models.GenerateGroupKey(orgID)
 	groupIntervalSeconds := int64(30)
 	gen := models.RuleGen
@@ -910,6 +933,7 @@ func TestUpdateAlertRule(t *testing.T) {
 			return a, ok
 		})
 		require.Len(t, updates, 1)
+		require.Equal(t, models.NewUserUID(u), updates[0].([]models.UpdateRule)[0].New.UpdatedBy)
 	})
 	t.Run("when user cannot write all rules", func(t *testing.T) {
 		rule := models.CopyRule(rules[0])
@@ -1339,7 +1363,7 @@ func TestGetAlertRules(t *testing.T) {
 
 func TestReplaceGroup(t *testing.T) {
 	orgID := rand.Int63()
-	u := &user.SignedInUser{OrgID: orgID}
+	u := &user.SignedInUser{OrgID: orgID, UserUID: "test-user"}
 	groupKey := models.GenerateGroupKey(orgID)
 	groupIntervalSeconds := int64(30)
 	gen := models.RuleGen
@@ -1387,6 +1411,9 @@ func TestReplaceGroup(t *testing.T) {
 			return a, ok
 		})
 		require.Len(t, updates, 1)
+		for _, update := range updates[0].([]models.UpdateRule) {
+			require.Equal(t, models.NewUserUID(u), update.New.UpdatedBy)
+		}
 	})
 	t.Run("when user cannot write all rules", func(t *testing.T) {
 		group := models.AlertRuleGroup{
@@ -2,6 +2,8 @@ package provisioning
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"sync"
 	"testing"
 
@@ -100,6 +102,16 @@ func (n *NotificationSettingsValidatorProviderFake) Validator(ctx context.Contex
 	return notifier.NoValidation{}, nil
 }
 
+// fakeRecordTargets accepts the recording rules that write to one of its targets.
+type fakeRecordTargets []string
+
+func (f fakeRecordTargets) ValidateTarget(target string) error {
+	if target == "" || slices.Contains(f, target) {
+		return nil
+	}
+	return fmt.Errorf("%w: unknown target %q of recording rule", models.ErrAlertRuleFailedValidation, target)
+}
+
 type call struct {
 	Method string
 	Args   []interface{}
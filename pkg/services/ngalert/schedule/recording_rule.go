@@ -13,6 +13,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/atomic"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
@@ -265,13 +266,13 @@ func (r *recordingRule) tryEvaluation(ctx context.Context, ev *Evaluation, logge
 	}
 
 	writeStart := r.clock.Now()
-	err = r.writer.Write(ctx, ev.rule.Record.Metric, ev.scheduledAt, frames, ev.rule.OrgID, ev.rule.Labels)
+	err = r.writer.WriteTo(ctx, ev.rule.Record.Target, ev.rule.Record.Metric, ev.scheduledAt, frames, ev.rule.OrgID, ev.rule.Labels)
 	writeDur := r.clock.Now().Sub(writeStart)
 
 	if err != nil {
 		span.SetStatus(codes.Error, "failed to write metrics")
 		span.RecordError(err)
-		return fmt.Errorf("remote write failed: %w", err)
+		return fmt.Errorf("write failed: %w", err)
 	}
 
 	logger.Debug("Metrics written", "duration", writeDur)
@@ -289,7 +290,7 @@ func (r *recordingRule) buildAndExecutePipeline(ctx context.Context, evalCtx eva
 		logger.Error("Failed to build rule evaluator", "error", err)
 		return nil, err
 	}
-	results, err := evaluator.EvaluateRaw(ctx, ev.scheduledAt)
+	results, err := evaluator.EvaluateRaw(expr.WithQueryDeduplicator(ctx, ev.queryDeduplicator), ev.scheduledAt)
 	if err != nil {
 		logger.Error("Failed to evaluate rule", "error", err, "duration", r.clock.Now().Sub(start))
 	}
@@ -12,6 +12,7 @@ import (
 	"time"
 	"unsafe"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
@@ -96,6 +97,9 @@ type Evaluation struct {
 	scheduledAt time.Time
 	rule        *models.AlertRule
 	folderTitle string
+	// queryDeduplicator is shared by the evaluations of the same tick, when
+	// deduplication of queries is enabled.
+	queryDeduplicator *expr.QueryDeduplicator
 }
 
 func (e *Evaluation) Fingerprint() fingerprint {
@@ -13,6 +13,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -48,8 +49,12 @@ type RulesStore interface {
 	GetAlertRulesForScheduling(ctx context.Context, query *ngmodels.GetAlertRulesForSchedulingQuery) error
 }
 
+// RecordingWriter writes the results of recording rules to the target of the rule.
+// If the target is empty, the results are written to the default target.
 type RecordingWriter interface {
-	Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error
+	WriteTo(ctx context.Context, target string, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error
+	// ValidateTarget returns an error if the target is not empty and cannot be written to.
+	ValidateTarget(target string) error
 }
 
 type schedule struct {
@@ -84,6 +89,8 @@ type schedule struct {
 	appURL               *url.URL
 	disableGrafanaFolder bool
 	jitterEvaluations    JitterStrategy
+	deduplicateQueries   bool
+	evaluationTimeout    time.Duration
 	rrCfg                setting.RecordingRuleSettings
 
 	metrics *metrics.Scheduler
@@ -100,6 +107,12 @@ type schedule struct {
 	tracer tracing.Tracer
 
 	recordingWriter RecordingWriter
+
+	// sharder decides which rule groups are evaluated by this scheduler. It is nil if every rule is evaluated.
+	sharder *ruleSharder
+	// notOwnedRules contains the rules that were evaluated by other schedulers in the previous tick.
+	// Their state is loaded from the database when this scheduler takes them over.
+	notOwnedRules map[ngmodels.AlertRuleKey]struct{}
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -112,6 +125,8 @@ type SchedulerCfg struct {
 	RecordingRulesCfg    setting.RecordingRuleSettings
 	AppURL               *url.URL
 	JitterEvaluations    JitterStrategy
+	DeduplicateQueries   bool
+	EvaluationTimeout    time.Duration
 	EvaluatorFactory     eval.EvaluatorFactory
 	RuleStore            RulesStore
 	Metrics              *metrics.Scheduler
@@ -119,6 +134,9 @@ type SchedulerCfg struct {
 	Tracer               tracing.Tracer
 	Log                  log.Logger
 	RecordingWriter      RecordingWriter
+	// Sharding spreads the evaluation of the rule groups across the schedulers that share the database.
+	// Every rule is evaluated if it is nil.
+	Sharding *ShardingCfg
 }
 
 // NewScheduler returns a new scheduler.
@@ -141,6 +159,8 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		appURL:                cfg.AppURL,
 		disableGrafanaFolder:  cfg.DisableGrafanaFolder,
 		jitterEvaluations:     cfg.JitterEvaluations,
+		deduplicateQueries:    cfg.DeduplicateQueries,
+		evaluationTimeout:     cfg.EvaluationTimeout,
 		rrCfg:                 cfg.RecordingRulesCfg,
 		stateManager:          stateManager,
 		minRuleInterval:       cfg.MinRuleInterval,
@@ -150,6 +170,11 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		recordingWriter:       cfg.RecordingWriter,
 	}
 
+	if cfg.Sharding != nil {
+		sch.sharder = newRuleSharder(*cfg.Sharding, cfg.C, cfg.Log, cfg.Metrics)
+		sch.notOwnedRules = make(map[ngmodels.AlertRuleKey]struct{})
+	}
+
 	return &sch
 }
 
@@ -158,6 +183,12 @@ func (sch *schedule) Run(ctx context.Context) error {
 	t := ticker.New(sch.clock, sch.baseInterval, sch.metrics.Ticker)
 	defer t.Stop()
 
+	if sch.sharder != nil {
+		// Join before the first tick, so that this scheduler does not evaluate the rule groups of the other members.
+		sch.sharder.sync(ctx)
+		go sch.sharder.run(ctx)
+	}
+
 	if err := sch.schedulePeriodic(ctx, t); err != nil {
 		sch.log.Error("Failure while running the rule evaluation loop", "error", err)
 	}
@@ -202,6 +233,17 @@ func (sch *schedule) deleteAlertRule(keys ...ngmodels.AlertRuleKey) {
 	sch.updateRulesMetrics(alertRules)
 }
 
+// handOverAlertRule stops the evaluation of a rule that is evaluated by another scheduler and drops its state from the cache.
+// Unlike deleteAlertRule, the rule stays schedulable and its state is kept in the database for the new owner.
+func (sch *schedule) handOverAlertRule(key ngmodels.AlertRuleKey, logger log.Logger) {
+	if ruleRoutine, ok := sch.registry.del(key); ok {
+		logger.Debug("Rule handed over to another scheduler")
+		ruleRoutine.Stop(errRuleHandedOver)
+	}
+	// The cache is cleaned up on every tick because an evaluation that was in flight during the handover can still update it.
+	sch.stateManager.ForgetRuleState(key)
+}
+
 func (sch *schedule) schedulePeriodic(ctx context.Context, t *ticker.T) error {
 	dispatcherGroup, ctx := errgroup.WithContext(ctx)
 	for {
@@ -260,6 +302,11 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 	updatedRules := make([]ngmodels.AlertRuleKeyWithVersion, 0, len(updated)) // this is needed for tests only
 	restartedRules := make([]Rule, 0)
 	missingFolder := make(map[string][]string)
+	var queryDeduplicator *expr.QueryDeduplicator
+	if sch.deduplicateQueries {
+		// identical queries of the rules evaluated in this tick are executed only once
+		queryDeduplicator = expr.NewQueryDeduplicator(sch.evaluationTimeout)
+	}
 	ruleFactory := newRuleFactory(
 		sch.appURL,
 		sch.disableGrafanaFolder,
@@ -277,11 +324,24 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.evalAppliedFunc,
 		sch.stopAppliedFunc,
 	)
+	notOwnedRules := make(map[ngmodels.AlertRuleKey]struct{})
+	ownedGroups := make(map[ngmodels.AlertRuleGroupKey]struct{})
 	for _, item := range alertRules {
-		ruleRoutine, newRoutine := sch.registry.getOrCreate(ctx, item, ruleFactory)
 		key := item.GetKey()
 		logger := sch.log.FromContext(ctx).New(key.LogContext()...)
 
+		if sch.sharder != nil {
+			if !sch.sharder.owns(item.GetGroupKey()) {
+				sch.handOverAlertRule(key, logger)
+				notOwnedRules[key] = struct{}{}
+				delete(registeredDefinitions, key)
+				continue
+			}
+			ownedGroups[item.GetGroupKey()] = struct{}{}
+		}
+
+		ruleRoutine, newRoutine := sch.registry.getOrCreate(ctx, item, ruleFactory)
+
 		// enforce minimum evaluation interval
 		if item.IntervalSeconds < int64(sch.minRuleInterval.Seconds()) {
 			logger.Debug("Interval adjusted", "originalInterval", item.IntervalSeconds, "adjustedInterval", sch.minRuleInterval.Seconds())
@@ -299,7 +359,13 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		}
 
 		if newRoutine && !invalidInterval {
+			_, takenOver := sch.notOwnedRules[key]
 			dispatcherGroup.Go(func() error {
+				if takenOver {
+					// The previous owner kept the state in the database. Evaluations wait until the routine runs.
+					logger.Debug("Rule taken over from another scheduler, loading its state")
+					sch.stateManager.LoadRuleState(ctx, item)
+				}
 				return ruleRoutine.Run()
 			})
 		}
@@ -328,9 +394,10 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		if isReadyToRun {
 			logger.Debug("Rule is ready to run on the current tick", "tick", tick, "frequency", itemFrequency, "offset", offset)
 			readyToRun = append(readyToRun, readyToRunItem{ruleRoutine: ruleRoutine, Evaluation: Evaluation{
-				scheduledAt: tick,
-				rule:        item,
-				folderTitle: folderTitle,
+				scheduledAt:       tick,
+				rule:              item,
+				folderTitle:       folderTitle,
+				queryDeduplicator: queryDeduplicator,
 			}})
 		}
 		if _, isUpdated := updated[key]; isUpdated && !isReadyToRun {
@@ -352,6 +419,11 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		delete(registeredDefinitions, key)
 	}
 
+	if sch.sharder != nil {
+		sch.notOwnedRules = notOwnedRules
+		sch.metrics.ShardingOwnedRuleGroups.Set(float64(len(ownedGroups)))
+	}
+
 	if len(missingFolder) > 0 { // if this happens then there can be problems with fetching folders from the database.
 		sch.log.Warn("Unable to obtain folder titles for some rules", "missingFolderUIDToRuleUID", missingFolder)
 	}
@@ -126,6 +126,7 @@ func TestProcessTicks(t *testing.T) {
 		require.Len(t, scheduled, 1)
 		require.Equal(t, alertRule1, scheduled[0].rule)
 		require.Equal(t, tick, scheduled[0].scheduledAt)
+		require.Nil(t, scheduled[0].queryDeduplicator, "queries should not be deduplicated by default")
 		require.Emptyf(t, stopped, "None rules are expected to be stopped")
 		require.Emptyf(t, updated, "None rules are expected to be updated")
 		assertEvalRun(t, evalAppliedCh, tick, alertRule1.GetKey())
@@ -458,6 +459,8 @@ func TestProcessTicks(t *testing.T) {
 
 	t.Run("on 14th tick both 1-tick alert rule and 2-tick recording rule should be evaluated", func(t *testing.T) {
 		tick = tick.Add(cfg.BaseInterval)
+		sched.deduplicateQueries = true
+		t.Cleanup(func() { sched.deduplicateQueries = false })
 
 		scheduled, stopped, updated := sched.processTick(ctx, dispatcherGroup, tick)
 
@@ -466,6 +469,8 @@ func TestProcessTicks(t *testing.T) {
 		require.Emptyf(t, updated, "No rules are expected to be updated")
 		assertScheduledContains(t, scheduled, alertRule3)
 		assertScheduledContains(t, scheduled, recordingRule1)
+		require.NotNil(t, scheduled[0].queryDeduplicator)
+		require.Same(t, scheduled[0].queryDeduplicator, scheduled[1].queryDeduplicator, "rules of the same tick should share the query deduplicator")
 	})
 
 	// Convert an alerting rule to a recording rule.
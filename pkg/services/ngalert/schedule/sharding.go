@@ -0,0 +1,186 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// errRuleHandedOver is the reason the routine of a rule stops when another scheduler takes over its evaluation.
+// The state of the rule is kept in the database, so that the new owner can load it.
+var errRuleHandedOver = errors.New("rule handed over to another scheduler")
+
+// ringTokensPerMember is the number of virtual nodes of a member in the hash ring.
+// More tokens spread the rule groups more evenly at the cost of a larger ring.
+const ringTokensPerMember = 128
+
+// MemberStore keeps the heartbeats of the schedulers that share the evaluation of the alert rules.
+type MemberStore interface {
+	HeartbeatSchedulerMember(ctx context.Context, memberID string, at time.Time) error
+	ListSchedulerMembers(ctx context.Context, aliveSince time.Time) ([]string, error)
+	DeleteSchedulerMember(ctx context.Context, memberID string) error
+}
+
+// ShardingCfg configures how the rule groups are spread across the schedulers.
+type ShardingCfg struct {
+	// MemberID identifies this scheduler among the members.
+	MemberID string
+	// HeartbeatInterval is how often the scheduler sends a heartbeat and refreshes the members.
+	HeartbeatInterval time.Duration
+	// HeartbeatTimeout is how long a member is considered alive after its last heartbeat.
+	HeartbeatTimeout time.Duration
+	Store            MemberStore
+}
+
+// hashRing assigns rule groups to members with consistent hashing,
+// so that only the groups of a member that joins or leaves move to another member.
+type hashRing struct {
+	tokens  []uint64
+	members []string // members[i] owns tokens[i]
+}
+
+func newHashRing(members []string) *hashRing {
+	type token struct {
+		hash   uint64
+		member string
+	}
+	tokens := make([]token, 0, len(members)*ringTokensPerMember)
+	for _, m := range members {
+		for i := 0; i < ringTokensPerMember; i++ {
+			tokens = append(tokens, token{hash: ringHash(m, strconv.Itoa(i)), member: m})
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].hash == tokens[j].hash {
+			return tokens[i].member < tokens[j].member
+		}
+		return tokens[i].hash < tokens[j].hash
+	})
+
+	r := &hashRing{
+		tokens:  make([]uint64, 0, len(tokens)),
+		members: make([]string, 0, len(tokens)),
+	}
+	for _, t := range tokens {
+		r.tokens = append(r.tokens, t.hash)
+		r.members = append(r.members, t.member)
+	}
+	return r
+}
+
+// owner returns the member that evaluates the rule group, which is the owner of the first token after the hash of the group.
+func (r *hashRing) owner(key ngmodels.AlertRuleGroupKey) string {
+	if len(r.tokens) == 0 {
+		return ""
+	}
+	h := ringHash(strconv.FormatInt(key.OrgID, 10), key.NamespaceUID, key.RuleGroup)
+	i := sort.Search(len(r.tokens), func(i int) bool { return r.tokens[i] >= h })
+	if i == len(r.tokens) {
+		i = 0
+	}
+	return r.members[i]
+}
+
+func ringHash(parts ...string) uint64 {
+	h := fnv.New64a()
+	for _, p := range parts {
+		_, _ = h.Write([]byte(p))
+		_, _ = h.Write([]byte{0})
+	}
+	// FNV does not spread similar inputs well enough over the whole ring, mix the bits with the finalizer of splitmix64.
+	x := h.Sum64()
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// ruleSharder keeps the members up to date with heartbeats and decides which rule groups this scheduler evaluates.
+type ruleSharder struct {
+	cfg     ShardingCfg
+	clock   clock.Clock
+	log     log.Logger
+	metrics *metrics.Scheduler
+
+	mu      sync.RWMutex
+	members []string
+	ring    *hashRing
+}
+
+func newRuleSharder(cfg ShardingCfg, clk clock.Clock, logger log.Logger, m *metrics.Scheduler) *ruleSharder {
+	members := []string{cfg.MemberID}
+	return &ruleSharder{
+		cfg:     cfg,
+		clock:   clk,
+		log:     logger.New("member", cfg.MemberID),
+		metrics: m,
+		members: members,
+		ring:    newHashRing(members),
+	}
+}
+
+// owns returns true if this scheduler evaluates the rule group.
+func (s *ruleSharder) owns(key ngmodels.AlertRuleGroupKey) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ring.owner(key) == s.cfg.MemberID
+}
+
+// sync sends a heartbeat and rebuilds the ring if members joined or left.
+// If the members can not be fetched, the previous ones are kept.
+func (s *ruleSharder) sync(ctx context.Context) {
+	now := s.clock.Now()
+	if err := s.cfg.Store.HeartbeatSchedulerMember(ctx, s.cfg.MemberID, now); err != nil {
+		s.log.Error("Failed to send heartbeat", "error", err)
+	}
+	members, err := s.cfg.Store.ListSchedulerMembers(ctx, now.Add(-s.cfg.HeartbeatTimeout))
+	if err != nil {
+		s.log.Error("Failed to fetch members, keeping the previous ones", "error", err)
+		return
+	}
+	// This scheduler is alive even if its last heartbeat did not make it to the store.
+	if !slices.Contains(members, s.cfg.MemberID) {
+		members = append(members, s.cfg.MemberID)
+	}
+	slices.Sort(members)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics.ShardingMembers.Set(float64(len(members)))
+	if slices.Equal(members, s.members) {
+		return
+	}
+	s.log.Info("Members changed, rebalancing rule groups", "previous", s.members, "current", members)
+	s.members = members
+	s.ring = newHashRing(members)
+}
+
+// run keeps the members in sync until the context is canceled, then leaves so that the other members
+// take over the rule groups of this scheduler without waiting for its heartbeat to expire.
+func (s *ruleSharder) run(ctx context.Context) {
+	t := s.clock.Ticker(s.cfg.HeartbeatInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			s.sync(ctx)
+		case <-ctx.Done():
+			leaveCtx, cancel := context.WithTimeout(context.Background(), s.cfg.HeartbeatInterval)
+			defer cancel()
+			if err := s.cfg.Store.DeleteSchedulerMember(leaveCtx, s.cfg.MemberID); err != nil {
+				s.log.Warn("Failed to leave, the other members take over when the heartbeat expires", "error", err)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+type fakeMemberStore struct {
+	mtx        sync.Mutex
+	heartbeats map[string]time.Time
+	listErr    error
+}
+
+func newFakeMemberStore() *fakeMemberStore {
+	return &fakeMemberStore{heartbeats: make(map[string]time.Time)}
+}
+
+func (f *fakeMemberStore) HeartbeatSchedulerMember(_ context.Context, memberID string, at time.Time) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.heartbeats[memberID] = at
+	return nil
+}
+
+func (f *fakeMemberStore) ListSchedulerMembers(_ context.Context, aliveSince time.Time) ([]string, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	members := make([]string, 0, len(f.heartbeats))
+	for m, at := range f.heartbeats {
+		if !at.Before(aliveSince) {
+			members = append(members, m)
+		}
+	}
+	return members, nil
+}
+
+func (f *fakeMemberStore) DeleteSchedulerMember(_ context.Context, memberID string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	delete(f.heartbeats, memberID)
+	return nil
+}
+
+func ruleGroupKeys(n int) []models.AlertRuleGroupKey {
+	keys := make([]models.AlertRuleGroupKey, 0, n)
+	for i := 0; i < n; i++ {
+		keys = append(keys, models.AlertRuleGroupKey{OrgID: int64(i%3 + 1), NamespaceUID: fmt.Sprintf("folder-%d", i%10), RuleGroup: fmt.Sprintf("group-%d", i)})
+	}
+	return keys
+}
+
+func TestHashRing(t *testing.T) {
+	keys := ruleGroupKeys(3000)
+
+	t.Run("rule groups are spread across members", func(t *testing.T) {
+		ring := newHashRing([]string{"a", "b", "c"})
+		owned := map[string]int{}
+		for _, k := range keys {
+			owned[ring.owner(k)]++
+		}
+		require.Len(t, owned, 3)
+		for m, n := range owned {
+			require.InDeltaf(t, len(keys)/3, n, float64(len(keys))/10, "member %s owns %d rule groups", m, n)
+		}
+	})
+
+	t.Run("only the rule groups of the new member move when it joins", func(t *testing.T) {
+		before := newHashRing([]string{"a", "b", "c"})
+		after := newHashRing([]string{"a", "b", "c", "d"})
+		moved := 0
+		for _, k := range keys {
+			if before.owner(k) != after.owner(k) {
+				require.Equal(t, "d", after.owner(k))
+				moved++
+			}
+		}
+		require.InDelta(t, len(keys)/4, moved, float64(len(keys))/10)
+	})
+
+	t.Run("the owner does not depend on the order of the members", func(t *testing.T) {
+		ring := newHashRing([]string{"a", "b", "c"})
+		reversed := newHashRing([]string{"c", "b", "a"})
+		for _, k := range keys {
+			require.Equal(t, ring.owner(k), reversed.owner(k))
+		}
+	})
+
+	t.Run("empty ring has no owner", func(t *testing.T) {
+		require.Empty(t, newHashRing(nil).owner(keys[0]))
+	})
+}
+
+func TestRuleSharder(t *testing.T) {
+	clk := clock.NewMock()
+	store := newFakeMemberStore()
+	cfg := ShardingCfg{MemberID: "a", HeartbeatInterval: time.Second, HeartbeatTimeout: 10 * time.Second, Store: store}
+	sharder := newRuleSharder(cfg, clk, log.NewNopLogger(), metrics.NewSchedulerMetrics(prometheus.NewPedanticRegistry()))
+	keys := ruleGroupKeys(100)
+
+	t.Run("owns every rule group when alone", func(t *testing.T) {
+		sharder.sync(context.Background())
+		require.Equal(t, []string{"a"}, sharder.members)
+		for _, k := range keys {
+			require.True(t, sharder.owns(k))
+		}
+	})
+
+	t.Run("rebalances when members join and leave", func(t *testing.T) {
+		require.NoError(t, store.HeartbeatSchedulerMember(context.Background(), "b", clk.Now()))
+		sharder.sync(context.Background())
+		require.Equal(t, []string{"a", "b"}, sharder.members)
+		owned := 0
+		for _, k := range keys {
+			if sharder.owns(k) {
+				owned++
+			}
+		}
+		require.Greater(t, owned, 0)
+		require.Less(t, owned, len(keys))
+
+		clk.Add(cfg.HeartbeatTimeout + time.Second)
+		sharder.sync(context.Background())
+		require.Equal(t, []string{"a"}, sharder.members)
+	})
+
+	t.Run("keeps the previous members if they can not be fetched", func(t *testing.T) {
+		require.NoError(t, store.HeartbeatSchedulerMember(context.Background(), "b", clk.Now()))
+		sharder.sync(context.Background())
+		store.listErr = errors.New("db is down")
+		defer func() { store.listErr = nil }()
+		_ = store.DeleteSchedulerMember(context.Background(), "b")
+		sharder.sync(context.Background())
+		require.Equal(t, []string{"a", "b"}, sharder.members)
+	})
+
+	t.Run("leaves when stopped", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		sharder.run(ctx)
+		members, err := store.ListSchedulerMembers(context.Background(), time.Time{})
+		require.NoError(t, err)
+		require.NotContains(t, members, "a")
+	})
+}
+
+func TestProcessTicksWithSharding(t *testing.T) {
+	ruleStore := newFakeRulesStore()
+	instanceStore := &state.FakeInstanceStore{}
+	sch := setupScheduler(t, ruleStore, instanceStore, nil, nil, nil)
+	sch.sharder = newRuleSharder(ShardingCfg{MemberID: "a"}, sch.clock, sch.log, sch.metrics)
+	sch.notOwnedRules = make(map[models.AlertRuleKey]struct{})
+	setMembers := func(members ...string) {
+		sch.sharder.mu.Lock()
+		defer sch.sharder.mu.Unlock()
+		sch.sharder.members = members
+		sch.sharder.ring = newHashRing(members)
+	}
+	setMembers("a", "b")
+
+	// find a rule evaluated by each member
+	gen := models.RuleGen.With(models.RuleGen.WithInterval(time.Second))
+	var ownedRule, otherRule *models.AlertRule
+	for ownedRule == nil || otherRule == nil {
+		rule := gen.GenerateRef()
+		if sch.sharder.owns(rule.GetGroupKey()) {
+			ownedRule = rule
+		} else {
+			otherRule = rule
+		}
+	}
+	ruleStore.PutRule(context.Background(), ownedRule, otherRule)
+
+	ctx := context.Background()
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+	tick := time.Time{}
+
+	t.Run("only the rules owned by the member are evaluated", func(t *testing.T) {
+		tick = tick.Add(time.Second)
+		scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Len(t, scheduled, 1)
+		require.Equal(t, ownedRule, scheduled[0].rule)
+		require.Empty(t, stopped)
+		require.False(t, sch.registry.exists(otherRule.GetKey()))
+	})
+
+	t.Run("the state of a rule is loaded when it is taken over", func(t *testing.T) {
+		setMembers("a")
+		tick = tick.Add(time.Second)
+		scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Len(t, scheduled, 2)
+		require.Empty(t, stopped)
+
+		require.Eventually(t, func() bool {
+			for _, op := range instanceStore.RecordedOps() {
+				if q, ok := op.(models.ListAlertInstancesQuery); ok && q.RuleUID == otherRule.UID && q.RuleOrgID == otherRule.OrgID {
+					return true
+				}
+			}
+			return false
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("the rules are handed over without deleting their state", func(t *testing.T) {
+		setMembers("b")
+		routine, ok := sch.registry.get(ownedRule.GetKey())
+		require.True(t, ok)
+
+		tick = tick.Add(time.Second)
+		scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Empty(t, scheduled)
+		require.Empty(t, stopped)
+		require.ErrorIs(t, routine.(*alertRule).ctx.Err(), errRuleHandedOver)
+		require.False(t, sch.registry.exists(ownedRule.GetKey()))
+
+		rules, _ := sch.schedulableAlertRules.all()
+		require.Len(t, rules, 2, "rules evaluated by other members should stay schedulable")
+	})
+}
@@ -24,11 +24,17 @@ const (
 	ErrorAlertName  = "DatasourceError"
 
 	Rulename = "rulename"
+
+	// SeverityAnnotation is the annotation that holds the severity level reported by a
+	// multi-level threshold condition. It is not a label, so that a change of the severity
+	// does not change the identity of the alert in the Alertmanager.
+	SeverityAnnotation = "grafana_severity"
 )
 
 // StateToPostableAlert converts a state to a model that is accepted by Alertmanager. Annotations and Labels are copied from the state.
 // - if state has at least one result, a new label '__value_string__' is added to the label set
 // - the alert's GeneratorURL is constructed to point to the alert detail view
+// - if state has a severity, a new annotation SeverityAnnotation is added to the annotations
 // - if evaluation state is either NoData or Error, the resulting set of labels is changed:
 //   - original alert name (label: model.AlertNameLabel) is backed up to OriginalAlertName
 //   - label model.AlertNameLabel is overwritten to either NoDataAlertName or ErrorAlertName
@@ -87,6 +93,10 @@ func StateToPostableAlert(transition StateTransition, appURL *url.URL) *models.P
 		return errorAlert(nL, nA, alertState, urlStr)
 	}
 
+	if alertState.Severity != "" {
+		nA[SeverityAnnotation] = alertState.Severity
+	}
+
 	return &models.PostableAlert{
 		Annotations: models.LabelSet(nA),
 		StartsAt:    strfmt.DateTime(alertState.StartsAt),
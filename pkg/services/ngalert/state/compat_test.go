@@ -225,6 +225,16 @@ func Test_StateToPostableAlert(t *testing.T) {
 					result := StateToPostableAlert(alertState, appURL)
 					require.Equal(t, models.LabelSet(alertState.Labels), result.Labels)
 				})
+
+				t.Run("should add severity annotation if state has a severity", func(t *testing.T) {
+					alertState := randomTransition(eval.Normal, tc.state)
+					alertState.Labels = randomMapOfStrings()
+					alertState.Severity = "critical"
+					result := StateToPostableAlert(alertState, appURL)
+					require.Equal(t, "critical", result.Annotations[SeverityAnnotation])
+					require.Equal(t, models.LabelSet(alertState.Labels), result.Labels)
+					require.NotContains(t, alertState.Annotations, SeverityAnnotation)
+				})
 			}
 		})
 	}
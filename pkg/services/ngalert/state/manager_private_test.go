@@ -3941,3 +3941,64 @@ func setCacheID(s *State) *State {
 
 	return s
 }
+
+type instancesFakeStore struct {
+	FakeInstanceStore
+	instances []*ngmodels.AlertInstance
+}
+
+func (f *instancesFakeStore) ListAlertInstances(_ context.Context, q *ngmodels.ListAlertInstancesQuery) ([]*ngmodels.AlertInstance, error) {
+	result := make([]*ngmodels.AlertInstance, 0, len(f.instances))
+	for _, instance := range f.instances {
+		if instance.RuleOrgID == q.RuleOrgID && instance.RuleUID == q.RuleUID {
+			result = append(result, instance)
+		}
+	}
+	return result, nil
+}
+
+func TestLoadAndForgetRuleState(t *testing.T) {
+	rule := ngmodels.RuleGen.With(ngmodels.RuleGen.WithAnnotations(map[string]string{"summary": "test"})).GenerateRef()
+	now := time.Now().UTC().Truncate(time.Second)
+	instance := &ngmodels.AlertInstance{
+		AlertInstanceKey: ngmodels.AlertInstanceKey{
+			RuleOrgID:  rule.OrgID,
+			RuleUID:    rule.UID,
+			LabelsHash: "hash",
+		},
+		Labels:            ngmodels.InstanceLabels{"instance": "a"},
+		CurrentState:      ngmodels.InstanceStateFiring,
+		CurrentStateSince: now.Add(-time.Minute),
+		CurrentStateEnd:   now.Add(time.Minute),
+		LastEvalTime:      now,
+		ResultFingerprint: data.Fingerprint(42).String(),
+	}
+	other := *instance
+	other.RuleUID = "other"
+
+	st := NewManager(ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+		InstanceStore: &instancesFakeStore{instances: []*ngmodels.AlertInstance{instance, &other}},
+		Images:        &NotAvailableImageService{},
+		Clock:         clock.NewMock(),
+		Historian:     &FakeHistorian{},
+	}, NewNoopPersister())
+
+	// a stale state of the rule is replaced
+	st.cache.set(&State{OrgID: rule.OrgID, AlertRuleUID: rule.UID, CacheID: 1, State: eval.Normal})
+
+	st.LoadRuleState(context.Background(), rule)
+	states := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+	require.Len(t, states, 1)
+	require.Equal(t, instance.Labels.Fingerprint(), states[0].CacheID)
+	require.Equal(t, eval.Alerting, states[0].State)
+	require.Equal(t, instance.CurrentStateSince, states[0].StartsAt)
+	require.Equal(t, data.Fingerprint(42), states[0].ResultFingerprint)
+	require.Equal(t, rule.Annotations, states[0].Annotations)
+	require.Empty(t, st.GetStatesForRuleUID(other.RuleOrgID, other.RuleUID))
+
+	st.ForgetRuleState(rule.GetKey())
+	require.Empty(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID))
+}
@@ -61,6 +61,11 @@ type State struct {
 	// conditions.
 	Values map[string]float64
 
+	// Severity is the severity level reported by the condition if it is a threshold expression
+	// with severity levels. It is kept when the state is resolved or when the rule keeps the last
+	// state on no data or errors.
+	Severity string
+
 	StartsAt time.Time
 	// EndsAt is different from the Prometheus EndsAt as EndsAt is updated for both Normal states
 	// and states that have been resolved. It cannot be used to determine when a state was resolved.
@@ -117,6 +117,30 @@ func (st DBstore) GetAlertRuleByUID(ctx context.Context, query *ngmodels.GetAler
 	return result, err
 }
 
+// GetAlertRuleVersions returns the versions of the alert rule that are kept in the history, the most recent first.
+// It returns models.ErrAlertRuleNotFound if there are no versions of the rule.
+func (st DBstore) GetAlertRuleVersions(ctx context.Context, orgID int64, ruleUID string) (result []*ngmodels.AlertRule, err error) {
+	err = st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		versions := make([]alertRuleVersion, 0)
+		if err := sess.Table(alertRuleVersion{}).Where("rule_org_id = ? AND rule_uid = ?", orgID, ruleUID).Desc("id").Find(&versions); err != nil {
+			return err
+		}
+		if len(versions) == 0 {
+			return ngmodels.ErrAlertRuleNotFound
+		}
+		result = make([]*ngmodels.AlertRule, 0, len(versions))
+		for _, v := range versions {
+			r, err := alertRuleVersionToModelsAlertRule(v, st.Logger)
+			if err != nil {
+				return fmt.Errorf("failed to convert version %d of alert rule: %w", v.Version, err)
+			}
+			result = append(result, &r)
+		}
+		return nil
+	})
+	return result, err
+}
+
 // GetRuleByID retrieves models.AlertRule by ID.
 // It returns models.ErrAlertRuleNotFound if no alert rule is found for the provided ID.
 func (st DBstore) GetRuleByID(ctx context.Context, query ngmodels.GetAlertRuleByIDQuery) (result *ngmodels.AlertRule, err error) {
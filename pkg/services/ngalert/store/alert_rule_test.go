@@ -1684,3 +1684,55 @@ func (f *fakeBus) Publish(ctx context.Context, msg bus.Msg) error {
 
 	return nil
 }
+
+func TestIntegration_GetAlertRuleVersions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting = setting.UnifiedAlertingSettings{
+		BaseInterval: time.Duration(rand.Int63n(100)+1) * time.Second,
+	}
+	sqlStore := db.InitTestDB(t)
+	folderService := setupFolderService(t, sqlStore, cfg, featuremgmt.WithFeatures())
+	b := &fakeBus{}
+	store := createTestStore(sqlStore, folderService, &logtest.Fake{}, cfg.UnifiedAlerting, b)
+	generator := models.RuleGen
+	generator = generator.With(generator.WithIntervalMatching(store.Cfg.BaseInterval), generator.WithUniqueOrgID())
+
+	createdBy := models.UserUID("user:author")
+	rule := generator.With(generator.WithUpdatedBy(&createdBy)).GenerateRef()
+	rule.ID = 0
+	_, err := store.InsertAlertRules(context.Background(), []models.AlertRule{*rule})
+	require.NoError(t, err)
+	rule, err = store.GetAlertRuleByUID(context.Background(), &models.GetAlertRuleByUIDQuery{OrgID: rule.OrgID, UID: rule.UID})
+	require.NoError(t, err)
+
+	updatedBy := models.UserUID("user:editor")
+	newRule := models.CopyRule(rule)
+	newRule.Title = "updated-title"
+	newRule.UpdatedBy = &updatedBy
+	err = store.UpdateAlertRules(context.Background(), []models.UpdateRule{{
+		Existing: rule,
+		New:      *newRule,
+	}})
+	require.NoError(t, err)
+
+	versions, err := store.GetAlertRuleVersions(context.Background(), rule.OrgID, rule.UID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.Equal(t, "updated-title", versions[0].Title)
+	require.Equal(t, rule.Version+1, versions[0].Version)
+	require.Equal(t, &updatedBy, versions[0].UpdatedBy)
+	require.Equal(t, rule.Title, versions[1].Title)
+	require.Equal(t, rule.Version, versions[1].Version)
+	require.Equal(t, &createdBy, versions[1].UpdatedBy)
+	require.Equal(t, rule.Data, versions[1].Data)
+
+	current, err := store.GetAlertRuleByUID(context.Background(), &models.GetAlertRuleByUIDQuery{OrgID: rule.OrgID, UID: rule.UID})
+	require.NoError(t, err)
+	require.Equal(t, &updatedBy, current.UpdatedBy)
+
+	_, err = store.GetAlertRuleVersions(context.Background(), rule.OrgID, "unknown")
+	require.ErrorIs(t, err, models.ErrAlertRuleNotFound)
+}
@@ -35,6 +35,11 @@ func alertRuleToModelsAlertRule(ar alertRule, l log.Logger) (models.AlertRule, e
 		IsPaused:        ar.IsPaused,
 	}
 
+	if ar.UpdatedBy != nil {
+		updatedBy := models.UserUID(*ar.UpdatedBy)
+		result.UpdatedBy = &updatedBy
+	}
+
 	if ar.NoDataState != "" {
 		result.NoDataState, err = models.NoDataStateFromString(ar.NoDataState)
 		if err != nil {
@@ -120,6 +125,11 @@ func alertRuleFromModelsAlertRule(ar models.AlertRule) (alertRule, error) {
 		IsPaused:        ar.IsPaused,
 	}
 
+	if ar.UpdatedBy != nil {
+		updatedBy := string(*ar.UpdatedBy)
+		result.UpdatedBy = &updatedBy
+	}
+
 	// Serialize complex types to JSON strings
 	data, err := json.Marshal(ar.Data)
 	if err != nil {
@@ -192,5 +202,34 @@ func alertRuleToAlertRuleVersion(rule alertRule) alertRuleVersion {
 		IsPaused:             rule.IsPaused,
 		NotificationSettings: rule.NotificationSettings,
 		Metadata:             rule.Metadata,
+		CreatedBy:            rule.UpdatedBy,
 	}
 }
+
+// alertRuleVersionToModelsAlertRule converts a version of a rule to the rule it was at that version.
+// The rule is updated at the time the version was created and by the identity that created it.
+func alertRuleVersionToModelsAlertRule(v alertRuleVersion, l log.Logger) (models.AlertRule, error) {
+	return alertRuleToModelsAlertRule(alertRule{
+		OrgID:                v.RuleOrgID,
+		Title:                v.Title,
+		Condition:            v.Condition,
+		Data:                 v.Data,
+		Updated:              v.Created,
+		IntervalSeconds:      v.IntervalSeconds,
+		Version:              v.Version,
+		UID:                  v.RuleUID,
+		NamespaceUID:         v.RuleNamespaceUID,
+		RuleGroup:            v.RuleGroup,
+		RuleGroupIndex:       v.RuleGroupIndex,
+		Record:               v.Record,
+		NoDataState:          v.NoDataState,
+		ExecErrState:         v.ExecErrState,
+		For:                  v.For,
+		Annotations:          v.Annotations,
+		Labels:               v.Labels,
+		IsPaused:             v.IsPaused,
+		NotificationSettings: v.NotificationSettings,
+		Metadata:             v.Metadata,
+		UpdatedBy:            v.CreatedBy,
+	}, l)
+}
@@ -25,8 +25,9 @@ type alertRule struct {
 	Annotations          string
 	Labels               string
 	IsPaused             bool
-	NotificationSettings string `xorm:"notification_settings"`
-	Metadata             string `xorm:"metadata"`
+	NotificationSettings string  `xorm:"notification_settings"`
+	Metadata             string  `xorm:"metadata"`
+	UpdatedBy            *string `xorm:"updated_by"`
 }
 
 func (a alertRule) TableName() string {
@@ -59,8 +60,9 @@ type alertRuleVersion struct {
 	Annotations          string
 	Labels               string
 	IsPaused             bool
-	NotificationSettings string `xorm:"notification_settings"`
-	Metadata             string `xorm:"metadata"`
+	NotificationSettings string  `xorm:"notification_settings"`
+	Metadata             string  `xorm:"metadata"`
+	CreatedBy            *string `xorm:"created_by"`
 }
 
 func (a alertRuleVersion) TableName() string {
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// HeartbeatSchedulerMember records that the scheduler member is alive at the given time.
+func (st DBstore) HeartbeatSchedulerMember(ctx context.Context, memberID string, at time.Time) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		upsertSQL := st.SQLStore.GetDialect().UpsertSQL(
+			"alert_scheduler_member",
+			[]string{"member_id"},
+			[]string{"member_id", "last_heartbeat"})
+		if _, err := sess.SQL(upsertSQL, memberID, at.UnixMilli()).Query(); err != nil {
+			return fmt.Errorf("failed to save heartbeat of scheduler member: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListSchedulerMembers returns the identifiers of the scheduler members that sent a heartbeat after the given time.
+func (st DBstore) ListSchedulerMembers(ctx context.Context, aliveSince time.Time) ([]string, error) {
+	members := make([]string, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("alert_scheduler_member").
+			Where("last_heartbeat >= ?", aliveSince.UnixMilli()).
+			OrderBy("member_id").
+			Cols("member_id").
+			Find(&members)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduler members: %w", err)
+	}
+	return members, nil
+}
+
+// DeleteSchedulerMember removes the scheduler member, so that the other members take over its rules without waiting for its heartbeat to expire.
+func (st DBstore) DeleteSchedulerMember(ctx context.Context, memberID string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM alert_scheduler_member WHERE member_id = ?", memberID)
+		return err
+	})
+}
@@ -0,0 +1,42 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationSchedulerMembers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	now := time.Now()
+
+	require.NoError(t, dbstore.HeartbeatSchedulerMember(ctx, "b", now.Add(-time.Minute)))
+	require.NoError(t, dbstore.HeartbeatSchedulerMember(ctx, "a", now))
+
+	members, err := dbstore.ListSchedulerMembers(ctx, now.Add(-30*time.Second))
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, members)
+
+	t.Run("heartbeat updates an existing member", func(t *testing.T) {
+		require.NoError(t, dbstore.HeartbeatSchedulerMember(ctx, "b", now))
+		members, err := dbstore.ListSchedulerMembers(ctx, now.Add(-30*time.Second))
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b"}, members)
+	})
+
+	t.Run("deleted members are not listed", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteSchedulerMember(ctx, "a"))
+		members, err := dbstore.ListSchedulerMembers(ctx, now.Add(-30*time.Second))
+		require.NoError(t, err)
+		require.Equal(t, []string{"b"}, members)
+	})
+}
@@ -22,7 +22,8 @@ type RuleStore struct {
 	mtx sync.Mutex
 	// OrgID -> RuleGroup -> Namespace -> Rules
 	Rules       map[int64][]*models.AlertRule
-	Hook        func(cmd any) error // use Hook if you need to intercept some query and return an error
+	Versions    map[int64][]*models.AlertRule // OrgID -> versions of the rules returned by GetAlertRuleVersions
+	Hook        func(cmd any) error           // use Hook if you need to intercept some query and return an error
 	RecordedOps []any
 	Folders     map[int64][]*folder.Folder
 }
@@ -34,8 +35,9 @@ type GenericRecordedQuery struct {
 
 func NewRuleStore(t *testing.T) *RuleStore {
 	return &RuleStore{
-		t:     t,
-		Rules: map[int64][]*models.AlertRule{},
+		t:        t,
+		Rules:    map[int64][]*models.AlertRule{},
+		Versions: map[int64][]*models.AlertRule{},
 		Hook: func(any) error {
 			return nil
 		},
@@ -144,6 +146,32 @@ func (f *RuleStore) GetAlertRuleByUID(_ context.Context, q *models.GetAlertRuleB
 	return nil, models.ErrAlertRuleNotFound
 }
 
+func (f *RuleStore) GetAlertRuleVersions(_ context.Context, orgID int64, ruleUID string) ([]*models.AlertRule, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	q := GenericRecordedQuery{
+		Name:   "GetAlertRuleVersions",
+		Params: []any{orgID, ruleUID},
+	}
+	f.RecordedOps = append(f.RecordedOps, q)
+	if err := f.Hook(q); err != nil {
+		return nil, err
+	}
+	var result []*models.AlertRule
+	for _, rule := range f.Versions[orgID] {
+		if rule.UID == ruleUID {
+			result = append(result, rule)
+		}
+	}
+	if len(result) == 0 {
+		return nil, models.ErrAlertRuleNotFound
+	}
+	slices.SortFunc(result, func(a, b *models.AlertRule) int {
+		return int(b.Version - a.Version)
+	})
+	return result, nil
+}
+
 func (f *RuleStore) GetAlertRulesGroupByRuleUID(_ context.Context, q *models.GetAlertRulesGroupByRuleUIDQuery) ([]*models.AlertRule, error) {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
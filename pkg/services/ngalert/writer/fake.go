@@ -18,3 +18,13 @@ func (w FakeWriter) Write(ctx context.Context, name string, t time.Time, frames
 
 	return w.WriteFunc(ctx, name, t, frames, orgID, extraLabels)
 }
+
+// WriteTo ignores the target and calls WriteFunc.
+func (w FakeWriter) WriteTo(ctx context.Context, _ string, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	return w.Write(ctx, name, t, frames, orgID, extraLabels)
+}
+
+// ValidateTarget accepts any target.
+func (w FakeWriter) ValidateTarget(string) error {
+	return nil
+}
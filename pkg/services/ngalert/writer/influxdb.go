@@ -0,0 +1,221 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const influxDBBackendType = "influxdb"
+
+// influxDBFieldName is the name of the field that holds the value of the recorded metric.
+const influxDBFieldName = "value"
+
+// maxInfluxDBErrorLength limits how much of the response body is included in the write errors.
+const maxInfluxDBErrorLength = 1024
+
+var (
+	lineProtocolMeasurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `, "\n", `\n`)
+	lineProtocolTagEscaper         = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `, "\n", `\n`)
+)
+
+// InfluxDBWriter writes the results of recording rules to InfluxDB using the line protocol.
+// Every point is written as a measurement named after the metric, with the labels as tags and a single "value" field.
+type InfluxDBWriter struct {
+	client   *http.Client
+	writeURL string
+	token    string
+	timeout  time.Duration
+	clock    clock.Clock
+	logger   log.Logger
+	metrics  *metrics.RemoteWriter
+}
+
+func NewInfluxDBWriter(
+	settings setting.RecordingRuleInfluxDBSettings,
+	httpClientProvider HttpClientProvider,
+	clock clock.Clock,
+	l log.Logger,
+	metrics *metrics.RemoteWriter,
+) (*InfluxDBWriter, error) {
+	writeURL, err := influxDBWriteURL(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	cl, err := httpClientProvider.New(httpclient.Options{
+		BasicAuth: createAuthOpts(settings.BasicAuthUsername, settings.BasicAuthPassword),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &InfluxDBWriter{
+		client:   cl,
+		writeURL: writeURL,
+		token:    settings.Token,
+		timeout:  settings.Timeout,
+		clock:    clock,
+		logger:   l,
+		metrics:  metrics,
+	}, nil
+}
+
+// influxDBWriteURL validates the settings and returns the URL of the write endpoint.
+// The InfluxDB 2.x API is used if the bucket is set, otherwise the InfluxDB 1.x API is used.
+func influxDBWriteURL(settings setting.RecordingRuleInfluxDBSettings) (string, error) {
+	if settings.URL == "" {
+		return "", fmt.Errorf("URL is required")
+	}
+	u, err := url.Parse(settings.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if settings.Timeout <= 0 {
+		return "", fmt.Errorf("timeout must be greater than 0")
+	}
+	if settings.BasicAuthUsername != "" && settings.BasicAuthPassword == "" {
+		return "", fmt.Errorf("basic auth password is required if username is set")
+	}
+
+	q := url.Values{}
+	q.Set("precision", "ms")
+	switch {
+	case settings.Bucket != "":
+		if settings.Organization == "" {
+			return "", fmt.Errorf("organization is required if bucket is set")
+		}
+		u = u.JoinPath("api", "v2", "write")
+		q.Set("org", settings.Organization)
+		q.Set("bucket", settings.Bucket)
+	case settings.Database != "":
+		u = u.JoinPath("write")
+		q.Set("db", settings.Database)
+	default:
+		return "", fmt.Errorf("either bucket or database is required")
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Write writes the given frames to InfluxDB.
+func (w InfluxDBWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	l := w.logger.FromContext(ctx)
+	lvs := []string{fmt.Sprint(orgID), influxDBBackendType}
+
+	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return errors.Join(ErrBadFrame, err)
+	}
+
+	var body bytes.Buffer
+	for _, p := range points {
+		// The line protocol has no representation of NaN and infinite values.
+		if math.IsNaN(p.Metric.V) || math.IsInf(p.Metric.V, 0) {
+			l.Debug("Skipping point with a value that cannot be written", "value", p.Metric.V)
+			continue
+		}
+		writeLineProtocol(&body, p)
+	}
+	if body.Len() == 0 {
+		return nil
+	}
+
+	l.Debug("Writing metric", "name", name)
+	writeStart := w.clock.Now()
+	statusCode, writeErr := w.send(ctx, &body)
+	w.metrics.WriteDuration.WithLabelValues(lvs...).Observe(w.clock.Now().Sub(writeStart).Seconds())
+
+	lvs = append(lvs, fmt.Sprint(statusCode))
+	w.metrics.WritesTotal.WithLabelValues(lvs...).Inc()
+
+	return writeErr
+}
+
+func (w InfluxDBWriter) send(ctx context.Context, body io.Reader) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.writeURL, body)
+	if err != nil {
+		return 0, errors.Join(ErrUnexpectedWriteFailure, err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("User-Agent", "grafana-recording-rule")
+	if w.token != "" {
+		req.Header.Set("Authorization", "Token "+w.token)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, errors.Join(ErrUnexpectedWriteFailure, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode/100 == 2 {
+		return resp.StatusCode, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxInfluxDBErrorLength))
+	return resp.StatusCode, checkInfluxDBWriteError(resp.StatusCode, strings.TrimSpace(string(msg)))
+}
+
+func checkInfluxDBWriteError(statusCode int, msg string) error {
+	writeErr := fmt.Errorf("InfluxDB responded with status code %d: %s", statusCode, msg)
+
+	// InfluxDB rejects malformed points and points that conflict with the schema of the measurement with 400,
+	// and points outside the retention period of the bucket with 422. Retrying will not help in both cases.
+	if statusCode == http.StatusBadRequest || statusCode == http.StatusUnprocessableEntity {
+		return errors.Join(ErrRejectedWrite, writeErr)
+	}
+
+	// All other errors, including authentication failures and the 500-range statuses, are unexpected.
+	return errors.Join(ErrUnexpectedWriteFailure, writeErr)
+}
+
+// writeLineProtocol appends the point to the buffer as a single line of the InfluxDB line protocol.
+// Tags are sorted by key, and tags with empty values are omitted because the line protocol does not allow them.
+func writeLineProtocol(buf *bytes.Buffer, p Point) {
+	buf.WriteString(lineProtocolMeasurementEscaper.Replace(p.Name))
+
+	keys := make([]string, 0, len(p.Labels))
+	for k, v := range p.Labels {
+		if k == "" || v == "" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf.WriteByte(',')
+		buf.WriteString(lineProtocolTagEscaper.Replace(k))
+		buf.WriteByte('=')
+		buf.WriteString(lineProtocolTagEscaper.Replace(p.Labels[k]))
+	}
+
+	buf.WriteByte(' ')
+	buf.WriteString(influxDBFieldName)
+	buf.WriteByte('=')
+	buf.WriteString(strconv.FormatFloat(p.Metric.V, 'g', -1, 64))
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.FormatInt(p.Metric.T.UnixMilli(), 10))
+	buf.WriteByte('\n')
+}
@@ -0,0 +1,258 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestInfluxDBWriteURL(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		settings setting.RecordingRuleInfluxDBSettings
+		expected string
+		err      bool
+	}{
+		{
+			name: "InfluxDB 2.x",
+			settings: setting.RecordingRuleInfluxDBSettings{
+				URL:          "http://localhost:8086",
+				Organization: "org",
+				Bucket:       "bucket",
+				Timeout:      10,
+			},
+			expected: "http://localhost:8086/api/v2/write?bucket=bucket&org=org&precision=ms",
+		},
+		{
+			name: "InfluxDB 1.x",
+			settings: setting.RecordingRuleInfluxDBSettings{
+				URL:      "http://localhost:8086/influx/",
+				Database: "db",
+				Timeout:  10,
+			},
+			expected: "http://localhost:8086/influx/write?db=db&precision=ms",
+		},
+		{
+			name: "missing url",
+			settings: setting.RecordingRuleInfluxDBSettings{
+				Database: "db",
+				Timeout:  10,
+			},
+			err: true,
+		},
+		{
+			name: "missing organization",
+			settings: setting.RecordingRuleInfluxDBSettings{
+				URL:     "http://localhost:8086",
+				Bucket:  "bucket",
+				Timeout: 10,
+			},
+			err: true,
+		},
+		{
+			name: "missing bucket and database",
+			settings: setting.RecordingRuleInfluxDBSettings{
+				URL:     "http://localhost:8086",
+				Timeout: 10,
+			},
+			err: true,
+		},
+		{
+			name: "missing password",
+			settings: setting.RecordingRuleInfluxDBSettings{
+				URL:               "http://localhost:8086",
+				Database:          "db",
+				BasicAuthUsername: "user",
+				Timeout:           10,
+			},
+			err: true,
+		},
+		{
+			name: "timeout is 0",
+			settings: setting.RecordingRuleInfluxDBSettings{
+				URL:      "http://localhost:8086",
+				Database: "db",
+			},
+			err: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			writeURL, err := influxDBWriteURL(tc.settings)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, writeURL)
+		})
+	}
+}
+
+func TestWriteLineProtocol(t *testing.T) {
+	now := time.UnixMilli(1700000000123)
+	for _, tc := range []struct {
+		name     string
+		point    Point
+		expected string
+	}{
+		{
+			name:     "sorts tags by key",
+			point:    Point{Name: "cpu", Labels: map[string]string{"b": "2", "a": "1"}, Metric: Metric{T: now, V: 1.5}},
+			expected: "cpu,a=1,b=2 value=1.5 1700000000123\n",
+		},
+		{
+			name:     "without tags",
+			point:    Point{Name: "cpu", Metric: Metric{T: now, V: 2}},
+			expected: "cpu value=2 1700000000123\n",
+		},
+		{
+			name:     "escapes special characters",
+			point:    Point{Name: "my cpu,total", Labels: map[string]string{"host name": "a=b,c"}, Metric: Metric{T: now, V: -1}},
+			expected: `my\ cpu\,total,host\ name=a\=b\,c value=-1 1700000000123` + "\n",
+		},
+		{
+			name:     "omits tags with empty values",
+			point:    Point{Name: "cpu", Labels: map[string]string{"a": "", "b": "1"}, Metric: Metric{T: now, V: 0}},
+			expected: "cpu,b=1 value=0 1700000000123\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeLineProtocol(&buf, tc.point)
+			require.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestInfluxDBWriter_Write(t *testing.T) {
+	type request struct {
+		query  url.Values
+		header http.Header
+		body   string
+	}
+	var (
+		requests   []request
+		statusCode int
+		respBody   string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v2/write", r.URL.Path)
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests = append(requests, request{query: r.URL.Query(), header: r.Header, body: string(body)})
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(respBody))
+	}))
+	defer srv.Close()
+
+	reg := prometheus.NewPedanticRegistry()
+	writer, err := NewInfluxDBWriter(setting.RecordingRuleInfluxDBSettings{
+		URL:          srv.URL,
+		Token:        "secret",
+		Organization: "org",
+		Bucket:       "bucket",
+		Timeout:      time.Second,
+	}, testHTTPClientProvider{}, clock.New(), log.New("test"), metrics.NewRemoteWriterMetrics(reg))
+	require.NoError(t, err)
+
+	now := time.UnixMilli(1700000000000)
+	frames := data.Frames{data.NewFrame("",
+		data.NewField("T", nil, []time.Time{now}),
+		data.NewField("value", data.Labels{"foo": "1"}, []float64{1}),
+		data.NewField("value", data.Labels{"foo": "2"}, []float64{2}),
+	)}
+	frames[0].SetMeta(&data.FrameMeta{Type: data.FrameTypeNumericWide, TypeVersion: data.FrameTypeVersion{0, 1}})
+	ctx := ngmodels.WithRuleKey(context.Background(), ngmodels.GenerateRuleKey(1))
+
+	reset := func(code int, body string) {
+		requests = nil
+		statusCode = code
+		respBody = body
+	}
+
+	t.Run("writes expected points", func(t *testing.T) {
+		reset(http.StatusNoContent, "")
+
+		err := writer.Write(ctx, "test", now, frames, 1, map[string]string{"extra": "label"})
+
+		require.NoError(t, err)
+		require.Len(t, requests, 1)
+		require.Equal(t, "org", requests[0].query.Get("org"))
+		require.Equal(t, "bucket", requests[0].query.Get("bucket"))
+		require.Equal(t, "ms", requests[0].query.Get("precision"))
+		require.Equal(t, "Token secret", requests[0].header.Get("Authorization"))
+		require.Equal(t, "test,extra=label,foo=1 value=1 1700000000000\ntest,extra=label,foo=2 value=2 1700000000000\n", requests[0].body)
+	})
+
+	t.Run("error when frames are empty", func(t *testing.T) {
+		reset(http.StatusNoContent, "")
+
+		err := writer.Write(ctx, "test", now, data.Frames{data.NewFrame("test")}, 1, nil)
+
+		require.ErrorIs(t, err, ErrBadFrame)
+		require.Empty(t, requests)
+	})
+
+	t.Run("skips values that cannot be written", func(t *testing.T) {
+		reset(http.StatusNoContent, "")
+		nanFrames := data.Frames{data.NewFrame("",
+			data.NewField("T", nil, []time.Time{now}),
+			data.NewField("value", nil, []float64{math.NaN()}),
+		)}
+		nanFrames[0].SetMeta(&data.FrameMeta{Type: data.FrameTypeNumericWide, TypeVersion: data.FrameTypeVersion{0, 1}})
+
+		err := writer.Write(ctx, "test", now, nanFrames, 1, nil)
+
+		require.NoError(t, err)
+		require.Empty(t, requests)
+	})
+
+	t.Run("server errors are unexpected", func(t *testing.T) {
+		reset(http.StatusInternalServerError, "internal error")
+
+		err := writer.Write(ctx, "test", now, frames, 1, nil)
+
+		require.ErrorIs(t, err, ErrUnexpectedWriteFailure)
+		require.ErrorContains(t, err, "internal error")
+	})
+
+	t.Run("rejected points fit under the client error category", func(t *testing.T) {
+		reset(http.StatusBadRequest, `{"code":"invalid","message":"field type conflict"}`)
+
+		err := writer.Write(ctx, "test", now, frames, 1, nil)
+
+		require.ErrorIs(t, err, ErrRejectedWrite)
+		require.ErrorContains(t, err, "field type conflict")
+	})
+
+	t.Run("authentication errors are unexpected", func(t *testing.T) {
+		reset(http.StatusUnauthorized, "unauthorized")
+
+		err := writer.Write(ctx, "test", now, frames, 1, nil)
+
+		require.ErrorIs(t, err, ErrUnexpectedWriteFailure)
+	})
+}
+
+type testHTTPClientProvider struct{}
+
+func (testHTTPClientProvider) New(options ...httpclient.Options) (*http.Client, error) {
+	return &http.Client{}, nil
+}
@@ -0,0 +1,195 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const lokiBackendType = "loki"
+
+// lokiMetricLabel is the stream label that holds the name of the recorded metric.
+const lokiMetricLabel = "metric"
+
+// maxLokiErrorLength limits how much of the response body is included in the write errors.
+const maxLokiErrorLength = 1024
+
+// LokiWriter pushes the results of recording rules to Loki.
+// Every point is written to the stream of its labels and a "metric" label with the name of the metric,
+// as a logfmt line with a single "value" key, so that it can be queried with "| logfmt | unwrap value".
+type LokiWriter struct {
+	client   *http.Client
+	pushURL  string
+	tenantID string
+	timeout  time.Duration
+	clock    clock.Clock
+	logger   log.Logger
+	metrics  *metrics.RemoteWriter
+}
+
+func NewLokiWriter(
+	settings setting.RecordingRuleLokiSettings,
+	httpClientProvider HttpClientProvider,
+	clock clock.Clock,
+	l log.Logger,
+	metrics *metrics.RemoteWriter,
+) (*LokiWriter, error) {
+	pushURL, err := lokiPushURL(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	cl, err := httpClientProvider.New(httpclient.Options{
+		BasicAuth: createAuthOpts(settings.BasicAuthUsername, settings.BasicAuthPassword),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &LokiWriter{
+		client:   cl,
+		pushURL:  pushURL,
+		tenantID: settings.TenantID,
+		timeout:  settings.Timeout,
+		clock:    clock,
+		logger:   l,
+		metrics:  metrics,
+	}, nil
+}
+
+// lokiPushURL validates the settings and returns the URL of the push endpoint.
+func lokiPushURL(settings setting.RecordingRuleLokiSettings) (string, error) {
+	if settings.URL == "" {
+		return "", fmt.Errorf("URL is required")
+	}
+	u, err := url.Parse(settings.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if settings.Timeout <= 0 {
+		return "", fmt.Errorf("timeout must be greater than 0")
+	}
+	if settings.BasicAuthUsername != "" && settings.BasicAuthPassword == "" {
+		return "", fmt.Errorf("basic auth password is required if username is set")
+	}
+	return u.JoinPath("loki", "api", "v1", "push").String(), nil
+}
+
+type lokiPushRequest struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	// Values are pairs of the timestamp in nanoseconds and the log line.
+	Values [][2]string `json:"values"`
+}
+
+// Write pushes the given frames to Loki.
+func (w LokiWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	l := w.logger.FromContext(ctx)
+	lvs := []string{fmt.Sprint(orgID), lokiBackendType}
+
+	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return errors.Join(ErrBadFrame, err)
+	}
+	if len(points) == 0 {
+		return nil
+	}
+
+	req := lokiPushRequest{Streams: make([]lokiStream, 0, len(points))}
+	for _, p := range points {
+		req.Streams = append(req.Streams, lokiStreamFromPoint(p))
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return errors.Join(ErrBadFrame, err)
+	}
+
+	l.Debug("Writing metric", "name", name)
+	writeStart := w.clock.Now()
+	statusCode, writeErr := w.send(ctx, bytes.NewReader(body))
+	w.metrics.WriteDuration.WithLabelValues(lvs...).Observe(w.clock.Now().Sub(writeStart).Seconds())
+
+	lvs = append(lvs, fmt.Sprint(statusCode))
+	w.metrics.WritesTotal.WithLabelValues(lvs...).Inc()
+
+	return writeErr
+}
+
+// lokiStreamFromPoint returns the stream of a single point. Labels with empty values are omitted because Loki
+// does not allow them, and the name of the metric takes precedence over a label with the same name.
+func lokiStreamFromPoint(p Point) lokiStream {
+	labels := make(map[string]string, len(p.Labels)+1)
+	for k, v := range p.Labels {
+		if v == "" {
+			continue
+		}
+		labels[k] = v
+	}
+	labels[lokiMetricLabel] = p.Name
+	line := "value=" + strconv.FormatFloat(p.Metric.V, 'g', -1, 64)
+	return lokiStream{
+		Stream: labels,
+		Values: [][2]string{{strconv.FormatInt(p.Metric.T.UnixNano(), 10), line}},
+	}
+}
+
+func (w LokiWriter) send(ctx context.Context, body io.Reader) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.pushURL, body)
+	if err != nil {
+		return 0, errors.Join(ErrUnexpectedWriteFailure, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "grafana-recording-rule")
+	if w.tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", w.tenantID)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, errors.Join(ErrUnexpectedWriteFailure, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode/100 == 2 {
+		return resp.StatusCode, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxLokiErrorLength))
+	return resp.StatusCode, checkLokiWriteError(resp.StatusCode, strings.TrimSpace(string(msg)))
+}
+
+func checkLokiWriteError(statusCode int, msg string) error {
+	writeErr := fmt.Errorf("Loki responded with status code %d: %s", statusCode, msg)
+
+	// Loki rejects invalid streams, and entries that are too old or out of order, with 400.
+	// Retrying will not help.
+	if statusCode == http.StatusBadRequest {
+		return errors.Join(ErrRejectedWrite, writeErr)
+	}
+
+	// All other errors, including rate limiting, authentication failures and the 500-range statuses, are unexpected.
+	return errors.Join(ErrUnexpectedWriteFailure, writeErr)
+}
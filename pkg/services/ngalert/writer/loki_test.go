@@ -0,0 +1,174 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestLokiPushURL(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		settings setting.RecordingRuleLokiSettings
+		expected string
+		err      bool
+	}{
+		{
+			name:     "appends the push path",
+			settings: setting.RecordingRuleLokiSettings{URL: "http://localhost:3100", Timeout: 10},
+			expected: "http://localhost:3100/loki/api/v1/push",
+		},
+		{
+			name:     "keeps the path of the URL",
+			settings: setting.RecordingRuleLokiSettings{URL: "http://localhost:3100/logs/", Timeout: 10},
+			expected: "http://localhost:3100/logs/loki/api/v1/push",
+		},
+		{
+			name:     "missing url",
+			settings: setting.RecordingRuleLokiSettings{Timeout: 10},
+			err:      true,
+		},
+		{
+			name: "missing password",
+			settings: setting.RecordingRuleLokiSettings{
+				URL:               "http://localhost:3100",
+				BasicAuthUsername: "user",
+				Timeout:           10,
+			},
+			err: true,
+		},
+		{
+			name:     "timeout is 0",
+			settings: setting.RecordingRuleLokiSettings{URL: "http://localhost:3100"},
+			err:      true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pushURL, err := lokiPushURL(tc.settings)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, pushURL)
+		})
+	}
+}
+
+func TestLokiWriter_Write(t *testing.T) {
+	type request struct {
+		header http.Header
+		body   string
+	}
+	var (
+		requests   []request
+		statusCode int
+		respBody   string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/loki/api/v1/push", r.URL.Path)
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests = append(requests, request{header: r.Header, body: string(body)})
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(respBody))
+	}))
+	defer srv.Close()
+
+	reg := prometheus.NewPedanticRegistry()
+	writer, err := NewLokiWriter(setting.RecordingRuleLokiSettings{
+		URL:      srv.URL,
+		TenantID: "tenant",
+		Timeout:  time.Second,
+	}, testHTTPClientProvider{}, clock.New(), log.New("test"), metrics.NewRemoteWriterMetrics(reg))
+	require.NoError(t, err)
+
+	now := time.UnixMilli(1700000000000)
+	frames := data.Frames{data.NewFrame("",
+		data.NewField("T", nil, []time.Time{now}),
+		data.NewField("value", data.Labels{"foo": "1"}, []float64{1}),
+		data.NewField("value", data.Labels{"foo": "2"}, []float64{2.5}),
+	)}
+	frames[0].SetMeta(&data.FrameMeta{Type: data.FrameTypeNumericWide, TypeVersion: data.FrameTypeVersion{0, 1}})
+	ctx := ngmodels.WithRuleKey(context.Background(), ngmodels.GenerateRuleKey(1))
+
+	reset := func(code int, body string) {
+		requests = nil
+		statusCode = code
+		respBody = body
+	}
+
+	t.Run("pushes a stream for every point", func(t *testing.T) {
+		reset(http.StatusNoContent, "")
+
+		err := writer.Write(ctx, "test", now, frames, 1, map[string]string{"extra": "label"})
+
+		require.NoError(t, err)
+		require.Len(t, requests, 1)
+		require.Equal(t, "tenant", requests[0].header.Get("X-Scope-OrgID"))
+		require.Equal(t, "application/json", requests[0].header.Get("Content-Type"))
+		require.JSONEq(t, `{"streams": [
+			{"stream": {"extra": "label", "foo": "1", "metric": "test"}, "values": [["1700000000000000000", "value=1"]]},
+			{"stream": {"extra": "label", "foo": "2", "metric": "test"}, "values": [["1700000000000000000", "value=2.5"]]}
+		]}`, requests[0].body)
+	})
+
+	t.Run("name of the metric takes precedence over the metric label", func(t *testing.T) {
+		reset(http.StatusNoContent, "")
+
+		err := writer.Write(ctx, "test", now, frames[:1], 1, map[string]string{"metric": "other", "empty": ""})
+
+		require.NoError(t, err)
+		require.Len(t, requests, 1)
+		require.NotContains(t, requests[0].body, "other")
+		require.NotContains(t, requests[0].body, "empty")
+	})
+
+	t.Run("error when frames are empty", func(t *testing.T) {
+		reset(http.StatusNoContent, "")
+
+		err := writer.Write(ctx, "test", now, data.Frames{data.NewFrame("test")}, 1, nil)
+
+		require.ErrorIs(t, err, ErrBadFrame)
+		require.Empty(t, requests)
+	})
+
+	t.Run("server errors are unexpected", func(t *testing.T) {
+		reset(http.StatusInternalServerError, "internal error")
+
+		err := writer.Write(ctx, "test", now, frames, 1, nil)
+
+		require.ErrorIs(t, err, ErrUnexpectedWriteFailure)
+		require.ErrorContains(t, err, "internal error")
+	})
+
+	t.Run("rejected entries fit under the client error category", func(t *testing.T) {
+		reset(http.StatusBadRequest, "entry too far behind")
+
+		err := writer.Write(ctx, "test", now, frames, 1, nil)
+
+		require.ErrorIs(t, err, ErrRejectedWrite)
+		require.ErrorContains(t, err, "entry too far behind")
+	})
+
+	t.Run("rate limited writes are unexpected", func(t *testing.T) {
+		reset(http.StatusTooManyRequests, "ingestion rate limit exceeded")
+
+		err := writer.Write(ctx, "test", now, frames, 1, nil)
+
+		require.ErrorIs(t, err, ErrUnexpectedWriteFailure)
+	})
+}
@@ -12,3 +12,11 @@ type NoopWriter struct{}
 func (w NoopWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
 	return nil
 }
+
+func (w NoopWriter) WriteTo(ctx context.Context, target string, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	return nil
+}
+
+func (w NoopWriter) ValidateTarget(target string) error {
+	return nil
+}
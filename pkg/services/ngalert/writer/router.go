@@ -0,0 +1,68 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// Writer writes the results of recording rules to a single backend.
+type Writer interface {
+	Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error
+}
+
+// Router sends the results of a recording rule to the writer of the target of the rule.
+// The rules that do not specify a target are written to the default target.
+type Router struct {
+	defaultTarget string
+	writers       map[string]Writer
+}
+
+func NewRouter(defaultTarget string, writers map[string]Writer) (*Router, error) {
+	if _, ok := writers[defaultTarget]; !ok {
+		return nil, fmt.Errorf("default target %q of recording rules is not configured", defaultTarget)
+	}
+	return &Router{
+		defaultTarget: defaultTarget,
+		writers:       writers,
+	}, nil
+}
+
+// WriteTo writes the given frames to the writer of the target.
+func (r *Router) WriteTo(ctx context.Context, target string, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	if target == "" {
+		target = r.defaultTarget
+	}
+	w, ok := r.writers[target]
+	if !ok {
+		return fmt.Errorf("%w: target %q is not configured", ErrRejectedWrite, target)
+	}
+	return w.Write(ctx, name, t, frames, orgID, extraLabels)
+}
+
+// ValidateTarget returns an error if the target is not empty and has no configured writer.
+func (r *Router) ValidateTarget(target string) error {
+	if target == "" {
+		return nil
+	}
+	if _, ok := r.writers[target]; ok {
+		return nil
+	}
+	return fmt.Errorf("%w: unknown target %q of recording rule, must be one of %s", ngmodels.ErrAlertRuleFailedValidation, target, strings.Join(r.Targets(), ", "))
+}
+
+// Targets returns the sorted names of the configured targets.
+func (r *Router) Targets() []string {
+	targets := make([]string, 0, len(r.writers))
+	for target := range r.writers {
+		targets = append(targets, target)
+	}
+	slices.Sort(targets)
+	return targets
+}
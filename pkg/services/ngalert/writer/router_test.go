@@ -0,0 +1,64 @@
+package writer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestRouter(t *testing.T) {
+	var written []string
+	writerFor := func(target string) Writer {
+		return FakeWriter{WriteFunc: func(_ context.Context, name string, _ time.Time, _ data.Frames, _ int64, _ map[string]string) error {
+			written = append(written, target+"/"+name)
+			return nil
+		}}
+	}
+	writers := map[string]Writer{
+		"prometheus": writerFor("prometheus"),
+		"sql":        writerFor("sql"),
+	}
+
+	t.Run("fails if default target is not configured", func(t *testing.T) {
+		_, err := NewRouter("influxdb", writers)
+		require.Error(t, err)
+	})
+
+	router, err := NewRouter("sql", writers)
+	require.NoError(t, err)
+
+	t.Run("writes to the writer of the target", func(t *testing.T) {
+		written = nil
+		require.NoError(t, router.WriteTo(context.Background(), "prometheus", "a", time.Now(), nil, 1, nil))
+		require.NoError(t, router.WriteTo(context.Background(), "sql", "b", time.Now(), nil, 1, nil))
+		require.Equal(t, []string{"prometheus/a", "sql/b"}, written)
+	})
+
+	t.Run("writes to the default target if target is empty", func(t *testing.T) {
+		written = nil
+		require.NoError(t, router.WriteTo(context.Background(), "", "a", time.Now(), nil, 1, nil))
+		require.Equal(t, []string{"sql/a"}, written)
+	})
+
+	t.Run("rejects the write if target is not configured", func(t *testing.T) {
+		written = nil
+		err := router.WriteTo(context.Background(), "influxdb", "a", time.Now(), nil, 1, nil)
+		require.ErrorIs(t, err, ErrRejectedWrite)
+		require.Empty(t, written)
+	})
+
+	t.Run("validates targets against the configured writers", func(t *testing.T) {
+		require.NoError(t, router.ValidateTarget(""))
+		require.NoError(t, router.ValidateTarget("prometheus"))
+		require.NoError(t, router.ValidateTarget("sql"))
+
+		err := router.ValidateTarget("influxdb")
+		require.ErrorIs(t, err, ngmodels.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "must be one of prometheus, sql")
+	})
+}
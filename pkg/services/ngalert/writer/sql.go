@@ -0,0 +1,170 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const sqlBackendType = "sql"
+
+const recordingSampleTable = "alert_recording_sample"
+
+// sqlCleanupInterval is how often the samples older than the retention are deleted.
+const sqlCleanupInterval = 10 * time.Minute
+
+// RecordingSample is a single sample of a recording rule stored in the Grafana database.
+type RecordingSample struct {
+	ID    int64 `xorm:"pk autoincr 'id'"`
+	OrgID int64 `xorm:"org_id"`
+	// Metric is the name of the metric the recording rule records.
+	Metric string `xorm:"metric"`
+	// Labels is the JSON representation of the labels of the series, sorted by key.
+	Labels string `xorm:"labels"`
+	// LabelsHash identifies the series among the ones of the metric.
+	LabelsHash string `xorm:"labels_hash"`
+	// Timestamp is the time of the evaluation in milliseconds.
+	Timestamp int64   `xorm:"timestamp"`
+	Value     float64 `xorm:"value"`
+}
+
+// SQLWriter stores the results of recording rules in the Grafana database, for installations without a time series database.
+// If the retention is set, the samples older than the retention are periodically deleted.
+type SQLWriter struct {
+	store     db.DB
+	retention time.Duration
+	clock     clock.Clock
+	logger    log.Logger
+	metrics   *metrics.RemoteWriter
+
+	cleanupMtx  sync.Mutex
+	lastCleanup time.Time
+}
+
+func NewSQLWriter(
+	settings setting.RecordingRuleSQLSettings,
+	store db.DB,
+	clock clock.Clock,
+	l log.Logger,
+	metrics *metrics.RemoteWriter,
+) *SQLWriter {
+	return &SQLWriter{
+		store:     store,
+		retention: settings.Retention,
+		clock:     clock,
+		logger:    l,
+		metrics:   metrics,
+	}
+}
+
+// Write stores the given frames in the Grafana database.
+func (w *SQLWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	l := w.logger.FromContext(ctx)
+	lvs := []string{fmt.Sprint(orgID), sqlBackendType}
+
+	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return errors.Join(ErrBadFrame, err)
+	}
+
+	samples := make([]RecordingSample, 0, len(points))
+	for _, p := range points {
+		// Not all supported databases can store NaN and infinite values.
+		if math.IsNaN(p.Metric.V) || math.IsInf(p.Metric.V, 0) {
+			l.Debug("Skipping point with a value that cannot be written", "value", p.Metric.V)
+			continue
+		}
+		labels := ngmodels.InstanceLabels(p.Labels)
+		labelsJSON, labelsHash, err := labels.StringAndHash()
+		if err != nil {
+			return errors.Join(ErrBadFrame, err)
+		}
+		samples = append(samples, RecordingSample{
+			OrgID:      orgID,
+			Metric:     p.Name,
+			Labels:     labelsJSON,
+			LabelsHash: labelsHash,
+			Timestamp:  p.Metric.T.UnixMilli(),
+			Value:      p.Metric.V,
+		})
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	l.Debug("Writing metric", "name", name)
+	writeStart := w.clock.Now()
+	writeErr := w.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.BulkInsert(recordingSampleTable, samples, sqlstore.NativeSettingsForDialect(w.store.GetDialect()))
+		return err
+	})
+	w.metrics.WriteDuration.WithLabelValues(lvs...).Observe(w.clock.Now().Sub(writeStart).Seconds())
+
+	// There is no status code for a database write, so use the HTTP status code with the same meaning to keep the metrics consistent.
+	statusCode := http.StatusOK
+	if writeErr != nil {
+		statusCode = http.StatusInternalServerError
+	}
+	lvs = append(lvs, fmt.Sprint(statusCode))
+	w.metrics.WritesTotal.WithLabelValues(lvs...).Inc()
+
+	if writeErr != nil {
+		return errors.Join(ErrUnexpectedWriteFailure, writeErr)
+	}
+
+	w.cleanupIfDue(ctx, l)
+	return nil
+}
+
+// cleanupIfDue deletes the samples older than the retention, at most once per sqlCleanupInterval.
+// A failure is only logged, because the write itself succeeded.
+func (w *SQLWriter) cleanupIfDue(ctx context.Context, l log.Logger) {
+	if w.retention <= 0 {
+		return
+	}
+	now := w.clock.Now()
+
+	w.cleanupMtx.Lock()
+	if now.Sub(w.lastCleanup) < sqlCleanupInterval {
+		w.cleanupMtx.Unlock()
+		return
+	}
+	w.lastCleanup = now
+	w.cleanupMtx.Unlock()
+
+	deleted, err := w.deleteSamplesBefore(ctx, now.Add(-w.retention))
+	if err != nil {
+		l.Warn("Failed to delete expired recording rule samples", "error", err)
+		return
+	}
+	if deleted > 0 {
+		l.Debug("Deleted expired recording rule samples", "count", deleted)
+	}
+}
+
+func (w *SQLWriter) deleteSamplesBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := w.store.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s < ?", recordingSampleTable, w.store.GetDialect().Quote("timestamp")), before.UnixMilli())
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
@@ -0,0 +1,139 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationSQLWriter_Write(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	store := db.InitTestDB(t)
+	clk := clock.NewMock()
+	clk.Set(time.UnixMilli(1700000000000))
+	writer := NewSQLWriter(setting.RecordingRuleSQLSettings{Retention: time.Hour}, store, clk, log.New("test"), metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()))
+	ctx := ngmodels.WithRuleKey(context.Background(), ngmodels.GenerateRuleKey(1))
+
+	newFrames := func(values ...float64) data.Frames {
+		fields := []*data.Field{data.NewField("T", nil, []time.Time{clk.Now()})}
+		for i, v := range values {
+			fields = append(fields, data.NewField("value", data.Labels{"foo": string(rune('a' + i))}, []float64{v}))
+		}
+		frame := data.NewFrame("", fields...)
+		frame.SetMeta(&data.FrameMeta{Type: data.FrameTypeNumericWide, TypeVersion: data.FrameTypeVersion{0, 1}})
+		return data.Frames{frame}
+	}
+	readSamples := func(t *testing.T) []RecordingSample {
+		t.Helper()
+		var samples []RecordingSample
+		err := store.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table(recordingSampleTable).OrderBy("timestamp, labels_hash").Find(&samples)
+		})
+		require.NoError(t, err)
+		for i := range samples {
+			samples[i].ID = 0
+		}
+		return samples
+	}
+	reset := func(t *testing.T) {
+		t.Helper()
+		err := store.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Exec("DELETE FROM " + recordingSampleTable)
+			return err
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("writes expected samples", func(t *testing.T) {
+		reset(t)
+		now := clk.Now()
+
+		err := writer.Write(ctx, "test", now, newFrames(1, 2), 1, map[string]string{"extra": "label"})
+		require.NoError(t, err)
+
+		samples := readSamples(t)
+		require.Len(t, samples, 2)
+		values := map[string]float64{}
+		for _, s := range samples {
+			require.Equal(t, int64(1), s.OrgID)
+			require.Equal(t, "test", s.Metric)
+			require.Equal(t, now.UnixMilli(), s.Timestamp)
+			require.Len(t, s.LabelsHash, 40)
+			values[s.Labels] = s.Value
+		}
+		require.Equal(t, map[string]float64{
+			`[["extra","label"],["foo","a"]]`: 1,
+			`[["extra","label"],["foo","b"]]`: 2,
+		}, values)
+	})
+
+	t.Run("error when frames are empty", func(t *testing.T) {
+		reset(t)
+
+		err := writer.Write(ctx, "test", clk.Now(), data.Frames{data.NewFrame("test")}, 1, nil)
+
+		require.ErrorIs(t, err, ErrBadFrame)
+		require.Empty(t, readSamples(t))
+	})
+
+	t.Run("skips values that cannot be written", func(t *testing.T) {
+		reset(t)
+
+		err := writer.Write(ctx, "test", clk.Now(), newFrames(math.NaN(), math.Inf(1), 3), 1, nil)
+
+		require.NoError(t, err)
+		samples := readSamples(t)
+		require.Len(t, samples, 1)
+		require.Equal(t, float64(3), samples[0].Value)
+	})
+
+	t.Run("writes more samples than fit in one batch", func(t *testing.T) {
+		reset(t)
+		count := 2*store.GetDialect().BatchSize() + 1
+		fields := []*data.Field{data.NewField("T", nil, []time.Time{clk.Now()})}
+		for i := 0; i < count; i++ {
+			fields = append(fields, data.NewField("value", data.Labels{"i": fmt.Sprint(i)}, []float64{float64(i)}))
+		}
+		frame := data.NewFrame("", fields...)
+		frame.SetMeta(&data.FrameMeta{Type: data.FrameTypeNumericWide, TypeVersion: data.FrameTypeVersion{0, 1}})
+
+		err := writer.Write(ctx, "test", clk.Now(), data.Frames{frame}, 1, nil)
+
+		require.NoError(t, err)
+		require.Len(t, readSamples(t), count)
+	})
+
+	t.Run("deletes samples older than retention", func(t *testing.T) {
+		reset(t)
+		old := clk.Now()
+		require.NoError(t, writer.Write(ctx, "test", old, newFrames(1), 1, nil))
+
+		clk.Add(time.Hour + sqlCleanupInterval)
+		require.NoError(t, writer.Write(ctx, "test", clk.Now(), newFrames(2), 1, nil))
+
+		samples := readSamples(t)
+		require.Len(t, samples, 1)
+		require.Equal(t, clk.Now().UnixMilli(), samples[0].Timestamp)
+	})
+}
@@ -45,10 +45,14 @@ func (cr *rulesConfigReader) readConfig(ctx context.Context, path string) ([]*Al
 		}
 		if alertFileV1 != nil {
 			alertFileV1.Filename = file.Name()
+			alertFileV1.Dir = path
 			alertFile, err := alertFileV1.MapToModel()
 			if err != nil {
 				return nil, fmt.Errorf("failure to map file %s: %w", alertFileV1.Filename, err)
 			}
+			for _, warning := range alertFile.Warnings {
+				cr.log.Warn("Prometheus rule was not provisioned as is", "file", alertFile.Filename, "warning", warning)
+			}
 			alertFiles = append(alertFiles, &alertFile)
 		}
 	}
@@ -34,6 +34,8 @@ const (
 	testFileCorrectProperties_t         = "./testdata/templates/correct-properties"
 	testFileCorrectPropertiesWithOrg_t  = "./testdata/templates/correct-properties-with-org"
 	testFileMultipleTs                  = "./testdata/templates/multiple-templates"
+	testFileCorrectProperties_s         = "./testdata/silences/correct-properties"
+	testFileCorrectPropertiesWithOrg_s  = "./testdata/silences/correct-properties-with-org"
 )
 
 func TestConfigReader(t *testing.T) {
@@ -75,6 +77,15 @@ func TestConfigReader(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, ruleFiles[0].Groups, 2)
 	})
+	t.Run("the config reader should read the referenced prometheus rule files", func(t *testing.T) {
+		ruleFiles, err := configReader.readConfig(ctx, testPrometheusRulesDir)
+		require.NoError(t, err)
+		require.Len(t, ruleFiles, 1)
+		require.Len(t, ruleFiles[0].Groups, 2)
+		require.Equal(t, int64(2), ruleFiles[0].Groups[0].OrgID)
+		require.Equal(t, int64(2), ruleFiles[0].Groups[0].Rules[0].OrgID)
+		require.Len(t, ruleFiles[0].Warnings, 1)
+	})
 	t.Run("the config reader should support .yaml,.yml and .json files", func(t *testing.T) {
 		ruleFiles, err := configReader.readConfig(ctx, testFileSupportedFiletypes)
 		require.NoError(t, err)
@@ -160,6 +171,23 @@ func TestConfigReader(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, file[0].Templates, 2)
 	})
+	t.Run("a silence file with correct properties should not error", func(t *testing.T) {
+		file, err := configReader.readConfig(ctx, testFileCorrectProperties_s)
+		require.NoError(t, err)
+		require.Len(t, file[0].Silences, 1)
+		silence := file[0].Silences[0]
+		require.Equal(t, int64(1), silence.OrgID)
+		require.Equal(t, "maintenance of the database cluster", *silence.Silence.Comment)
+		require.Len(t, silence.Silence.Matchers, 2)
+		require.True(t, *silence.Silence.Matchers[1].IsRegex)
+	})
+	t.Run("a silence file with correct properties and specific org should not error", func(t *testing.T) {
+		file, err := configReader.readConfig(ctx, testFileCorrectPropertiesWithOrg_s)
+		require.NoError(t, err)
+		t.Run("when an organization is set it should not overwrite it with the default of 1", func(t *testing.T) {
+			require.Equal(t, int64(1337), file[0].Silences[0].OrgID)
+		})
+	})
 	t.Run("a rule file with dasboard typo", func(t *testing.T) {
 		ruleFiles, err := configReader.readConfig(ctx, testFileDasboardTypoSupport)
 		require.NoError(t, err)
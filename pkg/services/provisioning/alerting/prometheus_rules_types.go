@@ -0,0 +1,107 @@
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+const defaultPrometheusRulesInterval = time.Minute
+
+// PrometheusRuleFileV1 references a Prometheus rule file whose rule groups are provisioned
+// as Grafana-managed rules that query the data source.
+type PrometheusRuleFileV1 struct {
+	OrgID          values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Folder         values.StringValue `json:"folder" yaml:"folder"`
+	DatasourceUID  values.StringValue `json:"datasourceUid" yaml:"datasourceUid"`
+	DatasourceType values.StringValue `json:"datasourceType" yaml:"datasourceType"`
+	// Path to the Prometheus rule file. Relative paths are resolved against the directory of the provisioning file.
+	Path values.StringValue `json:"path" yaml:"path"`
+	// Interval is the evaluation interval of the rule groups that do not define one.
+	Interval values.StringValue `json:"interval" yaml:"interval"`
+	IsPaused values.BoolValue   `json:"isPaused" yaml:"isPaused"`
+}
+
+// mapToModel reads and converts the Prometheus rule file. It returns the converted rule groups and
+// the descriptions of the parts of the rules that could not be converted as is.
+func (fileV1 *PrometheusRuleFileV1) mapToModel(dir string) ([]models.AlertRuleGroupWithFolderFullpath, []string, error) {
+	orgID := fileV1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	folder := fileV1.Folder.Value()
+	if strings.TrimSpace(folder) == "" {
+		return nil, nil, errors.New("prometheus rule file has no folder set")
+	}
+	path := fileV1.Path.Value()
+	if strings.TrimSpace(path) == "" {
+		return nil, nil, errors.New("prometheus rule file has no path set")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	interval := model.Duration(defaultPrometheusRulesInterval)
+	if fileV1.Interval.Value() != "" {
+		var err error
+		interval, err = model.ParseDuration(fileV1.Interval.Value())
+		if err != nil {
+			return nil, nil, fmt.Errorf("prometheus rule file '%s' failed to parse 'interval' field: %w", path, err)
+		}
+	}
+	dsType := fileV1.DatasourceType.Value()
+	if dsType == "" {
+		dsType = datasources.DS_PROMETHEUS
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because the path comes from the provisioning files
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := prom.ParseRulesFile(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("prometheus rule file '%s' failed to parse: %w", path, err)
+	}
+	converter, err := prom.NewConverter(prom.Config{
+		DatasourceUID:   fileV1.DatasourceUID.Value(),
+		DatasourceType:  dsType,
+		DefaultInterval: time.Duration(interval),
+		IsPaused:        fileV1.IsPaused.Value(),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("prometheus rule file '%s': %w", path, err)
+	}
+	// the folder is created by the provisioner, so the rules get its UID later
+	groups, warnings, err := converter.PrometheusRulesFileToGrafana(orgID, "", file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("prometheus rule file '%s' failed to convert: %w", path, err)
+	}
+
+	result := make([]models.AlertRuleGroupWithFolderFullpath, 0, len(groups))
+	for _, group := range groups {
+		for i := range group.Rules {
+			group.Rules[i].UID = prom.RuleUID(orgID, folder, group.Rules[i].Title)
+		}
+		result = append(result, models.AlertRuleGroupWithFolderFullpath{
+			AlertRuleGroup: group,
+			OrgID:          orgID,
+			FolderFullpath: folder,
+		})
+	}
+	messages := make([]string, 0, len(warnings))
+	for _, w := range warnings {
+		messages = append(messages, w.String())
+	}
+	return result, messages, nil
+}
@@ -0,0 +1,68 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+)
+
+const testPrometheusRulesDir = "./testdata/alert_rules/prometheus-rules"
+
+func TestPrometheusRuleFile(t *testing.T) {
+	parse := func(t *testing.T, content string) PrometheusRuleFileV1 {
+		t.Helper()
+		var fileV1 PrometheusRuleFileV1
+		require.NoError(t, yaml.Unmarshal([]byte(content), &fileV1))
+		return fileV1
+	}
+
+	t.Run("a valid prometheus rule file should be converted", func(t *testing.T) {
+		fileV1 := parse(t, "folder: prometheus\ndatasourceUid: prom\npath: prometheus/node.rules.yml\ninterval: 30s\nisPaused: true")
+
+		groups, warnings, err := fileV1.mapToModel(testPrometheusRulesDir)
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		require.Equal(t, []string{`rule group "node", rule "InstanceDown": 'keep_firing_for' is not supported and is ignored`}, warnings)
+
+		node := groups[0]
+		require.Equal(t, "node", node.Title)
+		require.Equal(t, "prometheus", node.FolderFullpath)
+		require.Equal(t, int64(1), node.OrgID)
+		require.Equal(t, int64(30), node.Interval)
+		require.Len(t, node.Rules, 2)
+		alert := node.Rules[0]
+		require.Equal(t, "InstanceDown", alert.Title)
+		require.Equal(t, prom.RuleUID(1, "prometheus", "InstanceDown"), alert.UID)
+		require.Equal(t, 5*time.Minute, alert.For)
+		require.Equal(t, "prom", alert.Data[0].DatasourceUID)
+		require.True(t, alert.IsPaused)
+		require.Equal(t, &models.Record{Metric: "job:up:sum", From: "query"}, node.Rules[1].Record)
+
+		require.Equal(t, "cpu", groups[1].Title)
+		require.Equal(t, int64(60), groups[1].Interval)
+	})
+
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{name: "without folder", content: "datasourceUid: prom\npath: prometheus/node.rules.yml"},
+		{name: "without path", content: "folder: prometheus\ndatasourceUid: prom"},
+		{name: "without data source", content: "folder: prometheus\npath: prometheus/node.rules.yml"},
+		{name: "with unsupported data source type", content: "folder: prometheus\ndatasourceUid: prom\ndatasourceType: influxdb\npath: prometheus/node.rules.yml"},
+		{name: "with invalid interval", content: "folder: prometheus\ndatasourceUid: prom\npath: prometheus/node.rules.yml\ninterval: 10x"},
+		{name: "with missing file", content: "folder: prometheus\ndatasourceUid: prom\npath: prometheus/missing.yml"},
+	}
+	for _, tc := range testCases {
+		t.Run("a prometheus rule file "+tc.name+" should error", func(t *testing.T) {
+			fileV1 := parse(t, tc.content)
+			_, _, err := fileV1.mapToModel(testPrometheusRulesDir)
+			require.Error(t, err)
+		})
+	}
+}
@@ -19,6 +19,9 @@ type ProvisionerConfig struct {
 	NotificiationPolicyService provisioning.NotificationPolicyService
 	MuteTimingService          provisioning.MuteTimingService
 	TemplateService            provisioning.TemplateService
+	SilenceStore               SilenceStore
+	OrgStore                   OrgStore
+	ProvenanceStore            provisioning.ProvisioningStore
 }
 
 func Provision(ctx context.Context, cfg ProvisionerConfig) error {
@@ -75,6 +78,15 @@ func Provision(ctx context.Context, cfg ProvisionerConfig) error {
 	if err != nil {
 		return fmt.Errorf("contact points: %w", err)
 	}
+	if cfg.SilenceStore != nil {
+		silenceProvisioner := NewSilencesProvisioner(logger, cfg.SilenceStore, cfg.OrgStore, cfg.ProvenanceStore)
+		err = silenceProvisioner.Provision(ctx, files)
+		if err != nil {
+			return fmt.Errorf("silences: %w", err)
+		}
+	} else {
+		logger.Debug("alertmanager is not available, skipping provisioning of silences")
+	}
 	logger.Info("finished to provision alerting")
 	return nil
 }
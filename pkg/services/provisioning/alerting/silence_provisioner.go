@@ -0,0 +1,231 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+)
+
+// SilenceStore manages the silences of the Alertmanager of each organization.
+type SilenceStore interface {
+	ListSilences(ctx context.Context, orgID int64, filter []string) ([]*models.Silence, error)
+	CreateSilence(ctx context.Context, orgID int64, ps models.Silence) (string, error)
+	DeleteSilence(ctx context.Context, orgID int64, silenceID string) error
+}
+
+// OrgStore lists the organizations that have an Alertmanager.
+type OrgStore interface {
+	GetOrgs(ctx context.Context) ([]int64, error)
+}
+
+type SilencesProvisioner interface {
+	Provision(ctx context.Context, files []*AlertingFile) error
+}
+
+type defaultSilencesProvisioner struct {
+	logger          log.Logger
+	silenceStore    SilenceStore
+	orgStore        OrgStore
+	provenanceStore provisioning.ProvisioningStore
+	now             func() time.Time
+}
+
+func NewSilencesProvisioner(logger log.Logger, silenceStore SilenceStore, orgStore OrgStore, provenanceStore provisioning.ProvisioningStore) SilencesProvisioner {
+	return &defaultSilencesProvisioner{
+		logger:          logger,
+		silenceStore:    silenceStore,
+		orgStore:        orgStore,
+		provenanceStore: provenanceStore,
+		now:             time.Now,
+	}
+}
+
+// Provision reconciles the provisioned silences of every organization with the
+// files. Silences that are declared in the files but do not exist are created,
+// and provisioned silences that are no longer declared are expired. Silences
+// that were not created by provisioning are never modified. Provisioned
+// silences are recognized by the file provenance that is stored for them.
+func (c *defaultSilencesProvisioner) Provision(ctx context.Context, files []*AlertingFile) error {
+	desired := map[int64][]Silence{}
+	for _, file := range files {
+		for _, silence := range file.Silences {
+			desired[silence.OrgID] = append(desired[silence.OrgID], silence)
+		}
+	}
+
+	orgIDs, err := c.orgStore.GetOrgs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, orgID := range orgIDs {
+		if _, ok := desired[orgID]; !ok {
+			desired[orgID] = nil
+		}
+	}
+
+	sorted := make([]int64, 0, len(desired))
+	for orgID := range desired {
+		sorted = append(sorted, orgID)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	for _, orgID := range sorted {
+		silences := desired[orgID]
+		if err := c.reconcileOrg(ctx, orgID, silences); err != nil {
+			if len(silences) == 0 {
+				// Organizations without provisioned silences may not have an
+				// Alertmanager yet, there is nothing to expire in them.
+				c.logger.Warn("failed to expire provisioned silences", "org", orgID, "error", err)
+				continue
+			}
+			return fmt.Errorf("org %d: %w", orgID, err)
+		}
+	}
+	return nil
+}
+
+func (c *defaultSilencesProvisioner) reconcileOrg(ctx context.Context, orgID int64, silences []Silence) error {
+	existing, err := c.silenceStore.ListSilences(ctx, orgID, nil)
+	if err != nil {
+		return err
+	}
+	provenances, err := c.provenanceStore.GetProvenances(ctx, orgID, (&models.Silence{}).ResourceType())
+	if err != nil {
+		return err
+	}
+	provisioned := make([]*models.Silence, 0, len(existing))
+	for _, s := range existing {
+		if s.ID == nil || provenances[*s.ID] != models.ProvenanceFile {
+			continue
+		}
+		if s.Status != nil && s.Status.State != nil && *s.Status.State == amv2.SilenceStatusStateExpired {
+			continue
+		}
+		provisioned = append(provisioned, s)
+		delete(provenances, *s.ID)
+	}
+	// The Alertmanager eventually drops expired silences, their provenance is not needed anymore.
+	for id, provenance := range provenances {
+		if provenance != models.ProvenanceFile {
+			continue
+		}
+		if err := c.provenanceStore.DeleteProvenance(ctx, &models.Silence{ID: &id}, orgID); err != nil {
+			return err
+		}
+	}
+
+	now := c.now()
+	kept := make(map[*models.Silence]bool, len(provisioned))
+	for _, silence := range silences {
+		if !time.Time(*silence.Silence.EndsAt).After(now) {
+			c.logger.Debug("skipping provisioned silence that already ended", "org", orgID, "comment", *silence.Silence.Comment)
+			continue
+		}
+		if match := findProvisionedSilence(provisioned, kept, silence.Silence); match != nil {
+			kept[match] = true
+			continue
+		}
+		ps := silence.Silence
+		if ps.StartsAt == nil {
+			ps.StartsAt = dateTimePointer(now)
+		}
+		id, err := c.silenceStore.CreateSilence(ctx, orgID, ps)
+		if err != nil {
+			return err
+		}
+		if err := c.provenanceStore.SetProvenance(ctx, &models.Silence{ID: &id}, orgID, models.ProvenanceFile); err != nil {
+			return err
+		}
+		c.logger.Debug("created provisioned silence", "org", orgID, "id", id)
+	}
+
+	for _, s := range provisioned {
+		if kept[s] || s.ID == nil {
+			continue
+		}
+		if err := c.silenceStore.DeleteSilence(ctx, orgID, *s.ID); err != nil {
+			return err
+		}
+		if err := c.provenanceStore.DeleteProvenance(ctx, s, orgID); err != nil {
+			return err
+		}
+		c.logger.Debug("expired provisioned silence", "org", orgID, "id", *s.ID)
+	}
+	return nil
+}
+
+// findProvisionedSilence returns the existing silence, not matched yet, that is
+// identical to the desired one.
+func findProvisionedSilence(existing []*models.Silence, kept map[*models.Silence]bool, desired models.Silence) *models.Silence {
+	for _, s := range existing {
+		if kept[s] || !sameSilence(*s, desired) {
+			continue
+		}
+		return s
+	}
+	return nil
+}
+
+func sameSilence(existing, desired models.Silence) bool {
+	if existing.Comment == nil || *existing.Comment != *desired.Comment {
+		return false
+	}
+	if existing.EndsAt == nil || !time.Time(*existing.EndsAt).Equal(time.Time(*desired.EndsAt)) {
+		return false
+	}
+	// The Alertmanager moves the start of silences that are created in the
+	// past to their creation time.
+	if desired.StartsAt != nil {
+		if existing.StartsAt == nil {
+			return false
+		}
+		start, desiredStart := time.Time(*existing.StartsAt), time.Time(*desired.StartsAt)
+		if !start.Equal(desiredStart) && !(start.After(desiredStart) && isActive(existing)) {
+			return false
+		}
+	}
+	return sameMatchers(existing.Matchers, desired.Matchers)
+}
+
+func isActive(s models.Silence) bool {
+	return s.Status != nil && s.Status.State != nil && *s.Status.State == amv2.SilenceStatusStateActive
+}
+
+func sameMatchers(a, b amv2.Matchers) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	key := func(m *amv2.Matcher) string {
+		return fmt.Sprintf("%q %q %t %t", deref(m.Name), deref(m.Value), derefBool(m.IsRegex), m.IsEqual == nil || *m.IsEqual)
+	}
+	keys := make(map[string]int, len(a))
+	for _, m := range a {
+		keys[key(m)]++
+	}
+	for _, m := range b {
+		k := key(m)
+		if keys[k] == 0 {
+			return false
+		}
+		keys[k]--
+	}
+	return true
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func derefBool(b *bool) bool {
+	return b != nil && *b
+}
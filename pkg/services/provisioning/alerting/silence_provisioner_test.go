@@ -0,0 +1,206 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type fakeSilenceStore struct {
+	silences map[int64][]*models.Silence
+	created  int
+	deleted  []string
+}
+
+func (f *fakeSilenceStore) ListSilences(_ context.Context, orgID int64, _ []string) ([]*models.Silence, error) {
+	silences, ok := f.silences[orgID]
+	if !ok {
+		return nil, fmt.Errorf("alertmanager does not exist for org %d", orgID)
+	}
+	return silences, nil
+}
+
+func (f *fakeSilenceStore) CreateSilence(_ context.Context, orgID int64, ps models.Silence) (string, error) {
+	f.created++
+	id := fmt.Sprintf("created-%d", f.created)
+	ps.ID = &id
+	ps.Status = &amv2.SilenceStatus{State: util.Pointer(amv2.SilenceStatusStateActive)}
+	f.silences[orgID] = append(f.silences[orgID], &ps)
+	return id, nil
+}
+
+func (f *fakeSilenceStore) DeleteSilence(_ context.Context, orgID int64, silenceID string) error {
+	for _, s := range f.silences[orgID] {
+		if *s.ID == silenceID {
+			s.Status.State = util.Pointer(amv2.SilenceStatusStateExpired)
+		}
+	}
+	f.deleted = append(f.deleted, silenceID)
+	return nil
+}
+
+type fakeOrgStore []int64
+
+func (f fakeOrgStore) GetOrgs(context.Context) ([]int64, error) {
+	return f, nil
+}
+
+func TestSilencesProvisioner(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	silenceV1 := func(orgID int64, comment string, startsAt, endsAt time.Time) Silence {
+		s := SilenceV1{
+			Comment:  stringToStringValue(comment),
+			EndsAt:   stringToStringValue(fmt.Sprintf("%q", endsAt.Format(time.RFC3339))),
+			Matchers: []SilenceMatcherV1{{Name: stringToStringValue("cluster"), Value: stringToStringValue("db")}},
+		}
+		if !startsAt.IsZero() {
+			s.StartsAt = stringToStringValue(fmt.Sprintf("%q", startsAt.Format(time.RFC3339)))
+		}
+		silence, err := s.mapToModel()
+		require.NoError(t, err)
+		silence.OrgID = orgID
+		return silence
+	}
+
+	newProvisioner := func(store *fakeSilenceStore, orgs ...int64) *defaultSilencesProvisioner {
+		p := NewSilencesProvisioner(log.NewNopLogger(), store, fakeOrgStore(orgs), fakes.NewFakeProvisioningStore()).(*defaultSilencesProvisioner)
+		p.now = func() time.Time { return now }
+		return p
+	}
+
+	t.Run("creates declared silences once", func(t *testing.T) {
+		store := &fakeSilenceStore{silences: map[int64][]*models.Silence{1: nil}}
+		p := newProvisioner(store, 1)
+		files := []*AlertingFile{{Silences: []Silence{
+			silenceV1(1, "maintenance", time.Time{}, now.Add(time.Hour)),
+			silenceV1(1, "upgrade", now.Add(-time.Hour), now.Add(time.Hour)),
+		}}}
+
+		require.NoError(t, p.Provision(context.Background(), files))
+		require.Equal(t, 2, store.created)
+		require.Equal(t, provisionedSilenceAuthor, *store.silences[1][0].CreatedBy)
+		require.Equal(t, now, time.Time(*store.silences[1][0].StartsAt))
+		provenances, err := p.provenanceStore.GetProvenances(context.Background(), 1, "silence")
+		require.NoError(t, err)
+		require.Equal(t, map[string]models.Provenance{"created-1": models.ProvenanceFile, "created-2": models.ProvenanceFile}, provenances)
+
+		// The Alertmanager starts silences created in the past when they are created.
+		store.silences[1][1].StartsAt = dateTimePointer(now)
+
+		require.NoError(t, p.Provision(context.Background(), files))
+		require.Equal(t, 2, store.created)
+		require.Empty(t, store.deleted)
+	})
+
+	t.Run("expires provisioned silences that are no longer declared", func(t *testing.T) {
+		store := &fakeSilenceStore{silences: map[int64][]*models.Silence{1: nil, 2: nil}}
+		p := newProvisioner(store, 1, 2)
+		files := []*AlertingFile{{Silences: []Silence{
+			silenceV1(1, "maintenance", time.Time{}, now.Add(time.Hour)),
+			silenceV1(2, "maintenance", time.Time{}, now.Add(time.Hour)),
+		}}}
+		require.NoError(t, p.Provision(context.Background(), files))
+
+		changed := []*AlertingFile{{Silences: []Silence{
+			silenceV1(1, "maintenance", time.Time{}, now.Add(2*time.Hour)),
+		}}}
+		require.NoError(t, p.Provision(context.Background(), changed))
+		require.Equal(t, 3, store.created)
+		require.ElementsMatch(t, []string{"created-1", "created-2"}, store.deleted)
+		provenances, err := p.provenanceStore.GetProvenances(context.Background(), 1, "silence")
+		require.NoError(t, err)
+		require.Equal(t, map[string]models.Provenance{"created-3": models.ProvenanceFile}, provenances)
+	})
+
+	t.Run("leaves silences that were not provisioned untouched", func(t *testing.T) {
+		manual := models.SilenceGen()()
+		store := &fakeSilenceStore{silences: map[int64][]*models.Silence{1: {&manual}}}
+		p := newProvisioner(store, 1)
+
+		require.NoError(t, p.Provision(context.Background(), nil))
+		require.Empty(t, store.deleted)
+	})
+
+	t.Run("does not rely on the author to recognize provisioned silences", func(t *testing.T) {
+		manual := models.SilenceGen()()
+		manual.CreatedBy = util.Pointer(provisionedSilenceAuthor)
+		store := &fakeSilenceStore{silences: map[int64][]*models.Silence{1: {&manual}}}
+		p := newProvisioner(store, 1)
+
+		require.NoError(t, p.Provision(context.Background(), nil))
+		require.Empty(t, store.deleted)
+	})
+
+	t.Run("skips silences that already ended", func(t *testing.T) {
+		store := &fakeSilenceStore{silences: map[int64][]*models.Silence{1: nil}}
+		p := newProvisioner(store, 1)
+		files := []*AlertingFile{{Silences: []Silence{
+			silenceV1(1, "maintenance", now.Add(-2*time.Hour), now.Add(-time.Hour)),
+		}}}
+
+		require.NoError(t, p.Provision(context.Background(), files))
+		require.Zero(t, store.created)
+	})
+
+	t.Run("fails when an organization with silences has no Alertmanager", func(t *testing.T) {
+		store := &fakeSilenceStore{silences: map[int64][]*models.Silence{1: nil}}
+		p := newProvisioner(store, 1, 3)
+		files := []*AlertingFile{{Silences: []Silence{
+			silenceV1(2, "maintenance", time.Time{}, now.Add(time.Hour)),
+		}}}
+
+		require.ErrorContains(t, p.Provision(context.Background(), files), "org 2")
+		require.NoError(t, p.Provision(context.Background(), nil))
+	})
+}
+
+func TestSilenceV1MapToModel(t *testing.T) {
+	valid := func() SilenceV1 {
+		return SilenceV1{
+			Comment:  stringToStringValue("maintenance"),
+			EndsAt:   stringToStringValue(`"2024-01-02T00:00:00Z"`),
+			Matchers: []SilenceMatcherV1{{Name: stringToStringValue("cluster"), Value: stringToStringValue("db")}},
+		}
+	}
+
+	t.Run("valid silence should not error", func(t *testing.T) {
+		v1 := valid()
+		silence, err := v1.mapToModel()
+		require.NoError(t, err)
+		require.Equal(t, int64(1), silence.OrgID)
+		require.Nil(t, silence.Silence.StartsAt)
+		require.True(t, *silence.Silence.Matchers[0].IsEqual)
+		require.False(t, *silence.Silence.Matchers[0].IsRegex)
+	})
+
+	tests := []struct {
+		name   string
+		mutate func(s *SilenceV1)
+		err    string
+	}{
+		{"missing comment", func(s *SilenceV1) { s.Comment = stringToStringValue(" ") }, "missing comment"},
+		{"missing endsAt", func(s *SilenceV1) { s.EndsAt = stringToStringValue("") }, "missing endsAt"},
+		{"invalid endsAt", func(s *SilenceV1) { s.EndsAt = stringToStringValue("tomorrow") }, "RFC3339"},
+		{"startsAt after endsAt", func(s *SilenceV1) { s.StartsAt = stringToStringValue(`"2024-01-03T00:00:00Z"`) }, "before endsAt"},
+		{"no matchers", func(s *SilenceV1) { s.Matchers = nil }, "at least one matcher"},
+		{"matcher without name", func(s *SilenceV1) { s.Matchers[0].Name = stringToStringValue("") }, "matcher missing name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+" should error", func(t *testing.T) {
+			v1 := valid()
+			tt.mutate(&v1)
+			_, err := v1.mapToModel()
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
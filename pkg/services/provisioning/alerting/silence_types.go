@@ -0,0 +1,122 @@
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+// provisionedSilenceAuthor is set as the author of every silence created from
+// provisioning files. It is only shown to users, provisioned silences are
+// identified by their provenance.
+const provisionedSilenceAuthor = "Grafana provisioning"
+
+type SilenceV1 struct {
+	OrgID    values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Comment  values.StringValue `json:"comment" yaml:"comment"`
+	StartsAt values.StringValue `json:"startsAt" yaml:"startsAt"`
+	EndsAt   values.StringValue `json:"endsAt" yaml:"endsAt"`
+	Matchers []SilenceMatcherV1 `json:"matchers" yaml:"matchers"`
+}
+
+type SilenceMatcherV1 struct {
+	Name    values.StringValue `json:"name" yaml:"name"`
+	Value   values.StringValue `json:"value" yaml:"value"`
+	IsRegex values.BoolValue   `json:"isRegex" yaml:"isRegex"`
+	IsEqual *values.BoolValue  `json:"isEqual" yaml:"isEqual"`
+}
+
+func (v1 *SilenceV1) mapToModel() (Silence, error) {
+	orgID := v1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	comment := strings.TrimSpace(v1.Comment.Value())
+	if comment == "" {
+		return Silence{}, errors.New("silence missing comment")
+	}
+	endsAt, err := parseSilenceTime("endsAt", v1.EndsAt.Value())
+	if err != nil {
+		return Silence{}, err
+	}
+	if endsAt.IsZero() {
+		return Silence{}, errors.New("silence missing endsAt")
+	}
+	startsAt, err := parseSilenceTime("startsAt", v1.StartsAt.Value())
+	if err != nil {
+		return Silence{}, err
+	}
+	if !startsAt.IsZero() && !startsAt.Before(endsAt) {
+		return Silence{}, errors.New("silence startsAt must be before endsAt")
+	}
+	if len(v1.Matchers) == 0 {
+		return Silence{}, errors.New("silence must have at least one matcher")
+	}
+
+	matchers := make(amv2.Matchers, 0, len(v1.Matchers))
+	for _, m := range v1.Matchers {
+		name := strings.TrimSpace(m.Name.Value())
+		if name == "" {
+			return Silence{}, errors.New("silence matcher missing name")
+		}
+		value := m.Value.Value()
+		isRegex := m.IsRegex.Value()
+		isEqual := true
+		if m.IsEqual != nil {
+			isEqual = m.IsEqual.Value()
+		}
+		matchers = append(matchers, &amv2.Matcher{
+			Name:    &name,
+			Value:   &value,
+			IsRegex: &isRegex,
+			IsEqual: &isEqual,
+		})
+	}
+
+	author := provisionedSilenceAuthor
+	silence := models.Silence{
+		Silence: amv2.Silence{
+			Comment:   &comment,
+			CreatedBy: &author,
+			EndsAt:    dateTimePointer(endsAt),
+			Matchers:  matchers,
+		},
+	}
+	// A silence without a start time starts as soon as it is provisioned.
+	if !startsAt.IsZero() {
+		silence.StartsAt = dateTimePointer(startsAt)
+	}
+	return Silence{
+		OrgID:   orgID,
+		Silence: silence,
+	}, nil
+}
+
+func parseSilenceTime(field, value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("silence %s must be a RFC3339 timestamp: %w", field, err)
+	}
+	return t, nil
+}
+
+func dateTimePointer(t time.Time) *strfmt.DateTime {
+	dt := strfmt.DateTime(t)
+	return &dt
+}
+
+type Silence struct {
+	OrgID   int64
+	Silence models.Silence
+}
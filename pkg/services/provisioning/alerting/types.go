@@ -26,12 +26,16 @@ type AlertingFile struct {
 	DeleteMuteTimes     []DeleteMuteTime
 	Templates           []Template
 	DeleteTemplates     []DeleteTemplate
+	Silences            []Silence
+	// Warnings describe the parts of Prometheus rules that could not be provisioned as is.
+	Warnings []string
 }
 
 type AlertingFileV1 struct {
 	configVersion
 	Filename            string
 	Groups              []AlertRuleGroupV1      `json:"groups" yaml:"groups"`
+	PrometheusRuleFiles []PrometheusRuleFileV1  `json:"prometheusRuleFiles" yaml:"prometheusRuleFiles"`
 	DeleteRules         []RuleDeleteV1          `json:"deleteRules" yaml:"deleteRules"`
 	ContactPoints       []ContactPointV1        `json:"contactPoints" yaml:"contactPoints"`
 	DeleteContactPoints []DeleteContactPointV1  `json:"deleteContactPoints" yaml:"deleteContactPoints"`
@@ -41,6 +45,9 @@ type AlertingFileV1 struct {
 	DeleteMuteTimes     []DeleteMuteTimeV1      `json:"deleteMuteTimes" yaml:"deleteMuteTimes"`
 	Templates           []TemplateV1            `json:"templates" yaml:"templates"`
 	DeleteTemplates     []DeleteTemplateV1      `json:"deleteTemplates" yaml:"deleteTemplates"`
+	Silences            []SilenceV1             `json:"silences" yaml:"silences"`
+	// Dir is the directory of the file. Paths in the file are relative to it.
+	Dir string `json:"-" yaml:"-"`
 }
 
 func (fileV1 *AlertingFileV1) MapToModel() (AlertingFile, error) {
@@ -61,9 +68,23 @@ func (fileV1 *AlertingFileV1) MapToModel() (AlertingFile, error) {
 	if err := fileV1.mapTemplates(&alertingFile); err != nil {
 		return AlertingFile{}, fmt.Errorf("failure parsing templates: %w", err)
 	}
+	if err := fileV1.mapSilences(&alertingFile); err != nil {
+		return AlertingFile{}, fmt.Errorf("failure parsing silences: %w", err)
+	}
 	return alertingFile, nil
 }
 
+func (fileV1 *AlertingFileV1) mapSilences(alertingFile *AlertingFile) error {
+	for _, silenceV1 := range fileV1.Silences {
+		silence, err := silenceV1.mapToModel()
+		if err != nil {
+			return err
+		}
+		alertingFile.Silences = append(alertingFile.Silences, silence)
+	}
+	return nil
+}
+
 func (fileV1 *AlertingFileV1) mapTemplates(alertingFile *AlertingFile) error {
 	for _, ttV1 := range fileV1.Templates {
 		alertingFile.Templates = append(alertingFile.Templates, ttV1.mapToModel())
@@ -128,6 +149,14 @@ func (fileV1 *AlertingFileV1) mapRules(alertingFile *AlertingFile) error {
 		}
 		alertingFile.Groups = append(alertingFile.Groups, group)
 	}
+	for _, promFileV1 := range fileV1.PrometheusRuleFiles {
+		groups, warnings, err := promFileV1.mapToModel(fileV1.Dir)
+		if err != nil {
+			return err
+		}
+		alertingFile.Groups = append(alertingFile.Groups, groups...)
+		alertingFile.Warnings = append(alertingFile.Warnings, warnings...)
+	}
 	for _, ruleDeleteV1 := range fileV1.DeleteRules {
 		orgID := ruleDeleteV1.OrgID.Value()
 		if orgID < 1 {
@@ -17,6 +17,7 @@ import (
 	datasourceservice "github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/ngalert"
 	alertingauthz "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
@@ -57,6 +58,7 @@ func ProvideService(
 	orgService org.Service,
 	resourcePermissions accesscontrol.ReceiverPermissionsService,
 	tracer tracing.Tracer,
+	alertNG *ngalert.AlertNG,
 ) (*ProvisioningServiceImpl, error) {
 	s := &ProvisioningServiceImpl{
 		Cfg:                          cfg,
@@ -83,6 +85,7 @@ func ProvideService(
 		folderService:                folderService,
 		resourcePermissions:          resourcePermissions,
 		tracer:                       tracer,
+		alertNG:                      alertNG,
 	}
 
 	if err := s.setDashboardProvisioner(); err != nil {
@@ -164,6 +167,7 @@ type ProvisioningServiceImpl struct {
 	folderService                folder.Service
 	resourcePermissions          accesscontrol.ReceiverPermissionsService
 	tracer                       tracing.Tracer
+	alertNG                      *ngalert.AlertNG
 }
 
 func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) error {
@@ -267,6 +271,11 @@ func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) erro
 
 func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error {
 	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
+	// the targets of recording rules can only be validated when the writers of the alerting service are available
+	var recordTargets provisioning.RecordTargetValidator
+	if ps.alertNG != nil && ps.alertNG.RecordingWriter != nil {
+		recordTargets = ps.alertNG.RecordingWriter
+	}
 	ruleService := provisioning.NewAlertRuleService(
 		ps.alertingStore,
 		ps.alertingStore,
@@ -279,6 +288,7 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		ps.Cfg.UnifiedAlerting.RulesPerRuleGroupLimit,
 		ps.log,
 		notifier.NewCachedNotificationSettingsValidationService(ps.alertingStore),
+		recordTargets,
 		alertingauthz.NewRuleService(ps.ac),
 	)
 	configStore := legacy_storage.NewAlertmanagerConfigStore(ps.alertingStore)
@@ -308,6 +318,11 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		NotificiationPolicyService: *notificationPolicyService,
 		MuteTimingService:          *mutetimingsService,
 		TemplateService:            *templateService,
+		OrgStore:                   ps.alertingStore,
+		ProvenanceStore:            ps.alertingStore,
+	}
+	if ps.alertNG != nil && ps.alertNG.MultiOrgAlertmanager != nil {
+		cfg.SilenceStore = ps.alertNG.MultiOrgAlertmanager
 	}
 	return ps.provisionAlerting(ctx, cfg)
 }
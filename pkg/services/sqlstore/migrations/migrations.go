@@ -138,6 +138,14 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	accesscontrol.AddActionSetPermissionsMigrator(mg)
 
 	externalsession.AddMigration(mg)
+
+	addLivePipelineMigrations(mg)
+
+	ualert.AddSchedulerMemberTable(mg)
+
+	ualert.AddAlertRuleUpdatedBy(mg)
+
+	ualert.AddRecordingSampleTable(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
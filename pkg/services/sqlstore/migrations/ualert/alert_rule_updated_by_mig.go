@@ -0,0 +1,19 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddAlertRuleUpdatedBy adds columns to store the identity that made a change to an alert rule.
+func AddAlertRuleUpdatedBy(mg *migrator.Migrator) {
+	mg.AddMigration(
+		"add updated_by column to alert_rule",
+		migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+			Name: "updated_by", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true,
+		}),
+	)
+	mg.AddMigration(
+		"add created_by column to alert_rule_version",
+		migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+			Name: "created_by", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true,
+		}),
+	)
+}
@@ -0,0 +1,27 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddRecordingSampleTable creates the table that keeps the results of the recording rules written to the Grafana database.
+func AddRecordingSampleTable(mg *migrator.Migrator) {
+	recordingSample := migrator.Table{
+		Name: "alert_recording_sample",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "metric", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "timestamp", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "value", Type: migrator.DB_Double, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "metric", "timestamp"}, Type: migrator.IndexType},
+			{Cols: []string{"timestamp"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_recording_sample table", migrator.NewAddTableMigration(recordingSample))
+	mg.AddMigration("add index alert_recording_sample.org_id-metric-timestamp", migrator.NewAddIndexMigration(recordingSample, recordingSample.Indices[0]))
+	mg.AddMigration("add index alert_recording_sample.timestamp", migrator.NewAddIndexMigration(recordingSample, recordingSample.Indices[1]))
+}
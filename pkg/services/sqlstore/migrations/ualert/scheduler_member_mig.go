@@ -0,0 +1,21 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddSchedulerMemberTable creates the table that keeps the heartbeats of the instances that share the evaluation of the alert rules.
+func AddSchedulerMemberTable(mg *migrator.Migrator) {
+	schedulerMember := migrator.Table{
+		Name: "alert_scheduler_member",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "member_id", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "last_heartbeat", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"member_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_scheduler_member table", migrator.NewAddTableMigration(schedulerMember))
+	mg.AddMigration("add unique index alert_scheduler_member.member_id", migrator.NewAddIndexMigration(schedulerMember, schedulerMember.Indices[0]))
+}
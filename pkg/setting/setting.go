@@ -345,6 +345,9 @@ type Cfg struct {
 	SqlDatasourceMaxIdleConnsDefault    int
 	SqlDatasourceMaxConnLifetimeDefault int
 
+	// SQLite data source
+	SQLiteAllowedPaths []string
+
 	// Snapshots
 	SnapshotEnabled      bool
 	ExternalSnapshotUrl  string
@@ -434,6 +437,24 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveManagedStreamBufferMaxFrames is a maximum number of recent frames
+	// kept per managed stream channel for subscribers requesting history.
+	LiveManagedStreamBufferMaxFrames int
+	// LiveManagedStreamBufferMaxAge is a maximum age of frames kept per
+	// managed stream channel. Buffering is disabled when both limits are 0.
+	LiveManagedStreamBufferMaxAge time.Duration
+	// LiveMQTTURL is an address of an MQTT broker to receive Live pipeline
+	// input from. Empty disables MQTT input.
+	LiveMQTTURL      string
+	LiveMQTTClientID string
+	LiveMQTTUsername string
+	LiveMQTTPassword string
+	// LiveMQTTTopics is a set of MQTT topic filters to subscribe to.
+	LiveMQTTTopics []string
+	// LiveMQTTChannelPrefix is prepended to MQTT topics to build Live channels.
+	LiveMQTTChannelPrefix string
+	// LiveMQTTOrgID is the organization whose channel rules process MQTT messages.
+	LiveMQTTOrgID int64
 
 	// Grafana.com URL, used for OAuth redirect.
 	GrafanaComURL string
@@ -459,6 +480,9 @@ type Cfg struct {
 
 	Search SearchSettings
 
+	// Query and resource caching
+	Caching CachingSettings
+
 	SecureSocksDSProxy SecureSocksDSProxySettings
 
 	// SAML Auth
@@ -1268,6 +1292,7 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 
 	cfg.Storage = readStorageSettings(iniFile)
 	cfg.Search = readSearchSettings(iniFile)
+	cfg.Caching = readCachingSettings(iniFile)
 
 	var err error
 	cfg.SecureSocksDSProxy, err = readSecureSocksDSProxySettings(iniFile)
@@ -2013,6 +2038,9 @@ func (cfg *Cfg) readSqlDataSourceSettings() {
 	cfg.SqlDatasourceMaxOpenConnsDefault = sqlDatasources.Key("max_open_conns_default").MustInt(100)
 	cfg.SqlDatasourceMaxIdleConnsDefault = sqlDatasources.Key("max_idle_conns_default").MustInt(100)
 	cfg.SqlDatasourceMaxConnLifetimeDefault = sqlDatasources.Key("max_conn_lifetime_default").MustInt(14400)
+
+	sqlite := cfg.Raw.Section("sqlite")
+	cfg.SQLiteAllowedPaths = util.SplitString(sqlite.Key("allowed_paths").MustString(""))
 }
 
 func GetAllowedOriginGlobs(originPatterns []string) ([]glob.Glob, error) {
@@ -2062,6 +2090,34 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 
 	cfg.LiveAllowedOrigins = originPatterns
+
+	cfg.LiveManagedStreamBufferMaxFrames = section.Key("managed_stream_buffer_max_frames").MustInt(0)
+	if cfg.LiveManagedStreamBufferMaxFrames < 0 {
+		return fmt.Errorf("unexpected value %d for [live] managed_stream_buffer_max_frames", cfg.LiveManagedStreamBufferMaxFrames)
+	}
+	cfg.LiveManagedStreamBufferMaxAge = section.Key("managed_stream_buffer_max_age").MustDuration(0)
+	if cfg.LiveManagedStreamBufferMaxAge < 0 {
+		return fmt.Errorf("unexpected value %s for [live] managed_stream_buffer_max_age", cfg.LiveManagedStreamBufferMaxAge)
+	}
+
+	cfg.LiveMQTTURL = section.Key("mqtt_url").MustString("")
+	// The broker closes the session of a client when another client connects with the same ID,
+	// so every instance needs its own ID.
+	cfg.LiveMQTTClientID = section.Key("mqtt_client_id").MustString("")
+	if cfg.LiveMQTTClientID == "" {
+		cfg.LiveMQTTClientID = "grafana-" + cfg.InstanceName
+	}
+	cfg.LiveMQTTUsername = section.Key("mqtt_username").MustString("")
+	cfg.LiveMQTTPassword = section.Key("mqtt_password").MustString("")
+	cfg.LiveMQTTTopics = util.SplitString(section.Key("mqtt_topics").MustString(""))
+	if cfg.LiveMQTTURL != "" && len(cfg.LiveMQTTTopics) == 0 {
+		return errors.New("[live] mqtt_topics must be set when mqtt_url is set")
+	}
+	cfg.LiveMQTTChannelPrefix = strings.Trim(section.Key("mqtt_channel_prefix").MustString("stream/mqtt"), "/")
+	if len(strings.Split(cfg.LiveMQTTChannelPrefix, "/")) < 2 {
+		return fmt.Errorf("unexpected value %q for [live] mqtt_channel_prefix, expected scope and namespace", cfg.LiveMQTTChannelPrefix)
+	}
+	cfg.LiveMQTTOrgID = section.Key("mqtt_org_id").MustInt64(1)
 	return nil
 }
 
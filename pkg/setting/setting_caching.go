@@ -0,0 +1,34 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// CachingSettings configures the built-in query and resource cache.
+type CachingSettings struct {
+	Enabled bool
+	// TTL is the default time to live for cached query responses.
+	TTL time.Duration
+	// ResourcesTTL is the default time to live for cached resource responses.
+	ResourcesTTL time.Duration
+	// TimeRangeAlignment is the step query time ranges are aligned to before
+	// building the cache key, so that requests issued a few seconds apart share
+	// an entry.
+	TimeRangeAlignment time.Duration
+	// MaxValueSize is the largest serialized response that is stored, in bytes.
+	MaxValueSize int
+}
+
+func readCachingSettings(iniFile *ini.File) CachingSettings {
+	s := CachingSettings{}
+
+	section := iniFile.Section("caching")
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.TTL = section.Key("ttl").MustDuration(5 * time.Minute)
+	s.ResourcesTTL = section.Key("resources_ttl").MustDuration(5 * time.Minute)
+	s.TimeRangeAlignment = section.Key("time_range_alignment").MustDuration(10 * time.Second)
+	s.MaxValueSize = section.Key("max_value_size").MustInt(10 * 1024 * 1024)
+	return s
+}
@@ -255,6 +255,24 @@ func TestLoadingSettings(t *testing.T) {
 		require.Equal(t, hostname, cfg.InstanceName)
 	})
 
+	t.Run("mqtt_client_id defaults to a unique ID of the instance", func(t *testing.T) {
+		cfg := NewCfg()
+		err := cfg.Load(CommandLineArgs{
+			HomePath: "../../",
+			Args:     []string{"cfg:instance_name=node-1"},
+		})
+		require.Nil(t, err)
+		require.Equal(t, "grafana-node-1", cfg.LiveMQTTClientID)
+
+		cfg = NewCfg()
+		err = cfg.Load(CommandLineArgs{
+			HomePath: "../../",
+			Args:     []string{"cfg:instance_name=node-1", "cfg:live.mqtt_client_id=custom"},
+		})
+		require.Nil(t, err)
+		require.Equal(t, "custom", cfg.LiveMQTTClientID)
+	})
+
 	t.Run("Reading callback_url should add trailing slash", func(t *testing.T) {
 		cfg := NewCfg()
 		err := cfg.Load(CommandLineArgs{
@@ -2,6 +2,7 @@ package setting
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -23,6 +24,11 @@ const (
 	alertmanagerDefaultPushPullInterval   = alertingCluster.DefaultPushPullInterval
 	alertmanagerDefaultConfigPollInterval = time.Minute
 	alertmanagerRedisDefaultMaxConns      = 5
+	evaluationShardingDefaultHeartbeat    = 5 * time.Second
+	evaluationShardingDefaultTimeout      = 30 * time.Second
+	recordingRulesDefaultTarget           = "prometheus"
+	backfillDefaultMaxEvaluations         = 10000
+	backfillDefaultMaxConcurrent          = 2
 	// To start, the alertmanager needs at least one route defined.
 	// TODO: we should move this to Grafana settings and define this as the default.
 	alertmanagerDefaultConfiguration = `{
@@ -90,11 +96,13 @@ type UnifiedAlertingSettings struct {
 	HARedisMaxConns                 int
 	HARedisTLSEnabled               bool
 	HARedisTLSConfig                dstls.ClientConfig
+	EvaluationSharding              UnifiedAlertingEvaluationShardingSettings
 	MaxAttempts                     int64
 	MinInterval                     time.Duration
 	EvaluationTimeout               time.Duration
 	EvaluationResultLimit           int
 	DisableJitter                   bool
+	DeduplicateQueries              bool
 	ExecuteAlerts                   bool
 	DefaultConfiguration            string
 	Enabled                         *bool // determines whether unified alerting is enabled. If it is nil then user did not define it and therefore its value will be determined during migration. Services should not use it directly.
@@ -128,6 +136,18 @@ type UnifiedAlertingSettings struct {
 	RuleVersionRecordLimit int
 }
 
+// UnifiedAlertingEvaluationShardingSettings configures how the evaluation of the alert rules
+// is spread across the Grafana instances that share the same database.
+type UnifiedAlertingEvaluationShardingSettings struct {
+	Enabled bool
+	// InstanceID identifies the instance among the ones that evaluate the rules. Defaults to the hostname.
+	InstanceID string
+	// HeartbeatInterval is how often the instance reports that it is alive and refreshes the list of instances.
+	HeartbeatInterval time.Duration
+	// HeartbeatTimeout is how long an instance is considered alive after its last heartbeat.
+	HeartbeatTimeout time.Duration
+}
+
 type RecordingRuleSettings struct {
 	Enabled           bool
 	URL               string
@@ -135,6 +155,52 @@ type RecordingRuleSettings struct {
 	BasicAuthPassword string
 	CustomHeaders     map[string]string
 	Timeout           time.Duration
+	// DefaultTarget is the writer that receives the results of the recording rules that do not specify a target.
+	DefaultTarget string
+	InfluxDB      RecordingRuleInfluxDBSettings
+	SQL           RecordingRuleSQLSettings
+	Loki          RecordingRuleLokiSettings
+	Backfill      RecordingRuleBackfillSettings
+}
+
+// RecordingRuleInfluxDBSettings configures the writer that sends the results of recording rules
+// to InfluxDB using the line protocol. If Bucket is set, the InfluxDB 2.x write API is used,
+// otherwise the results are written to Database using the InfluxDB 1.x write API.
+type RecordingRuleInfluxDBSettings struct {
+	Enabled           bool
+	URL               string
+	Token             string
+	Organization      string
+	Bucket            string
+	Database          string
+	BasicAuthUsername string
+	BasicAuthPassword string
+	Timeout           time.Duration
+}
+
+// RecordingRuleSQLSettings configures the writer that stores the results of recording rules in the Grafana database.
+type RecordingRuleSQLSettings struct {
+	Enabled bool
+	// Retention is how long the written samples are kept. Zero keeps them forever.
+	Retention time.Duration
+}
+
+// RecordingRuleLokiSettings configures the writer that pushes the results of recording rules to Loki.
+// TenantID is sent in the X-Scope-OrgID header if it is set.
+type RecordingRuleLokiSettings struct {
+	Enabled           bool
+	URL               string
+	TenantID          string
+	BasicAuthUsername string
+	BasicAuthPassword string
+	Timeout           time.Duration
+}
+
+// RecordingRuleBackfillSettings limits the backfills of recording rules. MaxEvaluations is the number of
+// evaluations a single backfill may run, and MaxConcurrent is the number of backfills that may run at a time.
+type RecordingRuleBackfillSettings struct {
+	MaxEvaluations int
+	MaxConcurrent  int
 }
 
 // RemoteAlertmanagerSettings contains the configuration needed
@@ -283,6 +349,26 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	uaCfg.HARedisTLSConfig.CipherSuites = ua.Key("ha_redis_tls_cipher_suites").MustString("")
 	uaCfg.HARedisTLSConfig.MinVersion = ua.Key("ha_redis_tls_min_version").MustString("")
 
+	uaCfg.EvaluationSharding.Enabled = ua.Key("ha_evaluation_sharding_enabled").MustBool(false)
+	uaCfg.EvaluationSharding.InstanceID = ua.Key("ha_evaluation_sharding_instance_id").MustString("")
+	if uaCfg.EvaluationSharding.Enabled && uaCfg.EvaluationSharding.InstanceID == "" {
+		uaCfg.EvaluationSharding.InstanceID, err = os.Hostname()
+		if err != nil {
+			return fmt.Errorf("failed to get the hostname to identify the instance for evaluation sharding: %w", err)
+		}
+	}
+	uaCfg.EvaluationSharding.HeartbeatInterval, err = gtime.ParseDuration(valueAsString(ua, "ha_evaluation_sharding_heartbeat_interval", evaluationShardingDefaultHeartbeat.String()))
+	if err != nil {
+		return err
+	}
+	uaCfg.EvaluationSharding.HeartbeatTimeout, err = gtime.ParseDuration(valueAsString(ua, "ha_evaluation_sharding_heartbeat_timeout", evaluationShardingDefaultTimeout.String()))
+	if err != nil {
+		return err
+	}
+	if uaCfg.EvaluationSharding.HeartbeatInterval <= 0 || uaCfg.EvaluationSharding.HeartbeatTimeout <= uaCfg.EvaluationSharding.HeartbeatInterval {
+		return fmt.Errorf("value of setting 'ha_evaluation_sharding_heartbeat_timeout' (%s) must be greater than 'ha_evaluation_sharding_heartbeat_interval' (%s)", uaCfg.EvaluationSharding.HeartbeatTimeout, uaCfg.EvaluationSharding.HeartbeatInterval)
+	}
+
 	// TODO load from ini file
 	uaCfg.DefaultConfiguration = alertmanagerDefaultConfiguration
 
@@ -317,6 +403,8 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	// We can consider removing the knob entirely in a release after 10.4.
 	uaCfg.DisableJitter = ua.Key("disable_jitter").MustBool(false)
 
+	uaCfg.DeduplicateQueries = ua.Key("deduplicate_queries").MustBool(false)
+
 	// The base interval of the scheduler for evaluating alerts.
 	// 1. It is used by the internal scheduler's timer to tick at this interval.
 	// 2. to spread evaluations of rules that need to be evaluated at the current tick T. In other words, the evaluation of rules at the tick T will be evenly spread in the interval from T to T+scheduler_tick_interval.
@@ -441,6 +529,55 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		uaCfgRecordingRules.CustomHeaders[key.Name()] = key.Value()
 	}
 
+	uaCfgRecordingRules.DefaultTarget = rr.Key("default_target").MustString(recordingRulesDefaultTarget)
+
+	rrInflux := iniFile.Section("recording_rules.influxdb")
+	uaCfgRecordingRules.InfluxDB = RecordingRuleInfluxDBSettings{
+		Enabled:           rrInflux.Key("enabled").MustBool(false),
+		URL:               rrInflux.Key("url").MustString(""),
+		Token:             rrInflux.Key("token").MustString(""),
+		Organization:      rrInflux.Key("organization").MustString(""),
+		Bucket:            rrInflux.Key("bucket").MustString(""),
+		Database:          rrInflux.Key("database").MustString(""),
+		BasicAuthUsername: rrInflux.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword: rrInflux.Key("basic_auth_password").MustString(""),
+		Timeout:           rrInflux.Key("timeout").MustDuration(defaultRecordingRequestTimeout),
+	}
+
+	rrSQL := iniFile.Section("recording_rules.sql")
+	uaCfgRecordingRules.SQL = RecordingRuleSQLSettings{
+		Enabled: rrSQL.Key("enabled").MustBool(false),
+	}
+	uaCfgRecordingRules.SQL.Retention, err = gtime.ParseDuration(valueAsString(rrSQL, "retention", "0"))
+	if err != nil {
+		return fmt.Errorf("setting 'retention' in section 'recording_rules.sql' is invalid: %w", err)
+	}
+	if uaCfgRecordingRules.SQL.Retention < 0 {
+		return fmt.Errorf("setting 'retention' in section 'recording_rules.sql' must not be negative")
+	}
+
+	rrLoki := iniFile.Section("recording_rules.loki")
+	uaCfgRecordingRules.Loki = RecordingRuleLokiSettings{
+		Enabled:           rrLoki.Key("enabled").MustBool(false),
+		URL:               rrLoki.Key("url").MustString(""),
+		TenantID:          rrLoki.Key("tenant_id").MustString(""),
+		BasicAuthUsername: rrLoki.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword: rrLoki.Key("basic_auth_password").MustString(""),
+		Timeout:           rrLoki.Key("timeout").MustDuration(defaultRecordingRequestTimeout),
+	}
+
+	rrBackfill := iniFile.Section("recording_rules.backfill")
+	uaCfgRecordingRules.Backfill = RecordingRuleBackfillSettings{
+		MaxEvaluations: rrBackfill.Key("max_evaluations").MustInt(backfillDefaultMaxEvaluations),
+		MaxConcurrent:  rrBackfill.Key("max_concurrent").MustInt(backfillDefaultMaxConcurrent),
+	}
+	if uaCfgRecordingRules.Backfill.MaxEvaluations <= 0 {
+		return fmt.Errorf("setting 'max_evaluations' in section 'recording_rules.backfill' must be greater than 0")
+	}
+	if uaCfgRecordingRules.Backfill.MaxConcurrent <= 0 {
+		return fmt.Errorf("setting 'max_concurrent' in section 'recording_rules.backfill' must be greater than 0")
+	}
+
 	uaCfg.RecordingRules = uaCfgRecordingRules
 
 	uaCfg.MaxStateSaveConcurrency = ua.Key("max_state_save_concurrency").MustInt(1)
@@ -2,6 +2,7 @@ package setting
 
 import (
 	"math/rand"
+	"os"
 	"strconv"
 	"testing"
 	"time"
@@ -346,3 +347,127 @@ func TestHARedisTLSSettings(t *testing.T) {
 	require.Equal(t, cipherSuites, cfg.UnifiedAlerting.HARedisTLSConfig.CipherSuites)
 	require.Equal(t, minVersion, cfg.UnifiedAlerting.HARedisTLSConfig.MinVersion)
 }
+
+func TestEvaluationShardingSettings(t *testing.T) {
+	read := func(t *testing.T, keys map[string]string) (*Cfg, error) {
+		t.Helper()
+		f := ini.Empty()
+		section, err := f.NewSection("unified_alerting")
+		require.NoError(t, err)
+		for k, v := range keys {
+			_, err = section.NewKey(k, v)
+			require.NoError(t, err)
+		}
+		cfg := NewCfg()
+		return cfg, cfg.ReadUnifiedAlertingSettings(f)
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := read(t, map[string]string{"ha_evaluation_sharding_enabled": "true"})
+		require.NoError(t, err)
+		hostname, err := os.Hostname()
+		require.NoError(t, err)
+		require.Equal(t, UnifiedAlertingEvaluationShardingSettings{
+			Enabled:           true,
+			InstanceID:        hostname,
+			HeartbeatInterval: 5 * time.Second,
+			HeartbeatTimeout:  30 * time.Second,
+		}, cfg.UnifiedAlerting.EvaluationSharding)
+	})
+
+	t.Run("the timeout must be greater than the heartbeat interval", func(t *testing.T) {
+		_, err := read(t, map[string]string{
+			"ha_evaluation_sharding_enabled":            "true",
+			"ha_evaluation_sharding_instance_id":        "grafana-0",
+			"ha_evaluation_sharding_heartbeat_interval": "1m",
+			"ha_evaluation_sharding_heartbeat_timeout":  "30s",
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestRecordingRulesSettings(t *testing.T) {
+	read := func(t *testing.T, sections map[string]map[string]string) (*Cfg, error) {
+		t.Helper()
+		f := ini.Empty()
+		for name, keys := range sections {
+			section, err := f.NewSection(name)
+			require.NoError(t, err)
+			for k, v := range keys {
+				_, err = section.NewKey(k, v)
+				require.NoError(t, err)
+			}
+		}
+		cfg := NewCfg()
+		return cfg, cfg.ReadUnifiedAlertingSettings(f)
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := read(t, nil)
+		require.NoError(t, err)
+		require.Equal(t, "prometheus", cfg.UnifiedAlerting.RecordingRules.DefaultTarget)
+		require.Equal(t, RecordingRuleInfluxDBSettings{Timeout: 10 * time.Second}, cfg.UnifiedAlerting.RecordingRules.InfluxDB)
+		require.Equal(t, RecordingRuleSQLSettings{}, cfg.UnifiedAlerting.RecordingRules.SQL)
+		require.Equal(t, RecordingRuleLokiSettings{Timeout: 10 * time.Second}, cfg.UnifiedAlerting.RecordingRules.Loki)
+		require.Equal(t, RecordingRuleBackfillSettings{MaxEvaluations: 10000, MaxConcurrent: 2}, cfg.UnifiedAlerting.RecordingRules.Backfill)
+	})
+
+	t.Run("reads settings of the writers", func(t *testing.T) {
+		cfg, err := read(t, map[string]map[string]string{
+			"recording_rules": {"enabled": "true", "default_target": "sql"},
+			"recording_rules.influxdb": {
+				"enabled":      "true",
+				"url":          "http://localhost:8086",
+				"token":        "token",
+				"organization": "org",
+				"bucket":       "bucket",
+				"timeout":      "5s",
+			},
+			"recording_rules.sql": {"enabled": "true", "retention": "30d"},
+			"recording_rules.loki": {
+				"enabled":   "true",
+				"url":       "http://localhost:3100",
+				"tenant_id": "tenant",
+				"timeout":   "5s",
+			},
+			"recording_rules.backfill": {"max_evaluations": "100", "max_concurrent": "1"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "sql", cfg.UnifiedAlerting.RecordingRules.DefaultTarget)
+		require.Equal(t, RecordingRuleInfluxDBSettings{
+			Enabled:      true,
+			URL:          "http://localhost:8086",
+			Token:        "token",
+			Organization: "org",
+			Bucket:       "bucket",
+			Timeout:      5 * time.Second,
+		}, cfg.UnifiedAlerting.RecordingRules.InfluxDB)
+		require.Equal(t, RecordingRuleSQLSettings{Enabled: true, Retention: 30 * 24 * time.Hour}, cfg.UnifiedAlerting.RecordingRules.SQL)
+		require.Equal(t, RecordingRuleLokiSettings{
+			Enabled:  true,
+			URL:      "http://localhost:3100",
+			TenantID: "tenant",
+			Timeout:  5 * time.Second,
+		}, cfg.UnifiedAlerting.RecordingRules.Loki)
+		require.Equal(t, RecordingRuleBackfillSettings{MaxEvaluations: 100, MaxConcurrent: 1}, cfg.UnifiedAlerting.RecordingRules.Backfill)
+	})
+
+	t.Run("fails if retention is negative", func(t *testing.T) {
+		_, err := read(t, map[string]map[string]string{
+			"recording_rules.sql": {"retention": "-1h"},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("fails if backfill limits are not positive", func(t *testing.T) {
+		_, err := read(t, map[string]map[string]string{
+			"recording_rules.backfill": {"max_evaluations": "0"},
+		})
+		require.Error(t, err)
+
+		_, err = read(t, map[string]map[string]string{
+			"recording_rules.backfill": {"max_concurrent": "0"},
+		})
+		require.Error(t, err)
+	})
+}
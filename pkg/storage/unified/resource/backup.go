@@ -0,0 +1,565 @@
+package resource
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	context "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// BackupStore is the part of the resource server used to back up and restore resources
+type BackupStore interface {
+	Read(context.Context, *ReadRequest) (*ReadResponse, error)
+	Create(context.Context, *CreateRequest) (*CreateResponse, error)
+	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	PutBlob(context.Context, *PutBlobRequest) (*PutBlobResponse, error)
+	GetBlob(context.Context, *GetBlobRequest) (*GetBlobResponse, error)
+}
+
+// NewBackupClientStore returns a BackupStore that reads and writes through the client.
+// It can not list the group/resources of the store, so the resources to back up are required.
+func NewBackupClientStore(client ResourceClient) BackupStore {
+	return &backupClientStore{client: client}
+}
+
+type backupClientStore struct {
+	client ResourceClient
+}
+
+func (s *backupClientStore) Read(ctx context.Context, req *ReadRequest) (*ReadResponse, error) {
+	return s.client.Read(ctx, req)
+}
+
+func (s *backupClientStore) Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error) {
+	return s.client.Create(ctx, req)
+}
+
+func (s *backupClientStore) Update(ctx context.Context, req *UpdateRequest) (*UpdateResponse, error) {
+	return s.client.Update(ctx, req)
+}
+
+func (s *backupClientStore) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	return s.client.List(ctx, req)
+}
+
+func (s *backupClientStore) PutBlob(ctx context.Context, req *PutBlobRequest) (*PutBlobResponse, error) {
+	return s.client.PutBlob(ctx, req)
+}
+
+func (s *backupClientStore) GetBlob(ctx context.Context, req *GetBlobRequest) (*GetBlobResponse, error) {
+	return s.client.GetBlob(ctx, req)
+}
+
+// ParseBackupResources reads group/resource pairs, eg. playlist.grafana.app/playlists
+func ParseBackupResources(values []string) ([]*ResourceKey, error) {
+	keys := make([]*ResourceKey, 0, len(values))
+	for _, v := range values {
+		group, res, ok := strings.Cut(v, "/")
+		if !ok || group == "" || res == "" {
+			return nil, fmt.Errorf("invalid resource %q, expected group/resource", v)
+		}
+		keys = append(keys, &ResourceKey{Group: group, Resource: res})
+	}
+	return keys, nil
+}
+
+// GroupResourceLister is implemented by storage backends which can list the
+// group/resources they hold, so all of them can be backed up.
+type GroupResourceLister interface {
+	ListGroupResources(ctx context.Context) ([]*ResourceKey, error)
+}
+
+// ListGroupResources implements GroupResourceLister.
+func (s *server) ListGroupResources(ctx context.Context) ([]*ResourceKey, error) {
+	lister, ok := s.backend.(GroupResourceLister)
+	if !ok {
+		return nil, fmt.Errorf("list group resources: %w", ErrNotImplementedYet)
+	}
+	if err := s.Init(ctx); err != nil {
+		return nil, err
+	}
+	return lister.ListGroupResources(ctx)
+}
+
+const (
+	backupFormatVersion = 1
+	backupManifestPath  = "manifest.json"
+	backupResourcesDir  = "resources"
+	backupBlobsDir      = "blobs"
+
+	// Used in place of the namespace in paths of cluster scoped resources,
+	// it can not collide with a namespace name.
+	backupClusterScope = "_cluster"
+
+	// PAX record keeping the content type of a blob
+	backupContentTypeRecord = "GRAFANA.content_type"
+
+	backupListPageSize = 100
+)
+
+// BackupManifest is the first entry of a backup archive.
+type BackupManifest struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	// The group/resources in the backup
+	Resources []*ResourceKey `json:"resources"`
+}
+
+type BackupOptions struct {
+	// Group+Resource and optionally Namespace of the resources to back up.
+	// All group/resources are backed up when empty, which requires the store
+	// to implement GroupResourceLister.
+	Resources []*ResourceKey
+
+	// Filter all group/resources by namespace
+	Namespace string
+}
+
+type BackupSummary struct {
+	Group     string `json:"group"`
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace,omitempty"`
+	// ResourceVersion of the list the resources were read from
+	ResourceVersion int64 `json:"resourceVersion"`
+	Count           int   `json:"count"`
+	Blobs           int   `json:"blobs"`
+}
+
+// WriteBackup writes the resources and their blobs to w as a gzipped tar
+// archive. Every group/resource is read at a single list resource version,
+// so it is consistent even when resources are written during the backup.
+func WriteBackup(ctx context.Context, store BackupStore, w io.Writer, opts BackupOptions) ([]BackupSummary, error) {
+	keys, err := backupKeys(ctx, store, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	raw, err := json.MarshalIndent(&BackupManifest{
+		Version:   backupFormatVersion,
+		Created:   time.Now().UTC(),
+		Resources: keys,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeTarFile(tw, backupManifestPath, raw, nil); err != nil {
+		return nil, err
+	}
+
+	summary := make([]BackupSummary, 0, len(keys))
+	for _, key := range keys {
+		info, err := backupResources(ctx, store, tw, key)
+		if err != nil {
+			return summary, fmt.Errorf("backup %s/%s: %w", key.Group, key.Resource, err)
+		}
+		summary = append(summary, info)
+	}
+	if err := tw.Close(); err != nil {
+		return summary, err
+	}
+	return summary, gw.Close()
+}
+
+func backupKeys(ctx context.Context, store BackupStore, opts BackupOptions) ([]*ResourceKey, error) {
+	keys := opts.Resources
+	if len(keys) == 0 {
+		lister, ok := store.(GroupResourceLister)
+		if !ok {
+			return nil, errors.New("the store can not list its resources, the resources to back up are required")
+		}
+		var err error
+		keys, err = lister.ListGroupResources(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	filtered := make([]*ResourceKey, 0, len(keys))
+	for _, k := range keys {
+		if k.Group == "" || k.Resource == "" {
+			return nil, fmt.Errorf("missing group or resource in %+v", k)
+		}
+		key := &ResourceKey{Group: k.Group, Resource: k.Resource, Namespace: k.Namespace}
+		if opts.Namespace != "" {
+			if key.Namespace != "" && key.Namespace != opts.Namespace {
+				continue
+			}
+			key.Namespace = opts.Namespace
+		}
+		filtered = append(filtered, key)
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		if filtered[i].Group != filtered[j].Group {
+			return filtered[i].Group < filtered[j].Group
+		}
+		return filtered[i].Resource < filtered[j].Resource
+	})
+	return filtered, nil
+}
+
+func backupResources(ctx context.Context, store BackupStore, tw *tar.Writer, key *ResourceKey) (BackupSummary, error) {
+	info := BackupSummary{
+		Group:     key.Group,
+		Resource:  key.Resource,
+		Namespace: key.Namespace,
+	}
+	req := &ListRequest{
+		Limit:   backupListPageSize,
+		Options: &ListOptions{Key: key},
+	}
+	for {
+		rsp, err := store.List(ctx, req)
+		if err != nil {
+			return info, err
+		}
+		if rsp.Error != nil {
+			return info, GetError(rsp.Error)
+		}
+		if info.ResourceVersion == 0 {
+			info.ResourceVersion = rsp.ResourceVersion
+		}
+
+		for _, item := range rsp.Items {
+			hasBlob, err := backupResource(ctx, store, tw, key, item)
+			if err != nil {
+				return info, err
+			}
+			info.Count++
+			if hasBlob {
+				info.Blobs++
+			}
+		}
+
+		if rsp.NextPageToken == "" {
+			return info, nil
+		}
+		if rsp.NextPageToken == req.NextPageToken {
+			return info, errors.New("list did not advance to the next page")
+		}
+		req.NextPageToken = rsp.NextPageToken
+	}
+}
+
+// backupResource writes the resource value, preceded by its blob if it has one.
+func backupResource(ctx context.Context, store BackupStore, tw *tar.Writer, listKey *ResourceKey, item *ResourceWrapper) (bool, error) {
+	tmp := &unstructured.Unstructured{}
+	if err := tmp.UnmarshalJSON(item.Value); err != nil {
+		return false, err
+	}
+	obj, err := utils.MetaAccessor(tmp)
+	if err != nil {
+		return false, err
+	}
+	key := &ResourceKey{
+		Group:     listKey.Group,
+		Resource:  listKey.Resource,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}
+
+	hasBlob := false
+	if blob := obj.GetBlob(); blob != nil && blob.UID != "" {
+		rsp, err := store.GetBlob(ctx, &GetBlobRequest{
+			Resource:        key,
+			ResourceVersion: item.ResourceVersion,
+			MustProxyBytes:  true,
+		})
+		if err != nil {
+			return false, err
+		}
+		if rsp.Error != nil {
+			return false, fmt.Errorf("get blob of %s: %w", key.Name, GetError(rsp.Error))
+		}
+		err = writeTarFile(tw, backupBlobPath(key), rsp.Value, map[string]string{
+			backupContentTypeRecord: rsp.ContentType,
+		})
+		if err != nil {
+			return false, err
+		}
+		hasBlob = true
+	}
+
+	return hasBlob, writeTarFile(tw, backupResourcePath(key), item.Value, nil)
+}
+
+func backupKeyPath(dir string, key *ResourceKey) string {
+	namespace := key.Namespace
+	if namespace == "" {
+		namespace = backupClusterScope
+	}
+	return path.Join(dir, key.Group, key.Resource, namespace, key.Name)
+}
+
+func backupResourcePath(key *ResourceKey) string {
+	return backupKeyPath(backupResourcesDir, key) + ".json"
+}
+
+func backupBlobPath(key *ResourceKey) string {
+	return backupKeyPath(backupBlobsDir, key)
+}
+
+// parseBackupPath returns the key of a resource or blob entry.
+func parseBackupPath(name string) (dir string, key *ResourceKey, err error) {
+	parts := strings.Split(name, "/")
+	if len(parts) != 5 || (parts[0] != backupResourcesDir && parts[0] != backupBlobsDir) {
+		return "", nil, fmt.Errorf("unexpected archive entry %q", name)
+	}
+	key = &ResourceKey{
+		Group:     parts[1],
+		Resource:  parts[2],
+		Namespace: parts[3],
+		Name:      parts[4],
+	}
+	if key.Namespace == backupClusterScope {
+		key.Namespace = ""
+	}
+	if parts[0] == backupResourcesDir {
+		if !strings.HasSuffix(key.Name, ".json") {
+			return "", nil, fmt.Errorf("unexpected archive entry %q", name)
+		}
+		key.Name = strings.TrimSuffix(key.Name, ".json")
+	}
+	return parts[0], key, nil
+}
+
+func writeTarFile(tw *tar.Writer, name string, value []byte, records map[string]string) error {
+	hdr := &tar.Header{
+		Typeflag:   tar.TypeReg,
+		Name:       name,
+		Mode:       0600,
+		Size:       int64(len(value)),
+		ModTime:    time.Now(),
+		PAXRecords: records,
+	}
+	if len(records) > 0 {
+		hdr.Format = tar.FormatPAX
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(value)
+	return err
+}
+
+// ConflictPolicy decides what happens when a restored resource already exists.
+type ConflictPolicy string
+
+const (
+	// ConflictSkip keeps the existing resource
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite replaces the existing resource with the one from the backup
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictFail stops the restore
+	ConflictFail ConflictPolicy = "fail"
+)
+
+// Validate returns an error if the policy is not empty and is not one of the known policies.
+func (p ConflictPolicy) Validate() error {
+	switch p {
+	case "", ConflictSkip, ConflictOverwrite, ConflictFail:
+		return nil
+	}
+	return fmt.Errorf("unknown conflict policy %q", p)
+}
+
+type RestoreOptions struct {
+	// What to do with resources that already exist, defaults to ConflictFail
+	Conflict ConflictPolicy
+
+	// When set, only resources matching one of the keys are restored
+	Resources []*ResourceKey
+
+	// When set, only resources in the namespace are restored
+	Namespace string
+}
+
+type RestoreSummary struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+	Blobs   int `json:"blobs"`
+}
+
+// RestoreBackup reads an archive written by WriteBackup and writes its
+// resources and blobs to the store.
+func RestoreBackup(ctx context.Context, store BackupStore, r io.Reader, opts RestoreOptions) (*RestoreSummary, error) {
+	if err := opts.Conflict.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Conflict == "" {
+		opts.Conflict = ConflictFail
+	}
+
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("read backup: %w", err)
+	}
+	defer func() { _ = gr.Close() }()
+	tr := tar.NewReader(gr)
+
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("read backup: %w", err)
+	}
+	if hdr.Name != backupManifestPath {
+		return nil, fmt.Errorf("read backup: expected %s, found %s", backupManifestPath, hdr.Name)
+	}
+	manifest := &BackupManifest{}
+	if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("read backup manifest: %w", err)
+	}
+	if manifest.Version != backupFormatVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", manifest.Version)
+	}
+
+	summary := &RestoreSummary{}
+	// blob preceding the resource entry it belongs to
+	var blob *PutBlobRequest
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return summary, nil
+		}
+		if err != nil {
+			return summary, fmt.Errorf("read backup: %w", err)
+		}
+		dir, key, err := parseBackupPath(hdr.Name)
+		if err != nil {
+			return summary, err
+		}
+		value, err := io.ReadAll(tr)
+		if err != nil {
+			return summary, err
+		}
+
+		if dir == backupBlobsDir {
+			blob = &PutBlobRequest{
+				Resource:    key,
+				Method:      PutBlobRequest_GRPC,
+				ContentType: hdr.PAXRecords[backupContentTypeRecord],
+				Value:       value,
+			}
+			continue
+		}
+
+		if blob != nil && !keysEqual(blob.Resource, key) {
+			blob = nil
+		}
+		if restoreMatches(opts, key) {
+			if err := restoreResource(ctx, store, key, value, blob, opts.Conflict, summary); err != nil {
+				return summary, fmt.Errorf("restore %s/%s %s: %w", key.Group, key.Resource, key.Name, err)
+			}
+		}
+		blob = nil
+	}
+}
+
+func restoreMatches(opts RestoreOptions, key *ResourceKey) bool {
+	if opts.Namespace != "" && key.Namespace != opts.Namespace {
+		return false
+	}
+	if len(opts.Resources) == 0 {
+		return true
+	}
+	for _, query := range opts.Resources {
+		if matchesQueryKey(query, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func keysEqual(a, b *ResourceKey) bool {
+	return a.Group == b.Group && a.Resource == b.Resource && a.Namespace == b.Namespace && a.Name == b.Name
+}
+
+func restoreResource(ctx context.Context, store BackupStore, key *ResourceKey, value []byte, blob *PutBlobRequest, conflict ConflictPolicy, summary *RestoreSummary) error {
+	found, err := store.Read(ctx, &ReadRequest{Key: key})
+	if err != nil {
+		return err
+	}
+	exists := found.Error == nil && len(found.Value) > 0
+	if found.Error != nil && found.Error.Code != http.StatusNotFound {
+		return GetError(found.Error)
+	}
+	if exists {
+		switch conflict {
+		case ConflictSkip:
+			summary.Skipped++
+			return nil
+		case ConflictFail:
+			return errors.New("resource already exists")
+		}
+	}
+
+	tmp := &unstructured.Unstructured{}
+	if err := tmp.UnmarshalJSON(value); err != nil {
+		return err
+	}
+	obj, err := utils.MetaAccessor(tmp)
+	if err != nil {
+		return err
+	}
+	// The resource version is set by the store
+	obj.SetResourceVersion("")
+
+	if blob != nil {
+		rsp, err := store.PutBlob(ctx, blob)
+		if err != nil {
+			return err
+		}
+		if rsp.Error != nil {
+			return fmt.Errorf("put blob: %w", GetError(rsp.Error))
+		}
+		info := obj.GetBlob()
+		if info == nil {
+			info = &utils.BlobInfo{}
+		}
+		info.UID = rsp.Uid
+		info.Size = rsp.Size
+		info.Hash = rsp.Hash
+		obj.SetBlob(info)
+		summary.Blobs++
+	}
+
+	value, err = tmp.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		rsp, err := store.Update(ctx, &UpdateRequest{Key: key, Value: value})
+		if err != nil {
+			return err
+		}
+		if rsp.Error != nil {
+			return GetError(rsp.Error)
+		}
+		summary.Updated++
+		return nil
+	}
+
+	rsp, err := store.Create(ctx, &CreateRequest{Key: key, Value: value})
+	if err != nil {
+		return err
+	}
+	if rsp.Error != nil {
+		return GetError(rsp.Error)
+	}
+	summary.Created++
+	return nil
+}
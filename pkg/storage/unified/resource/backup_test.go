@@ -0,0 +1,214 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob/memblob"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newBackupTestServer(t *testing.T) (context.Context, ResourceServer) {
+	t.Helper()
+	ctx := claims.WithClaims(context.Background(), &identity.StaticRequester{
+		Type:           claims.TypeUser,
+		Login:          "testuser",
+		UserID:         123,
+		UserUID:        "u123",
+		OrgRole:        identity.RoleAdmin,
+		IsGrafanaAdmin: true,
+	})
+
+	backend, err := NewCDKBackend(ctx, CDKBackendOptions{Bucket: memblob.OpenBucket(nil)})
+	require.NoError(t, err)
+	blobstore, err := NewCDKBlobSupport(ctx, CDKBlobSupportOptions{Bucket: memblob.OpenBucket(nil)})
+	require.NoError(t, err)
+	server, err := NewResourceServer(ResourceServerOptions{
+		Backend: backend,
+		Blob:    BlobConfig{Backend: blobstore},
+	})
+	require.NoError(t, err)
+	return ctx, server
+}
+
+func playlistKey(namespace, name string) *ResourceKey {
+	return &ResourceKey{
+		Group:     "playlist.grafana.app",
+		Resource:  "playlists",
+		Namespace: namespace,
+		Name:      name,
+	}
+}
+
+func playlistValue(key *ResourceKey, title string) []byte {
+	return []byte(fmt.Sprintf(`{
+		"apiVersion": "playlist.grafana.app/v0alpha1",
+		"kind": "Playlist",
+		"metadata": {
+			"name": %q,
+			"namespace": %q,
+			"uid": "uid-%s"
+		},
+		"spec": {
+			"title": %q,
+			"interval": "5m"
+		}
+	}`, key.Name, key.Namespace, key.Name, title))
+}
+
+func createPlaylist(t *testing.T, ctx context.Context, server ResourceServer, key *ResourceKey, title string, blob []byte) {
+	t.Helper()
+	value := playlistValue(key, title)
+	if blob != nil {
+		rsp, err := server.PutBlob(ctx, &PutBlobRequest{
+			Resource:    key,
+			ContentType: "text/plain; charset=utf-8",
+			Value:       blob,
+		})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+
+		tmp := &unstructured.Unstructured{}
+		require.NoError(t, tmp.UnmarshalJSON(value))
+		obj, err := utils.MetaAccessor(tmp)
+		require.NoError(t, err)
+		obj.SetBlob(&utils.BlobInfo{UID: rsp.Uid, Size: rsp.Size, Hash: rsp.Hash, MimeType: rsp.MimeType, Charset: rsp.Charset})
+		value, err = tmp.MarshalJSON()
+		require.NoError(t, err)
+	}
+	rsp, err := server.Create(ctx, &CreateRequest{Key: key, Value: value})
+	require.NoError(t, err)
+	require.Nil(t, rsp.Error)
+}
+
+func readPlaylistTitle(t *testing.T, ctx context.Context, server ResourceServer, key *ResourceKey) string {
+	t.Helper()
+	rsp, err := server.Read(ctx, &ReadRequest{Key: key})
+	require.NoError(t, err)
+	require.Nil(t, rsp.Error)
+	playlist := struct {
+		Spec struct {
+			Title string `json:"title"`
+		} `json:"spec"`
+	}{}
+	require.NoError(t, json.Unmarshal(rsp.Value, &playlist))
+	return playlist.Spec.Title
+}
+
+func TestBackupRestore(t *testing.T) {
+	ctx, source := newBackupTestServer(t)
+	createPlaylist(t, ctx, source, playlistKey("default", "a"), "A", []byte("hello blob"))
+	createPlaylist(t, ctx, source, playlistKey("default", "b"), "B", nil)
+	createPlaylist(t, ctx, source, playlistKey("other", "c"), "C", nil)
+
+	resources := []*ResourceKey{{Group: "playlist.grafana.app", Resource: "playlists"}}
+
+	backup := &bytes.Buffer{}
+	summary, err := WriteBackup(ctx, source, backup, BackupOptions{Resources: resources})
+	require.NoError(t, err)
+	require.Len(t, summary, 1)
+	require.Equal(t, 3, summary[0].Count)
+	require.Equal(t, 1, summary[0].Blobs)
+
+	t.Run("restore into an empty store", func(t *testing.T) {
+		ctx, target := newBackupTestServer(t)
+		restored, err := RestoreBackup(ctx, target, bytes.NewReader(backup.Bytes()), RestoreOptions{})
+		require.NoError(t, err)
+		require.Equal(t, &RestoreSummary{Created: 3, Blobs: 1}, restored)
+
+		require.Equal(t, "A", readPlaylistTitle(t, ctx, target, playlistKey("default", "a")))
+		require.Equal(t, "C", readPlaylistTitle(t, ctx, target, playlistKey("other", "c")))
+
+		blob, err := target.GetBlob(ctx, &GetBlobRequest{Resource: playlistKey("default", "a"), MustProxyBytes: true})
+		require.NoError(t, err)
+		require.Nil(t, blob.Error)
+		require.Equal(t, "hello blob", string(blob.Value))
+	})
+
+	t.Run("restore a namespace", func(t *testing.T) {
+		ctx, target := newBackupTestServer(t)
+		restored, err := RestoreBackup(ctx, target, bytes.NewReader(backup.Bytes()), RestoreOptions{Namespace: "other"})
+		require.NoError(t, err)
+		require.Equal(t, &RestoreSummary{Created: 1}, restored)
+
+		rsp, err := target.Read(ctx, &ReadRequest{Key: playlistKey("default", "a")})
+		require.NoError(t, err)
+		require.NotNil(t, rsp.Error)
+	})
+
+	t.Run("conflict policies", func(t *testing.T) {
+		ctx, target := newBackupTestServer(t)
+		createPlaylist(t, ctx, target, playlistKey("default", "b"), "existing", nil)
+
+		_, err := RestoreBackup(ctx, target, bytes.NewReader(backup.Bytes()), RestoreOptions{Conflict: ConflictFail})
+		require.ErrorContains(t, err, "already exists")
+
+		restored, err := RestoreBackup(ctx, target, bytes.NewReader(backup.Bytes()), RestoreOptions{Conflict: ConflictSkip})
+		require.NoError(t, err)
+		// a was restored before the conflict on b stopped the previous attempt
+		require.Equal(t, &RestoreSummary{Created: 1, Skipped: 2}, restored)
+		require.Equal(t, "existing", readPlaylistTitle(t, ctx, target, playlistKey("default", "b")))
+
+		restored, err = RestoreBackup(ctx, target, bytes.NewReader(backup.Bytes()), RestoreOptions{Conflict: ConflictOverwrite})
+		require.NoError(t, err)
+		require.Equal(t, 3, restored.Updated)
+		require.Equal(t, "B", readPlaylistTitle(t, ctx, target, playlistKey("default", "b")))
+	})
+
+	t.Run("unknown conflict policy", func(t *testing.T) {
+		ctx, target := newBackupTestServer(t)
+		_, err := RestoreBackup(ctx, target, bytes.NewReader(backup.Bytes()), RestoreOptions{Conflict: "merge"})
+		require.Error(t, err)
+	})
+}
+
+func TestBackupKeys(t *testing.T) {
+	ctx, server := newBackupTestServer(t)
+
+	t.Run("resources are required when the backend can not list them", func(t *testing.T) {
+		_, err := WriteBackup(ctx, server, &bytes.Buffer{}, BackupOptions{})
+		require.ErrorIs(t, err, ErrNotImplementedYet)
+	})
+
+	t.Run("namespace filter", func(t *testing.T) {
+		keys, err := backupKeys(ctx, server, BackupOptions{
+			Resources: []*ResourceKey{
+				{Group: "g", Resource: "b"},
+				{Group: "g", Resource: "a", Namespace: "other"},
+			},
+			Namespace: "default",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*ResourceKey{{Group: "g", Resource: "b", Namespace: "default"}}, keys)
+	})
+}
+
+func TestParseBackupPath(t *testing.T) {
+	tests := []struct {
+		key *ResourceKey
+	}{
+		{key: playlistKey("default", "a")},
+		{key: &ResourceKey{Group: "folder.grafana.app", Resource: "folders", Name: "cluster-scoped"}},
+	}
+	for _, tt := range tests {
+		dir, key, err := parseBackupPath(backupResourcePath(tt.key))
+		require.NoError(t, err)
+		require.Equal(t, backupResourcesDir, dir)
+		require.Equal(t, tt.key, key)
+
+		dir, key, err = parseBackupPath(backupBlobPath(tt.key))
+		require.NoError(t, err)
+		require.Equal(t, backupBlobsDir, dir)
+		require.Equal(t, tt.key, key)
+	}
+
+	_, _, err := parseBackupPath("resources/too/short.json")
+	require.Error(t, err)
+}
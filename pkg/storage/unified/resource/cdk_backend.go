@@ -219,6 +219,120 @@ func (s *cdkBackend) ListIterator(ctx context.Context, req *ListRequest, cb func
 	return resources.listRV, err
 }
 
+// HistoryIterator implements HistorySupport.
+func (s *cdkBackend) HistoryIterator(ctx context.Context, req *HistoryRequest, cb func(ListIterator) error) (int64, error) {
+	offset := 0
+	if req.NextPageToken != "" {
+		var err error
+		offset, err = strconv.Atoi(req.NextPageToken)
+		if err != nil {
+			return 0, fmt.Errorf("invalid continue token: %w", err)
+		}
+	}
+
+	var versions []cdkVersion
+	iter := s.bucket.List(&blob.ListOptions{Prefix: s.getPath(req.Key, 0) + "/", Delimiter: "/"})
+	for {
+		obj, err := iter.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if strings.HasSuffix(obj.Key, ".json") {
+			idx := strings.LastIndex(obj.Key, "/") + 1
+			edx := strings.LastIndex(obj.Key, ".")
+			rv, err := strconv.ParseInt(obj.Key[idx:edx], 10, 64)
+			if err == nil {
+				versions = append(versions, cdkVersion{rv: rv, key: obj.Key})
+			}
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].rv > versions[j].rv
+	})
+
+	err := cb(&cdkHistoryIterator{
+		ctx:         ctx,
+		bucket:      s.bucket,
+		versions:    versions,
+		showDeleted: req.ShowDeleted,
+		namespace:   req.Key.Namespace,
+		name:        req.Key.Name,
+		index:       offset - 1, // must call next first
+	})
+	return s.rv.Load(), err
+}
+
+// TrashIterator implements TrashSupport.
+func (s *cdkBackend) TrashIterator(ctx context.Context, req *ListRequest, cb func(ListIterator) error) (int64, error) {
+	resources, err := buildTree(ctx, s, req.Options.Key)
+	if err != nil {
+		return 0, err
+	}
+	resources.deleted = true
+	if req.NextPageToken != "" {
+		// the token points to the last item that was returned
+		if _, err := fmt.Sscanf(req.NextPageToken, "index:%d/", &resources.index); err != nil {
+			return 0, fmt.Errorf("invalid continue token: %w", err)
+		}
+	}
+	err = cb(resources)
+	return resources.listRV, err
+}
+
+// PurgeResource implements TrashSupport.
+func (s *cdkBackend) PurgeResource(ctx context.Context, key *ResourceKey) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var keys []string
+	iter := s.bucket.List(&blob.ListOptions{Prefix: s.getPath(key, 0) + "/", Delimiter: "/"})
+	for {
+		obj, err := iter.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(obj.Key, ".json") {
+			keys = append(keys, obj.Key)
+		}
+	}
+	sort.Strings(keys)
+
+	// Only resources that are deleted can be purged
+	latest := ""
+	latestRV := int64(0)
+	for _, k := range keys {
+		idx := strings.LastIndex(k, "/") + 1
+		edx := strings.LastIndex(k, ".")
+		rv, err := strconv.ParseInt(k[idx:edx], 10, 64)
+		if err == nil && rv > latestRV {
+			latest, latestRV = k, rv
+		}
+	}
+	if latest == "" {
+		return nil
+	}
+	raw, err := s.bucket.ReadAll(ctx, latest)
+	if err != nil {
+		return err
+	}
+	if !isDeletedMarker(raw) {
+		return fmt.Errorf("only deleted resources can be purged")
+	}
+
+	for _, k := range keys {
+		if err := s.bucket.Delete(ctx, k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *cdkBackend) WatchWriteEvents(ctx context.Context) (<-chan *WrittenEvent, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -255,6 +369,9 @@ type cdkListIterator struct {
 	resources []cdkResource
 	index     int
 
+	// only iterate the resources that are deleted
+	deleted bool
+
 	currentRV  int64
 	currentKey string
 	currentVal []byte
@@ -279,7 +396,7 @@ func (c *cdkListIterator) Next() bool {
 			c.err = err
 			return false
 		}
-		if !isDeletedMarker(raw) {
+		if isDeletedMarker(raw) == c.deleted {
 			c.currentRV = latest.rv
 			c.currentKey = latest.key
 			c.currentVal = raw
@@ -320,6 +437,83 @@ func (c *cdkListIterator) Namespace() string {
 
 var _ ListIterator = (*cdkListIterator)(nil)
 
+// cdkHistoryIterator iterates the versions of a single resource
+type cdkHistoryIterator struct {
+	bucket      CDKBucket
+	ctx         context.Context
+	err         error
+	versions    []cdkVersion
+	showDeleted bool
+	namespace   string
+	name        string
+	index       int
+
+	currentRV  int64
+	currentVal []byte
+}
+
+// Next implements ListIterator.
+func (c *cdkHistoryIterator) Next() bool {
+	if c.err != nil {
+		return false
+	}
+	for {
+		c.currentVal = nil
+		c.index += 1
+		if c.index >= len(c.versions) {
+			return false
+		}
+
+		version := c.versions[c.index]
+		raw, err := c.bucket.ReadAll(c.ctx, version.key)
+		if err != nil {
+			c.err = err
+			return false
+		}
+		if c.showDeleted || !isDeletedMarker(raw) {
+			c.currentRV = version.rv
+			c.currentVal = raw
+			return true
+		}
+	}
+}
+
+// Error implements ListIterator.
+func (c *cdkHistoryIterator) Error() error {
+	return c.err
+}
+
+// ResourceVersion implements ListIterator.
+func (c *cdkHistoryIterator) ResourceVersion() int64 {
+	return c.currentRV
+}
+
+// Value implements ListIterator.
+func (c *cdkHistoryIterator) Value() []byte {
+	return c.currentVal
+}
+
+// ContinueToken implements ListIterator.
+func (c *cdkHistoryIterator) ContinueToken() string {
+	return strconv.Itoa(c.index + 1)
+}
+
+// Name implements ListIterator.
+func (c *cdkHistoryIterator) Name() string {
+	return c.name
+}
+
+// Namespace implements ListIterator.
+func (c *cdkHistoryIterator) Namespace() string {
+	return c.namespace
+}
+
+var (
+	_ ListIterator   = (*cdkHistoryIterator)(nil)
+	_ HistorySupport = (*cdkBackend)(nil)
+	_ TrashSupport   = (*cdkBackend)(nil)
+)
+
 func buildTree(ctx context.Context, s *cdkBackend, key *ResourceKey) (*cdkListIterator, error) {
 	byPrefix := make(map[string]*cdkResource)
 	path := s.getPath(key, 0)
@@ -5,7 +5,9 @@ import (
 	context "context"
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"mime"
 	"time"
 
@@ -76,6 +78,26 @@ type cdkBlobSupport struct {
 }
 
 func (s *cdkBlobSupport) getBlobPath(key *ResourceKey, info *utils.BlobInfo) (string, error) {
+	path, err := s.getResourcePath(key)
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	buffer.WriteString(path)
+	buffer.WriteString(info.UID)
+
+	ext, err := mime.ExtensionsByType(info.MimeType)
+	if err != nil {
+		return "", err
+	}
+	if len(ext) > 0 {
+		buffer.WriteString(ext[0])
+	}
+	return buffer.String(), nil
+}
+
+// getResourcePath returns the folder of the blobs of a resource
+func (s *cdkBlobSupport) getResourcePath(key *ResourceKey) (string, error) {
 	var buffer bytes.Buffer
 	buffer.WriteString(s.root)
 
@@ -103,15 +125,6 @@ func (s *cdkBlobSupport) getBlobPath(key *ResourceKey, info *utils.BlobInfo) (st
 	}
 	buffer.WriteString(key.Name)
 	buffer.WriteString("/")
-	buffer.WriteString(info.UID)
-
-	ext, err := mime.ExtensionsByType(info.MimeType)
-	if err != nil {
-		return "", err
-	}
-	if len(ext) > 0 {
-		buffer.WriteString(ext[0])
-	}
 	return buffer.String(), nil
 }
 
@@ -183,3 +196,30 @@ func (s *cdkBlobSupport) GetResourceBlob(ctx context.Context, resource *Resource
 	})
 	return rsp, err
 }
+
+func (s *cdkBlobSupport) DeleteResourceBlobs(ctx context.Context, resource *ResourceKey) error {
+	path, err := s.getResourcePath(resource)
+	if err != nil {
+		return err
+	}
+
+	// Find the blobs first, buckets may not support deletes while listing
+	var keys []string
+	iter := s.bucket.List(&blob.ListOptions{Prefix: path})
+	for {
+		obj, err := iter.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		keys = append(keys, obj.Key)
+	}
+	for _, key := range keys {
+		if err := s.bucket.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -63,4 +63,32 @@ func TestCDKBlobStore(t *testing.T) {
 		require.Equal(t, raw, found.Value)
 		require.Equal(t, "application/json", found.ContentType)
 	})
+
+	t.Run("can delete the blobs of a resource", func(t *testing.T) {
+		put := func(name string) *utils.BlobInfo {
+			rsp, err := store.PutResourceBlob(ctx, &PutBlobRequest{
+				Resource:    &ResourceKey{Group: "playlist.grafana.app", Resource: "rrrr", Namespace: "default", Name: name},
+				Method:      PutBlobRequest_GRPC,
+				ContentType: "application/json",
+				Value:       []byte(`{}`),
+			})
+			require.NoError(t, err)
+			return &utils.BlobInfo{UID: rsp.Uid, MimeType: rsp.MimeType}
+		}
+		key := &ResourceKey{Group: "playlist.grafana.app", Resource: "rrrr", Namespace: "default", Name: "deleted"}
+		// The name of this resource starts with the name of the deleted resource
+		other := &ResourceKey{Group: key.Group, Resource: key.Resource, Namespace: key.Namespace, Name: "deleted2"}
+		first, second, kept := put(key.Name), put(key.Name), put(other.Name)
+
+		err := store.DeleteResourceBlobs(ctx, key)
+		require.NoError(t, err)
+
+		for _, info := range []*utils.BlobInfo{first, second} {
+			_, err = store.GetResourceBlob(ctx, key, info, true)
+			require.Error(t, err)
+		}
+		found, err := store.GetResourceBlob(ctx, other, kept, true)
+		require.NoError(t, err)
+		require.Equal(t, []byte(`{}`), found.Value)
+	})
 }
@@ -28,6 +28,7 @@ type ResourceClient interface {
 	ResourceIndexClient
 	BlobStoreClient
 	DiagnosticsClient
+	ResourceTrashClient
 }
 
 // Internal implementation
@@ -36,6 +37,7 @@ type resourceClient struct {
 	ResourceIndexClient
 	BlobStoreClient
 	DiagnosticsClient
+	ResourceTrashClient
 }
 
 func NewLegacyResourceClient(channel *grpc.ClientConn) ResourceClient {
@@ -45,6 +47,7 @@ func NewLegacyResourceClient(channel *grpc.ClientConn) ResourceClient {
 		ResourceIndexClient: NewResourceIndexClient(cc),
 		BlobStoreClient:     NewBlobStoreClient(cc),
 		DiagnosticsClient:   NewDiagnosticsClient(cc),
+		ResourceTrashClient: NewResourceTrashClient(cc),
 	}
 }
 
@@ -58,6 +61,7 @@ func NewLocalResourceClient(server ResourceServer) ResourceClient {
 		&ResourceIndex_ServiceDesc,
 		&BlobStore_ServiceDesc,
 		&Diagnostics_ServiceDesc,
+		&ResourceTrash_ServiceDesc,
 	} {
 		channel.RegisterService(
 			grpchan.InterceptServer(
@@ -81,6 +85,7 @@ func NewLocalResourceClient(server ResourceServer) ResourceClient {
 		ResourceIndexClient: NewResourceIndexClient(cc),
 		BlobStoreClient:     NewBlobStoreClient(cc),
 		DiagnosticsClient:   NewDiagnosticsClient(cc),
+		ResourceTrashClient: NewResourceTrashClient(cc),
 	}
 }
 
@@ -101,6 +106,7 @@ func NewGRPCResourceClient(tracer tracing.Tracer, conn *grpc.ClientConn) (Resour
 		ResourceStoreClient: NewResourceStoreClient(cc),
 		ResourceIndexClient: NewResourceIndexClient(cc),
 		DiagnosticsClient:   NewDiagnosticsClient(cc),
+		ResourceTrashClient: NewResourceTrashClient(cc),
 	}, nil
 }
 
@@ -125,6 +131,7 @@ func NewCloudResourceClient(tracer tracing.Tracer, conn *grpc.ClientConn, cfg au
 		ResourceStoreClient: NewResourceStoreClient(cc),
 		ResourceIndexClient: NewResourceIndexClient(cc),
 		DiagnosticsClient:   NewDiagnosticsClient(cc),
+		ResourceTrashClient: NewResourceTrashClient(cc),
 	}, nil
 }
 
@@ -0,0 +1,177 @@
+package resource
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+)
+
+// HistorySupport is implemented by backends that keep the previous versions
+// of each resource.
+type HistorySupport interface {
+	// Iterate the versions of a single resource, the most recent first.
+	// The iterator returns the full resource value of each version.
+	HistoryIterator(context.Context, *HistoryRequest, func(ListIterator) error) (int64, error)
+}
+
+// history lists the versions of a single resource from the storage backend
+func (s *server) history(ctx context.Context, req *HistoryRequest) (*HistoryResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "storage_server.History")
+	defer span.End()
+
+	if req.Key == nil || req.Key.Group == "" || req.Key.Resource == "" || req.Key.Name == "" {
+		return &HistoryResponse{Error: NewBadRequestError("history requires a group, resource and name")}, nil
+	}
+	backend, ok := s.backend.(HistorySupport)
+	if !ok {
+		return &HistoryResponse{Error: &ErrorResult{
+			Message: "history not supported by the storage backend",
+			Code:    http.StatusNotImplemented,
+		}}, nil
+	}
+	if req.Limit < 1 {
+		req.Limit = 50 // default max 50 items in a page
+	}
+
+	rsp := &HistoryResponse{}
+	rv, err := backend.HistoryIterator(ctx, req, func(iter ListIterator) error {
+		for iter.Next() {
+			if err := iter.Error(); err != nil {
+				return err
+			}
+
+			meta, err := resourceMeta(iter.ResourceVersion(), iter.Value())
+			if err != nil {
+				return err
+			}
+			rsp.Items = append(rsp.Items, meta)
+			if len(rsp.Items) >= int(req.Limit) {
+				t := iter.ContinueToken()
+				if iter.Next() {
+					rsp.NextPageToken = t
+				}
+				break
+			}
+		}
+		return iter.Error()
+	})
+	if err != nil {
+		rsp.Error = AsErrorResult(err)
+		return rsp, nil
+	}
+	rsp.ResourceVersion = rv
+	return rsp, nil
+}
+
+// origin lists the resources that were saved from an origin, such as
+// provisioning or a synced repository
+func (s *server) origin(ctx context.Context, req *OriginRequest) (*OriginResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "storage_server.Origin")
+	defer span.End()
+
+	if req.Key == nil || req.Key.Group == "" || req.Key.Resource == "" {
+		return &OriginResponse{Error: NewBadRequestError("origin requires a group and resource")}, nil
+	}
+	if req.Limit < 1 {
+		req.Limit = 50 // default max 50 items in a page
+	}
+
+	rsp := &OriginResponse{}
+	listReq := &ListRequest{
+		NextPageToken: req.NextPageToken,
+		Options:       &ListOptions{Key: req.Key},
+	}
+	rv, err := s.backend.ListIterator(ctx, listReq, func(iter ListIterator) error {
+		for iter.Next() {
+			if err := iter.Error(); err != nil {
+				return err
+			}
+
+			info, err := resourceOriginInfo(req.Key, iter.Value())
+			if err != nil {
+				return err
+			}
+			if info == nil || (req.Origin != "" && info.Origin != req.Origin) {
+				continue
+			}
+			rsp.Items = append(rsp.Items, info)
+			if len(rsp.Items) >= int(req.Limit) {
+				t := iter.ContinueToken()
+				if iter.Next() {
+					rsp.NextPageToken = t
+				}
+				break
+			}
+		}
+		return iter.Error()
+	})
+	if err != nil {
+		rsp.Error = AsErrorResult(err)
+		return rsp, nil
+	}
+	rsp.ResourceVersion = rv
+	return rsp, nil
+}
+
+func resourceMeta(rv int64, value []byte) (*ResourceMeta, error) {
+	partial := &metav1.PartialObjectMetadata{}
+	if err := json.Unmarshal(value, partial); err != nil {
+		return nil, fmt.Errorf("read resource version %d: %w", rv, err)
+	}
+	meta, err := json.Marshal(partial.ObjectMeta)
+	if err != nil {
+		return nil, err
+	}
+	return &ResourceMeta{
+		ResourceVersion:   rv,
+		Size:              int32(len(value)),
+		Hash:              valueHash(value),
+		PartialObjectMeta: meta,
+	}, nil
+}
+
+// resourceOriginInfo returns nil when the resource was not saved from an origin
+func resourceOriginInfo(key *ResourceKey, value []byte) (*ResourceOriginInfo, error) {
+	partial := &metav1.PartialObjectMetadata{}
+	if err := json.Unmarshal(value, partial); err != nil {
+		return nil, err
+	}
+	obj, err := utils.MetaAccessor(partial)
+	if err != nil {
+		return nil, err
+	}
+	origin, err := obj.GetOriginInfo()
+	if err != nil || origin == nil {
+		return nil, err
+	}
+
+	info := &ResourceOriginInfo{
+		Key: &ResourceKey{
+			Group:     key.Group,
+			Resource:  key.Resource,
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+		},
+		ResourceSize: int32(len(value)),
+		ResourceHash: valueHash(value),
+		Origin:       origin.Name,
+		Path:         origin.Path,
+		Hash:         origin.Hash,
+	}
+	if origin.Timestamp != nil {
+		info.Timestamp = origin.Timestamp.UnixMilli()
+	}
+	return info, nil
+}
+
+func valueHash(value []byte) string {
+	h := md5.Sum(value)
+	return hex.EncodeToString(h[:])
+}
@@ -43,12 +43,26 @@ func (is *IndexServer) Search(ctx context.Context, req *SearchRequest) (*SearchR
 	return res, nil
 }
 
+// History lists the versions of a resource from the storage backend
 func (is *IndexServer) History(ctx context.Context, req *HistoryRequest) (*HistoryResponse, error) {
-	return nil, nil
+	ctx, span := is.tracer.Start(ctx, tracingPrefixIndexServer+"History")
+	defer span.End()
+
+	if is.s == nil {
+		return nil, ErrNotImplementedYet
+	}
+	return is.s.history(ctx, req)
 }
 
+// Origin lists the resources that were saved from an origin
 func (is *IndexServer) Origin(ctx context.Context, req *OriginRequest) (*OriginResponse, error) {
-	return nil, nil
+	ctx, span := is.tracer.Start(ctx, tracingPrefixIndexServer+"Origin")
+	defer span.End()
+
+	if is.s == nil {
+		return nil, ErrNotImplementedYet
+	}
+	return is.s.origin(ctx, req)
 }
 
 // Load the index
@@ -27,6 +27,7 @@ type ResourceServer interface {
 	ResourceIndexServer
 	BlobStoreServer
 	DiagnosticsServer
+	ResourceTrashServer
 }
 
 type ListIterator interface {
@@ -91,7 +92,10 @@ type BlobSupport interface {
 	// For large payloads, signed URLs are required to avoid protobuf message size limits
 	GetResourceBlob(ctx context.Context, resource *ResourceKey, info *utils.BlobInfo, mustProxy bool) (*GetBlobResponse, error)
 
-	// TODO? List+Delete?  This is for admin access
+	// Delete all the blobs of a resource, used when the resource is purged
+	DeleteResourceBlobs(ctx context.Context, resource *ResourceKey) error
+
+	// TODO? List?  This is for admin access
 }
 
 type BlobConfig struct {
@@ -130,6 +134,10 @@ type ResourceServerOptions struct {
 
 	// Registerer to register prometheus Metrics for the Resource server
 	Reg prometheus.Registerer
+
+	// How long deleted resources can be restored, when set they are purged afterwards.
+	// Requires a backend with TrashSupport
+	TrashRetention time.Duration
 }
 
 func NewResourceServer(opts ResourceServerOptions) (ResourceServer, error) {
@@ -192,6 +200,7 @@ func NewResourceServer(opts ResourceServerOptions) (ResourceServer, error) {
 		access:      opts.WriteAccess,
 		lifecycle:   opts.Lifecycle,
 		now:         opts.Now,
+		retention:   opts.TrashRetention,
 		ctx:         ctx,
 		cancel:      cancel,
 	}, nil
@@ -209,6 +218,7 @@ type server struct {
 	access       WriteAccessHooks
 	lifecycle    LifecycleHooks
 	now          func() int64
+	retention    time.Duration
 	mostRecentRV atomic.Int64 // The most recent resource version seen by the server
 
 	// Background watch task -- this has permissions for everything
@@ -237,6 +247,13 @@ func (s *server) Init(ctx context.Context) error {
 			s.initErr = s.initWatcher()
 		}
 
+		// Purge the trash in the background
+		if s.initErr == nil && s.retention > 0 {
+			if _, ok := s.backend.(TrashSupport); ok {
+				go s.runTrashPurge(s.retention)
+			}
+		}
+
 		if s.initErr != nil {
 			s.log.Error("error initializing resource server", "error", s.initErr)
 		}
@@ -509,7 +526,10 @@ func (s *server) Delete(ctx context.Context, req *DeleteRequest) (*DeleteRespons
 		Kind:       "DeletedMarker",
 		APIVersion: "common.grafana.app/v0alpha1", // ?? or can we stick this in common?
 	}
-	marker.Annotations["RestoreResourceVersion"] = fmt.Sprintf("%d", event.PreviousRV)
+	if marker.Annotations == nil {
+		marker.Annotations = make(map[string]string)
+	}
+	marker.Annotations[restoreResourceVersionAnnotation] = fmt.Sprintf("%d", event.PreviousRV)
 	event.Value, err = json.Marshal(marker)
 	if err != nil {
 		return nil, apierrors.NewBadRequest(
@@ -752,6 +772,9 @@ func (s *server) History(ctx context.Context, req *HistoryRequest) (*HistoryResp
 	if err := s.Init(ctx); err != nil {
 		return nil, err
 	}
+	if s.index == nil {
+		return s.history(ctx, req)
+	}
 	return s.index.History(ctx, req)
 }
 
@@ -760,6 +783,9 @@ func (s *server) Origin(ctx context.Context, req *OriginRequest) (*OriginRespons
 	if err := s.Init(ctx); err != nil {
 		return nil, err
 	}
+	if s.index == nil {
+		return s.origin(ctx, req)
+	}
 	return s.index.Origin(ctx, req)
 }
 
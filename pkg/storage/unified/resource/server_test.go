@@ -144,6 +144,43 @@ func TestSimpleServer(t *testing.T) {
 		require.Len(t, all.Items, 1)
 		require.Equal(t, updated.ResourceVersion, all.Items[0].ResourceVersion)
 
+		// Both versions are in the history, the most recent first
+		history, err := server.History(ctx, &HistoryRequest{Key: key})
+		require.NoError(t, err)
+		require.Nil(t, history.Error)
+		require.Len(t, history.Items, 2)
+		require.Equal(t, updated.ResourceVersion, history.Items[0].ResourceVersion)
+		require.Equal(t, created.ResourceVersion, history.Items[1].ResourceVersion)
+		require.Contains(t, string(history.Items[0].PartialObjectMeta), `"test":"hello"`)
+		require.NotContains(t, string(history.Items[1].PartialObjectMeta), `"test":"hello"`)
+
+		page, err := server.History(ctx, &HistoryRequest{Key: key, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		require.NotEmpty(t, page.NextPageToken)
+		page, err = server.History(ctx, &HistoryRequest{Key: key, Limit: 1, NextPageToken: page.NextPageToken})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		require.Equal(t, created.ResourceVersion, page.Items[0].ResourceVersion)
+		require.Empty(t, page.NextPageToken)
+
+		origins, err := server.Origin(ctx, &OriginRequest{Key: &ResourceKey{
+			Group:    key.Group,
+			Resource: key.Resource,
+		}, Origin: "elsewhere"})
+		require.NoError(t, err)
+		require.Nil(t, origins.Error)
+		require.Len(t, origins.Items, 1)
+		require.Equal(t, key.Name, origins.Items[0].Key.Name)
+		require.Equal(t, "path/to/item", origins.Items[0].Path)
+
+		origins, err = server.Origin(ctx, &OriginRequest{Key: &ResourceKey{
+			Group:    key.Group,
+			Resource: key.Resource,
+		}, Origin: "other"})
+		require.NoError(t, err)
+		require.Len(t, origins.Items, 0)
+
 		deleted, err := server.Delete(ctx, &DeleteRequest{Key: key, ResourceVersion: updated.ResourceVersion})
 		require.NoError(t, err)
 		require.True(t, deleted.ResourceVersion > updated.ResourceVersion)
@@ -0,0 +1,367 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/authlib/claims"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+)
+
+// TrashSupport is implemented by backends that can find and purge deleted resources.
+type TrashSupport interface {
+	// Iterate the resources whose most recent version is a deleted marker.
+	// The iterator returns the marker of each resource.
+	TrashIterator(context.Context, *ListRequest, func(ListIterator) error) (int64, error)
+
+	// Permanently remove all versions of a deleted resource
+	PurgeResource(ctx context.Context, key *ResourceKey) error
+}
+
+var _ ResourceTrashServer = &server{}
+
+const (
+	// Annotation set on the deleted marker with the last version before the delete
+	restoreResourceVersionAnnotation = "RestoreResourceVersion"
+
+	// How often expired resources are purged from the trash
+	trashPurgeInterval = time.Hour
+
+	folderGroup    = "folder.grafana.app"
+	folderResource = "folders"
+)
+
+// ListDeleted lists the deleted resources of a namespace, ordered by name.
+func (s *server) ListDeleted(ctx context.Context, req *ListDeletedRequest) (*ListDeletedResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "storage_server.ListDeleted")
+	defer span.End()
+
+	if err := s.Init(ctx); err != nil {
+		return nil, err
+	}
+	if req.Key == nil || req.Key.Group == "" || req.Key.Resource == "" || req.Key.Namespace == "" {
+		return &ListDeletedResponse{Error: NewBadRequestError("listing deleted resources requires a group, resource and namespace")}, nil
+	}
+	backend, ok := s.backend.(TrashSupport)
+	if !ok {
+		return &ListDeletedResponse{Error: trashNotSupported()}, nil
+	}
+	if req.Limit < 1 {
+		req.Limit = 50 // default max 50 items in a page
+	}
+
+	rsp := &ListDeletedResponse{}
+	listReq := &ListRequest{
+		NextPageToken: req.NextPageToken,
+		Options:       &ListOptions{Key: req.Key},
+	}
+	rv, err := backend.TrashIterator(ctx, listReq, func(iter ListIterator) error {
+		for iter.Next() {
+			if err := iter.Error(); err != nil {
+				return err
+			}
+
+			item, err := deletedResource(req.Key, iter.ResourceVersion(), iter.Value())
+			if err != nil {
+				return err
+			}
+			rsp.Items = append(rsp.Items, item)
+			if len(rsp.Items) >= int(req.Limit) {
+				t := iter.ContinueToken()
+				if iter.Next() {
+					rsp.NextPageToken = t
+				}
+				break
+			}
+		}
+		return iter.Error()
+	})
+	if err != nil {
+		rsp.Error = AsErrorResult(err)
+		return rsp, nil
+	}
+	rsp.ResourceVersion = rv
+	return rsp, nil
+}
+
+// Undelete restores a deleted resource to one of the versions it had before it was deleted.
+// The resource keeps the folder it had at that version, the folder must not have been deleted.
+func (s *server) Undelete(ctx context.Context, req *UndeleteRequest) (*UndeleteResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "storage_server.Undelete")
+	defer span.End()
+
+	if err := s.Init(ctx); err != nil {
+		return nil, err
+	}
+
+	rsp := &UndeleteResponse{}
+	user, ok := claims.From(ctx)
+	if !ok || user == nil {
+		rsp.Error = &ErrorResult{
+			Message: "no user found in context",
+			Code:    http.StatusUnauthorized,
+		}
+		return rsp, nil
+	}
+	if req.Key == nil || req.Key.Group == "" || req.Key.Resource == "" || req.Key.Name == "" {
+		rsp.Error = NewBadRequestError("undelete requires a group, resource and name")
+		return rsp, nil
+	}
+	if req.ResourceVersion < 0 {
+		rsp.Error = NewBadRequestError("invalid resource version")
+		return rsp, nil
+	}
+	history, ok := s.backend.(HistorySupport)
+	if !ok {
+		rsp.Error = trashNotSupported()
+		return rsp, nil
+	}
+
+	found := s.backend.ReadResource(ctx, &ReadRequest{Key: req.Key})
+	if found != nil && len(found.Value) > 0 {
+		rsp.Error = &ErrorResult{
+			Code:    http.StatusConflict,
+			Message: "the resource is not deleted",
+		}
+		return rsp, nil
+	}
+
+	value, err := s.deletedVersion(ctx, history, req.Key, req.ResourceVersion)
+	if err != nil {
+		rsp.Error = AsErrorResult(err)
+		return rsp, nil
+	}
+	if value == nil {
+		rsp.Error = NewNotFoundError(req.Key)
+		return rsp, nil
+	}
+
+	tmp := &unstructured.Unstructured{}
+	if err := tmp.UnmarshalJSON(value); err != nil {
+		return nil, err
+	}
+	obj, err := utils.MetaAccessor(tmp)
+	if err != nil {
+		return nil, err
+	}
+	if folder := obj.GetFolder(); folder != "" {
+		deleted, err := s.isDeleted(ctx, history, &ResourceKey{
+			Group:     folderGroup,
+			Resource:  folderResource,
+			Namespace: req.Key.Namespace,
+			Name:      folder,
+		})
+		if err != nil {
+			rsp.Error = AsErrorResult(err)
+			return rsp, nil
+		}
+		if deleted {
+			rsp.Error = NewBadRequestError(fmt.Sprintf("the folder %s is deleted, it must be restored first", folder))
+			return rsp, nil
+		}
+	}
+
+	now := time.UnixMilli(s.now())
+	obj.SetResourceVersion("")
+	obj.SetDeletionTimestamp(nil)
+	obj.SetUpdatedTimestamp(&now)
+	obj.SetUpdatedBy(user.GetUID())
+	value, err = tmp.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	event, e := s.newEvent(ctx, user, req.Key, value, nil)
+	if e != nil {
+		rsp.Error = e
+		return rsp, nil
+	}
+	rsp.ResourceVersion, err = s.backend.WriteEvent(ctx, *event)
+	if err != nil {
+		rsp.Error = AsErrorResult(err)
+	}
+	return rsp, nil
+}
+
+// PurgeDeleted permanently removes the resources that were deleted before a point in time, with their blobs.
+func (s *server) PurgeDeleted(ctx context.Context, req *PurgeDeletedRequest) (*PurgeDeletedResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "storage_server.PurgeDeleted")
+	defer span.End()
+
+	if err := s.Init(ctx); err != nil {
+		return nil, err
+	}
+	if req.Key == nil || req.Key.Group == "" || req.Key.Resource == "" {
+		return &PurgeDeletedResponse{Error: NewBadRequestError("purge requires a group and resource")}, nil
+	}
+	backend, ok := s.backend.(TrashSupport)
+	if !ok {
+		return &PurgeDeletedResponse{Error: trashNotSupported()}, nil
+	}
+
+	// Find the expired resources first, backends may not support writes while iterating
+	var expired []*ResourceKey
+	key := &ResourceKey{Group: req.Key.Group, Resource: req.Key.Resource, Namespace: req.Key.Namespace}
+	_, err := backend.TrashIterator(ctx, &ListRequest{Options: &ListOptions{Key: key}}, func(iter ListIterator) error {
+		for iter.Next() {
+			if err := iter.Error(); err != nil {
+				return err
+			}
+			item, err := deletedResource(key, iter.ResourceVersion(), iter.Value())
+			if err != nil {
+				return err
+			}
+			if item.DeletedAt < req.DeletedBefore {
+				expired = append(expired, item.Key)
+			}
+		}
+		return iter.Error()
+	})
+	if err != nil {
+		return &PurgeDeletedResponse{Error: AsErrorResult(err)}, nil
+	}
+
+	rsp := &PurgeDeletedResponse{}
+	for _, k := range expired {
+		if err := backend.PurgeResource(ctx, k); err != nil {
+			rsp.Error = AsErrorResult(err)
+			return rsp, nil
+		}
+		rsp.Purged++
+
+		// The blobs are removed after the resource, so that a failure never leaves a restorable resource without its blobs
+		if s.blob != nil {
+			if err := s.blob.DeleteResourceBlobs(ctx, k); err != nil {
+				rsp.Error = AsErrorResult(fmt.Errorf("delete blobs of %s: %w", k.Name, err))
+				return rsp, nil
+			}
+		}
+	}
+	return rsp, nil
+}
+
+// deletedVersion finds a version of a deleted resource, it returns nil when the
+// resource is not deleted or the version does not exist.
+func (s *server) deletedVersion(ctx context.Context, history HistorySupport, key *ResourceKey, rv int64) ([]byte, error) {
+	var value []byte
+	_, err := history.HistoryIterator(ctx, &HistoryRequest{Key: key, ShowDeleted: true}, func(iter ListIterator) error {
+		// The most recent version is the deleted marker
+		if !iter.Next() || !isDeletedMarker(iter.Value()) {
+			return iter.Error()
+		}
+		if rv == 0 {
+			item, err := deletedResource(key, iter.ResourceVersion(), iter.Value())
+			if err != nil {
+				return err
+			}
+			rv = item.RestoreResourceVersion
+		}
+		for iter.Next() {
+			if iter.ResourceVersion() == rv {
+				if !isDeletedMarker(iter.Value()) {
+					value = iter.Value()
+				}
+				break
+			}
+		}
+		return iter.Error()
+	})
+	return value, err
+}
+
+// isDeleted checks if a resource was deleted, false when it exists or never existed
+func (s *server) isDeleted(ctx context.Context, history HistorySupport, key *ResourceKey) (bool, error) {
+	found := s.backend.ReadResource(ctx, &ReadRequest{Key: key})
+	if found != nil && len(found.Value) > 0 {
+		return false, nil
+	}
+	deleted := false
+	_, err := history.HistoryIterator(ctx, &HistoryRequest{Key: key, ShowDeleted: true}, func(iter ListIterator) error {
+		deleted = iter.Next() && isDeletedMarker(iter.Value())
+		return iter.Error()
+	})
+	return deleted, err
+}
+
+// purgeExpired removes the resources deleted longer than the retention ago
+func (s *server) purgeExpired(ctx context.Context, retention time.Duration) {
+	keys, err := s.ListGroupResources(ctx)
+	if err != nil {
+		s.log.Warn("unable to find resources to purge", "error", err)
+		return
+	}
+	before := time.UnixMilli(s.now()).Add(-retention).UnixMilli()
+	for _, key := range keys {
+		rsp, err := s.PurgeDeleted(ctx, &PurgeDeletedRequest{Key: key, DeletedBefore: before})
+		if err == nil && rsp.Error != nil {
+			err = GetError(rsp.Error)
+		}
+		if err != nil {
+			s.log.Warn("error purging deleted resources", "group", key.Group, "resource", key.Resource, "error", err)
+			continue
+		}
+		if rsp.Purged > 0 {
+			s.log.Info("purged deleted resources", "group", key.Group, "resource", key.Resource, "count", rsp.Purged)
+		}
+	}
+}
+
+func (s *server) runTrashPurge(retention time.Duration) {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+	for {
+		s.purgeExpired(s.ctx, retention)
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func deletedResource(key *ResourceKey, rv int64, value []byte) (*DeletedResource, error) {
+	marker := &metav1.PartialObjectMetadata{}
+	if err := json.Unmarshal(value, marker); err != nil {
+		return nil, fmt.Errorf("read deleted marker %d: %w", rv, err)
+	}
+	obj, err := utils.MetaAccessor(marker)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &DeletedResource{
+		Key: &ResourceKey{
+			Group:     key.Group,
+			Resource:  key.Resource,
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+		},
+		ResourceVersion: rv,
+		DeletedBy:       obj.GetUpdatedBy(),
+		Folder:          obj.GetFolder(),
+	}
+	if ts := obj.GetDeletionTimestamp(); ts != nil {
+		item.DeletedAt = ts.UnixMilli()
+	}
+	if v := obj.GetAnnotations()[restoreResourceVersionAnnotation]; v != "" {
+		item.RestoreResourceVersion, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %w", restoreResourceVersionAnnotation, err)
+		}
+	}
+	return item, nil
+}
+
+func trashNotSupported() *ErrorResult {
+	return &ErrorResult{
+		Message: "deleted resources are not supported by the storage backend",
+		Code:    http.StatusNotImplemented,
+	}
+}
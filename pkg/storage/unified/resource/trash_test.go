@@ -0,0 +1,197 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob/memblob"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+)
+
+func TestTrash(t *testing.T) {
+	testUserA := &identity.StaticRequester{
+		Type:           claims.TypeUser,
+		Login:          "testuser",
+		UserID:         123,
+		UserUID:        "u123",
+		OrgRole:        identity.RoleAdmin,
+		IsGrafanaAdmin: true, // can do anything
+	}
+	ctx := claims.WithClaims(context.Background(), testUserA)
+
+	backend, err := NewCDKBackend(ctx, CDKBackendOptions{Bucket: memblob.OpenBucket(nil)})
+	require.NoError(t, err)
+	blobBucket := memblob.OpenBucket(nil)
+	blobs, err := NewCDKBlobSupport(ctx, CDKBlobSupportOptions{Bucket: blobBucket})
+	require.NoError(t, err)
+	store, err := NewResourceServer(ResourceServerOptions{
+		Backend: backend,
+		Blob:    BlobConfig{Backend: blobs},
+		WriteAccess: WriteAccessHooks{
+			Folder: func(ctx context.Context, user claims.AuthInfo, uid string) bool {
+				return true
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	folder := &ResourceKey{Group: "folder.grafana.app", Resource: "folders", Namespace: "default", Name: "f1"}
+	dashboard := &ResourceKey{Group: "dashboard.grafana.app", Resource: "dashboards", Namespace: "default", Name: "d1"}
+	dashboards := &ResourceKey{Group: dashboard.Group, Resource: dashboard.Resource, Namespace: dashboard.Namespace}
+
+	folderValue := []byte(`{
+		"apiVersion": "folder.grafana.app/v0alpha1",
+		"kind": "Folder",
+		"metadata": {"name": "f1", "namespace": "default", "uid": "f1-uid"},
+		"spec": {"title": "Folder"}
+	}`)
+	dashboardValue := func(title string) []byte {
+		return []byte(fmt.Sprintf(`{
+			"apiVersion": "dashboard.grafana.app/v0alpha1",
+			"kind": "Dashboard",
+			"metadata": {
+				"name": "d1",
+				"namespace": "default",
+				"uid": "d1-uid",
+				"annotations": {"grafana.app/folder": "f1"}
+			},
+			"spec": {"title": %q}
+		}`, title))
+	}
+	readTitle := func(t *testing.T) string {
+		t.Helper()
+		found, err := store.Read(ctx, &ReadRequest{Key: dashboard})
+		require.NoError(t, err)
+		require.Nil(t, found.Error)
+		require.Contains(t, string(found.Value), `"grafana.app/folder":"f1"`)
+		return readPlaylistTitle(t, ctx, store, dashboard)
+	}
+	deleteDashboard := func(t *testing.T) {
+		t.Helper()
+		found, err := store.Read(ctx, &ReadRequest{Key: dashboard})
+		require.NoError(t, err)
+		deleted, err := store.Delete(ctx, &DeleteRequest{Key: dashboard, ResourceVersion: found.ResourceVersion})
+		require.NoError(t, err)
+		require.Nil(t, deleted.Error)
+	}
+
+	created, err := store.Create(ctx, &CreateRequest{Key: folder, Value: folderValue})
+	require.NoError(t, err)
+	require.Nil(t, created.Error)
+
+	v1, err := store.Create(ctx, &CreateRequest{Key: dashboard, Value: dashboardValue("v1")})
+	require.NoError(t, err)
+	require.Nil(t, v1.Error)
+	v2, err := store.Update(ctx, &UpdateRequest{Key: dashboard, Value: dashboardValue("v2"), ResourceVersion: v1.ResourceVersion})
+	require.NoError(t, err)
+	require.Nil(t, v2.Error)
+	deleteDashboard(t)
+
+	t.Run("list deleted resources", func(t *testing.T) {
+		rsp, err := store.ListDeleted(ctx, &ListDeletedRequest{Key: dashboards})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Len(t, rsp.Items, 1)
+		item := rsp.Items[0]
+		require.Equal(t, dashboard, item.Key)
+		require.Equal(t, v2.ResourceVersion, item.RestoreResourceVersion)
+		require.Greater(t, item.ResourceVersion, v2.ResourceVersion)
+		require.Equal(t, testUserA.GetUID(), item.DeletedBy)
+		require.Equal(t, "f1", item.Folder)
+		require.Greater(t, item.DeletedAt, int64(0))
+
+		rsp, err = store.ListDeleted(ctx, &ListDeletedRequest{Key: &ResourceKey{Group: dashboard.Group, Resource: dashboard.Resource}})
+		require.NoError(t, err)
+		require.Equal(t, int32(http.StatusBadRequest), rsp.Error.Code)
+	})
+
+	t.Run("undelete a prior version", func(t *testing.T) {
+		rsp, err := store.Undelete(ctx, &UndeleteRequest{Key: dashboard, ResourceVersion: v1.ResourceVersion})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Greater(t, rsp.ResourceVersion, v2.ResourceVersion)
+		require.Equal(t, "v1", readTitle(t))
+
+		deleted, err := store.ListDeleted(ctx, &ListDeletedRequest{Key: dashboards})
+		require.NoError(t, err)
+		require.Empty(t, deleted.Items)
+
+		rsp, err = store.Undelete(ctx, &UndeleteRequest{Key: dashboard})
+		require.NoError(t, err)
+		require.Equal(t, int32(http.StatusConflict), rsp.Error.Code)
+	})
+
+	t.Run("the folder must be restored first", func(t *testing.T) {
+		deleteDashboard(t)
+		found, err := store.Read(ctx, &ReadRequest{Key: folder})
+		require.NoError(t, err)
+		deleted, err := store.Delete(ctx, &DeleteRequest{Key: folder, ResourceVersion: found.ResourceVersion})
+		require.NoError(t, err)
+		require.Nil(t, deleted.Error)
+
+		rsp, err := store.Undelete(ctx, &UndeleteRequest{Key: dashboard})
+		require.NoError(t, err)
+		require.Equal(t, int32(http.StatusBadRequest), rsp.Error.Code)
+
+		rsp, err = store.Undelete(ctx, &UndeleteRequest{Key: folder})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		rsp, err = store.Undelete(ctx, &UndeleteRequest{Key: dashboard})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Equal(t, "v1", readTitle(t))
+	})
+
+	t.Run("purge deleted resources", func(t *testing.T) {
+		deleteDashboard(t)
+		blobKeys := func(t *testing.T) []string {
+			t.Helper()
+			var keys []string
+			iter := blobBucket.List(nil)
+			for {
+				obj, err := iter.Next(ctx)
+				if errors.Is(err, io.EOF) {
+					return keys
+				}
+				require.NoError(t, err)
+				keys = append(keys, obj.Key)
+			}
+		}
+		for _, key := range []*ResourceKey{dashboard, folder} {
+			_, err := blobs.PutResourceBlob(ctx, &PutBlobRequest{Resource: key, Method: PutBlobRequest_GRPC, ContentType: "application/json", Value: []byte(`{}`)})
+			require.NoError(t, err)
+		}
+		require.Len(t, blobKeys(t), 2)
+
+		rsp, err := store.PurgeDeleted(ctx, &PurgeDeletedRequest{Key: dashboards, DeletedBefore: time.Now().Add(-time.Hour).UnixMilli()})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Equal(t, int64(0), rsp.Purged)
+
+		rsp, err = store.PurgeDeleted(ctx, &PurgeDeletedRequest{Key: dashboards, DeletedBefore: time.Now().Add(time.Hour).UnixMilli()})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Equal(t, int64(1), rsp.Purged)
+
+		// only the blobs of the purged resource are deleted
+		keys := blobKeys(t)
+		require.Len(t, keys, 1)
+		require.Contains(t, keys[0], "/folders/f1/")
+
+		deleted, err := store.ListDeleted(ctx, &ListDeletedRequest{Key: dashboards})
+		require.NoError(t, err)
+		require.Empty(t, deleted.Items)
+
+		undeleted, err := store.Undelete(ctx, &UndeleteRequest{Key: dashboard})
+		require.NoError(t, err)
+		require.Equal(t, int32(http.StatusNotFound), undeleted.Error.Code)
+	})
+}
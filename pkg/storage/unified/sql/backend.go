@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"sync"
 	"time"
 
@@ -31,6 +32,8 @@ type Backend interface {
 	resource.StorageBackend
 	resource.DiagnosticsServer
 	resource.LifecycleHooks
+	resource.HistorySupport
+	resource.TrashSupport
 }
 
 type BackendOptions struct {
@@ -509,6 +512,154 @@ func (b *backend) listAtRevision(ctx context.Context, req *resource.ListRequest,
 	return iter.listRV, err
 }
 
+// HistoryIterator fetches the versions of a single resource from the resource_history table, most recent first.
+func (b *backend) HistoryIterator(ctx context.Context, req *resource.HistoryRequest, cb func(resource.ListIterator) error) (int64, error) {
+	_, span := b.tracer.Start(ctx, tracePrefix+"History")
+	defer span.End()
+
+	iter := &listIter{}
+	if req.NextPageToken != "" {
+		continueToken, err := GetContinueToken(req.NextPageToken)
+		if err != nil {
+			return 0, fmt.Errorf("get continue token: %w", err)
+		}
+		iter.listRV = continueToken.ResourceVersion
+		iter.offset = continueToken.StartOffset
+	}
+
+	err := b.db.WithTx(ctx, ReadCommittedRO, func(ctx context.Context, tx db.Tx) error {
+		if iter.listRV < 1 {
+			var err error
+			iter.listRV, err = fetchLatestRV(ctx, tx, b.dialect, req.Key.Group, req.Key.Resource)
+			if err != nil {
+				return err
+			}
+		}
+
+		limit := int64(0) // ignore limit
+		if iter.offset > 0 {
+			limit = math.MaxInt64 // a limit is required for offset
+		}
+		historyReq := sqlResourceHistoryGetRequest{
+			SQLTemplate: sqltemplate.New(b.dialect),
+			Request: &historyGetRequest{
+				Key:             req.Key,
+				ShowDeleted:     req.ShowDeleted,
+				ResourceVersion: iter.listRV,
+				Limit:           limit,
+				Offset:          iter.offset,
+			},
+		}
+
+		rows, err := dbutil.QueryRows(ctx, tx, sqlResourceHistoryGet, historyReq)
+		if rows != nil {
+			defer func() {
+				if err := rows.Close(); err != nil {
+					b.log.Warn("history error closing rows", "error", err)
+				}
+			}()
+		}
+		if err != nil {
+			return err
+		}
+
+		iter.rows = rows
+		return cb(iter)
+	})
+	return iter.listRV, err
+}
+
+// TrashIterator fetches the resources whose latest version in the resource_history table is a delete.
+func (b *backend) TrashIterator(ctx context.Context, req *resource.ListRequest, cb func(resource.ListIterator) error) (int64, error) {
+	_, span := b.tracer.Start(ctx, tracePrefix+"Trash")
+	defer span.End()
+
+	if req.Options == nil || req.Options.Key.Group == "" || req.Options.Key.Resource == "" {
+		return 0, fmt.Errorf("missing group or resource")
+	}
+
+	iter := &listIter{}
+	if req.NextPageToken != "" {
+		continueToken, err := GetContinueToken(req.NextPageToken)
+		if err != nil {
+			return 0, fmt.Errorf("get continue token: %w", err)
+		}
+		iter.listRV = continueToken.ResourceVersion
+		iter.offset = continueToken.StartOffset
+	}
+
+	err := b.db.WithTx(ctx, ReadCommittedRO, func(ctx context.Context, tx db.Tx) error {
+		if iter.listRV < 1 {
+			var err error
+			iter.listRV, err = fetchLatestRV(ctx, tx, b.dialect, req.Options.Key.Group, req.Options.Key.Resource)
+			if err != nil {
+				return err
+			}
+		}
+
+		limit := int64(0) // ignore limit
+		if iter.offset > 0 {
+			limit = math.MaxInt64 // a limit is required for offset
+		}
+		listReq := sqlResourceHistoryListRequest{
+			SQLTemplate: sqltemplate.New(b.dialect),
+			Request: &historyListRequest{
+				ResourceVersion: iter.listRV,
+				Limit:           limit,
+				Offset:          iter.offset,
+				Options:         req.Options,
+				Deleted:         true,
+			},
+		}
+
+		rows, err := dbutil.QueryRows(ctx, tx, sqlResourceHistoryList, listReq)
+		if rows != nil {
+			defer func() {
+				if err := rows.Close(); err != nil {
+					b.log.Warn("trash error closing rows", "error", err)
+				}
+			}()
+		}
+		if err != nil {
+			return err
+		}
+
+		iter.rows = rows
+		return cb(iter)
+	})
+	return iter.listRV, err
+}
+
+// PurgeResource removes all the versions of a deleted resource from the resource_history table.
+func (b *backend) PurgeResource(ctx context.Context, key *resource.ResourceKey) error {
+	ctx, span := b.tracer.Start(ctx, tracePrefix+"Purge")
+	defer span.End()
+
+	return b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
+		// 1. Make sure the resource is deleted
+		_, err := dbutil.QueryRow(ctx, tx, sqlResourceRead, &sqlResourceReadRequest{
+			SQLTemplate:  sqltemplate.New(b.dialect),
+			Request:      &resource.ReadRequest{Key: key},
+			readResponse: new(readResponse),
+		})
+		if err == nil {
+			return fmt.Errorf("only deleted resources can be purged")
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("read resource: %w", err)
+		}
+
+		// 2. Delete its history
+		if _, err := dbutil.Exec(ctx, tx, sqlResourceHistoryDelete, sqlResourceRequest{
+			SQLTemplate: sqltemplate.New(b.dialect),
+			WriteEvent:  resource.WriteEvent{Key: key},
+		}); err != nil {
+			return fmt.Errorf("delete from resource history: %w", err)
+		}
+		return nil
+	})
+}
+
 func (b *backend) WatchWriteEvents(ctx context.Context) (<-chan *resource.WrittenEvent, error) {
 	// Get the latest RV
 	since, err := b.listLatestRVs(ctx)
@@ -597,6 +748,27 @@ func (b *backend) listLatestRVs(ctx context.Context) (groupResourceRV, error) {
 	return since, nil
 }
 
+// ListGroupResources implements resource.GroupResourceLister.
+func (b *backend) ListGroupResources(ctx context.Context) ([]*resource.ResourceKey, error) {
+	grvs, err := b.listLatestRVs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var keys []*resource.ResourceKey
+	for group, resources := range grvs {
+		for res := range resources {
+			keys = append(keys, &resource.ResourceKey{Group: group, Resource: res})
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Group != keys[j].Group {
+			return keys[i].Group < keys[j].Group
+		}
+		return keys[i].Resource < keys[j].Resource
+	})
+	return keys, nil
+}
+
 // fetchLatestRV returns the current maximum RV in the resource table
 func fetchLatestRV(ctx context.Context, x db.ContextExecer, d sqltemplate.Dialect, group, resource string) (int64, error) {
 	res, err := dbutil.QueryRow(ctx, x, sqlResourceVersionGet, sqlResourceVersionGetRequest{
@@ -574,3 +574,41 @@ func TestBackend_delete(t *testing.T) {
 		require.ErrorContains(t, err, "update history rv")
 	})
 }
+
+func TestBackend_ListGroupResources(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy path", func(t *testing.T) {
+		t.Parallel()
+		b, ctx := setupBackendTest(t)
+
+		b.SQLMock.ExpectBegin()
+		b.QueryWithResult("select resource_version group resource", 3, Rows{
+			{300, "playlist.grafana.app", "playlists"},
+			{200, "dashboard.grafana.app", "dashboards"},
+			{100, "dashboard.grafana.app", "librarypanels"},
+		})
+		b.SQLMock.ExpectCommit()
+
+		keys, err := b.ListGroupResources(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []*resource.ResourceKey{
+			{Group: "dashboard.grafana.app", Resource: "dashboards"},
+			{Group: "dashboard.grafana.app", Resource: "librarypanels"},
+			{Group: "playlist.grafana.app", Resource: "playlists"},
+		}, keys)
+	})
+
+	t.Run("error listing resource versions", func(t *testing.T) {
+		t.Parallel()
+		b, ctx := setupBackendTest(t)
+
+		b.SQLMock.ExpectBegin()
+		b.QueryWithErr("select resource_version group resource", errTest)
+		b.SQLMock.ExpectRollback()
+
+		keys, err := b.ListGroupResources(ctx)
+		require.Nil(t, keys)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,7 @@
+DELETE FROM {{ .Ident "resource_history" }}
+    WHERE 1 = 1
+        AND {{ .Ident "namespace" }} = {{ .Arg .WriteEvent.Key.Namespace }}
+        AND {{ .Ident "group" }}     = {{ .Arg .WriteEvent.Key.Group }}
+        AND {{ .Ident "resource" }}  = {{ .Arg .WriteEvent.Key.Resource }}
+        AND {{ .Ident "name" }}      = {{ .Arg .WriteEvent.Key.Name }}
+;
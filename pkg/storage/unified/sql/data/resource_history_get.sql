@@ -0,0 +1,20 @@
+SELECT
+    {{ .Ident "resource_version" }},
+    {{ .Ident "namespace" }},
+    {{ .Ident "name" }},
+    {{ .Ident "value" }}
+    FROM {{ .Ident "resource_history" }}
+    WHERE 1 = 1
+        AND {{ .Ident "namespace" }} = {{ .Arg .Request.Key.Namespace }}
+        AND {{ .Ident "group" }}     = {{ .Arg .Request.Key.Group }}
+        AND {{ .Ident "resource" }}  = {{ .Arg .Request.Key.Resource }}
+        AND {{ .Ident "name" }}      = {{ .Arg .Request.Key.Name }}
+        AND {{ .Ident "resource_version" }} <= {{ .Arg .Request.ResourceVersion }}
+    {{ if not .Request.ShowDeleted }}
+        AND {{ .Ident "action" }} != 3
+    {{ end }}
+    ORDER BY {{ .Ident "resource_version" }} DESC
+    {{ if (gt .Request.Limit 0) }}
+    LIMIT {{ .Arg .Request.Limit }} OFFSET {{ .Arg .Request.Offset }}
+    {{ end }}
+;
@@ -31,7 +31,7 @@ SELECT
         AND maxkv.{{ .Ident "group" }}         = kv.{{ .Ident "group" }}
         AND maxkv.{{ .Ident "resource" }}      = kv.{{ .Ident "resource" }}
         AND maxkv.{{ .Ident "name" }}          = kv.{{ .Ident "name" }}
-    WHERE kv.{{ .Ident "action" }}  != 3 
+    WHERE kv.{{ .Ident "action" }} {{ if .Request.Deleted }} = 3 {{ else }} != 3 {{ end }}
     {{ if and .Request.Options .Request.Options.Key }}
         {{ if .Request.Options.Key.Namespace }}
         AND kv.{{ .Ident "namespace" }} = {{ .Arg .Request.Options.Key.Namespace }}
@@ -38,6 +38,8 @@ var (
 	sqlResourceHistoryUpdateRV = mustTemplate("resource_history_update_rv.sql")
 	sqlResourceHistoryInsert   = mustTemplate("resource_history_insert.sql")
 	sqlResourceHistoryPoll     = mustTemplate("resource_history_poll.sql")
+	sqlResourceHistoryGet      = mustTemplate("resource_history_get.sql")
+	sqlResourceHistoryDelete   = mustTemplate("resource_history_delete.sql")
 
 	// sqlResourceLabelsInsert = mustTemplate("resource_labels_insert.sql")
 	sqlResourceVersionGet    = mustTemplate("resource_version_get.sql")
@@ -155,6 +157,8 @@ func (r sqlResourceListRequest) Validate() error {
 type historyListRequest struct {
 	ResourceVersion, Limit, Offset int64
 	Options                        *resource.ListOptions
+	// List the resources that are deleted instead
+	Deleted bool
 }
 type sqlResourceHistoryListRequest struct {
 	sqltemplate.SQLTemplate
@@ -178,6 +182,21 @@ func (r sqlResourceHistoryListRequest) Results() (*resource.ResourceWrapper, err
 	}, nil
 }
 
+type historyGetRequest struct {
+	Key                            *resource.ResourceKey
+	ShowDeleted                    bool
+	ResourceVersion, Limit, Offset int64
+}
+
+type sqlResourceHistoryGetRequest struct {
+	sqltemplate.SQLTemplate
+	Request *historyGetRequest
+}
+
+func (r sqlResourceHistoryGetRequest) Validate() error {
+	return nil // TODO
+}
+
 // update RV
 
 type sqlResourceUpdateRVRequest struct {
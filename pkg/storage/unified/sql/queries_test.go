@@ -113,6 +113,24 @@ func TestUnifiedStorageQueries(t *testing.T) {
 						Response: new(resource.ResourceWrapper),
 					},
 				},
+				{
+					Name: "deleted",
+					Data: &sqlResourceHistoryListRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Request: &historyListRequest{
+							ResourceVersion: 123,
+							Options: &resource.ListOptions{
+								Key: &resource.ResourceKey{
+									Namespace: "ns",
+									Group:     "gg",
+									Resource:  "rr",
+								},
+							},
+							Deleted: true,
+						},
+						Response: new(resource.ResourceWrapper),
+					},
+				},
 			},
 			sqlResourceHistoryPoll: {
 				{
@@ -150,6 +168,43 @@ func TestUnifiedStorageQueries(t *testing.T) {
 				},
 			},
 
+			sqlResourceHistoryGet: {
+				{
+					Name: "single resource",
+					Data: &sqlResourceHistoryGetRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Request: &historyGetRequest{
+							Key: &resource.ResourceKey{
+								Namespace: "nn",
+								Group:     "gg",
+								Resource:  "rr",
+								Name:      "name",
+							},
+							ResourceVersion: 123,
+							Limit:           10,
+							Offset:          20,
+						},
+					},
+				},
+			},
+
+			sqlResourceHistoryDelete: {
+				{
+					Name: "single resource",
+					Data: &sqlResourceRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						WriteEvent: resource.WriteEvent{
+							Key: &resource.ResourceKey{
+								Namespace: "nn",
+								Group:     "gg",
+								Resource:  "rr",
+								Name:      "name",
+							},
+						},
+					},
+				},
+			},
+
 			sqlResourceHistoryUpdateRV: {
 				{
 					Name: "single path",
@@ -25,6 +25,8 @@ func NewResourceServer(ctx context.Context, db infraDB.DB, cfg *setting.Cfg, fea
 			URL: apiserverCfg.Key("blob_url").MustString(""),
 		},
 		Reg: reg,
+		// Deleted resources are kept forever by default
+		TrashRetention: apiserverCfg.Key("trash_retention").MustDuration(0),
 	}
 
 	// Support local file blob
@@ -112,6 +112,7 @@ func (s *service) start(ctx context.Context) error {
 	resource.RegisterResourceIndexServer(srv, server)
 	resource.RegisterBlobStoreServer(srv, server)
 	resource.RegisterDiagnosticsServer(srv, server)
+	resource.RegisterResourceTrashServer(srv, server)
 	grpc_health_v1.RegisterHealthServer(srv, healthService)
 
 	// register reflection service
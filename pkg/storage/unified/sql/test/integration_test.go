@@ -125,6 +125,60 @@ func TestIntegrationBackendHappyPath(t *testing.T) {
 		require.Equal(t, "item2 ADDED", string(resp.Value))
 	})
 
+	t.Run("History of item2", func(t *testing.T) {
+		var versions []int64
+		var values []string
+		_, err := backend.HistoryIterator(ctx, &resource.HistoryRequest{Key: resourceKey("item2")}, func(iter resource.ListIterator) error {
+			for iter.Next() {
+				versions = append(versions, iter.ResourceVersion())
+				values = append(values, string(iter.Value()))
+			}
+			return iter.Error()
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int64{rv4, rv2}, versions)
+		require.Equal(t, []string{"item2 MODIFIED", "item2 ADDED"}, values)
+	})
+
+	t.Run("History of deleted item1", func(t *testing.T) {
+		count := func(showDeleted bool) int {
+			n := 0
+			_, err := backend.HistoryIterator(ctx, &resource.HistoryRequest{Key: resourceKey("item1"), ShowDeleted: showDeleted}, func(iter resource.ListIterator) error {
+				for iter.Next() {
+					n++
+				}
+				return iter.Error()
+			})
+			require.NoError(t, err)
+			return n
+		}
+		require.Equal(t, 1, count(false))
+		require.Equal(t, 2, count(true))
+	})
+
+	t.Run("Trash contains item1", func(t *testing.T) {
+		trash := func() []string {
+			var values []string
+			_, err := backend.TrashIterator(ctx, &resource.ListRequest{Options: &resource.ListOptions{Key: &resource.ResourceKey{
+				Group:    "group",
+				Resource: "resource",
+			}}}, func(iter resource.ListIterator) error {
+				for iter.Next() {
+					values = append(values, string(iter.Value()))
+				}
+				return iter.Error()
+			})
+			require.NoError(t, err)
+			return values
+		}
+		require.Equal(t, []string{"item1 DELETED"}, trash())
+
+		// only deleted resources can be purged
+		require.Error(t, backend.PurgeResource(ctx, resourceKey("item2")))
+		require.NoError(t, backend.PurgeResource(ctx, resourceKey("item1")))
+		require.Empty(t, trash())
+	})
+
 	t.Run("PrepareList latest", func(t *testing.T) {
 		resp, err := server.List(ctx, &resource.ListRequest{
 			Options: &resource.ListOptions{
@@ -355,7 +409,7 @@ func TestClientServer(t *testing.T) {
 
 	svc, err := sql.ProvideUnifiedStorageGrpcService(cfg, features, dbstore, nil, prometheus.NewPedanticRegistry())
 	require.NoError(t, err)
-	var client resource.ResourceStoreClient
+	var client resource.ResourceClient
 
 	// Test with an admin identity
 	clientCtx := identity.WithRequester(ctx, &identity.StaticRequester{
@@ -408,6 +462,50 @@ func TestClientServer(t *testing.T) {
 		require.Greater(t, resp.ResourceVersion, int64(0))
 	})
 
+	deleteItem1 := func(t *testing.T) {
+		t.Helper()
+		found, err := client.Read(clientCtx, &resource.ReadRequest{Key: resourceKey("item1")})
+		require.NoError(t, err)
+		require.Empty(t, found.Error)
+		resp, err := client.Delete(clientCtx, &resource.DeleteRequest{Key: resourceKey("item1"), ResourceVersion: found.ResourceVersion})
+		require.NoError(t, err)
+		require.Empty(t, resp.Error)
+	}
+	namespaceKey := &resource.ResourceKey{Namespace: "namespace", Group: "group", Resource: "resource"}
+
+	t.Run("List and restore the deleted resource", func(t *testing.T) {
+		deleteItem1(t)
+
+		list, err := client.ListDeleted(clientCtx, &resource.ListDeletedRequest{Key: namespaceKey})
+		require.NoError(t, err)
+		require.Empty(t, list.Error)
+		require.Len(t, list.Items, 1)
+		require.Equal(t, "item1", list.Items[0].Key.Name)
+		require.Equal(t, "u123", list.Items[0].DeletedBy)
+
+		undeleted, err := client.Undelete(clientCtx, &resource.UndeleteRequest{Key: resourceKey("item1")})
+		require.NoError(t, err)
+		require.Empty(t, undeleted.Error)
+
+		found, err := client.Read(clientCtx, &resource.ReadRequest{Key: resourceKey("item1")})
+		require.NoError(t, err)
+		require.Empty(t, found.Error)
+		require.Equal(t, undeleted.ResourceVersion, found.ResourceVersion)
+	})
+
+	t.Run("Purge the deleted resource", func(t *testing.T) {
+		deleteItem1(t)
+
+		purged, err := client.PurgeDeleted(clientCtx, &resource.PurgeDeletedRequest{Key: namespaceKey, DeletedBefore: time.Now().Add(time.Hour).UnixMilli()})
+		require.NoError(t, err)
+		require.Empty(t, purged.Error)
+		require.Equal(t, int64(1), purged.Purged)
+
+		list, err := client.ListDeleted(clientCtx, &resource.ListDeletedRequest{Key: namespaceKey})
+		require.NoError(t, err)
+		require.Empty(t, list.Items)
+	})
+
 	t.Run("Stop the service", func(t *testing.T) {
 		err = services.StopAndAwaitTerminated(ctx, svc)
 		require.NoError(t, err)
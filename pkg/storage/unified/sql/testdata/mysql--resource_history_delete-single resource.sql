@@ -0,0 +1,7 @@
+DELETE FROM `resource_history`
+    WHERE 1 = 1
+        AND `namespace` = 'nn'
+        AND `group`     = 'gg'
+        AND `resource`  = 'rr'
+        AND `name`      = 'name'
+;
@@ -0,0 +1,16 @@
+SELECT
+    "resource_version",
+    "namespace",
+    "name",
+    "value"
+    FROM "resource_history"
+    WHERE 1 = 1
+        AND "namespace" = 'nn'
+        AND "group"     = 'gg'
+        AND "resource"  = 'rr'
+        AND "name"      = 'name'
+        AND "resource_version" <= 123
+        AND "action" != 3
+    ORDER BY "resource_version" DESC
+    LIMIT 10 OFFSET 20
+;
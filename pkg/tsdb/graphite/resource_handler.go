@@ -0,0 +1,333 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// variableQueryLimit is the maximum number of tags or tag values returned for a template variable
+const variableQueryLimit = 10000
+
+var (
+	tagValuesVariableQuery = regexp.MustCompile(`^tag_values\((.*)\)$`)
+	tagsVariableQuery      = regexp.MustCompile(`^tags\((.*)\)$`)
+	expandVariableQuery    = regexp.MustCompile(`^expand\((.*)\)$`)
+
+	// Fix for a Graphite bug: https://github.com/graphite-project/graphite-web/issues/2609
+	// Graphite 1.1.7 returns Infinity as the default value of some function parameters
+	infinityFunctionDefault = regexp.MustCompile(`"default": ?Infinity`)
+)
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics/find", handleResourceReq(s, s.handleMetricsFind))
+	mux.HandleFunc("/metrics/expand", handleResourceReq(s, s.handleMetricsExpand))
+	mux.HandleFunc("/tags/autoComplete/tags", handleResourceReq(s, s.handleTagsAutoComplete))
+	mux.HandleFunc("/tags/autoComplete/values", handleResourceReq(s, s.handleTagValuesAutoComplete))
+	mux.HandleFunc("/functions", handleResourceReq(s, s.handleFunctions))
+	mux.HandleFunc("/variable", handleResourceReq(s, s.handleVariableQuery))
+	return mux
+}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return s.resourceHandler.CallResource(ctx, req, sender)
+}
+
+// handleResourceReq decodes the JSON body of a resource request, resolves the datasource instance
+// and writes the JSON encoded result of the handler
+func handleResourceReq[T any, R any](s *Service, handlerFn func(context.Context, *datasourceInfo, *T) (R, int, error)) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		dsInfo, err := s.getDSInfo(ctx, backend.PluginConfigFromContext(ctx))
+		if err != nil {
+			writeResponse(rw, http.StatusInternalServerError, fmt.Sprintf("unexpected error %v", err))
+			return
+		}
+
+		parsedReq := new(T)
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			writeResponse(rw, http.StatusBadRequest, fmt.Sprintf("failed to read request body: %v", err))
+			return
+		}
+		if len(body) > 0 {
+			if err := json.Unmarshal(body, parsedReq); err != nil {
+				writeResponse(rw, http.StatusBadRequest, fmt.Sprintf("failed to parse request body: %v", err))
+				return
+			}
+		}
+
+		res, code, err := handlerFn(ctx, dsInfo, parsedReq)
+		if err != nil {
+			writeResponse(rw, code, err.Error())
+			return
+		}
+
+		resBody, err := json.Marshal(res)
+		if err != nil {
+			writeResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to marshal response: %v", err))
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		writeResponseBytes(rw, code, resBody)
+	}
+}
+
+func (s *Service) handleMetricsFind(ctx context.Context, dsInfo *datasourceInfo, req *GraphiteMetricsFindRequest) ([]GraphiteMetricsFindResponse, int, error) {
+	if req.Query == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("query is required")
+	}
+	params := url.Values{"query": []string{req.Query}}
+	setTimeRangeParams(params, req.From, req.Until)
+
+	var res []GraphiteMetricsFindResponse
+	code, err := s.doGraphiteRequest(ctx, dsInfo, "metrics/find", params, &res)
+	return res, code, err
+}
+
+func (s *Service) handleMetricsExpand(ctx context.Context, dsInfo *datasourceInfo, req *GraphiteMetricsFindRequest) ([]string, int, error) {
+	if req.Query == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("query is required")
+	}
+	params := url.Values{"query": []string{req.Query}}
+	setTimeRangeParams(params, req.From, req.Until)
+
+	var res GraphiteMetricsExpandResponse
+	code, err := s.doGraphiteRequest(ctx, dsInfo, "metrics/expand", params, &res)
+	return res.Results, code, err
+}
+
+func (s *Service) handleTagsAutoComplete(ctx context.Context, dsInfo *datasourceInfo, req *GraphiteTagsRequest) ([]string, int, error) {
+	params := url.Values{"expr": req.Expr}
+	if req.TagPrefix != "" {
+		params.Set("tagPrefix", req.TagPrefix)
+	}
+	setLimitParam(params, req.Limit)
+	setTimeRangeParams(params, req.From, req.Until)
+
+	var res []string
+	code, err := s.doGraphiteRequest(ctx, dsInfo, "tags/autoComplete/tags", params, &res)
+	return res, code, err
+}
+
+func (s *Service) handleTagValuesAutoComplete(ctx context.Context, dsInfo *datasourceInfo, req *GraphiteTagValuesRequest) ([]string, int, error) {
+	if req.Tag == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("tag is required")
+	}
+	params := url.Values{"expr": req.Expr, "tag": []string{req.Tag}}
+	if req.ValuePrefix != "" {
+		params.Set("valuePrefix", req.ValuePrefix)
+	}
+	setLimitParam(params, req.Limit)
+	setTimeRangeParams(params, req.From, req.Until)
+
+	var res []string
+	code, err := s.doGraphiteRequest(ctx, dsInfo, "tags/autoComplete/values", params, &res)
+	return res, code, err
+}
+
+// handleFunctions returns the function definitions of the Graphite server as they are,
+// only fixing the invalid JSON returned by some Graphite versions
+func (s *Service) handleFunctions(ctx context.Context, dsInfo *datasourceInfo, _ *struct{}) (json.RawMessage, int, error) {
+	body, code, err := s.graphiteRequest(ctx, dsInfo, "functions", url.Values{})
+	if err != nil {
+		return nil, code, err
+	}
+	body = infinityFunctionDefault.ReplaceAll(body, []byte(`"default": 1e9999`))
+	if !json.Valid(body) {
+		return nil, http.StatusInternalServerError, fmt.Errorf("invalid function definitions returned by graphite")
+	}
+	return body, code, nil
+}
+
+// handleVariableQuery resolves a template variable query the same way the frontend does:
+// tag_values(<tag>,<expr>,...) and tags(<expr>,...) use the tag autocomplete endpoints,
+// expand(<path>) the metrics expand endpoint and any other query is a metric path search
+func (s *Service) handleVariableQuery(ctx context.Context, dsInfo *datasourceInfo, req *GraphiteVariableRequest) ([]GraphiteVariableResponse, int, error) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("query is required")
+	}
+
+	if m := tagValuesVariableQuery.FindStringSubmatch(query); m != nil {
+		expressions := splitTagExpressions(m[1])
+		if len(expressions) == 0 {
+			return nil, http.StatusBadRequest, fmt.Errorf("tag_values requires a tag")
+		}
+		values, code, err := s.handleTagValuesAutoComplete(ctx, dsInfo, &GraphiteTagValuesRequest{
+			Tag:   expressions[0],
+			Expr:  expressions[1:],
+			Limit: variableQueryLimit,
+			From:  req.From,
+			Until: req.Until,
+		})
+		return toVariableResponse(values), code, err
+	}
+
+	if m := tagsVariableQuery.FindStringSubmatch(query); m != nil {
+		tags, code, err := s.handleTagsAutoComplete(ctx, dsInfo, &GraphiteTagsRequest{
+			Expr:  splitTagExpressions(m[1]),
+			Limit: variableQueryLimit,
+			From:  req.From,
+			Until: req.Until,
+		})
+		return toVariableResponse(tags), code, err
+	}
+
+	if m := expandVariableQuery.FindStringSubmatch(query); m != nil {
+		metrics, code, err := s.handleMetricsExpand(ctx, dsInfo, &GraphiteMetricsFindRequest{Query: m[1], From: req.From, Until: req.Until})
+		return toVariableResponse(metrics), code, err
+	}
+
+	metrics, code, err := s.handleMetricsFind(ctx, dsInfo, &GraphiteMetricsFindRequest{Query: query, From: req.From, Until: req.Until})
+	if err != nil {
+		return nil, code, err
+	}
+	res := make([]GraphiteVariableResponse, 0, len(metrics))
+	for _, metric := range metrics {
+		res = append(res, GraphiteVariableResponse{Text: metric.Text, Expandable: metric.Expandable == 1})
+	}
+	return res, code, nil
+}
+
+// doGraphiteRequest calls a Graphite HTTP API endpoint and decodes its JSON response into res
+func (s *Service) doGraphiteRequest(ctx context.Context, dsInfo *datasourceInfo, endpoint string, params url.Values, res any) (int, error) {
+	body, code, err := s.graphiteRequest(ctx, dsInfo, endpoint, params)
+	if err != nil {
+		return code, err
+	}
+	if err := json.Unmarshal(body, res); err != nil {
+		logger.FromContext(ctx).Info("Failed to unmarshal graphite response", "error", err, "endpoint", endpoint, "body", string(body))
+		return http.StatusInternalServerError, fmt.Errorf("failed to parse graphite response: %w", err)
+	}
+	return code, nil
+}
+
+func (s *Service) graphiteRequest(ctx context.Context, dsInfo *datasourceInfo, endpoint string, params url.Values) ([]byte, int, error) {
+	logger := logger.FromContext(ctx)
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	u.Path = path.Join(u.Path, endpoint)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		logger.Info("Failed to create request", "error", err)
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	ctx, span := s.tracer.Start(ctx, "graphite resource")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("endpoint", endpoint),
+		attribute.Int64("datasource_id", dsInfo.Id),
+		attribute.Int64("org_id", backend.PluginConfigFromContext(ctx).OrgID),
+	)
+	s.tracer.Inject(ctx, req.Header, span)
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if res != nil {
+		span.SetAttributes(attribute.Int("graphite.response.code", res.StatusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, http.StatusBadGateway, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if res.StatusCode/100 != 2 {
+		logger.Info("Request failed", "status", res.Status, "endpoint", endpoint, "body", string(body))
+		err := fmt.Errorf("request failed, status: %s", res.Status)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, res.StatusCode, err
+	}
+	return body, http.StatusOK, nil
+}
+
+func setTimeRangeParams(params url.Values, from, until string) {
+	if from != "" {
+		params.Set("from", from)
+	}
+	if until != "" {
+		params.Set("until", until)
+	}
+}
+
+func setLimitParam(params url.Values, limit int) {
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+}
+
+// splitTagExpressions splits comma separated tag expressions, ignoring the commas
+// inside of value lists such as name=~{a,b}
+func splitTagExpressions(s string) []string {
+	expressions := []string{}
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				expressions = appendTagExpression(expressions, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return appendTagExpression(expressions, s[start:])
+}
+
+func appendTagExpression(expressions []string, expression string) []string {
+	if expression = strings.TrimSpace(expression); expression != "" {
+		expressions = append(expressions, expression)
+	}
+	return expressions
+}
+
+func toVariableResponse(values []string) []GraphiteVariableResponse {
+	res := make([]GraphiteVariableResponse, 0, len(values))
+	for _, v := range values {
+		res = append(res, GraphiteVariableResponse{Text: v})
+	}
+	return res
+}
+
+func writeResponseBytes(rw http.ResponseWriter, code int, msg []byte) {
+	rw.WriteHeader(code)
+	if _, err := rw.Write(msg); err != nil {
+		logger.Error("Unable to write HTTP response", "error", err)
+	}
+}
+
+func writeResponse(rw http.ResponseWriter, code int, msg string) {
+	writeResponseBytes(rw, code, []byte(msg))
+}
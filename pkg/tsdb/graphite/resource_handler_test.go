@@ -0,0 +1,198 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+type fakeResourceInstanceManager struct {
+	fakeInstanceManager
+	dsInfo datasourceInfo
+}
+
+func (f fakeResourceInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return f.dsInfo, nil
+}
+
+// setupResourceTest starts a fake Graphite server that records the last request
+// and answers with the response registered for its path
+func setupResourceTest(t *testing.T, responses map[string]string) (*Service, *url.URL) {
+	t.Helper()
+
+	lastRequest := &url.URL{}
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		*lastRequest = *req.URL
+		body, ok := responses[req.URL.Path]
+		if !ok {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = rw.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	s := &Service{
+		im:     fakeResourceInstanceManager{dsInfo: datasourceInfo{HTTPClient: srv.Client(), URL: srv.URL}},
+		tracer: tracing.InitializeTracerForTest(),
+	}
+	return s, lastRequest
+}
+
+func callResource(t *testing.T, s *Service, path string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	rw := httptest.NewRecorder()
+	s.newResourceMux().ServeHTTP(rw, req)
+	return rw
+}
+
+func TestResourceHandler(t *testing.T) {
+	t.Run("metrics find", func(t *testing.T) {
+		s, lastRequest := setupResourceTest(t, map[string]string{
+			"/metrics/find": `[{"text":"cpu","id":"servers.001.cpu","allowChildren":0,"expandable":0,"leaf":1}]`,
+		})
+
+		rw := callResource(t, s, "/metrics/find", `{"query":"servers.*.cpu","from":"-1h","until":"now"}`)
+		require.Equal(t, http.StatusOK, rw.Code, rw.Body.String())
+
+		var res []GraphiteMetricsFindResponse
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &res))
+		assert.Equal(t, []GraphiteMetricsFindResponse{{Text: "cpu", Id: "servers.001.cpu", Leaf: 1}}, res)
+		assert.Equal(t, "servers.*.cpu", lastRequest.Query().Get("query"))
+		assert.Equal(t, "-1h", lastRequest.Query().Get("from"))
+		assert.Equal(t, "now", lastRequest.Query().Get("until"))
+	})
+
+	t.Run("metrics find requires a query", func(t *testing.T) {
+		s, _ := setupResourceTest(t, nil)
+
+		rw := callResource(t, s, "/metrics/find", `{}`)
+		assert.Equal(t, http.StatusBadRequest, rw.Code)
+	})
+
+	t.Run("tags autocomplete", func(t *testing.T) {
+		s, lastRequest := setupResourceTest(t, map[string]string{
+			"/tags/autoComplete/tags": `["dc","host"]`,
+		})
+
+		rw := callResource(t, s, "/tags/autoComplete/tags", `{"expr":["name=cpu"],"tagPrefix":"d","limit":10}`)
+		require.Equal(t, http.StatusOK, rw.Code, rw.Body.String())
+		assert.JSONEq(t, `["dc","host"]`, rw.Body.String())
+		assert.Equal(t, []string{"name=cpu"}, lastRequest.Query()["expr"])
+		assert.Equal(t, "d", lastRequest.Query().Get("tagPrefix"))
+		assert.Equal(t, "10", lastRequest.Query().Get("limit"))
+	})
+
+	t.Run("tag values autocomplete", func(t *testing.T) {
+		s, lastRequest := setupResourceTest(t, map[string]string{
+			"/tags/autoComplete/values": `["eu","us"]`,
+		})
+
+		rw := callResource(t, s, "/tags/autoComplete/values", `{"tag":"dc","valuePrefix":"e"}`)
+		require.Equal(t, http.StatusOK, rw.Code, rw.Body.String())
+		assert.JSONEq(t, `["eu","us"]`, rw.Body.String())
+		assert.Equal(t, "dc", lastRequest.Query().Get("tag"))
+		assert.Equal(t, "e", lastRequest.Query().Get("valuePrefix"))
+		assert.Empty(t, lastRequest.Query().Get("limit"))
+	})
+
+	t.Run("functions fixes infinite defaults", func(t *testing.T) {
+		s, _ := setupResourceTest(t, map[string]string{
+			"/functions": `{"aggregateLine":{"params":[{"name":"keepStep","default": Infinity}]}}`,
+		})
+
+		rw := callResource(t, s, "/functions", "")
+		require.Equal(t, http.StatusOK, rw.Code, rw.Body.String())
+		assert.Contains(t, rw.Body.String(), `"default":1e9999`)
+	})
+
+	t.Run("graphite errors are returned", func(t *testing.T) {
+		s, _ := setupResourceTest(t, nil)
+
+		rw := callResource(t, s, "/tags/autoComplete/tags", `{}`)
+		assert.Equal(t, http.StatusNotFound, rw.Code)
+	})
+}
+
+func TestVariableQuery(t *testing.T) {
+	responses := map[string]string{
+		"/metrics/find":             `[{"text":"001","expandable":1},{"text":"002","expandable":0}]`,
+		"/metrics/expand":           `{"results":["servers.001.cpu","servers.002.cpu"]}`,
+		"/tags/autoComplete/tags":   `["dc","host"]`,
+		"/tags/autoComplete/values": `["eu","us"]`,
+	}
+
+	testCases := []struct {
+		name     string
+		query    string
+		path     string
+		params   url.Values
+		expected []GraphiteVariableResponse
+	}{
+		{
+			name:     "metric path",
+			query:    "servers.*",
+			path:     "/metrics/find",
+			params:   url.Values{"query": {"servers.*"}},
+			expected: []GraphiteVariableResponse{{Text: "001", Expandable: true}, {Text: "002"}},
+		},
+		{
+			name:     "expand",
+			query:    "expand(servers.*.cpu)",
+			path:     "/metrics/expand",
+			params:   url.Values{"query": {"servers.*.cpu"}},
+			expected: []GraphiteVariableResponse{{Text: "servers.001.cpu"}, {Text: "servers.002.cpu"}},
+		},
+		{
+			name:     "tags",
+			query:    "tags(name=cpu, dc=eu)",
+			path:     "/tags/autoComplete/tags",
+			params:   url.Values{"expr": {"name=cpu", "dc=eu"}, "limit": {"10000"}},
+			expected: []GraphiteVariableResponse{{Text: "dc"}, {Text: "host"}},
+		},
+		{
+			name:     "tag values",
+			query:    "tag_values(dc,name=~{cpu,mem})",
+			path:     "/tags/autoComplete/values",
+			params:   url.Values{"tag": {"dc"}, "expr": {"name=~{cpu,mem}"}, "limit": {"10000"}},
+			expected: []GraphiteVariableResponse{{Text: "eu"}, {Text: "us"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, lastRequest := setupResourceTest(t, responses)
+
+			body, err := json.Marshal(GraphiteVariableRequest{Query: tc.query})
+			require.NoError(t, err)
+			rw := callResource(t, s, "/variable", string(body))
+			require.Equal(t, http.StatusOK, rw.Code, rw.Body.String())
+
+			var res []GraphiteVariableResponse
+			require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &res))
+			assert.Equal(t, tc.expected, res)
+			assert.Equal(t, tc.path, lastRequest.Path)
+			assert.Equal(t, tc.params, lastRequest.Query())
+		})
+	}
+
+	t.Run("tag_values without a tag", func(t *testing.T) {
+		s, _ := setupResourceTest(t, responses)
+
+		rw := callResource(t, s, "/variable", `{"query":"tag_values()"}`)
+		assert.Equal(t, http.StatusBadRequest, rw.Code)
+	})
+}
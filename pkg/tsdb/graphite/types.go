@@ -13,3 +13,51 @@ type TargetResponseDTO struct {
 
 type DataTimePoint [2]null.Float
 type DataTimeSeriesPoints []DataTimePoint
+
+type GraphiteMetricsFindRequest struct {
+	Query string `json:"query"`
+	From  string `json:"from,omitempty"`
+	Until string `json:"until,omitempty"`
+}
+
+type GraphiteMetricsFindResponse struct {
+	Text          string `json:"text"`
+	Id            string `json:"id"`
+	AllowChildren int    `json:"allowChildren"`
+	Expandable    int    `json:"expandable"`
+	Leaf          int    `json:"leaf"`
+}
+
+type GraphiteMetricsExpandResponse struct {
+	Results []string `json:"results"`
+}
+
+type GraphiteTagsRequest struct {
+	Expr      []string `json:"expr,omitempty"`
+	TagPrefix string   `json:"tagPrefix,omitempty"`
+	Limit     int      `json:"limit,omitempty"`
+	From      string   `json:"from,omitempty"`
+	Until     string   `json:"until,omitempty"`
+}
+
+type GraphiteTagValuesRequest struct {
+	Expr        []string `json:"expr,omitempty"`
+	Tag         string   `json:"tag"`
+	ValuePrefix string   `json:"valuePrefix,omitempty"`
+	Limit       int      `json:"limit,omitempty"`
+	From        string   `json:"from,omitempty"`
+	Until       string   `json:"until,omitempty"`
+}
+
+// GraphiteVariableRequest is a template variable query, such as a metric path,
+// expand(<path>), tags(<expr>,...) or tag_values(<tag>,<expr>,...)
+type GraphiteVariableRequest struct {
+	Query string `json:"query"`
+	From  string `json:"from,omitempty"`
+	Until string `json:"until,omitempty"`
+}
+
+type GraphiteVariableResponse struct {
+	Text       string `json:"text"`
+	Expandable bool   `json:"expandable"`
+}
@@ -38,6 +38,12 @@ type SqlQueryResultTransformer interface {
 	GetConverterList() []sqlutil.StringConverter
 }
 
+// FrameTransformer can be implemented by a SqlQueryResultTransformer to adjust the frame read from the rows
+// of a query, before the time columns are converted.
+type FrameTransformer interface {
+	TransformFrame(frame *data.Frame, columnTypes []*sql.ColumnType)
+}
+
 type JsonData struct {
 	MaxOpenConns            int    `json:"maxOpenConns"`
 	MaxIdleConns            int    `json:"maxIdleConns"`
@@ -282,6 +288,10 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
+	if t, ok := e.queryResultTransformer.(FrameTransformer); ok {
+		t.TransformFrame(frame, qm.columnTypes)
+	}
+
 	if frame.Meta == nil {
 		frame.Meta = &data.FrameMeta{}
 	}
@@ -8,12 +8,15 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -25,18 +28,42 @@ import (
 var logger = log.New("tsdb.opentsdb")
 
 type Service struct {
-	im instancemgmt.InstanceManager
+	im              instancemgmt.InstanceManager
+	resourceHandler backend.CallResourceHandler
 }
 
 func ProvideService(httpClientProvider httpclient.Provider) *Service {
-	return &Service{
+	s := &Service{
 		im: datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
 	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
 }
 
+const (
+	// tsdbVersion23 is the tsdbVersion setting of OpenTSDB 2.3, which returns
+	// the index of the query of each series when showQuery is set
+	tsdbVersion23 = 3
+	// tsdbResolutionMs is the tsdbResolution setting for millisecond timestamps
+	tsdbResolutionMs   = 2
+	defaultLookupLimit = 1000
+)
+
+// tagAliasPattern matches the $tag_<key>, ${tag_<key>} and [[tag_<key>]] alias variables
+var tagAliasPattern = regexp.MustCompile(`\$\{tag_(\w+)\}|\[\[tag_(\w+)\]\]|\$tag_(\w+)`)
+
 type datasourceInfo struct {
-	HTTPClient *http.Client
-	URL        string
+	HTTPClient     *http.Client
+	URL            string
+	TSDBVersion    int
+	TSDBResolution int
+	LookupLimit    int
+}
+
+type datasourceJSONData struct {
+	TSDBVersion    int `json:"tsdbVersion"`
+	TSDBResolution int `json:"tsdbResolution"`
+	LookupLimit    int `json:"lookupLimit"`
 }
 
 type DsAccess string
@@ -53,9 +80,28 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		jsonData := datasourceJSONData{}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+		if jsonData.TSDBVersion == 0 {
+			jsonData.TSDBVersion = 1
+		}
+		if jsonData.TSDBResolution == 0 {
+			jsonData.TSDBResolution = 1
+		}
+		if jsonData.LookupLimit == 0 {
+			jsonData.LookupLimit = defaultLookupLimit
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
+			HTTPClient:     client,
+			URL:            settings.URL,
+			TSDBVersion:    jsonData.TSDBVersion,
+			TSDBResolution: jsonData.TSDBResolution,
+			LookupLimit:    jsonData.LookupLimit,
 		}
 
 		return model, nil
@@ -63,18 +109,44 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 }
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
-	var tsdbQuery OpenTsdbQuery
-
 	logger := logger.FromContext(ctx)
 
-	q := req.Queries[0]
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
 
-	myRefID := q.RefID
+	result := backend.NewQueryDataResponse()
+	queries := make([]backend.DataQuery, 0, len(req.Queries))
+	models := make([]QueryModel, 0, len(req.Queries))
+	for _, query := range req.Queries {
+		var model QueryModel
+		if err := json.Unmarshal(query.JSON, &model); err != nil {
+			return nil, fmt.Errorf("failed to parse query %s: %w", query.RefID, err)
+		}
+		if model.FromAnnotations {
+			result.Responses[query.RefID] = s.annotationQuery(ctx, logger, dsInfo, query, model)
+			continue
+		}
+		if model.Metric == "" {
+			continue
+		}
+		queries = append(queries, query)
+		models = append(models, model)
+	}
 
-	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
-	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+	// No valid metric queries, save a round trip
+	if len(queries) == 0 {
+		return result, nil
+	}
 
-	for _, query := range req.Queries {
+	tsdbQuery := OpenTsdbQuery{
+		Start:        queries[0].TimeRange.From.UnixNano() / int64(time.Millisecond),
+		End:          queries[0].TimeRange.To.UnixNano() / int64(time.Millisecond),
+		MsResolution: dsInfo.TSDBResolution == tsdbResolutionMs,
+		ShowQuery:    dsInfo.TSDBVersion == tsdbVersion23,
+	}
+	for _, query := range queries {
 		metric := s.buildMetric(query)
 		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
 	}
@@ -84,11 +156,6 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		logger.Debug("OpenTsdb request", "params", tsdbQuery)
 	}
 
-	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
-	if err != nil {
-		return nil, err
-	}
-
 	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
 	if err != nil {
 		return &backend.QueryDataResponse{}, err
@@ -106,14 +173,67 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		}
 	}()
 
-	result, err := s.parseResponse(logger, res, myRefID)
+	metricResult, err := s.parseResponse(logger, res, dsInfo, queries, models)
 	if err != nil {
 		return &backend.QueryDataResponse{}, err
 	}
 
+	for refID, response := range metricResult.Responses {
+		result.Responses[refID] = response
+	}
 	return result, nil
 }
 
+// annotationQuery returns the annotations of the annotation target metric, or the global
+// annotations when the annotation query is global
+func (s *Service) annotationQuery(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, query backend.DataQuery, model QueryModel) backend.DataResponse {
+	if model.Target == "" {
+		return backend.DataResponse{}
+	}
+
+	tsdbQuery := OpenTsdbQuery{
+		Start:             query.TimeRange.From.UnixNano() / int64(time.Millisecond),
+		End:               query.TimeRange.To.UnixNano() / int64(time.Millisecond),
+		Queries:           []map[string]any{{"aggregator": "sum", "metric": model.Target}},
+		GlobalAnnotations: true,
+	}
+
+	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
+	if err != nil {
+		return backend.ErrDataResponse(backend.StatusInternal, err.Error())
+	}
+
+	res, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		return backend.ErrDataResponse(backend.StatusBadGateway, err.Error())
+	}
+
+	responseData, err := s.decodeResponse(logger, res)
+	if err != nil {
+		return backend.ErrDataResponse(backend.StatusInternal, err.Error())
+	}
+
+	var annotations []OpenTsdbAnnotation
+	if len(responseData) > 0 {
+		annotations = responseData[0].Annotations
+		if model.IsGlobal {
+			annotations = responseData[0].GlobalAnnotations
+		}
+	}
+
+	times := make([]time.Time, 0, len(annotations))
+	texts := make([]string, 0, len(annotations))
+	for _, annotation := range annotations {
+		// Annotation times are always stored in seconds
+		times = append(times, time.Unix(int64(annotation.StartTime), 0).UTC())
+		texts = append(texts, annotation.Description)
+	}
+
+	frame := data.NewFrame("", data.NewField("time", nil, times), data.NewField("text", nil, texts))
+	frame.RefID = query.RefID
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
 func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, data OpenTsdbQuery) (*http.Request, error) {
 	u, err := url.Parse(dsInfo.URL)
 	if err != nil {
@@ -140,9 +260,7 @@ func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *
 	return req, nil
 }
 
-func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID string) (*backend.QueryDataResponse, error) {
-	resp := backend.NewQueryDataResponse()
-
+func (s *Service) decodeResponse(logger log.Logger, res *http.Response) ([]OpenTsdbResponse, error) {
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
 		return nil, err
@@ -164,9 +282,26 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 		logger.Info("Failed to unmarshal opentsdb response", "error", err, "status", res.Status, "body", string(body))
 		return nil, err
 	}
+	return responseData, nil
+}
+
+// parseResponse converts the returned series to data frames of the queries they were requested by
+func (s *Service) parseResponse(logger log.Logger, res *http.Response, dsInfo *datasourceInfo, queries []backend.DataQuery, models []QueryModel) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	responseData, err := s.decodeResponse(logger, res)
+	if err != nil {
+		return nil, err
+	}
 
-	frames := data.Frames{}
 	for _, val := range responseData {
+		idx, ok := queryIndex(val, models)
+		if !ok {
+			logger.Warn("Dropping series that does not match any query", "metric", val.Metric, "tags", val.Tags)
+			continue
+		}
+		refID := queries[idx].RefID
+
 		labels := data.Labels{}
 		for label, value := range val.Tags {
 			labels[label] = value
@@ -174,25 +309,82 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 
 		frame := data.NewFrameOfFieldTypes(val.Metric, len(val.DataPoints), data.FieldTypeTime, data.FieldTypeFloat64)
 		frame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesMulti, TypeVersion: data.FrameTypeVersion{0, 1}}
-		frame.RefID = myRefID
+		frame.RefID = refID
 		timeField := frame.Fields[0]
 		timeField.Name = data.TimeSeriesTimeFieldName
 		dataField := frame.Fields[1]
 		dataField.Name = "value"
 		dataField.Labels = labels
+		if alias := models[idx].Alias; alias != "" {
+			dataField.Config = &data.FieldConfig{DisplayNameFromDS: formatAlias(alias, val.Tags)}
+		}
 
 		points := val.DataPoints
 		for i, point := range points {
-			frame.SetRow(i, time.Unix(int64(point[0]), 0).UTC(), point[1])
+			if len(point) != 2 {
+				return nil, fmt.Errorf("invalid data point in series %s", val.Metric)
+			}
+			timestamp := time.Unix(int64(point[0]), 0).UTC()
+			if dsInfo.TSDBResolution == tsdbResolutionMs {
+				timestamp = time.UnixMilli(int64(point[0])).UTC()
+			}
+			frame.SetRow(i, timestamp, point[1])
 		}
-		frames = append(frames, frame)
+
+		result := resp.Responses[refID]
+		result.Frames = append(result.Frames, frame)
+		resp.Responses[refID] = result
 	}
-	result := resp.Responses[myRefID]
-	result.Frames = frames
-	resp.Responses[myRefID] = result
 	return resp, nil
 }
 
+// queryIndex returns the index of the query a series was returned for. OpenTSDB 2.3 returns the
+// index of the query, series of older versions are matched by metric and tags. Queries with tag
+// filters are matched by metric only, as their series may not include the filtered tags.
+// It returns false when the series matches none of the queries.
+func queryIndex(series OpenTsdbResponse, models []QueryModel) (int, bool) {
+	if series.Query != nil && series.Query.Index >= 0 && series.Query.Index < len(models) {
+		return series.Query.Index, true
+	}
+	for i, model := range models {
+		if model.Metric != series.Metric {
+			continue
+		}
+		if len(model.Filters) > 0 || tagsMatch(model.Tags, series.Tags) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// tagsMatch checks the tags of a series against the tags of a query, where a query
+// tag value is either * or a list of values separated by |
+func tagsMatch(queryTags map[string]any, seriesTags map[string]string) bool {
+	for key, value := range queryTags {
+		tagValue := fmt.Sprint(value)
+		if tagValue == "*" {
+			continue
+		}
+		if !slices.Contains(strings.Split(tagValue, "|"), seriesTags[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// formatAlias replaces the tag variables of an alias with the tag values of the series
+func formatAlias(alias string, tags map[string]string) string {
+	return tagAliasPattern.ReplaceAllStringFunc(alias, func(match string) string {
+		groups := tagAliasPattern.FindStringSubmatch(match)
+		for _, key := range groups[1:] {
+			if value, ok := tags[key]; key != "" && ok {
+				return value
+			}
+		}
+		return match
+	})
+}
+
 func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 	metric := make(map[string]any)
 
@@ -204,6 +396,9 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 	// Setting metric and aggregator
 	metric["metric"] = model.Get("metric").MustString()
 	metric["aggregator"] = model.Get("aggregator").MustString()
+	if metric["aggregator"] == "" {
+		metric["aggregator"] = "avg"
+	}
 
 	// Setting downsampling options
 	disableDownsampling := model.Get("disableDownsampling").MustBool()
@@ -243,21 +438,45 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 		metric["rateOptions"] = rateOptions
 	}
 
-	// Setting tags
-	tags, tagsCheck := model.CheckGet("tags")
-	if tagsCheck && len(tags.MustMap()) > 0 {
-		metric["tags"] = tags.MustMap()
+	// Setting filters, which replace the tags since OpenTSDB 2.2
+	filters := parseFilters(model)
+	if len(filters) > 0 {
+		metric["filters"] = filters
+	} else {
+		tags, tagsCheck := model.CheckGet("tags")
+		if tagsCheck && len(tags.MustMap()) > 0 {
+			metric["tags"] = tags.MustMap()
+		}
 	}
 
-	// Setting filters
-	filters, filtersCheck := model.CheckGet("filters")
-	if filtersCheck && len(filters.MustArray()) > 0 {
-		metric["filters"] = filters.MustArray()
+	// Only return the series with exactly the tags of the query
+	if model.Get("explicitTags").MustBool() {
+		metric["explicitTags"] = true
 	}
 
 	return metric
 }
 
+// parseFilters returns the tag filters of the query model, skipping filters without a tag key
+func parseFilters(model *simplejson.Json) []OpenTsdbFilter {
+	filters := []OpenTsdbFilter{}
+	for _, f := range model.Get("filters").MustArray() {
+		filter, ok := f.(map[string]any)
+		if !ok {
+			continue
+		}
+		tagk, _ := filter["tagk"].(string)
+		if tagk == "" {
+			continue
+		}
+		filterType, _ := filter["type"].(string)
+		value, _ := filter["filter"].(string)
+		groupBy, _ := filter["groupBy"].(bool)
+		filters = append(filters, OpenTsdbFilter{Type: filterType, Tagk: tagk, Filter: value, GroupBy: groupBy})
+	}
+	return filters
+}
+
 func (s *Service) getDSInfo(ctx context.Context, pluginCtx backend.PluginContext) (*datasourceInfo, error) {
 	i, err := s.im.Get(ctx, pluginCtx)
 	if err != nil {
@@ -2,14 +2,17 @@ package opentsdb
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -33,7 +36,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 	t.Run("Parse response should handle invalid JSON", func(t *testing.T) {
 		response := `{ invalid }`
 
-		result, err := service.parseResponse(logger, &http.Response{Body: io.NopCloser(strings.NewReader(response))}, "A")
+		result, err := service.parseResponse(logger, &http.Response{Body: io.NopCloser(strings.NewReader(response))}, &datasourceInfo{}, []backend.DataQuery{{RefID: "A"}}, []QueryModel{{}})
 		require.Nil(t, result)
 		require.Error(t, err)
 	})
@@ -68,7 +71,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 
 		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(logger, &resp, "A")
+		result, err := service.parseResponse(logger, &resp, &datasourceInfo{}, []backend.DataQuery{{RefID: "A"}}, []QueryModel{{Metric: "test"}})
 		require.NoError(t, err)
 
 		frame := result.Responses["A"]
@@ -110,7 +113,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 
 		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(logger, &resp, myRefid)
+		result, err := service.parseResponse(logger, &resp, &datasourceInfo{}, []backend.DataQuery{{RefID: myRefid}}, []QueryModel{{Metric: "test"}})
 		require.NoError(t, err)
 
 		if diff := cmp.Diff(testFrame, result.Responses[myRefid].Frames[0], data.FrameTestCompareOptions()...); diff != "" {
@@ -286,4 +289,163 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		require.Equal(t, float64(45), metricRateOptions["counterMax"])
 		require.Equal(t, float64(60), metricRateOptions["resetValue"])
 	})
+
+	t.Run("Build metric with filters and explicit tags", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"disableDownsampling": true,
+						"explicitTags": true,
+						"tags": {
+							"env": "prod"
+						},
+						"filters": [
+							{"type": "wildcard", "tagk": "host", "filter": "web*", "groupBy": true},
+							{"type": "literal_or", "tagk": "", "filter": "skipped", "groupBy": false}
+						]
+					}`,
+			),
+		}
+
+		metric := service.buildMetric(query)
+
+		require.Len(t, metric, 4)
+		require.Equal(t, "avg", metric["aggregator"])
+		require.Nil(t, metric["tags"])
+		require.True(t, metric["explicitTags"].(bool))
+		require.Equal(t, []OpenTsdbFilter{{Type: "wildcard", Tagk: "host", Filter: "web*", GroupBy: true}}, metric["filters"])
+	})
+
+	t.Run("Parse response attributes series to their queries", func(t *testing.T) {
+		response := `
+		[
+			{"metric": "cpu", "tags": {"host": "a"}, "dps": [[1405544146, 1.0]]},
+			{"metric": "cpu", "tags": {"host": "b"}, "dps": [[1405544146, 2.0]]},
+			{"metric": "mem", "tags": {"host": "a"}, "dps": [[1405544146, 3.0]]}
+		]`
+		queries := []backend.DataQuery{{RefID: "A"}, {RefID: "B"}, {RefID: "C"}}
+		models := []QueryModel{
+			{Metric: "cpu", Tags: map[string]any{"host": "a"}},
+			{Metric: "cpu", Tags: map[string]any{"host": "b|c"}, Alias: "cpu on $tag_host"},
+			{Metric: "mem", Filters: []OpenTsdbFilter{{Type: "wildcard", Tagk: "host", Filter: "*"}}},
+		}
+
+		resp := http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(response))}
+		result, err := service.parseResponse(logger, &resp, &datasourceInfo{}, queries, models)
+		require.NoError(t, err)
+
+		require.Len(t, result.Responses, 3)
+		require.Equal(t, "a", result.Responses["A"].Frames[0].Fields[1].Labels["host"])
+		require.Equal(t, "cpu on b", result.Responses["B"].Frames[0].Fields[1].Config.DisplayNameFromDS)
+		require.Equal(t, "mem", result.Responses["C"].Frames[0].Name)
+	})
+
+	t.Run("Parse response drops series that match no query", func(t *testing.T) {
+		response := `
+		[
+			{"metric": "cpu", "tags": {"host": "a"}, "dps": [[1405544146, 1.0]]},
+			{"metric": "disk", "tags": {"host": "a"}, "dps": [[1405544146, 2.0]]}
+		]`
+		queries := []backend.DataQuery{{RefID: "A"}, {RefID: "B"}}
+		models := []QueryModel{
+			{Metric: "mem"},
+			{Metric: "cpu", Tags: map[string]any{"host": "b"}},
+		}
+
+		resp := http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(response))}
+		result, err := service.parseResponse(logger, &resp, &datasourceInfo{}, queries, models)
+		require.NoError(t, err)
+
+		require.Empty(t, result.Responses)
+	})
+
+	t.Run("Parse response uses the query index of OpenTSDB 2.3", func(t *testing.T) {
+		response := `
+		[
+			{"metric": "cpu", "tags": {}, "dps": [[1405544146000, 1.0]], "query": {"index": 1}}
+		]`
+		queries := []backend.DataQuery{{RefID: "A"}, {RefID: "B"}}
+		models := []QueryModel{{Metric: "cpu"}, {Metric: "cpu"}}
+
+		resp := http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(response))}
+		result, err := service.parseResponse(logger, &resp, &datasourceInfo{TSDBVersion: tsdbVersion23, TSDBResolution: tsdbResolutionMs}, queries, models)
+		require.NoError(t, err)
+
+		require.Len(t, result.Responses, 1)
+		frame := result.Responses["B"].Frames[0]
+		require.Equal(t, time.Date(2014, 7, 16, 20, 55, 46, 0, time.UTC), frame.Fields[0].At(0))
+	})
+}
+
+func TestAnnotationQuery(t *testing.T) {
+	var requestBody OpenTsdbQuery
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_ = json.NewDecoder(req.Body).Decode(&requestBody)
+		_, _ = rw.Write([]byte(`[{
+			"metric": "deploys",
+			"dps": [],
+			"annotations": [{"description": "deploy", "startTime": 1405544146}],
+			"globalAnnotations": [{"description": "outage", "startTime": 1405544100}]
+		}]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	service := &Service{im: fakeInstanceManager{dsInfo: &datasourceInfo{HTTPClient: srv.Client(), URL: srv.URL}}}
+	timeRange := backend.TimeRange{From: time.Unix(1405544000, 0), To: time.Unix(1405545000, 0)}
+
+	testCases := []struct {
+		name     string
+		isGlobal bool
+		expected *data.Frame
+	}{
+		{
+			name: "annotations of the target metric",
+			expected: data.NewFrame("",
+				data.NewField("time", nil, []time.Time{time.Unix(1405544146, 0).UTC()}),
+				data.NewField("text", nil, []string{"deploy"}),
+			),
+		},
+		{
+			name:     "global annotations",
+			isGlobal: true,
+			expected: data.NewFrame("",
+				data.NewField("time", nil, []time.Time{time.Unix(1405544100, 0).UTC()}),
+				data.NewField("text", nil, []string{"outage"}),
+			),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			model, err := json.Marshal(QueryModel{FromAnnotations: true, Target: "deploys", IsGlobal: tc.isGlobal})
+			require.NoError(t, err)
+
+			result, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+				Queries: []backend.DataQuery{{RefID: "Anno", JSON: model, TimeRange: timeRange}},
+			})
+			require.NoError(t, err)
+			require.NoError(t, result.Responses["Anno"].Error)
+
+			tc.expected.RefID = "Anno"
+			if diff := cmp.Diff(tc.expected, result.Responses["Anno"].Frames[0], data.FrameTestCompareOptions()...); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+			require.True(t, requestBody.GlobalAnnotations)
+			require.Equal(t, "deploys", requestBody.Queries[0]["metric"])
+			require.Equal(t, "sum", requestBody.Queries[0]["aggregator"])
+		})
+	}
+}
+
+type fakeInstanceManager struct {
+	dsInfo *datasourceInfo
+}
+
+func (f fakeInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return f.dsInfo, nil
+}
+
+func (f fakeInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
 }
@@ -0,0 +1,163 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/suggest", s.handleSuggest)
+	mux.HandleFunc("/api/aggregators", s.handleAggregators)
+	mux.HandleFunc("/api/config/filters", s.handleFilterTypes)
+	return mux
+}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return s.resourceHandler.CallResource(ctx, req, sender)
+}
+
+// handleSuggest returns the metrics, tag keys or tag values starting with q,
+// limited by max or else the lookup limit of the datasource
+func (s *Service) handleSuggest(rw http.ResponseWriter, req *http.Request) {
+	dsInfo, err := s.getDSInfo(req.Context(), backend.PluginConfigFromContext(req.Context()))
+	if err != nil {
+		writeResponse(rw, http.StatusInternalServerError, fmt.Sprintf("unexpected error %v", err))
+		return
+	}
+
+	query := req.URL.Query()
+	suggestType := query.Get("type")
+	if !slices.Contains([]string{"metrics", "tagk", "tagv"}, suggestType) {
+		writeResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid suggest type %q, expected metrics, tagk or tagv", suggestType))
+		return
+	}
+	limit := dsInfo.LookupLimit
+	if maxParam := query.Get("max"); maxParam != "" {
+		if limit, err = strconv.Atoi(maxParam); err != nil || limit < 1 {
+			writeResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid max %q", maxParam))
+			return
+		}
+	}
+
+	params := url.Values{
+		"type": []string{suggestType},
+		"q":    []string{query.Get("q")},
+		"max":  []string{strconv.Itoa(limit)},
+	}
+	var suggestions []string
+	if code, err := s.getJSON(req.Context(), dsInfo, "api/suggest", params, &suggestions); err != nil {
+		writeResponse(rw, code, err.Error())
+		return
+	}
+	writeJSONResponse(rw, suggestions)
+}
+
+func (s *Service) handleAggregators(rw http.ResponseWriter, req *http.Request) {
+	dsInfo, err := s.getDSInfo(req.Context(), backend.PluginConfigFromContext(req.Context()))
+	if err != nil {
+		writeResponse(rw, http.StatusInternalServerError, fmt.Sprintf("unexpected error %v", err))
+		return
+	}
+
+	var aggregators []string
+	if code, err := s.getJSON(req.Context(), dsInfo, "api/aggregators", url.Values{}, &aggregators); err != nil {
+		writeResponse(rw, code, err.Error())
+		return
+	}
+	slices.Sort(aggregators)
+	writeJSONResponse(rw, aggregators)
+}
+
+// handleFilterTypes returns the names of the tag filter types supported by OpenTSDB
+func (s *Service) handleFilterTypes(rw http.ResponseWriter, req *http.Request) {
+	dsInfo, err := s.getDSInfo(req.Context(), backend.PluginConfigFromContext(req.Context()))
+	if err != nil {
+		writeResponse(rw, http.StatusInternalServerError, fmt.Sprintf("unexpected error %v", err))
+		return
+	}
+
+	var filters map[string]any
+	if code, err := s.getJSON(req.Context(), dsInfo, "api/config/filters", url.Values{}, &filters); err != nil {
+		writeResponse(rw, code, err.Error())
+		return
+	}
+	filterTypes := make([]string, 0, len(filters))
+	for filterType := range filters {
+		filterTypes = append(filterTypes, filterType)
+	}
+	slices.Sort(filterTypes)
+	writeJSONResponse(rw, filterTypes)
+}
+
+// getJSON calls an OpenTSDB HTTP API endpoint and decodes its JSON response into v
+func (s *Service) getJSON(ctx context.Context, dsInfo *datasourceInfo, endpoint string, params url.Values, v any) (int, error) {
+	logger := logger.FromContext(ctx)
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	u.Path = path.Join(u.Path, endpoint)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		logger.Info("Failed to create request", "error", err)
+		return http.StatusInternalServerError, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return http.StatusBadGateway, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if res.StatusCode/100 != 2 {
+		logger.Info("Request failed", "status", res.Status, "endpoint", endpoint, "body", string(body))
+		return res.StatusCode, fmt.Errorf("request failed, status: %s", res.Status)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		logger.Info("Failed to unmarshal opentsdb response", "error", err, "endpoint", endpoint, "body", string(body))
+		return http.StatusInternalServerError, fmt.Errorf("failed to parse opentsdb response: %w", err)
+	}
+	return http.StatusOK, nil
+}
+
+func writeJSONResponse(rw http.ResponseWriter, res any) {
+	body, err := json.Marshal(res)
+	if err != nil {
+		writeResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to marshal response: %v", err))
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	writeResponseBytes(rw, http.StatusOK, body)
+}
+
+func writeResponseBytes(rw http.ResponseWriter, code int, msg []byte) {
+	rw.WriteHeader(code)
+	if _, err := rw.Write(msg); err != nil {
+		logger.Error("Unable to write HTTP response", "error", err)
+	}
+}
+
+func writeResponse(rw http.ResponseWriter, code int, msg string) {
+	writeResponseBytes(rw, code, []byte(msg))
+}
@@ -0,0 +1,67 @@
+package opentsdb
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceHandler(t *testing.T) {
+	lastRequest := &url.URL{}
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		*lastRequest = *req.URL
+		switch req.URL.Path {
+		case "/api/suggest":
+			_, _ = rw.Write([]byte(`["cpu.idle","cpu.user"]`))
+		case "/api/aggregators":
+			_, _ = rw.Write([]byte(`["sum","avg","max"]`))
+		case "/api/config/filters":
+			_, _ = rw.Write([]byte(`{"wildcard":{},"literal_or":{},"regexp":{}}`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	service := &Service{im: fakeInstanceManager{dsInfo: &datasourceInfo{HTTPClient: srv.Client(), URL: srv.URL, LookupLimit: defaultLookupLimit}}}
+
+	callResource := func(path string) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		service.newResourceMux().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, path, nil))
+		return rw
+	}
+
+	t.Run("suggest uses the lookup limit", func(t *testing.T) {
+		rw := callResource("/api/suggest?type=metrics&q=cpu")
+		require.Equal(t, http.StatusOK, rw.Code, rw.Body.String())
+		assert.JSONEq(t, `["cpu.idle","cpu.user"]`, rw.Body.String())
+		assert.Equal(t, url.Values{"type": {"metrics"}, "q": {"cpu"}, "max": {"1000"}}, lastRequest.Query())
+	})
+
+	t.Run("suggest with max", func(t *testing.T) {
+		rw := callResource("/api/suggest?type=tagk&q=ho&max=10")
+		require.Equal(t, http.StatusOK, rw.Code, rw.Body.String())
+		assert.Equal(t, "10", lastRequest.Query().Get("max"))
+	})
+
+	t.Run("suggest rejects invalid parameters", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, callResource("/api/suggest?type=series&q=cpu").Code)
+		assert.Equal(t, http.StatusBadRequest, callResource("/api/suggest?type=tagv&max=all").Code)
+	})
+
+	t.Run("aggregators are sorted", func(t *testing.T) {
+		rw := callResource("/api/aggregators")
+		require.Equal(t, http.StatusOK, rw.Code, rw.Body.String())
+		assert.JSONEq(t, `["avg","max","sum"]`, rw.Body.String())
+	})
+
+	t.Run("filter types", func(t *testing.T) {
+		rw := callResource("/api/config/filters")
+		require.Equal(t, http.StatusOK, rw.Code, rw.Body.String())
+		assert.JSONEq(t, `["literal_or","regexp","wildcard"]`, rw.Body.String())
+	})
+}
@@ -1,13 +1,53 @@
 package opentsdb
 
 type OpenTsdbQuery struct {
-	Start   int64            `json:"start"`
-	End     int64            `json:"end"`
-	Queries []map[string]any `json:"queries"`
+	Start             int64            `json:"start"`
+	End               int64            `json:"end"`
+	Queries           []map[string]any `json:"queries"`
+	MsResolution      bool             `json:"msResolution,omitempty"`
+	GlobalAnnotations bool             `json:"globalAnnotations,omitempty"`
+	ShowQuery         bool             `json:"showQuery,omitempty"`
 }
 
 type OpenTsdbResponse struct {
-	Metric     string            `json:"metric"`
-	Tags       map[string]string `json:"tags"`
-	DataPoints [][]float64       `json:"dps"`
+	Metric            string               `json:"metric"`
+	Tags              map[string]string    `json:"tags"`
+	AggregateTags     []string             `json:"aggregateTags"`
+	DataPoints        [][]float64          `json:"dps"`
+	Annotations       []OpenTsdbAnnotation `json:"annotations"`
+	GlobalAnnotations []OpenTsdbAnnotation `json:"globalAnnotations"`
+	// Query is only returned by OpenTSDB 2.3 when showQuery is set
+	Query *OpenTsdbResponseQuery `json:"query"`
+}
+
+type OpenTsdbResponseQuery struct {
+	Index int `json:"index"`
+}
+
+type OpenTsdbAnnotation struct {
+	Description string  `json:"description"`
+	StartTime   float64 `json:"startTime"`
+	EndTime     float64 `json:"endTime"`
+}
+
+// OpenTsdbFilter is a tag filter, supported since OpenTSDB 2.2. Series are only
+// grouped by the tag of a filter when GroupBy is set.
+type OpenTsdbFilter struct {
+	Type    string `json:"type"`
+	Tagk    string `json:"tagk"`
+	Filter  string `json:"filter"`
+	GroupBy bool   `json:"groupBy"`
+}
+
+// QueryModel holds the parts of the query model needed to attribute the
+// returned series to their query, and the annotation query attributes
+type QueryModel struct {
+	Metric  string           `json:"metric"`
+	Alias   string           `json:"alias"`
+	Tags    map[string]any   `json:"tags"`
+	Filters []OpenTsdbFilter `json:"filters"`
+
+	FromAnnotations bool   `json:"fromAnnotations"`
+	IsGlobal        bool   `json:"isGlobal"`
+	Target          string `json:"target"`
 }
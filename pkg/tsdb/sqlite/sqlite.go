@@ -0,0 +1,229 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/mysql/sqleng"
+	"github.com/grafana/grafana/pkg/util/sqliteutil"
+)
+
+// driverName is the sqlite3 driver that denies access to other files than the
+// database of the data source, see sqliteutil.RestrictConn.
+const driverName = "sqlite3_datasource"
+
+func init() {
+	sqliteutil.RegisterRestrictedDriver(driverName)
+}
+
+type Service struct {
+	im     instancemgmt.InstanceManager
+	logger log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg) *Service {
+	logger := backend.NewLoggerWith("logger", "tsdb.sqlite")
+	return &Service{
+		im:     datasource.NewInstanceManager(NewInstanceSettings(cfg, logger)),
+		logger: logger,
+	}
+}
+
+func (s *Service) getDataSourceHandler(ctx context.Context, pluginCtx backend.PluginContext) (*sqleng.DataSourceHandler, error) {
+	i, err := s.im.Get(ctx, pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	instance := i.(*sqleng.DataSourceHandler)
+	return instance, nil
+}
+
+// NOTE: do not put any business logic into this method. it's whole job is to forward the call "inside"
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return dsHandler.CheckHealth(ctx, req)
+}
+
+// NOTE: do not put any business logic into this method. it's whole job is to forward the call "inside"
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.QueryData(ctx, req)
+}
+
+func NewInstanceSettings(cfg *setting.Cfg, logger log.Logger) datasource.InstanceFactoryFunc {
+	return func(ctx context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		grafCfg := backend.GrafanaConfigFromContext(ctx)
+		sqlCfg, err := grafCfg.SQL()
+		if err != nil {
+			return nil, err
+		}
+		jsonData := sqleng.JsonData{
+			MaxOpenConns:    sqlCfg.DefaultMaxOpenConns,
+			MaxIdleConns:    sqlCfg.DefaultMaxIdleConns,
+			ConnMaxLifetime: sqlCfg.DefaultMaxConnLifetimeSeconds,
+		}
+
+		err = json.Unmarshal(settings.JSONData, &jsonData)
+		if err != nil {
+			return nil, fmt.Errorf("error reading settings: %w", err)
+		}
+
+		// The database is the path of the SQLite file on the Grafana server.
+		database := jsonData.Database
+		if database == "" {
+			database = settings.Database
+		}
+		if database == "" {
+			return nil, errors.New("missing path of the SQLite database file")
+		}
+		database, err = checkAllowedPath(cfg.SQLiteAllowedPaths, database)
+		if err != nil {
+			return nil, err
+		}
+
+		dsInfo := sqleng.DataSourceInfo{
+			JsonData:                jsonData,
+			URL:                     settings.URL,
+			User:                    settings.User,
+			Database:                database,
+			ID:                      settings.ID,
+			Updated:                 settings.Updated,
+			UID:                     settings.UID,
+			DecryptedSecureJSONData: settings.DecryptedSecureJSONData,
+		}
+
+		config := sqleng.DataPluginConfiguration{
+			DSInfo:            dsInfo,
+			TimeColumnNames:   []string{"time", "time_sec"},
+			MetricColumnTypes: []string{"TEXT", "VARCHAR", "CHAR", "NVARCHAR", "NCHAR", "CLOB"},
+			RowLimit:          sqlCfg.RowLimit,
+		}
+
+		userFacingDefaultError, err := grafCfg.UserFacingDefaultError()
+		if err != nil {
+			return nil, err
+		}
+
+		db, err := sql.Open(driverName, connectionString(dsInfo.Database))
+		if err != nil {
+			return nil, err
+		}
+
+		db.SetMaxOpenConns(config.DSInfo.JsonData.MaxOpenConns)
+		db.SetMaxIdleConns(config.DSInfo.JsonData.MaxIdleConns)
+		db.SetConnMaxLifetime(time.Duration(config.DSInfo.JsonData.ConnMaxLifetime) * time.Second)
+
+		return sqleng.NewQueryDataHandler(userFacingDefaultError, db, config, &sqliteQueryResultTransformer{}, newSqliteMacroEngine(logger, userFacingDefaultError), logger)
+	}
+}
+
+// checkAllowedPath returns the resolved path of the database file if it is one of the allowed
+// paths or is inside one of the allowed directories. Symbolic links are resolved first, so that
+// they cannot point outside of the allowed paths.
+func checkAllowedPath(allowedPaths []string, path string) (string, error) {
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path of the SQLite database file: %w", err)
+	}
+	for _, allowed := range allowedPaths {
+		allowed, err := resolvePath(allowed)
+		if err != nil {
+			continue
+		}
+		if resolved == allowed || strings.HasPrefix(resolved, allowed+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("the SQLite database file %q is not in the allowed_paths of the [sqlite] configuration section", path)
+}
+
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// connectionString opens the database file read-only, the file is never
+// created or modified by queries.
+func connectionString(path string) string {
+	u := url.URL{Scheme: "file", Opaque: (&url.URL{Path: path}).EscapedPath()}
+	q := url.Values{}
+	q.Set("mode", "ro")
+	q.Set("_query_only", "true")
+	q.Set("_busy_timeout", "5000")
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+type sqliteQueryResultTransformer struct{}
+
+func (t *sqliteQueryResultTransformer) TransformQueryError(_ log.Logger, err error) error {
+	return err
+}
+
+func (t *sqliteQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	return []sqlutil.StringConverter{}
+}
+
+// TransformFrame converts the columns without a declared type, e.g. results of
+// aggregate functions or $__timeGroup, to numbers when all their values are
+// numbers. SQLite is dynamically typed, so such columns are read as strings.
+func (t *sqliteQueryResultTransformer) TransformFrame(frame *data.Frame, columnTypes []*sql.ColumnType) {
+	for i, columnType := range columnTypes {
+		if i >= len(frame.Fields) || columnType.DatabaseTypeName() != "" {
+			continue
+		}
+		convertNumericField(frame, i)
+	}
+}
+
+// convertNumericField replaces the string field at index i with a float field
+// if all its non-null values are numbers.
+func convertNumericField(frame *data.Frame, i int) {
+	field := frame.Fields[i]
+	if field.Type() != data.FieldTypeNullableString {
+		return
+	}
+
+	values := make([]*float64, field.Len())
+	for j := 0; j < field.Len(); j++ {
+		s, ok := field.ConcreteAt(j)
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseFloat(s.(string), 64)
+		if err != nil {
+			return
+		}
+		values[j] = &v
+	}
+
+	converted := data.NewField(field.Name, field.Labels, values)
+	converted.Config = field.Config
+	frame.Fields[i] = converted
+}
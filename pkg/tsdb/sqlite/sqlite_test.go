@@ -0,0 +1,271 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/mysql/sqleng"
+)
+
+func TestIntegrationSQLite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	path := filepath.Join(t.TempDir(), "metrics.db")
+	fromStart := time.Date(2018, 3, 15, 13, 0, 0, 0, time.UTC)
+
+	rw, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	_, err = rw.Exec("CREATE TABLE metric (time DATETIME, host TEXT, value REAL)")
+	require.NoError(t, err)
+	for i, value := range []float64{15, 20, 25, 30, 35, 40} {
+		tm := fromStart.Add(time.Duration(i) * 2 * time.Minute)
+		_, err = rw.Exec("INSERT INTO metric VALUES (?, ?, ?)", tm.Format("2006-01-02 15:04:05"), "server1", value)
+		require.NoError(t, err)
+	}
+	require.NoError(t, rw.Close())
+
+	dsInfo := sqleng.DataSourceInfo{
+		JsonData: sqleng.JsonData{
+			MaxOpenConns:    0,
+			MaxIdleConns:    2,
+			ConnMaxLifetime: 14400,
+		},
+		Database: path,
+	}
+
+	config := sqleng.DataPluginConfiguration{
+		DSInfo:            dsInfo,
+		TimeColumnNames:   []string{"time", "time_sec"},
+		MetricColumnTypes: []string{"TEXT", "VARCHAR", "CHAR", "NVARCHAR", "NCHAR", "CLOB"},
+		RowLimit:          1000000,
+	}
+
+	logger := backend.NewLoggerWith("logger", "sqlite.test")
+
+	db, err := sql.Open(driverName, connectionString(path))
+	require.NoError(t, err)
+
+	exe, err := sqleng.NewQueryDataHandler("", db, config, &sqliteQueryResultTransformer{}, newSqliteMacroEngine(logger, ""), logger)
+	require.NoError(t, err)
+	t.Cleanup(exe.Dispose)
+
+	timeRange := backend.TimeRange{From: fromStart, To: fromStart.Add(30 * time.Minute)}
+
+	t.Run("When doing a health check should succeed", func(t *testing.T) {
+		res, err := exe.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+
+	t.Run("When doing a table query should return typed columns", func(t *testing.T) {
+		query := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					JSON: []byte(`{
+						"rawSql": "SELECT host, value, count(*) AS cnt FROM metric GROUP BY host",
+						"format": "table"
+					}`),
+					RefID:     "A",
+					TimeRange: timeRange,
+				},
+			},
+		}
+
+		resp, err := exe.QueryData(context.Background(), query)
+		require.NoError(t, err)
+		queryResult := resp.Responses["A"]
+		require.NoError(t, queryResult.Error)
+
+		frames := queryResult.Frames
+		require.Len(t, frames, 1)
+		require.Len(t, frames[0].Fields, 3)
+		require.Equal(t, "server1", *frames[0].Fields[0].At(0).(*string))
+		require.Equal(t, float64(6), *frames[0].Fields[2].At(0).(*float64))
+	})
+
+	t.Run("When doing a metric query using timeGroup", func(t *testing.T) {
+		query := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					JSON: []byte(`{
+						"rawSql": "SELECT $__timeGroupAlias(time, '5m'), avg(value) AS value FROM metric WHERE $__timeFilter(time) GROUP BY 1 ORDER BY 1",
+						"format": "time_series"
+					}`),
+					RefID:     "A",
+					TimeRange: timeRange,
+				},
+			},
+		}
+
+		resp, err := exe.QueryData(context.Background(), query)
+		require.NoError(t, err)
+		queryResult := resp.Responses["A"]
+		require.NoError(t, queryResult.Error)
+
+		frames := queryResult.Frames
+		require.Len(t, frames, 1)
+		require.Equal(t, 3, frames[0].Fields[0].Len())
+
+		expected := []struct {
+			time  time.Time
+			value float64
+		}{
+			{time: fromStart, value: 20},
+			{time: fromStart.Add(5 * time.Minute), value: 32.5},
+			{time: fromStart.Add(10 * time.Minute), value: 40},
+		}
+		for i, e := range expected {
+			require.Equal(t, e.time.UnixMilli(), frames[0].Fields[0].At(i).(*time.Time).UnixMilli())
+			require.Equal(t, e.value, *frames[0].Fields[1].At(i).(*float64))
+		}
+	})
+
+	t.Run("When doing a metric query using timeGroup with NULL fill", func(t *testing.T) {
+		query := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					JSON: []byte(`{
+						"rawSql": "SELECT $__timeGroupAlias(time, '5m', NULL), avg(value) AS value FROM metric WHERE $__timeFilter(time) GROUP BY 1 ORDER BY 1",
+						"format": "time_series"
+					}`),
+					RefID:     "A",
+					TimeRange: timeRange,
+				},
+			},
+		}
+
+		resp, err := exe.QueryData(context.Background(), query)
+		require.NoError(t, err)
+		queryResult := resp.Responses["A"]
+		require.NoError(t, queryResult.Error)
+
+		frames := queryResult.Frames
+		require.Len(t, frames, 1)
+		// buckets up to the end of the time range are added and filled with NULL
+		n := frames[0].Fields[0].Len()
+		require.Greater(t, n, 3)
+		require.Nil(t, frames[0].Fields[1].At(n-1))
+	})
+
+	t.Run("When writing to the database should fail", func(t *testing.T) {
+		query := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					JSON: []byte(`{
+						"rawSql": "DELETE FROM metric",
+						"format": "table"
+					}`),
+					RefID:     "A",
+					TimeRange: timeRange,
+				},
+			},
+		}
+
+		resp, err := exe.QueryData(context.Background(), query)
+		require.NoError(t, err)
+		require.Error(t, resp.Responses["A"].Error)
+	})
+
+	t.Run("When accessing other files should fail", func(t *testing.T) {
+		other := filepath.Join(t.TempDir(), "other.db")
+		tcs := map[string]string{
+			"attach":                  fmt.Sprintf("ATTACH DATABASE '%s' AS o", other),
+			"attach after a select":   fmt.Sprintf("select 1;/**/attach/**/database '%s' as o", other),
+			"vacuum into":             fmt.Sprintf("VACUUM INTO '%s'", other),
+			"load extension":          "SELECT load_extension('mod_spatialite')",
+			"load extension any case": "SELECT LOAD_EXTENSION ('mod_spatialite')",
+		}
+		for name, rawSQL := range tcs {
+			t.Run(name, func(t *testing.T) {
+				queryJSON, err := json.Marshal(map[string]string{"rawSql": rawSQL, "format": "table"})
+				require.NoError(t, err)
+				query := &backend.QueryDataRequest{
+					Queries: []backend.DataQuery{{JSON: queryJSON, RefID: "A", TimeRange: timeRange}},
+				}
+
+				resp, err := exe.QueryData(context.Background(), query)
+				require.NoError(t, err)
+				require.Error(t, resp.Responses["A"].Error)
+				require.NoFileExists(t, other)
+			})
+		}
+	})
+}
+
+func TestCheckAllowedPath(t *testing.T) {
+	dir := t.TempDir()
+	allowedDir := filepath.Join(dir, "allowed")
+	require.NoError(t, os.Mkdir(allowedDir, 0o750))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "allow"), 0o750))
+	allowed := filepath.Join(allowedDir, "metrics.db")
+	other := filepath.Join(dir, "other.db")
+	for _, f := range []string{allowed, other} {
+		require.NoError(t, os.WriteFile(f, nil, 0o600))
+	}
+	link := filepath.Join(allowedDir, "link.db")
+	require.NoError(t, os.Symlink(other, link))
+
+	for _, tc := range []struct {
+		name         string
+		allowedPaths []string
+		path         string
+		err          bool
+	}{
+		{name: "file in allowed directory", allowedPaths: []string{allowedDir}, path: allowed},
+		{name: "allowed file", allowedPaths: []string{allowed}, path: allowed},
+		{name: "relative path out of allowed directory", allowedPaths: []string{allowedDir}, path: filepath.Join(allowedDir, "..", "other.db"), err: true},
+		{name: "link out of allowed directory", allowedPaths: []string{allowedDir}, path: link, err: true},
+		{name: "directory with the same prefix", allowedPaths: []string{filepath.Join(dir, "allow")}, path: allowed, err: true},
+		{name: "no allowed paths", path: allowed, err: true},
+		{name: "file does not exist", allowedPaths: []string{allowedDir}, path: filepath.Join(allowedDir, "missing.db"), err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resolved, err := checkAllowedPath(tc.allowedPaths, tc.path)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			expected, err := filepath.EvalSymlinks(tc.path)
+			require.NoError(t, err)
+			require.Equal(t, expected, resolved)
+		})
+	}
+}
+
+func TestConvertNumericField(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	floatPtr := func(f float64) *float64 { return &f }
+
+	t.Run("converts numbers and keeps nulls", func(t *testing.T) {
+		frame := data.NewFrame("", data.NewField("count", data.Labels{"a": "b"}, []*string{strPtr("1"), nil, strPtr("2.5")}))
+
+		convertNumericField(frame, 0)
+
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[0].Type())
+		require.Equal(t, data.Labels{"a": "b"}, frame.Fields[0].Labels)
+		require.Equal(t, floatPtr(1), frame.Fields[0].At(0))
+		require.Nil(t, frame.Fields[0].At(1))
+		require.Equal(t, floatPtr(2.5), frame.Fields[0].At(2))
+	})
+
+	t.Run("keeps strings that are not numbers", func(t *testing.T) {
+		frame := data.NewFrame("", data.NewField("host", nil, []*string{strPtr("1"), strPtr("server1")}))
+
+		convertNumericField(frame, 0)
+
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[0].Type())
+	})
+}
@@ -0,0 +1,39 @@
+// Package sqliteutil contains helpers for SQLite connections that run queries
+// written by users.
+package sqliteutil
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// RegisterRestrictedDriver registers a sqlite3 driver under the name whose
+// connections are restricted by RestrictConn.
+func RegisterRestrictedDriver(name string) {
+	sql.Register(name, &sqlite3.SQLiteDriver{ConnectHook: RestrictConn})
+}
+
+// RestrictConn prevents the connection from reaching other files than its own
+// database, by attaching other databases, like the Grafana database, or by
+// loading extensions. It is enforced by the engine, so it cannot be bypassed
+// by the way the statement is written.
+func RestrictConn(conn *sqlite3.SQLiteConn) error {
+	conn.SetLimit(sqlite3.SQLITE_LIMIT_ATTACHED, 0)
+	conn.RegisterAuthorizer(authorize)
+	return nil
+}
+
+func authorize(action int, _, arg2, _ string) int {
+	switch action {
+	case sqlite3.SQLITE_ATTACH, sqlite3.SQLITE_DETACH:
+		return sqlite3.SQLITE_DENY
+	case sqlite3.SQLITE_FUNCTION:
+		// arg2 is the name of the function
+		if strings.EqualFold(arg2, "load_extension") {
+			return sqlite3.SQLITE_DENY
+		}
+	}
+	return sqlite3.SQLITE_OK
+}
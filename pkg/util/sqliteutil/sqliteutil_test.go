@@ -0,0 +1,34 @@
+package sqliteutil
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestrictConn(t *testing.T) {
+	RegisterRestrictedDriver("sqlite3_sqliteutil_test")
+	db, err := sql.Open("sqlite3_sqliteutil_test", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	var n int
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(&n))
+	require.Equal(t, 1, n)
+
+	other := filepath.Join(t.TempDir(), "other.db")
+	for name, query := range map[string]string{
+		"attach":                         fmt.Sprintf("ATTACH DATABASE '%s' AS o", other),
+		"detach":                         "DETACH DATABASE main",
+		"load extension":                 "SELECT load_extension('mod_spatialite')",
+		"load extension in another case": "SELECT LOAD_EXTENSION('mod_spatialite')",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := db.Exec(query)
+			require.Error(t, err)
+		})
+	}
+}
@@ -88,6 +88,30 @@ export const Math = ({ labelWidth, onChange, query, onRunQuery }: Props) => {
                       name="floor"
                       description="rounds the number down to the nearest integer value. It's able to operate on series or escalar values."
                     />
+                    <DocumentedFunction
+                      name="clamp_min, clamp_max"
+                      description="limit each value to be no lower or no higher than the second argument. It's able to operate on series or scalar values."
+                    />
+                    <DocumentedFunction
+                      name="abs_diff"
+                      description="returns the absolute difference between its two arguments, for example abs_diff($A, $B)."
+                    />
+                    <DocumentedFunction
+                      name="shift"
+                      description='moves a series forward in time by a duration, for example $A - shift($A, "1w") compares to a week earlier.'
+                    />
+                    <DocumentedFunction
+                      name="delta, rate"
+                      description="return the difference, or the per-second rate of increase, between each point of a series and the point before it."
+                    />
+                    <DocumentedFunction
+                      name="cumsum"
+                      description="returns the running sum of a series."
+                    />
+                    <DocumentedFunction
+                      name="moving_avg"
+                      description="returns the average of the last n points at each point of a series, for example moving_avg($A, 5)."
+                    />
                   </div>
                 </div>
               }
@@ -1,7 +1,7 @@
-import { ChangeEvent } from 'react';
+import { ChangeEvent, FormEvent } from 'react';
 
 import { SelectableValue } from '@grafana/data';
-import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+import { InlineField, InlineFieldRow, InlineSwitch, Input, Select } from '@grafana/ui';
 
 import { downsamplingTypes, ExpressionQuery, upsamplingTypes } from '../types';
 
@@ -32,6 +32,15 @@ export const Resample = ({ labelWidth = 'auto', onChange, refIds, query }: Props
     onChange({ ...query, upsampler: value.value });
   };
 
+  const onFillValueChange = (event: ChangeEvent<HTMLInputElement>) => {
+    const value = parseFloat(event.target.value);
+    onChange({ ...query, fillValue: isNaN(value) ? undefined : value });
+  };
+
+  const onAlignChange = (event: FormEvent<HTMLInputElement>) => {
+    onChange({ ...query, alignToWindow: event.currentTarget.checked });
+  };
+
   return (
     <>
       <InlineFieldRow>
@@ -49,6 +58,14 @@ export const Resample = ({ labelWidth = 'auto', onChange, refIds, query }: Props
         <InlineField label="Upsample">
           <Select options={upsamplingTypes} value={upsampler} onChange={onSelectUpsampler} width={25} />
         </InlineField>
+        {query.upsampler === 'constant' && (
+          <InlineField label="Fill value">
+            <Input type="number" onChange={onFillValueChange} value={query.fillValue ?? ''} width={15} />
+          </InlineField>
+        )}
+        <InlineField label="Align to window" tooltip="Align the points to multiples of the window">
+          <InlineSwitch value={query.alignToWindow ?? false} onChange={onAlignChange} />
+        </InlineField>
       </InlineFieldRow>
     </>
   );
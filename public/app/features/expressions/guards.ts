@@ -34,5 +34,15 @@ export function isReducerType(value: string): value is ReducerType {
     'percent_diff',
     'percent_diff_abs',
     'count_non_null',
+    'first',
+    'stddev',
+    'increase',
+    'rate',
+    'p50',
+    'p75',
+    'p90',
+    'p95',
+    'p99',
+    'p99.9',
   ].includes(value);
 }
@@ -83,6 +83,22 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
   { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  { value: 'first', label: 'First', description: 'Get the first value' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Get the standard deviation of the values' },
+  { value: 'delta', label: 'Delta', description: 'Get the difference between the last and the first value' },
+  { value: 'increase', label: 'Increase', description: 'Get the increase of a counter, accounting for resets' },
+  {
+    value: 'rate',
+    label: 'Rate',
+    description: 'Get the per second increase of a counter between its first and last value',
+  },
+  { value: 'count_non_null', label: 'Count non-null', description: 'Get the number of non-null values' },
+  { value: 'p50', label: '50th percentile', description: 'Get the 50th percentile' },
+  { value: 'p75', label: '75th percentile', description: 'Get the 75th percentile' },
+  { value: 'p90', label: '90th percentile', description: 'Get the 90th percentile' },
+  { value: 'p95', label: '95th percentile', description: 'Get the 95th percentile' },
+  { value: 'p99', label: '99th percentile', description: 'Get the 99th percentile' },
+  { value: 'p99.9', label: '99.9th percentile', description: 'Get the 99.9th percentile' },
 ];
 
 export enum ReducerMode {
@@ -115,12 +131,25 @@ export const downsamplingTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.max, label: 'Max', description: 'Fill with the maximum value' },
   { value: ReducerID.mean, label: 'Mean', description: 'Fill with the average value' },
   { value: ReducerID.sum, label: 'Sum', description: 'Fill with the sum of all values' },
+  { value: 'median', label: 'Median', description: 'Fill with the median value' },
+  { value: 'first', label: 'First', description: 'Fill with the first value' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Fill with the standard deviation of the values' },
+  { value: 'increase', label: 'Increase', description: 'Fill with the increase of a counter' },
+  {
+    value: 'rate',
+    label: 'Rate',
+    description: 'Fill with the per second increase of a counter between its first and last value in the window',
+  },
+  { value: 'p95', label: '95th percentile', description: 'Fill with the 95th percentile' },
+  { value: 'p99', label: '99th percentile', description: 'Fill with the 99th percentile' },
 ];
 
 export const upsamplingTypes: Array<SelectableValue<string>> = [
   { value: 'pad', label: 'pad', description: 'fill with the last known value' },
   { value: 'backfilling', label: 'backfilling', description: 'fill with the next known value' },
   { value: 'fillna', label: 'fillna', description: 'Fill with NaNs' },
+  { value: 'linear', label: 'linear', description: 'Interpolate linearly between the surrounding values' },
+  { value: 'constant', label: 'constant', description: 'Fill with a constant value' },
 ];
 
 export const thresholdFunctions: Array<SelectableValue<EvalFunction>> = [
@@ -141,6 +170,8 @@ export interface ExpressionQuery extends DataQuery {
   window?: string;
   downsampler?: string;
   upsampler?: string;
+  fillValue?: number;
+  alignToWindow?: boolean;
   conditions?: ClassicCondition[];
   settings?: ExpressionQuerySettings;
 }
@@ -187,4 +218,14 @@ export type ReducerType =
   | 'diff_abs'
   | 'percent_diff'
   | 'percent_diff_abs'
-  | 'count_non_null';
+  | 'count_non_null'
+  | 'first'
+  | 'stddev'
+  | 'increase'
+  | 'rate'
+  | 'p50'
+  | 'p75'
+  | 'p90'
+  | 'p95'
+  | 'p99'
+  | 'p99.9';
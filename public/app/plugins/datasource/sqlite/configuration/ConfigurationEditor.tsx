@@ -0,0 +1,46 @@
+import { DataSourcePluginOptionsEditorProps, onUpdateDatasourceJsonDataOption } from '@grafana/data';
+import { ConfigSection, DataSourceDescription } from '@grafana/experimental';
+import { ConnectionLimits, Divider, useMigrateDatabaseFields } from '@grafana/sql';
+import { Field, Input } from '@grafana/ui';
+
+import { SQLiteOptions } from '../types';
+
+export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<SQLiteOptions>) => {
+  const { options, onOptionsChange } = props;
+
+  useMigrateDatabaseFields(props);
+
+  return (
+    <>
+      <DataSourceDescription
+        dataSourceName="SQLite"
+        docsLink="https://grafana.com/docs/grafana/latest/datasources/"
+        hasRequiredFields={true}
+      />
+
+      <Divider />
+
+      <ConfigSection title="Connection">
+        <Field
+          label="Database file"
+          description="Path of the SQLite database file on the Grafana server. The file is opened read-only, and must be in the allowed_paths of the [sqlite] configuration section."
+          required
+        >
+          <Input
+            width={60}
+            name="database"
+            value={options.jsonData.database || ''}
+            placeholder="/srv/sqlite/metrics.db"
+            onChange={onUpdateDatasourceJsonDataOption(props, 'database')}
+          />
+        </Field>
+      </ConfigSection>
+
+      <Divider />
+
+      <ConfigSection title="Additional settings">
+        <ConnectionLimits options={options} onOptionsChange={onOptionsChange} />
+      </ConfigSection>
+    </>
+  );
+};
@@ -0,0 +1,160 @@
+import { isEmpty } from 'lodash';
+
+import { DataSourceInstanceSettings, TimeRange } from '@grafana/data';
+import { LanguageDefinition } from '@grafana/experimental';
+import {
+  DB,
+  RAQBFieldTypes,
+  SQLQuery,
+  SQLSelectableValue,
+  SqlDatasource,
+  createSelectClause,
+  formatSQL,
+  haveColumns,
+} from '@grafana/sql';
+
+import { SQLiteOptions } from './types';
+
+// An SQLite data source queries a single database file, its main schema.
+const MAIN_DATASET = 'main';
+
+export class SQLiteDatasource extends SqlDatasource {
+  sqlLanguageDefinition: LanguageDefinition | undefined;
+
+  constructor(instanceSettings: DataSourceInstanceSettings<SQLiteOptions>) {
+    super(instanceSettings);
+  }
+
+  getQueryModel() {
+    return { quoteLiteral };
+  }
+
+  getSqlLanguageDefinition(): LanguageDefinition {
+    if (this.sqlLanguageDefinition !== undefined) {
+      return this.sqlLanguageDefinition;
+    }
+
+    this.sqlLanguageDefinition = {
+      id: 'sql',
+      formatter: formatSQL,
+    };
+
+    return this.sqlLanguageDefinition;
+  }
+
+  async fetchTables(): Promise<string[]> {
+    const tables = await this.runSql<string[]>(
+      "SELECT name FROM sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%' ORDER BY name",
+      { refId: 'tables' }
+    );
+    return tables.map((t) => quoteIdentifier(t[0]));
+  }
+
+  async fetchFields(query: Partial<SQLQuery>): Promise<SQLSelectableValue[]> {
+    if (!query.table) {
+      return [];
+    }
+    const frame = await this.runSql<string[]>(
+      `SELECT name, type FROM pragma_table_info(${quoteLiteral(unquoteIdentifier(query.table))}) ORDER BY cid`,
+      { refId: 'fields' }
+    );
+    return frame.map((f) => ({
+      name: f[0],
+      text: f[0],
+      value: quoteIdentifier(f[0]),
+      type: f[1],
+      label: f[0],
+      raqbFieldType: fieldType(f[1]),
+    }));
+  }
+
+  getDB(): DB {
+    if (this.db !== undefined) {
+      return this.db;
+    }
+
+    return {
+      datasets: () => Promise.resolve([MAIN_DATASET]),
+      tables: () => this.fetchTables(),
+      fields: (query: SQLQuery) => this.fetchFields(query),
+      validateQuery: (query: SQLQuery, _range?: TimeRange) =>
+        Promise.resolve({ query, error: '', isError: false, isValid: true }),
+      dsID: () => this.id,
+      toRawSql,
+      functions: () => ['TOTAL', 'GROUP_CONCAT'],
+      getEditorLanguageDefinition: () => this.getSqlLanguageDefinition(),
+    };
+  }
+}
+
+export function toRawSql({ sql, table }: SQLQuery): string {
+  let rawQuery = '';
+
+  // Return early with empty string if there is no sql column
+  if (!sql || !haveColumns(sql.columns)) {
+    return rawQuery;
+  }
+
+  rawQuery += createSelectClause(sql.columns);
+
+  if (table) {
+    rawQuery += `FROM ${table} `;
+  }
+
+  if (sql.whereString) {
+    rawQuery += `WHERE ${sql.whereString} `;
+  }
+
+  if (sql.groupBy?.[0]?.property.name) {
+    const groupBy = sql.groupBy.map((g) => g.property.name).filter((g) => !isEmpty(g));
+    rawQuery += `GROUP BY ${groupBy.join(', ')} `;
+  }
+
+  if (sql.orderBy?.property.name) {
+    rawQuery += `ORDER BY ${sql.orderBy.property.name} `;
+  }
+
+  if (sql.orderBy?.property.name && sql.orderByDirection) {
+    rawQuery += `${sql.orderByDirection} `;
+  }
+
+  if (sql.limit !== undefined && sql.limit >= 0) {
+    rawQuery += `LIMIT ${sql.limit} `;
+  }
+  return rawQuery;
+}
+
+export function quoteLiteral(value: string) {
+  return "'" + value.replace(/'/g, "''") + "'";
+}
+
+// Puts double quotes around the identifier if it is necessary.
+export function quoteIdentifier(value: string) {
+  return /^[a-zA-Z_][a-zA-Z0-9_]*$/.test(value) ? value : `"${value.replace(/"/g, '""')}"`;
+}
+
+export function unquoteIdentifier(value: string) {
+  if (value[0] === '"' && value[value.length - 1] === '"') {
+    return value.substring(1, value.length - 1).replace(/""/g, '"');
+  }
+  return value;
+}
+
+// fieldType maps a declared column type to a query builder type following the
+// SQLite type affinity rules.
+function fieldType(declaredType?: string): RAQBFieldTypes {
+  const type = declaredType?.toUpperCase() ?? '';
+  if (type === 'DATE') {
+    return 'date';
+  }
+  if (type.includes('DATETIME') || type.includes('TIMESTAMP')) {
+    return 'datetime';
+  }
+  if (type.includes('BOOL')) {
+    return 'boolean';
+  }
+  if (['INT', 'REAL', 'FLOA', 'DOUB', 'NUM', 'DEC'].some((affinity) => type.includes(affinity))) {
+    return 'number';
+  }
+  return 'text';
+}